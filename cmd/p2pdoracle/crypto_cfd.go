@@ -0,0 +1,17 @@
+// +build cgo
+
+package main
+
+import (
+	"p2pderivatives-oracle/internal/cfddlccrypto"
+	"p2pderivatives-oracle/internal/dlccrypto"
+	"p2pderivatives-oracle/internal/godlccrypto"
+)
+
+// newCryptoService returns the crypto service implemented by the given backend
+func newCryptoService(backend string) (dlccrypto.CryptoService, error) {
+	if backend == goCryptoBackend {
+		return godlccrypto.NewGoCryptoService(), nil
+	}
+	return cfddlccrypto.NewCfdgoCryptoService(), nil
+}
@@ -0,0 +1,19 @@
+// +build !cgo
+
+package main
+
+import (
+	"p2pderivatives-oracle/internal/dlccrypto"
+	"p2pderivatives-oracle/internal/godlccrypto"
+
+	"github.com/pkg/errors"
+)
+
+// newCryptoService returns the crypto service implemented by the given backend,
+// the cfd backend being unavailable in binaries built without cgo
+func newCryptoService(backend string) (dlccrypto.CryptoService, error) {
+	if backend == cfdCryptoBackend {
+		return nil, errors.New("The cfd crypto backend requires a build with cgo enabled, use the go backend instead")
+	}
+	return godlccrypto.NewGoCryptoService(), nil
+}
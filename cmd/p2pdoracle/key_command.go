@@ -0,0 +1,231 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"p2pderivatives-oracle/internal/dlccrypto"
+	"p2pderivatives-oracle/internal/godlccrypto"
+	"p2pderivatives-oracle/internal/threshold"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/cryptogarageinc/server-common-go/pkg/utils/file"
+	"github.com/pkg/errors"
+)
+
+// keyCommand name of the subcommand managing the oracle key files,
+// run with `p2pdoracle key <subcommand> [flags]` (without configuration)
+const keyCommand = "key"
+
+const keyCommandUsage = `Usage: p2pdoracle key <subcommand> [flags]
+
+Subcommands:
+  generate   generate a new oracle key in an encrypted PKCS#8 file
+  encrypt    encrypt an unencrypted key (SEC1 or PKCS#8) in a new encrypted PKCS#8 file
+  reencrypt  re-encrypt an encrypted key (any supported format) with a new password in a new encrypted PKCS#8 file
+  inspect    print the format and encryption of a key file (and its public key if it can be read)
+  pubkey     print the schnorr public key of a key
+  split      split a key in encrypted key shares of the participants of a threshold oracle
+
+Run p2pdoracle key <subcommand> -h for the flags of a subcommand.
+`
+
+// runKeyCommand runs a key management subcommand, writing its output to out
+func runKeyCommand(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(out, keyCommandUsage)
+		return errors.New("No key subcommand specified")
+	}
+
+	// the public keys are computed in pure go so that the command is available without cgo
+	cryptoService := godlccrypto.NewGoCryptoService()
+	flags := flag.NewFlagSet(keyCommand+" "+args[0], flag.ContinueOnError)
+	flags.SetOutput(out)
+
+	switch args[0] {
+	case "generate":
+		outPath := flags.String("out", "", "Path of the key file to create.")
+		passFile := flags.String("pass-file", "", "Path to the file containing the password encrypting the key (first line).")
+		kdf := flags.String("kdf", dlccrypto.KDFScrypt, "Key derivation function of the password, either scrypt or pbkdf2.")
+		if err := parseKeyCommandFlags(flags, args[1:], "out", "pass-file"); err != nil {
+			return err
+		}
+		privateKey, publicKey, err := cryptoService.GenerateSchnorrKeyPair()
+		if err != nil {
+			return err
+		}
+		defer privateKey.Zero()
+		if err := writeKeyFile(*outPath, privateKey, *passFile, *kdf); err != nil {
+			return err
+		}
+		fmt.Fprintln(out, publicKey.EncodeToString())
+	case "encrypt":
+		inPath := flags.String("in", "", "Path of the unencrypted key file.")
+		outPath := flags.String("out", "", "Path of the encrypted key file to create.")
+		passFile := flags.String("pass-file", "", "Path to the file containing the password encrypting the key (first line).")
+		kdf := flags.String("kdf", dlccrypto.KDFScrypt, "Key derivation function of the password, either scrypt or pbkdf2.")
+		if err := parseKeyCommandFlags(flags, args[1:], "in", "out", "pass-file"); err != nil {
+			return err
+		}
+		privateKey, err := readKeyFile(*inPath, "")
+		if err != nil {
+			return err
+		}
+		defer privateKey.Zero()
+		return writeKeyFile(*outPath, privateKey, *passFile, *kdf)
+	case "reencrypt":
+		inPath := flags.String("in", "", "Path of the encrypted key file.")
+		passFile := flags.String("pass-file", "", "Path to the file containing the current password of the key (first line).")
+		outPath := flags.String("out", "", "Path of the re-encrypted key file to create.")
+		newPassFile := flags.String("new-pass-file", "", "Path to the file containing the new password of the key (first line).")
+		kdf := flags.String("kdf", dlccrypto.KDFScrypt, "Key derivation function of the new password, either scrypt or pbkdf2.")
+		if err := parseKeyCommandFlags(flags, args[1:], "in", "pass-file", "out", "new-pass-file"); err != nil {
+			return err
+		}
+		privateKey, err := readKeyFile(*inPath, *passFile)
+		if err != nil {
+			return err
+		}
+		defer privateKey.Zero()
+		return writeKeyFile(*outPath, privateKey, *newPassFile, *kdf)
+	case "inspect":
+		inPath := flags.String("in", "", "Path of the key file.")
+		passFile := flags.String("pass-file", "", "Path to the file containing the password of the key (first line), to print its public key.")
+		if err := parseKeyCommandFlags(flags, args[1:], "in"); err != nil {
+			return err
+		}
+		content, err := ioutil.ReadFile(*inPath)
+		if err != nil {
+			return err
+		}
+		info, err := dlccrypto.InspectPemKey(content)
+		if err != nil {
+			return err
+		}
+		encryption := info.Encryption
+		if !info.IsEncrypted() {
+			encryption = "none"
+		}
+		fmt.Fprintf(out, "format: %s\nencryption: %s\n", info.Format, encryption)
+		if info.IsEncrypted() && *passFile == "" {
+			return nil
+		}
+		publicKey, err := readPublicKey(cryptoService, *inPath, *passFile)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "public key: %s\n", publicKey.EncodeToString())
+	case "pubkey":
+		inPath := flags.String("in", "", "Path of the key file.")
+		passFile := flags.String("pass-file", "", "Path to the file containing the password of the key (first line), if it is encrypted.")
+		if err := parseKeyCommandFlags(flags, args[1:], "in"); err != nil {
+			return err
+		}
+		publicKey, err := readPublicKey(cryptoService, *inPath, *passFile)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, publicKey.EncodeToString())
+	case "split":
+		inPath := flags.String("in", "", "Path of the key file to split.")
+		passFile := flags.String("pass-file", "", "Path to the file containing the password of the key (first line), if it is encrypted.")
+		thresholdFlag := flags.Int("threshold", 0, "Number of participants required to sign (more than half of the participants).")
+		participants := flags.Int("participants", 0, "Number of participants.")
+		outDir := flags.String("out-dir", "", "Directory in which the share_<index>.pem files are created.")
+		sharePassFiles := flags.String("share-pass-files", "",
+			"Comma separated paths to the files containing the password encrypting the share of each participant (first line).")
+		kdf := flags.String("kdf", dlccrypto.KDFScrypt, "Key derivation function of the passwords, either scrypt or pbkdf2.")
+		if err := parseKeyCommandFlags(flags, args[1:], "in", "out-dir", "share-pass-files"); err != nil {
+			return err
+		}
+		passFiles := strings.Split(*sharePassFiles, ",")
+		if len(passFiles) != *participants {
+			return errors.Errorf("%d share password files are required, got %d", *participants, len(passFiles))
+		}
+		privateKey, err := readKeyFile(*inPath, *passFile)
+		if err != nil {
+			return err
+		}
+		defer privateKey.Zero()
+		shares, err := threshold.Split(privateKey, *thresholdFlag, *participants)
+		if err != nil {
+			return err
+		}
+		for i, share := range shares {
+			defer share.Zero()
+			pass, err := readPassFile(passFiles[i])
+			if err != nil {
+				return err
+			}
+			if pass == nil {
+				return errors.Errorf("No password file for the share of participant %d", share.Index)
+			}
+			sharePath := filepath.Join(*outDir, "share_"+strconv.Itoa(share.Index)+".pem")
+			if err := threshold.WriteKeyShareFile(sharePath, share, pass, *kdf); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintln(out, shares[0].PublicKey().EncodeToString())
+	default:
+		fmt.Fprint(out, keyCommandUsage)
+		return errors.Errorf("Unknown key subcommand %s", args[0])
+	}
+	return nil
+}
+
+// parseKeyCommandFlags parses the flags of a subcommand, checking that the required ones are set
+func parseKeyCommandFlags(flags *flag.FlagSet, args []string, required ...string) error {
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	for _, name := range required {
+		if flags.Lookup(name).Value.String() == "" {
+			flags.Usage()
+			return errors.Errorf("Flag -%s is required", name)
+		}
+	}
+	return nil
+}
+
+// readPassFile returns the password from the first line of the file (nil if no file is given)
+func readPassFile(passFile string) ([]byte, error) {
+	if passFile == "" {
+		return nil, nil
+	}
+	pass, err := file.ReadFirstLineFromFile(passFile)
+	if err != nil {
+		return nil, err
+	}
+	if pass == "" {
+		return nil, errors.Errorf("Could not read password from %s", passFile)
+	}
+	return []byte(pass), nil
+}
+
+func readKeyFile(keyFile string, passFile string) (*dlccrypto.PrivateKey, error) {
+	pass, err := readPassFile(passFile)
+	if err != nil {
+		return nil, err
+	}
+	return dlccrypto.ReadPemKeyFile(keyFile, pass)
+}
+
+func writeKeyFile(keyFile string, privateKey *dlccrypto.PrivateKey, passFile string, kdf string) error {
+	pass, err := readPassFile(passFile)
+	if err != nil {
+		return err
+	}
+	return dlccrypto.WritePemKeyFile(keyFile, privateKey, pass, kdf)
+}
+
+func readPublicKey(cryptoService dlccrypto.CryptoService, keyFile string, passFile string) (*dlccrypto.SchnorrPublicKey, error) {
+	privateKey, err := readKeyFile(keyFile, passFile)
+	if err != nil {
+		return nil, err
+	}
+	defer privateKey.Zero()
+	return cryptoService.SchnorrPublicKeyFromPrivateKey(privateKey)
+}
@@ -8,11 +8,16 @@ import (
 	"os"
 	"os/signal"
 	"p2pderivatives-oracle/internal/api"
-	"p2pderivatives-oracle/internal/cfddlccrypto"
 	"p2pderivatives-oracle/internal/cryptocompare"
 	"p2pderivatives-oracle/internal/database/entity"
 	"p2pderivatives-oracle/internal/datafeed"
+	"p2pderivatives-oracle/internal/dlccrypto"
+	"p2pderivatives-oracle/internal/envelope"
+	"p2pderivatives-oracle/internal/exchange"
+	"p2pderivatives-oracle/internal/hsm"
+	"p2pderivatives-oracle/internal/lock"
 	"p2pderivatives-oracle/internal/oracle"
+	"p2pderivatives-oracle/internal/threshold"
 	"syscall"
 	"time"
 
@@ -20,6 +25,7 @@ import (
 	"github.com/cryptogarageinc/server-common-go/pkg/database/orm"
 	"github.com/cryptogarageinc/server-common-go/pkg/log"
 	"github.com/cryptogarageinc/server-common-go/pkg/rest/router"
+	"github.com/pkg/errors"
 	"gorm.io/gorm/clause"
 )
 
@@ -28,6 +34,7 @@ var (
 	appName    = flag.String("appname", "", "The name of the application. Will be use as a prefix for environment variables.")
 	envname    = flag.String("e", "", "environment (ex., \"development\"). Should match with the name of the configuration file.")
 	migrate    = flag.Bool("migrate", false, "If set performs a db migration before starting.")
+	rewrap     = flag.Bool("rewrap-kvalues", false, "If set re-encrypts the stored kvalues with the active master key and exits (without starting the server).")
 )
 
 // Config contains the configuration parameters for the server.
@@ -38,9 +45,25 @@ type Config struct {
 	KeyFile  string `configkey:"server.keyfile" validate:"required_with=TLS"`
 }
 
+// CryptoConfig contains the configuration of the crypto service.
+type CryptoConfig struct {
+	// Backend implementation of the crypto service, either cfd (cfd-go through cgo) or go (pure Go)
+	Backend string `configkey:"crypto.backend" validate:"oneof=cfd go" default:"cfd"`
+}
+
+const (
+	cfdCryptoBackend = "cfd"
+	goCryptoBackend  = "go"
+)
+
 func init() {
 	flag.Parse()
 
+	// the key management subcommand does not use the configuration
+	if flag.Arg(0) == keyCommand {
+		return
+	}
+
 	if *configPath == "" {
 		stdlog.Fatal("No configuration path specified")
 	}
@@ -55,6 +78,12 @@ func init() {
 }
 
 func main() {
+	if flag.Arg(0) == keyCommand {
+		if err := runKeyCommand(flag.Args()[1:], os.Stdout); err != nil {
+			stdlog.Fatal(err)
+		}
+		return
+	}
 
 	config := conf.NewConfiguration(*appName, *envname, []string{*configPath})
 	err := config.Initialize()
@@ -66,15 +95,66 @@ func main() {
 	logInstance := newInitializedLog(config)
 	log := logInstance.Logger
 
+	if *rewrap {
+		if err := doRewrapKvalues(config, logInstance); err != nil {
+			log.Fatalf("Could not re-encrypt the stored kvalues %v", err)
+		}
+		logInstance.Finalize()
+		return
+	}
+
+	// a participant of a threshold oracle only serves the requests of the coordinator
+	participantConfig := &threshold.ParticipantServerConfig{}
+	config.InitializeComponentConfig(participantConfig)
+	if participantConfig.Enabled {
+		participantAPI := NewParticipantAPI(logInstance, config, participantConfig)
+		routerInstance := newInitializedRouter(logInstance, participantAPI)
+		log.Info("Starting threshold participant")
+		serve(logInstance, config, routerInstance.GetEngine(), nil)
+		routerInstance.Finalize()
+		log.Println("Server exiting")
+		logInstance.Finalize()
+		return
+	}
+
 	// Initialize Router
-	routerInstance := newInitializedRouter(logInstance, config)
+	oracleAPI := NewDefaultOracleAPI(logInstance, config)
+	routerInstance := newInitializedRouter(logInstance, oracleAPI)
+
+	// Start the scheduler creating announcements and attestations in the background
+	schedulerConfig := &api.SchedulerConfig{}
+	if err := config.InitializeComponentConfig(schedulerConfig); err != nil {
+		log.Fatalf("Could not read scheduler configuration %v", err)
+	}
+	scheduler := oracleAPI.NewScheduler(schedulerConfig)
+	if schedulerConfig.Enabled {
+		log.Info("Starting scheduler")
+		scheduler.Start()
+	}
+
+	serve(logInstance, config, routerInstance.GetEngine(), oracleAPI.CloseStreams)
+
+	scheduler.Stop()
+	routerInstance.Finalize()
+	log.Println("Server exiting")
+	logInstance.Finalize()
+}
 
+// serve serves the requests with the handler until an interrupt signal is received,
+// and then shuts down the server gracefully
+// serve runs the server until an interrupt signal is received, onShutdown (if not nil) being called when shutting down
+// to end the long running requests (which are not cancelled by the graceful shutdown)
+func serve(logInstance *log.Log, config *conf.Configuration, handler http.Handler, onShutdown func()) {
+	log := logInstance.Logger
 	serverConfig := &Config{}
 	config.InitializeComponentConfig(serverConfig)
 
 	srv := &http.Server{
 		Addr:    serverConfig.Address,
-		Handler: routerInstance.GetEngine(),
+		Handler: handler,
+	}
+	if onShutdown != nil {
+		srv.RegisterOnShutdown(onShutdown)
 	}
 
 	listenAndServe := func() error {
@@ -105,7 +185,7 @@ func main() {
 
 	// Wait for interrupt signal to gracefully shutdown the server with
 	// a timeout of 5 seconds.
-	quit := make(chan os.Signal)
+	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("Shuting down server...")
@@ -114,13 +194,10 @@ func main() {
 	// the request it is currently handling
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
+	// the resources are released by the caller even if some requests did not finish in time
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+		log.Errorf("Server forced to shutdown: %v", err)
 	}
-
-	routerInstance.Finalize()
-	log.Println("Server exiting")
-	logInstance.Finalize()
 }
 
 func newInitializedLog(config *conf.Configuration) *log.Log {
@@ -131,7 +208,7 @@ func newInitializedLog(config *conf.Configuration) *log.Log {
 	return logger
 }
 
-func newInitializedOrm(config *conf.Configuration, log *log.Log) *orm.ORM {
+func newInitializedOrm(config *conf.Configuration, log *log.Log, apiConfig *api.Config, oracleInstance *oracle.Oracle) *orm.ORM {
 	ormConfig := &orm.Config{}
 	if err := config.InitializeComponentConfig(ormConfig); err != nil {
 		panic(err)
@@ -144,7 +221,7 @@ func newInitializedOrm(config *conf.Configuration, log *log.Log) *orm.ORM {
 	}
 
 	if *migrate {
-		if err := doMigration(ormInstance); err != nil {
+		if err := doMigration(ormInstance, apiConfig, oracleInstance); err != nil {
 			log.Logger.Fatalf("Could not apply migrations")
 			panic(err)
 		}
@@ -153,8 +230,7 @@ func newInitializedOrm(config *conf.Configuration, log *log.Log) *orm.ORM {
 	return ormInstance
 }
 
-func newInitializedRouter(log *log.Log, config *conf.Configuration) *router.Router {
-	api := NewDefaultOracleAPI(log, config)
+func newInitializedRouter(log *log.Log, api router.API) *router.Router {
 	routerInstance := router.NewRouter(log, api)
 	err := routerInstance.Initialize()
 
@@ -165,49 +241,363 @@ func newInitializedRouter(log *log.Log, config *conf.Configuration) *router.Rout
 	return routerInstance
 }
 
-// NewDefaultOracleAPI returns a router.API with default crypto, database and datafeed services
-func NewDefaultOracleAPI(l *log.Log, config *conf.Configuration) router.API {
+// NewDefaultOracleAPI returns an OracleAPI with default crypto, database and datafeed services
+func NewDefaultOracleAPI(l *log.Log, config *conf.Configuration) *api.OracleAPI {
 	// Setup crypto service
-	cryptoInstance := cfddlccrypto.NewCfdgoCryptoService()
+	cryptoConfig := &CryptoConfig{}
+	if err := config.InitializeComponentConfig(cryptoConfig); err != nil {
+		l.Logger.Fatalf("Invalid crypto configuration %v", err)
+		panic(err)
+	}
+	cryptoInstance, err := newCryptoService(cryptoConfig.Backend)
+	if err != nil {
+		l.Logger.Fatalf("Could not create crypto service %v", err)
+		panic(err)
+	}
 
 	// Setup Oracle
-	oracleConfig := &oracle.Config{}
-	config.InitializeComponentConfig(oracleConfig)
-	oracleInstance, err := oracle.FromConfig(oracleConfig, cryptoInstance)
+	oracleInstance, err := newOracle(config, cryptoInstance)
 	if err != nil {
 		l.Logger.Fatalf("Could not create a oracle instance %v", err)
 		panic(err)
 	}
+	oracleInstance.KvalueKeyring, err = newKvalueKeyring(config)
+	if err != nil {
+		l.Logger.Fatalf("Invalid kvalues configuration %v", err)
+		panic(err)
+	}
+
+	apiConfig := &api.Config{}
+	err = config.InitializeComponentConfig(apiConfig)
+	if err != nil {
+		panic(err)
+	}
+	settlementConfig := &datafeed.SettlementConfig{}
+	if err := config.Sub("datafeed").InitializeComponentConfig(settlementConfig); err != nil {
+		l.Logger.Fatalf("Invalid datafeed settlement configuration %v", err)
+		panic(err)
+	}
+	if err := apiConfig.Validate(settlementConfig); err != nil {
+		l.Logger.Fatalf("Invalid api configuration %v", err)
+		panic(err)
+	}
 
 	// Setup orm service
-	ormInstance := newInitializedOrm(config, l)
+	ormInstance := newInitializedOrm(config, l, apiConfig, oracleInstance)
 
 	// Setup DataFeed service
-	var feedInstance datafeed.DataFeed
+	feedInstance := newInitializedDataFeed(l, config)
+
+	// Setup the locks shared with the other oracle processes
+	lockConfig := &lock.Config{}
+	if err := config.InitializeComponentConfig(lockConfig); err != nil {
+		l.Logger.Fatalf("Invalid lock configuration %v", err)
+		panic(err)
+	}
+	locker, err := lock.NewLocker(lockConfig, ormInstance.GetDB())
+	if err != nil {
+		l.Logger.Fatalf("Could not create the locker %v", err)
+		panic(err)
+	}
+
+	oracleAPI := api.NewOracleAPI(apiConfig, l, oracleInstance, ormInstance, cryptoInstance, feedInstance)
+	oracleAPI.SetLocker(locker)
+	return oracleAPI
+}
+
+// newOracle returns the oracle signing either with its key files or with the participants of a threshold oracle
+func newOracle(config *conf.Configuration, cryptoService dlccrypto.CryptoService) (*oracle.Oracle, error) {
+	thresholdConfig := &threshold.Config{}
+	if err := config.InitializeComponentConfig(thresholdConfig); err != nil {
+		return nil, err
+	}
+	if thresholdConfig.Enabled {
+		signer, err := threshold.NewSigner(thresholdConfig)
+		if err != nil {
+			return nil, err
+		}
+		return oracle.New(signer), nil
+	}
+
+	hsmConfig := &hsm.Config{}
+	if err := config.InitializeComponentConfig(hsmConfig); err != nil {
+		return nil, err
+	}
+	if hsmConfig.Enabled {
+		signer, err := hsm.NewSignerFromConfig(hsmConfig, cryptoService)
+		if err != nil {
+			return nil, err
+		}
+		return oracle.New(signer), nil
+	}
+
+	oracleConfig := &oracle.Config{}
+	config.InitializeComponentConfig(oracleConfig)
+	return oracle.FromConfig(oracleConfig, cryptoService)
+}
+
+// NewParticipantAPI returns the api of a participant of a threshold oracle, checking what it signs
+// against the api configuration and its own datafeed
+func NewParticipantAPI(l *log.Log, config *conf.Configuration, participantConfig *threshold.ParticipantServerConfig) *threshold.ParticipantAPI {
+	share, err := participantConfig.ReadKeyShare()
+	if err != nil {
+		l.Logger.Fatalf("Could not read key share %v", err)
+		panic(err)
+	}
+	secret, err := threshold.ReadSecret(participantConfig.Secret, participantConfig.SecretFile)
+	if err != nil {
+		l.Logger.Fatalf("Invalid participant secret %v", err)
+		panic(err)
+	}
+
+	apiConfig := &api.Config{}
+	err = config.InitializeComponentConfig(apiConfig)
+	if err != nil {
+		panic(err)
+	}
+	settlementConfig := &datafeed.SettlementConfig{}
+	if err := config.Sub("datafeed").InitializeComponentConfig(settlementConfig); err != nil {
+		l.Logger.Fatalf("Invalid datafeed settlement configuration %v", err)
+		panic(err)
+	}
+	if err := apiConfig.Validate(settlementConfig); err != nil {
+		l.Logger.Fatalf("Invalid api configuration %v", err)
+		panic(err)
+	}
+
+	ormConfig := &orm.Config{}
+	if err := config.InitializeComponentConfig(ormConfig); err != nil {
+		panic(err)
+	}
+	ormInstance := orm.NewORM(ormConfig, l)
+	if err := ormInstance.Initialize(); err != nil {
+		panic("Could not initialize database.")
+	}
+	if *migrate {
+		if err := ormInstance.GetDB().AutoMigrate(&entity.ThresholdNonce{}); err != nil {
+			l.Logger.Fatalf("Could not apply migrations")
+			panic(err)
+		}
+	}
+
+	feedInstance := newInitializedDataFeed(l, config)
+	participant := threshold.NewParticipant(share, api.NewEventVerifier(apiConfig, feedInstance))
+	return threshold.NewParticipantAPI(l, participant, ormInstance, secret)
+}
+
+// newInitializedDataFeed returns the configured datafeed, resolving enum events from the price of an asset
+func newInitializedDataFeed(l *log.Log, config *conf.Configuration) datafeed.DataFeed {
 	datafeedConfig := config.Sub("datafeed")
-	dummyFeedConfig := &datafeed.DummyConfig{}
-	err = datafeedConfig.InitializeComponentConfig(dummyFeedConfig)
+	feedInstance, err := newDataFeed(l, datafeedConfig)
 	if err != nil {
+		l.Logger.Fatalf("Could not create datafeed %v", err)
+		panic(err)
+	}
+	// enum events can be resolved from the price of an asset
+	strikeConfig := &datafeed.StrikeConfig{}
+	datafeedConfig.InitializeComponentConfig(strikeConfig)
+	return datafeed.NewStrikeOutcomeFeed(feedInstance, strikeConfig)
+}
+
+// newDataFeed returns the datafeed of the configured type, either the dummy one, the aggregation of
+// the configured sources, the file one or one of the price sources (cryptocompare by default)
+func newDataFeed(l *log.Log, datafeedConfig *conf.Configuration) (datafeed.DataFeed, error) {
+	config := &datafeed.Config{}
+	if err := datafeedConfig.InitializeComponentConfig(config); err != nil {
+		return nil, err
+	}
+	feedType := config.Type
+	if feedType == "" {
+		feedType = detectDataFeedType(datafeedConfig)
+	}
+
+	switch feedType {
+	case datafeed.TypeDummy:
+		dummyFeedConfig := &datafeed.DummyConfig{}
+		if err := datafeedConfig.InitializeComponentConfig(dummyFeedConfig); err != nil {
+			return nil, err
+		}
+		return datafeed.NewDummyDataFeed(dummyFeedConfig), nil
+	case datafeed.TypeAggregator:
+		aggregatorConfig := datafeedConfig.Sub("aggregator")
+		if aggregatorConfig == nil {
+			return nil, errors.New("Missing datafeed aggregator configuration")
+		}
+		config := &datafeed.AggregatorConfig{}
+		if err := aggregatorConfig.InitializeComponentConfig(config); err != nil {
+			return nil, err
+		}
+		sources := make(map[string]datafeed.AssetPriceFeed, len(config.Sources))
+		for _, name := range config.Sources {
+			source, err := newPriceSource(l, datafeedConfig, name)
+			if err != nil {
+				return nil, err
+			}
+			sources[name] = source
+		}
+		return datafeed.NewAggregatedDataFeed(l, sources, config), nil
+	case datafeed.TypeFile:
+		config := &datafeed.FileConfig{}
+		if err := datafeedConfig.InitializeComponentConfig(config); err != nil {
+			return nil, err
+		}
+		return datafeed.NewFileDataFeed(l, config)
+	default:
+		return newPriceSource(l, datafeedConfig, feedType)
+	}
+}
+
+// detectDataFeedType returns the type of datafeed used by the configurations without datafeed type:
+// the dummy datafeed if configured, then the aggregator if configured, cryptocompare otherwise
+func detectDataFeedType(datafeedConfig *conf.Configuration) string {
+	if err := datafeedConfig.InitializeComponentConfig(&datafeed.DummyConfig{}); err == nil {
+		return datafeed.TypeDummy
+	}
+	if datafeedConfig.Sub("aggregator") != nil {
+		return datafeed.TypeAggregator
+	}
+	return cryptocompare.Source
+}
+
+// priceSourceFactory creates a price source from the datafeed configuration
+type priceSourceFactory func(l *log.Log, datafeedConfig *conf.Configuration) (datafeed.DataFeed, error)
+
+// priceSources registry of the price sources, by name
+var priceSources = map[string]priceSourceFactory{
+	cryptocompare.Source: func(l *log.Log, datafeedConfig *conf.Configuration) (datafeed.DataFeed, error) {
 		ccFeedConfig := &cryptocompare.Config{}
 		datafeedConfig.InitializeComponentConfig(ccFeedConfig)
 		cryptoCompareClient := cryptocompare.NewClient(l, ccFeedConfig)
 		cryptoCompareClient.Initialize()
-		feedInstance = cryptoCompareClient
-	} else {
-		feedInstance = datafeed.NewDummyDataFeed(dummyFeedConfig)
+		return cryptoCompareClient, nil
+	},
+	exchange.KrakenSource: func(l *log.Log, datafeedConfig *conf.Configuration) (datafeed.DataFeed, error) {
+		config := &exchange.KrakenConfig{}
+		if err := datafeedConfig.InitializeComponentConfig(config); err != nil {
+			return nil, err
+		}
+		return exchange.NewKrakenClient(l, config), nil
+	},
+	exchange.BitstampSource: func(l *log.Log, datafeedConfig *conf.Configuration) (datafeed.DataFeed, error) {
+		config := &exchange.BitstampConfig{}
+		if err := datafeedConfig.InitializeComponentConfig(config); err != nil {
+			return nil, err
+		}
+		return exchange.NewBitstampClient(l, config), nil
+	},
+	exchange.CoinbaseSource: func(l *log.Log, datafeedConfig *conf.Configuration) (datafeed.DataFeed, error) {
+		config := &exchange.CoinbaseConfig{}
+		if err := datafeedConfig.InitializeComponentConfig(config); err != nil {
+			return nil, err
+		}
+		return exchange.NewCoinbaseClient(l, config), nil
+	},
+	exchange.BinanceSource: func(l *log.Log, datafeedConfig *conf.Configuration) (datafeed.DataFeed, error) {
+		config := &exchange.BinanceConfig{}
+		if err := datafeedConfig.InitializeComponentConfig(config); err != nil {
+			return nil, err
+		}
+		return exchange.NewBinanceClient(l, config), nil
+	},
+}
+
+// newPriceSource returns the datafeed corresponding to the given source name, with the timeout,
+// retries and circuit breaker of the resilience configuration, settling the past prices on
+// the reference of the settlement configuration
+func newPriceSource(l *log.Log, datafeedConfig *conf.Configuration, name string) (datafeed.DataFeed, error) {
+	factory, ok := priceSources[name]
+	if !ok {
+		return nil, errors.Errorf("Unknown datafeed source %s", name)
+	}
+	source, err := factory(l, datafeedConfig)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "Could not create datafeed source %s", name)
 	}
+	resilienceConfig := &datafeed.ResilienceConfig{}
+	if err := datafeedConfig.InitializeComponentConfig(resilienceConfig); err != nil {
+		return nil, err
+	}
+	settlementConfig := &datafeed.SettlementConfig{}
+	if err := datafeedConfig.InitializeComponentConfig(settlementConfig); err != nil {
+		return nil, err
+	}
+	source = datafeed.NewResilientDataFeed(l, name, source, resilienceConfig)
+	return datafeed.NewSettlementDataFeed(l, name, source, settlementConfig), nil
+}
 
-	apiConfig := &api.Config{}
-	err = config.InitializeComponentConfig(apiConfig)
+// newKvalueKeyring returns the keyring encrypting the kvalues stored in database
+func newKvalueKeyring(config *conf.Configuration) (*envelope.Keyring, error) {
+	keyringConfig := &envelope.Config{}
+	if err := config.InitializeComponentConfig(keyringConfig); err != nil {
+		return nil, err
+	}
+	return envelope.FromConfig(keyringConfig)
+}
+
+// doRewrapKvalues re-encrypts the data keys of the stored kvalues with the active master key,
+// so that the previous master keys can be removed from the configuration
+func doRewrapKvalues(config *conf.Configuration, l *log.Log) error {
+	keyring, err := newKvalueKeyring(config)
 	if err != nil {
-		panic(err)
+		return err
+	}
+	ormConfig := &orm.Config{}
+	if err := config.InitializeComponentConfig(ormConfig); err != nil {
+		return err
+	}
+	ormInstance := orm.NewORM(ormConfig, l)
+	if err := ormInstance.Initialize(); err != nil {
+		return err
 	}
-	return api.NewOracleAPI(apiConfig, l, oracleInstance, ormInstance, cryptoInstance, feedInstance)
+	defer ormInstance.Finalize()
+
+	db := ormInstance.GetDB()
+	nb, err := entity.EncryptStoredKvalues(db, keyring)
+	if err != nil {
+		return err
+	}
+	l.Logger.Infof("Encrypted the plaintext kvalues of %d events", nb)
+	nb, err = entity.RewrapStoredKvalues(db, keyring)
+	if err != nil {
+		return err
+	}
+	l.Logger.Infof("Re-encrypted the kvalues of %d events with master key %s", nb, keyring.ActiveMasterKeyID())
+	return nil
 }
 
-func doMigration(o *orm.ORM) error {
+func doMigration(o *orm.ORM, apiConfig *api.Config, oracleInstance *oracle.Oracle) error {
 	db := o.GetDB()
-	err := db.AutoMigrate(&entity.Asset{}, &entity.EventData{})
+	err := db.AutoMigrate(&entity.Asset{}, &entity.EventData{}, &entity.PriceProvenance{}, &entity.NonceSignature{}, &entity.StreamEntry{})
+	if err != nil {
+		return err
+	}
+
+	// kvalues are only kept for the events created before the nonces were derived and not signed yet
+	_, err = entity.MigrateDerivedNonces(db)
+	if err != nil {
+		return err
+	}
+
+	// kvalues must not be stored in plaintext
+	_, err = entity.EncryptStoredKvalues(db, oracleInstance.KvalueKeyring)
+	if err != nil {
+		return err
+	}
+
+	// events created before the event ID was stored keep the ID without separator that they were announced with
+	_, err = entity.MigrateEventIDs(db)
+	if err != nil {
+		return err
+	}
+
+	// the nonces of the events signed before the nonces were recorded must never sign other values
+	_, err = entity.MigrateNonceSignatures(db)
+	if err != nil {
+		return err
+	}
+
+	// events created before the oracle key was recorded were announced with the first oracle key
+	_, err = entity.MigrateOraclePublicKeys(db, oracleInstance.Keys[0].PublicKey.EncodeToString())
 	if err != nil {
 		return err
 	}
@@ -218,6 +608,16 @@ func doMigration(o *orm.ORM) error {
 	}
 
 	err = db.Clauses(clause.OnConflict{DoNothing: true}).Create(&entity.Asset{AssetID: "btcjpy", Description: "BTC JPY"}).Error
+	if err != nil {
+		return err
+	}
+
+	for assetID := range apiConfig.EnumAssetConfigs {
+		err = db.Clauses(clause.OnConflict{DoNothing: true}).Create(&entity.Asset{AssetID: assetID, Description: assetID}).Error
+		if err != nil {
+			return err
+		}
+	}
 
-	return err
+	return nil
 }
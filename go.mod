@@ -6,14 +6,17 @@ require (
 	github.com/Bose/go-gin-logrus v1.0.3
 	github.com/cryptogarageinc/cfd-go v0.2.3
 	github.com/cryptogarageinc/server-common-go v1.1.3
+	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.0.1
 	github.com/gin-gonic/gin v1.7.2
 	github.com/go-resty/resty/v2 v2.2.0
 	github.com/golang/mock v1.5.0
 	github.com/mattn/go-sqlite3 v2.0.1+incompatible // indirect
+	github.com/miekg/pkcs11 v1.0.3
 	github.com/mitchellh/reflectwalk v1.0.1 // indirect
 	github.com/pkg/errors v0.9.1
 	github.com/sirupsen/logrus v1.6.0
 	github.com/stretchr/testify v1.7.0
+	golang.org/x/crypto v0.0.0-20200622213623-75b288015ac9
 	gorm.io/gorm v1.20.5
 	gotest.tools v2.2.0+incompatible
 	gotest.tools/gotestsum v0.5.2
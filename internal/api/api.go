@@ -5,12 +5,13 @@ import (
 	"net/http"
 	"p2pderivatives-oracle/internal/datafeed"
 	"p2pderivatives-oracle/internal/dlccrypto"
+	"p2pderivatives-oracle/internal/lock"
 	"p2pderivatives-oracle/internal/oracle"
+	"sync"
 
 	"github.com/cryptogarageinc/server-common-go/pkg/database/orm"
 	"github.com/cryptogarageinc/server-common-go/pkg/log"
 	"github.com/cryptogarageinc/server-common-go/pkg/rest/middleware"
-	"github.com/cryptogarageinc/server-common-go/pkg/rest/router"
 
 	"github.com/pkg/errors"
 
@@ -32,17 +33,32 @@ const (
 	AssetBaseRoute = "/asset"
 	// OracleBaseRoute base route of oracle api
 	OracleBaseRoute = "/oracle"
+	// EventBaseRoute base route of event api
+	EventBaseRoute = "/event"
 )
 
 // NewOracleAPI returns a new oracle api instance
-func NewOracleAPI(config *Config, log *log.Log, oracle *oracle.Oracle, orm *orm.ORM, cryptoService dlccrypto.CryptoService, feed datafeed.DataFeed) router.API {
+func NewOracleAPI(config *Config, log *log.Log, oracle *oracle.Oracle, orm *orm.ORM, cryptoService dlccrypto.CryptoService, feed datafeed.DataFeed) *OracleAPI {
+	streamsDone := make(chan struct{})
+	assetControllers := make(map[string]*AssetController, len(config.AssetConfigs)+len(config.EnumAssetConfigs))
+	for assetID, assetConfig := range config.AssetConfigs {
+		assetControllers[assetID] = newAssetController(assetID, assetConfig)
+	}
+	for assetID, enumConfig := range config.EnumAssetConfigs {
+		assetControllers[assetID] = newAssetController(assetID, enumConfig.toAssetConfig())
+	}
+	for _, controller := range assetControllers {
+		controller.streamsDone = streamsDone
+	}
 	return &OracleAPI{
-		logger:        log,
-		config:        config,
-		oracle:        oracle,
-		orm:           orm,
-		cryptoService: cryptoService,
-		feed:          feed,
+		logger:           log,
+		config:           config,
+		oracle:           oracle,
+		orm:              orm,
+		cryptoService:    cryptoService,
+		feed:             feed,
+		assetControllers: assetControllers,
+		streamsDone:      streamsDone,
 	}
 }
 
@@ -55,16 +71,23 @@ type OracleAPI struct {
 	orm           *orm.ORM
 	cryptoService dlccrypto.CryptoService
 	feed          datafeed.DataFeed
+	// shared by the routes and the scheduler so that they use the same locks
+	assetControllers map[string]*AssetController
+	// closed to end the asset streams
+	streamsDone      chan struct{}
+	closeStreamsOnce sync.Once
 }
 
 // Routes defines (and attached to a gin.routerGroup) the routes of the api
 func (a *OracleAPI) Routes(route *gin.RouterGroup) {
 	NewOracleController().Routes(route.Group(OracleBaseRoute))
+	NewEventController(a.assetControllers).Routes(route.Group(EventBaseRoute))
+	NewVerifyController().Routes(route)
 	assetRoutes := []string{}
-	for assetID, config := range a.config.AssetConfigs {
+	for assetID, controller := range a.assetControllers {
 		assetRoute := fmt.Sprintf("%s/%s", AssetBaseRoute, assetID)
 		assetRoutes = append(assetRoutes, assetID)
-		NewAssetController(assetID, config).Routes(route.Group(assetRoute))
+		controller.Routes(route.Group(assetRoute))
 	}
 
 	route.Group(AssetBaseRoute).GET("", func(c *gin.Context) {
@@ -72,6 +95,22 @@ func (a *OracleAPI) Routes(route *gin.RouterGroup) {
 	})
 }
 
+// SetLocker sets the locker serializing the creation of the announcements and attestations
+// (a local locker is used by default, which is only safe with a single oracle process)
+func (a *OracleAPI) SetLocker(locker lock.Locker) {
+	for _, controller := range a.assetControllers {
+		controller.locker = locker
+	}
+}
+
+// CloseStreams ends the asset streams, the streaming requests returning so that the server can shut down
+// (it should be registered with http.Server.RegisterOnShutdown as Shutdown does not cancel the active requests)
+func (a *OracleAPI) CloseStreams() {
+	a.closeStreamsOnce.Do(func() {
+		close(a.streamsDone)
+	})
+}
+
 // GlobalMiddlewares returns the global middlewares that the api should use
 func (a *OracleAPI) GlobalMiddlewares() []gin.HandlerFunc {
 	return []gin.HandlerFunc{
@@ -1,10 +1,66 @@
 package api
 
-import "time"
+import (
+	"p2pderivatives-oracle/internal/datafeed"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
 
 // Config contains the API configuration
 type Config struct {
-	AssetConfigs map[string]AssetConfig `configkey:"api.assets" validate:"required"`
+	AssetConfigs     map[string]AssetConfig     `configkey:"api.assets" validate:"required"`
+	EnumAssetConfigs map[string]EnumAssetConfig `configkey:"api.enumAssets"`
+}
+
+// Validate checks that the settlement and enumerated event configurations are consistent, the settlement
+// method of each asset being checked against the candle reference of the datafeed settlement configuration
+// (if not nil), which only applies to the spot prices
+func (c *Config) Validate(settlement *datafeed.SettlementConfig) error {
+	for assetID, assetConfig := range c.AssetConfigs {
+		switch assetConfig.SettlementMethod {
+		case "", datafeed.MethodSpot:
+		case datafeed.MethodTWAP, datafeed.MethodVWAP:
+			if assetConfig.SettlementWindow <= 0 {
+				return errors.Errorf(
+					"Asset %s should have a settlement window for the %s method", assetID, assetConfig.SettlementMethod)
+			}
+			if assetConfig.SettlementMinCoverage < 0 || assetConfig.SettlementMinCoverage > 1 {
+				return errors.Errorf(
+					"Asset %s should have a minimum settlement coverage between 0 and 1, got %v", assetID, assetConfig.SettlementMinCoverage)
+			}
+			if settlement != nil {
+				if assetSettlement, ok := settlement.Assets[assetID]; ok {
+					return errors.Errorf(
+						"Asset %s is settled with the %s method which does not use the %s reference of the datafeed settlement",
+						assetID, assetConfig.SettlementMethod, assetSettlement.Reference)
+				}
+			}
+		default:
+			return errors.Errorf("Unknown settlement method %s for asset %s", assetConfig.SettlementMethod, assetID)
+		}
+	}
+	for assetID, enumConfig := range c.EnumAssetConfigs {
+		if _, ok := c.AssetConfigs[assetID]; ok {
+			return errors.Errorf("Asset %s is configured both as a numeric and an enum asset", assetID)
+		}
+		if len(enumConfig.Outcomes) < 2 {
+			return errors.Errorf("Enum asset %s should have at least two outcomes", assetID)
+		}
+		seen := make(map[string]bool, len(enumConfig.Outcomes))
+		for _, outcome := range enumConfig.Outcomes {
+			// outcomes are stored as a comma separated list
+			if outcome == "" || strings.Contains(outcome, ",") {
+				return errors.Errorf("Invalid outcome %q for enum asset %s", outcome, assetID)
+			}
+			if seen[outcome] {
+				return errors.Errorf("Duplicated outcome %q for enum asset %s", outcome, assetID)
+			}
+			seen[outcome] = true
+		}
+	}
+	return nil
 }
 
 // SigningConfig contains parameters for the oracle to sign event outcomes
@@ -22,4 +78,70 @@ type AssetConfig struct {
 	RangeD     time.Duration `configkey:"range,duration,iso8601" validate:"required"`
 	SignConfig SigningConfig `configkey:"signconfig" validate:"required"`
 	Unit       string        `configkey:"unit" validate:"required"`
+	// SettlementMethod spot (price at the publish date, by default), twap or vwap over the settlement window
+	// before the publish date
+	SettlementMethod string        `configkey:"settlement.method"`
+	SettlementWindow time.Duration `configkey:"settlement.window,duration,iso8601"`
+	// SettlementMinCoverage minimum fraction of the settlement window covered by the averaged candles
+	// and SettlementMinCandles minimum number of averaged candles (no minimum if zero)
+	SettlementMinCoverage float64 `configkey:"settlement.minCoverage" default:"0.9"`
+	SettlementMinCandles  int     `configkey:"settlement.minCandles" default:"2"`
+	// only set for enumerated events (see EnumAssetConfig)
+	Outcomes []string
+}
+
+// IsEnum returns true if the asset serves enumerated outcome events
+func (c AssetConfig) IsEnum() bool {
+	return len(c.Outcomes) > 0
+}
+
+// IsAverageSettlement returns true if the value attested is averaged over the settlement window
+func (c AssetConfig) IsAverageSettlement() bool {
+	return c.SettlementMethod == datafeed.MethodTWAP || c.SettlementMethod == datafeed.MethodVWAP
+}
+
+// Settlement returns the settlement method and window of the values attested for the asset
+func (c AssetConfig) Settlement() (string, time.Duration) {
+	if c.IsAverageSettlement() {
+		return c.SettlementMethod, c.SettlementWindow
+	}
+	return datafeed.MethodSpot, 0
+}
+
+// findSettlementPriceRecord returns the price of the asset at the date computed with the settlement method
+func findSettlementPriceRecord(feed datafeed.DataFeed, assetID string, date time.Time, config AssetConfig) (*datafeed.PriceRecord, error) {
+	if config.IsAverageSettlement() {
+		return datafeed.FindAveragePriceRecord(
+			feed, assetID, config.SettlementMethod, date, config.SettlementWindow,
+			config.SettlementMinCoverage, config.SettlementMinCandles)
+	}
+	return feed.FindPastAssetPriceRecord(assetID, date)
+}
+
+// EnumAssetConfig represents one enumerated event configuration delivered by the oracle,
+// each event having one of the outcomes as result
+type EnumAssetConfig struct {
+	StartDate time.Time     `configkey:"startDate" validate:"required"`
+	Frequency time.Duration `configkey:"frequency,duration,iso8601" validate:"required"`
+	RangeD    time.Duration `configkey:"range,duration,iso8601" validate:"required"`
+	Outcomes  []string      `configkey:"outcomes" validate:"required"`
+}
+
+func (c EnumAssetConfig) toAssetConfig() AssetConfig {
+	return AssetConfig{
+		StartDate: c.StartDate,
+		Frequency: c.Frequency,
+		RangeD:    c.RangeD,
+		Outcomes:  c.Outcomes,
+	}
+}
+
+// SchedulerConfig contains the parameters of the background scheduler
+// creating announcements and attestations ahead of client requests
+type SchedulerConfig struct {
+	Enabled  bool          `configkey:"scheduler.enabled"`
+	Interval time.Duration `configkey:"scheduler.interval,duration,iso8601" default:"PT1M"`
+	// MaxAnnouncements maximum number of announcements created per asset in a single run,
+	// the remaining ones being created on the next runs
+	MaxAnnouncements int `configkey:"scheduler.maxAnnouncements" default:"100" validate:"gt=0"`
 }
@@ -4,10 +4,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"p2pderivatives-oracle/internal/api"
+	"p2pderivatives-oracle/internal/datafeed"
 	"p2pderivatives-oracle/test"
 	mock_datafeed "p2pderivatives-oracle/test/mock/datafeed"
 	mock_dlccrypto "p2pderivatives-oracle/test/mock/dlccrypto"
 	"testing"
+	"time"
 
 	"github.com/cryptogarageinc/server-common-go/pkg/rest/router"
 
@@ -93,3 +95,66 @@ func TestAPIConfig_Initialize_NoError(t *testing.T) {
 	err := test.InitializeConfig(apiConfig)
 	assert.NoError(t, err)
 }
+
+func TestAPIConfig_Validate(t *testing.T) {
+	enumConfig := func(outcomes ...string) api.EnumAssetConfig {
+		return api.EnumAssetConfig{Outcomes: outcomes}
+	}
+	tests := []struct {
+		name       string
+		config     *api.Config
+		settlement *datafeed.SettlementConfig
+		isValid    bool
+	}{
+		{name: "valid", config: &api.Config{EnumAssetConfigs: map[string]api.EnumAssetConfig{"etf": enumConfig("yes", "no")}}, isValid: true},
+		{name: "single outcome", config: &api.Config{EnumAssetConfigs: map[string]api.EnumAssetConfig{"etf": enumConfig("yes")}}},
+		{name: "outcome with comma", config: &api.Config{EnumAssetConfigs: map[string]api.EnumAssetConfig{"etf": enumConfig("yes", "no,maybe")}}},
+		{name: "duplicated outcome", config: &api.Config{EnumAssetConfigs: map[string]api.EnumAssetConfig{"etf": enumConfig("yes", "yes")}}},
+		{
+			name:    "twap with window",
+			config:  &api.Config{AssetConfigs: map[string]api.AssetConfig{"btcusd": {SettlementMethod: "twap", SettlementWindow: time.Hour}}},
+			isValid: true,
+		},
+		{
+			name:   "vwap without window",
+			config: &api.Config{AssetConfigs: map[string]api.AssetConfig{"btcusd": {SettlementMethod: "vwap"}}},
+		},
+		{
+			name:   "twap with candle reference",
+			config: &api.Config{AssetConfigs: map[string]api.AssetConfig{"btcusd": {SettlementMethod: "twap", SettlementWindow: time.Hour}}},
+			settlement: &datafeed.SettlementConfig{
+				Assets: map[string]datafeed.SettlementAssetConfig{"btcusd": {Reference: datafeed.ReferenceVWAP}},
+			},
+		},
+		{
+			name:   "spot with candle reference",
+			config: &api.Config{AssetConfigs: map[string]api.AssetConfig{"btcusd": {}}},
+			settlement: &datafeed.SettlementConfig{
+				Assets: map[string]datafeed.SettlementAssetConfig{"btcusd": {Reference: datafeed.ReferenceVWAP}},
+			},
+			isValid: true,
+		},
+		{
+			name:   "unknown method",
+			config: &api.Config{AssetConfigs: map[string]api.AssetConfig{"btcusd": {SettlementMethod: "median"}}},
+		},
+		{
+			name: "both numeric and enum",
+			config: &api.Config{
+				AssetConfigs:     map[string]api.AssetConfig{"etf": {}},
+				EnumAssetConfigs: map[string]api.EnumAssetConfig{"etf": enumConfig("yes", "no")},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.config.Validate(test.settlement)
+			if test.isValid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
@@ -1,12 +1,15 @@
 package api
 
 import (
+	"io"
 	"net/http"
 	"p2pderivatives-oracle/internal/database/entity"
 	"p2pderivatives-oracle/internal/datafeed"
 	"p2pderivatives-oracle/internal/dlccrypto"
+	"p2pderivatives-oracle/internal/envelope"
+	"p2pderivatives-oracle/internal/lock"
 	"p2pderivatives-oracle/internal/oracle"
-	"sync"
+	"strconv"
 	"time"
 
 	"github.com/cryptogarageinc/server-common-go/pkg/database/orm"
@@ -28,45 +31,322 @@ const (
 	RouteGETAssetConfig = "/config"
 	// RouteGETAssetAnnouncement relative GET route to retrieve asset rvalues
 	RouteGETAssetAnnouncement = "/announcement/:" + URLParamTagTime
+	// RouteGETAssetAnnouncementPoints relative GET route to retrieve the anticipation points of an asset announcement
+	RouteGETAssetAnnouncementPoints = RouteGETAssetAnnouncement + "/points"
 	// RouteGETAssetAttestation relative GET route to retrieve asset signatures
 	RouteGETAssetAttestation = "/attestation/:" + URLParamTagTime
+	// RouteGETAssetAttestationProvenance relative GET route to retrieve the data used to compute an attested value
+	RouteGETAssetAttestationProvenance = RouteGETAssetAttestation + "/provenance"
+	// RouteGETAssetEvents relative GET route to list the asset events
+	RouteGETAssetEvents = "/events"
+	// RouteGETAssetStream relative GET route to stream the asset announcements and attestations
+	RouteGETAssetStream = "/stream"
+)
+
+const (
+	// QueryParamFrom query parameter to list the events published at or after a date
+	QueryParamFrom = "from"
+	// QueryParamTo query parameter to list the events published at or before a date
+	QueryParamTo = "to"
+	// QueryParamStatus query parameter to list only the announced or attested events
+	QueryParamStatus = "status"
+	// QueryParamLimit query parameter to set the maximum number of events returned
+	QueryParamLimit = "limit"
+	// QueryParamCursor query parameter to get the events following a previous page
+	QueryParamCursor = "cursor"
+
+	// EventStatusAnnounced status of an event which is not attested yet
+	EventStatusAnnounced = "announced"
+	// EventStatusAttested status of an attested event
+	EventStatusAttested = "attested"
+
+	// DefaultEventsLimit number of events returned if no limit is requested
+	DefaultEventsLimit = 100
+	// MaxEventsLimit maximum number of events that can be requested at once
+	MaxEventsLimit = 1000
 )
 
 // AssetController represents the asset api Controller
 type AssetController struct {
-	assetID       string
-	config        AssetConfig
-	rValuesMutMap sync.Map
-	sigsMutMap    sync.Map
+	assetID string
+	config  AssetConfig
+	// serializes the creation of the announcement and attestation of an event
+	locker lock.Locker
+	// anticipation points of the recently requested events
+	points *pointsCache
+	// closed to end the streams of the asset
+	streamsDone <-chan struct{}
 }
 
 // NewAssetController creates a new Controller structure with the given parameters.
 func NewAssetController(assetID string, config AssetConfig) Controller {
+	return newAssetController(assetID, config)
+}
+
+func newAssetController(assetID string, config AssetConfig) *AssetController {
 	return &AssetController{
 		assetID: assetID,
 		config:  config,
+		locker:  lock.NewLocalLocker(),
+		points:  newPointsCache(maxCachedAnnouncementPoints),
 	}
 }
 
 // Routes list and binds all routes to the router group provided
 func (ct *AssetController) Routes(route *gin.RouterGroup) {
 	route.GET(RouteGETAssetAnnouncement, ct.GetAssetAnnouncement)
+	route.GET(RouteGETAssetAnnouncementPoints, ct.GetAssetAnnouncementPoints)
 	route.GET(RouteGETAssetAttestation, ct.GetAssetAttestation)
+	route.GET(RouteGETAssetAttestationProvenance, ct.GetAssetAttestationProvenance)
 	route.GET(RouteGETAssetConfig, ct.GetConfiguration)
+	route.GET(RouteGETAssetEvents, ct.GetAssetEvents)
+	route.GET(RouteGETAssetStream, ct.GetAssetStream)
 }
 
 // GetConfiguration handler returns the asset configuration
 func (ct *AssetController) GetConfiguration(c *gin.Context) {
 	ginlogrus.SetCtxLoggerHeader(c, "request-header", "Get Asset Configuration")
-	c.JSON(http.StatusOK, &AssetConfigResponse{
+	res := &AssetConfigResponse{
 		StartDate: ct.config.StartDate,
 		Frequency: iso8601.EncodeDuration(ct.config.Frequency),
 		RangeD:    iso8601.EncodeDuration(ct.config.RangeD),
-	})
+		Outcomes:  ct.config.Outcomes,
+	}
+	if !ct.config.IsEnum() {
+		res.Settlement = NewSettlementResponse(ct.config.Settlement())
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// GetAssetEvents handler returns the events of the asset ordered by publish date, optionally filtered
+// by publish date range and status, a page at a time (the nextCursor of the response is used to get the next page)
+func (ct *AssetController) GetAssetEvents(c *gin.Context) {
+	ginlogrus.SetCtxLoggerHeader(c, "request-header", "Get Asset Events")
+	filter, err := parseEventsFilter(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	limit := filter.Limit
+	// request one more event to know if there is a next page
+	filter.Limit++
+
+	oracleInstance := c.MustGet(ContextIDOracle).(*oracle.Oracle)
+	db := c.MustGet(ContextIDOrm).(*orm.ORM).GetDB()
+	dlcData, err := entity.FindDLCDataPage(db, ct.assetID, filter)
+	if err != nil {
+		c.Error(NewUnknownDBError(err))
+		return
+	}
+
+	response := &EventsResponse{Events: make([]EventSummary, 0, len(dlcData))}
+	if len(dlcData) > limit {
+		dlcData = dlcData[:limit]
+		response.NextCursor = strconv.FormatInt(dlcData[limit-1].PublishedDate.Unix(), 10)
+	}
+	for i := range dlcData {
+		oraclePubKey, err := eventPublicKey(oracleInstance, &dlcData[i])
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		response.Events = append(response.Events, *NewEventSummary(oraclePubKey, &dlcData[i]))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func parseEventsFilter(c *gin.Context) (*entity.EventDataFilter, error) {
+	filter := &entity.EventDataFilter{Limit: DefaultEventsLimit}
+	var err error
+	if filter.From, err = parseOptionalTimeQuery(c, QueryParamFrom); err != nil {
+		return nil, err
+	}
+	if filter.To, err = parseOptionalTimeQuery(c, QueryParamTo); err != nil {
+		return nil, err
+	}
+
+	switch status := c.Query(QueryParamStatus); status {
+	case "":
+	case EventStatusAnnounced, EventStatusAttested:
+		isSigned := status == EventStatusAttested
+		filter.IsSigned = &isSigned
+	default:
+		cause := errors.Errorf("Invalid status, expected %s or %s", EventStatusAnnounced, EventStatusAttested)
+		return nil, NewBadRequestError(InvalidQueryParameterBadRequestErrorCode, cause, status)
+	}
+
+	if value := c.Query(QueryParamLimit); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err == nil && (limit < 1 || limit > MaxEventsLimit) {
+			err = errors.Errorf("Limit should be between 1 and %d", MaxEventsLimit)
+		}
+		if err != nil {
+			return nil, NewBadRequestError(InvalidQueryParameterBadRequestErrorCode, err, value)
+		}
+		filter.Limit = limit
+	}
+
+	// the cursor is the publish date (unix timestamp) of the last event of the previous page
+	if value := c.Query(QueryParamCursor); value != "" {
+		timestamp, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, NewBadRequestError(InvalidQueryParameterBadRequestErrorCode, errors.WithMessage(err, "Invalid cursor"), value)
+		}
+		after := time.Unix(timestamp, 0).UTC()
+		filter.After = &after
+	}
+
+	return filter, nil
+}
+
+func parseOptionalTimeQuery(c *gin.Context, param string) (*time.Time, error) {
+	value := c.Query(param)
+	if value == "" {
+		return nil, nil
+	}
+	date, err := ParseTime(value)
+	if err != nil {
+		return nil, NewBadRequestError(InvalidTimeFormatBadRequestErrorCode, err, value)
+	}
+	return date, nil
+}
+
+// GetAssetStream handler streams the announcements and attestations of the asset as server-sent events
+// as soon as they are recorded (by any oracle process sharing the database). If the ID of the last received event
+// is given (Last-Event-ID header or lastEventId query parameter), the announcements and attestations recorded after it
+// are sent first. An event can be sent more than once, the client has to ignore the IDs it already received.
+// The stream is closed when the api streams are closed (see OracleAPI.CloseStreams).
+func (ct *AssetController) GetAssetStream(c *gin.Context) {
+	ginlogrus.SetCtxLoggerHeader(c, "request-header", "Get Asset Stream")
+	logger := ginlogrus.GetCtxLogger(c)
+	oracleInstance := c.MustGet(ContextIDOracle).(*oracle.Oracle)
+	db := c.MustGet(ContextIDOrm).(*orm.ORM).GetDB()
+
+	lastEventID := c.GetHeader(HeaderLastEventID)
+	if lastEventID == "" {
+		lastEventID = c.Query(QueryParamLastEventID)
+	}
+	var cursor *streamCursor
+	if lastEventID != "" {
+		last, err := ct.findStreamEntry(db, lastEventID)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		cursor = newStreamCursor(ct.assetID, last)
+	} else {
+		var err error
+		cursor, err = newLiveStreamCursor(db, ct.assetID, time.Now())
+		if err != nil {
+			c.Error(NewUnknownDBError(err))
+			return
+		}
+	}
+
+	c.Header("Content-Type", MIMEEventStream)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	send := func(entries []entity.StreamEntry) error {
+		return writeStreamEvents(c.Writer, db, oracleInstance, entries)
+	}
+	poll := time.NewTicker(StreamPollInterval)
+	defer poll.Stop()
+	keepAlive := time.NewTicker(StreamKeepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		if err := cursor.poll(db, time.Now(), send); err != nil {
+			logger.Errorf("Could not stream events of asset %s: %v", ct.assetID, err)
+			return
+		}
+		c.Writer.Flush()
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-ct.streamsDone:
+			return
+		case <-poll.C:
+		case <-keepAlive.C:
+			if _, err := c.Writer.WriteString(": keep-alive\n\n"); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// findStreamEntry returns the stream entry of the asset with the given (server-sent event) ID
+func (ct *AssetController) findStreamEntry(db *gorm.DB, streamEventID string) (*entity.StreamEntry, error) {
+	id, err := strconv.ParseUint(streamEventID, 10, 64)
+	if err != nil {
+		return nil, NewRecordNotFoundDBError(gorm.ErrRecordNotFound, streamEventID)
+	}
+	entry, err := entity.FindStreamEntry(db, id)
+	if err == nil && entry.AssetID != ct.assetID {
+		err = gorm.ErrRecordNotFound
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, NewRecordNotFoundDBError(err, streamEventID)
+		}
+		return nil, NewUnknownDBError(err)
+	}
+	return entry, nil
+}
+
+// writeStreamEvents writes the announcements and attestations of the given stream entries
+func writeStreamEvents(w io.Writer, db *gorm.DB, oracleInstance *oracle.Oracle, entries []entity.StreamEntry) error {
+	events, err := newStreamEvents(db, oracleInstance, entries)
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		if _, err := event.WriteTo(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newStreamEvents creates the stream events of the given entries
+func newStreamEvents(db *gorm.DB, oracleInstance *oracle.Oracle, entries []entity.StreamEntry) ([]*StreamEvent, error) {
+	eventIDs := make([]string, len(entries))
+	for i, entry := range entries {
+		eventIDs[i] = entry.EventID
+	}
+	dlcData, err := entity.FindDLCDataByEventIDs(db, eventIDs)
+	if err != nil {
+		return nil, err
+	}
+	dlcDataByID := make(map[string]entity.EventData, len(dlcData))
+	for _, data := range dlcData {
+		dlcDataByID[data.GetEventID()] = data
+	}
+
+	events := make([]*StreamEvent, 0, len(entries))
+	for _, entry := range entries {
+		data, ok := dlcDataByID[entry.EventID]
+		if !ok {
+			return nil, errors.Errorf("Event %s of stream entry %d not found", entry.EventID, entry.ID)
+		}
+		data.StreamEntryID = entry.ID
+		if entry.Type == entity.StreamEntryAttestation {
+			events = append(events, NewAttestationStreamEvent(&data))
+			continue
+		}
+		oraclePubKey, err := eventPublicKey(oracleInstance, &data)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, NewAnnouncementStreamEvent(oraclePubKey, &data))
+	}
+	return events, nil
 }
 
 // GetAssetAnnouncement handler returns the stored Rvalue related to the asset and time
 // if not present and future time, it will generates a new one using the config start date as reference
+// (the announcement can be returned as an oracle_announcement TLV, see renderWithFormat)
 func (ct *AssetController) GetAssetAnnouncement(c *gin.Context) {
 	ginlogrus.SetCtxLoggerHeader(c, "request-header", "Get Asset Rvalue")
 	logger := ginlogrus.GetCtxLogger(c)
@@ -83,17 +363,68 @@ func (ct *AssetController) GetAssetAnnouncement(c *gin.Context) {
 
 	oracleInstance := c.MustGet(ContextIDOracle).(*oracle.Oracle)
 	db := c.MustGet(ContextIDOrm).(*orm.ORM).GetDB()
-	crypto := c.MustGet(ContextIDCryptoService).(dlccrypto.CryptoService)
-	dlcData, err := ct.findOrCreateDLCData(logger, db, crypto, ct.assetID, *publishDate, ct.config, oracleInstance)
+	dlcData, err := ct.findOrCreateDLCData(logger, db, ct.assetID, *publishDate, ct.config, oracleInstance)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	oraclePubKey, err := eventPublicKey(oracleInstance, dlcData)
 	if err != nil {
 		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, NewOracleAnnouncement(oracleInstance.PublicKey, dlcData))
+	renderWithFormat(c, NewOracleAnnouncement(oraclePubKey, dlcData), func() ([]byte, error) {
+		return NewOracleAnnouncementTLV(oraclePubKey, dlcData)
+	})
+}
+
+// GetAssetAnnouncementPoints handler returns the anticipation points of the announcement related to the asset and time
+// (the signature point of each outcome of each nonce), creating the announcement like GetAssetAnnouncement
+func (ct *AssetController) GetAssetAnnouncementPoints(c *gin.Context) {
+	ginlogrus.SetCtxLoggerHeader(c, "request-header", "Get Asset Announcement Points")
+	logger := ginlogrus.GetCtxLogger(c)
+	_, requestedDate, err := validateAssetAndTime(c, ct.assetID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	publishDate, err := calculatePublishDate(*requestedDate, ct.config)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	oracleInstance := c.MustGet(ContextIDOracle).(*oracle.Oracle)
+	db := c.MustGet(ContextIDOrm).(*orm.ORM).GetDB()
+	dlcData, err := ct.findOrCreateDLCData(logger, db, ct.assetID, *publishDate, ct.config, oracleInstance)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	points, ok := ct.points.get(dlcData.GetEventID())
+	if !ok {
+		oraclePubKey, err := eventPublicKey(oracleInstance, dlcData)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		cryptoService := c.MustGet(ContextIDCryptoService).(dlccrypto.CryptoService)
+		points, err = computeAnnouncementPoints(cryptoService, oraclePubKey, dlcData)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		ct.points.add(points)
+	}
+
+	// the points of an announced event never change
+	c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	c.JSON(http.StatusOK, points)
 }
 
 // GetAssetAttestation handler returns the stored signature and asset value related to the asset and time
 // or if not present, it will generate a new one using the config start date as reference
+// (the attestation can be returned as an oracle_attestation TLV, see renderWithFormat)
 func (ct *AssetController) GetAssetAttestation(c *gin.Context) {
 	ginlogrus.SetCtxLoggerHeader(c, "request-header", "Get Asset Signature")
 	logger := ginlogrus.GetCtxLogger(c)
@@ -116,59 +447,276 @@ func (ct *AssetController) GetAssetAttestation(c *gin.Context) {
 	}
 
 	db := c.MustGet(ContextIDOrm).(*orm.ORM).GetDB()
-	crypto := c.MustGet(ContextIDCryptoService).(dlccrypto.CryptoService)
 	oracleInstance := c.MustGet(ContextIDOracle).(*oracle.Oracle)
-	dlcData, err := ct.findOrCreateDLCData(logger, db, crypto, ct.assetID, *publishDate, ct.config, oracleInstance)
+	// the datafeed is only needed if the event is not signed yet
+	feed, _ := c.MustGet(ContextIDDataFeed).(datafeed.DataFeed)
+	dlcData, err := ct.findOrCreateAttestation(logger, db, feed, *publishDate, oracleInstance)
 	if err != nil {
 		c.Error(err)
 		return
 	}
-	if !dlcData.HasSignature() {
-		logger.Debug("Computing Signature")
-		res, _ := ct.sigsMutMap.LoadOrStore(publishDate.String(), &sync.Mutex{})
-		mut, _ := res.(*sync.Mutex)
-		mut.Lock()
-		defer mut.Unlock()
-		defer ct.sigsMutMap.Delete(publishDate.String())
-		// try again after getting lock
-		dlcData, err = entity.FindDLCDataPublishedAt(db, ct.assetID, *publishDate)
-		if err != nil {
-			c.Error(err)
-			return
+	oraclePubKey, err := eventPublicKey(oracleInstance, dlcData)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	renderWithFormat(c, NewOracleAttestation(dlcData), func() ([]byte, error) {
+		return NewOracleAttestationTLV(oraclePubKey, dlcData)
+	})
+}
+
+// GetAssetAttestationProvenance handler returns the price data used to compute the value attested
+// for the asset and time (only available for signed numeric events)
+func (ct *AssetController) GetAssetAttestationProvenance(c *gin.Context) {
+	ginlogrus.SetCtxLoggerHeader(c, "request-header", "Get Asset Attestation Provenance")
+	_, requestedDate, err := validateAssetAndTime(c, ct.assetID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	publishDate, err := calculatePublishDate(*requestedDate, ct.config)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	db := c.MustGet(ContextIDOrm).(*orm.ORM).GetDB()
+	dlcData, err := entity.FindDLCDataPublishedAt(db, ct.assetID, *publishDate)
+	if err == nil && !dlcData.HasSignature() {
+		err = errors.Errorf("Event %s is not signed yet", dlcData.GetEventID())
+	}
+	if err != nil {
+		c.Error(NewRecordNotFoundDBError(err, publishDate.String()))
+		return
+	}
+	provenance, err := entity.FindPriceProvenance(db, ct.assetID, *publishDate)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Error(NewRecordNotFoundDBError(err, publishDate.String()))
+		} else {
+			c.Error(NewUnknownDBError(err))
 		}
-		if !dlcData.HasSignature() {
-			feed := c.MustGet(ContextIDDataFeed).(datafeed.DataFeed)
-			value, err := feed.FindPastAssetPrice(ct.assetID, dlcData.PublishedDate)
-			if err != nil {
-				c.Error(NewUnknownDataFeedError(err))
-				return
-			}
+		return
+	}
 
-			oracleInstance := c.MustGet(ContextIDOracle).(*oracle.Oracle)
-			sigs, decomposedValue, err := dlccrypto.GetRoundedDecomposedSignaturesForValue(*value, ct.config.SignConfig.Base, ct.config.SignConfig.NbDigits, oracleInstance.PrivateKey, dlcData.Kvalues, crypto)
-			if err != nil {
-				c.Error(NewUnknownCryptoServiceError(err))
-				return
-			}
+	c.JSON(http.StatusOK, NewPriceProvenanceResponse(dlcData, provenance))
+}
 
-			dlcData, err = entity.UpdateDLCDataSignatureAndValue(
-				db,
-				dlcData.AssetID,
-				dlcData.PublishedDate,
-				sigs,
-				decomposedValue)
+// findOrCreateAttestation returns the event data at the given publish date, signing its outcome
+// if it has not been signed yet (the publish date is expected to be in the past)
+func (ct *AssetController) findOrCreateAttestation(logger *logrus.Entry, db *gorm.DB, feed datafeed.DataFeed, publishDate time.Time, oracleInstance *oracle.Oracle) (*entity.EventData, error) {
+	dlcData, err := ct.findOrCreateDLCData(logger, db, ct.assetID, publishDate, ct.config, oracleInstance)
+	if err != nil {
+		return nil, err
+	}
+	if dlcData.HasSignature() {
+		return dlcData, nil
+	}
 
-			if err != nil {
-				c.Error(NewUnknownDBError(err))
-				return
-			}
+	logger.Debug("Computing Signature")
+	unlock, err := ct.locker.Lock(eventLockKey("attestation", ct.assetID, publishDate))
+	if err != nil {
+		return nil, NewUnknownDBError(err)
+	}
+	defer unlock()
+	// try again after getting lock
+	dlcData, err = entity.FindDLCDataPublishedAt(db, ct.assetID, publishDate)
+	if err != nil {
+		return nil, NewUnknownDBError(err)
+	}
+	if dlcData.HasSignature() {
+		return dlcData, nil
+	}
+
+	var sigs, values []string
+	// the provenance of the attested value is only recorded for numeric events
+	var provenance *entity.PriceProvenance
+	if dlcData.IsEnum() {
+		sigs, values, err = signOutcome(feed, dlcData, oracleInstance)
+	} else {
+		sigs, values, provenance, err = signValue(feed, dlcData, ct.config, oracleInstance)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dlcData, err = entity.UpdateDLCDataSignatureAndValue(
+		db,
+		dlcData.AssetID,
+		dlcData.PublishedDate,
+		sigs,
+		values,
+		provenance)
+	if errors.Is(err, entity.ErrNonceReused) {
+		// the event was signed with other values by another oracle process, whose signatures are the only ones released
+		signed, findErr := entity.FindDLCDataPublishedAt(db, ct.assetID, publishDate)
+		if findErr == nil && signed.HasSignature() {
+			logger.Warnf("Event %s was signed concurrently with other values", signed.GetEventID())
+			return signed, nil
+		}
+		return nil, NewUnknownCryptoServiceError(err)
+	}
+	if err != nil {
+		return nil, NewUnknownDBError(err)
+	}
+	return dlcData, nil
+}
+
+func signValue(feed datafeed.DataFeed, dlcData *entity.EventData, config AssetConfig, oracleInstance *oracle.Oracle) ([]string, []string, *entity.PriceProvenance, error) {
+	record, err := findSettlementPriceRecord(feed, dlcData.AssetID, dlcData.PublishedDate, config)
+	if err != nil {
+		return nil, nil, nil, NewDataFeedError(err)
+	}
+	key, err := oracleInstance.EventKey(dlcData.OraclePublicKey)
+	if err != nil {
+		return nil, nil, nil, NewUnknownCryptoServiceError(err)
+	}
+	nonces, err := eventNonces(dlcData, oracleInstance.KvalueKeyring)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer zeroEventNonces(nonces)
+
+	// sign using the announced parameters to match the published descriptor
+	sigs, decomposedValue, err := dlccrypto.GetRoundedDecomposedSignaturesForValue(
+		record.Price,
+		dlcData.Base,
+		dlcData.NbDigits(),
+		dlcData.IsSigned,
+		dlcData.Precision,
+		key.Signer,
+		nonces)
+	if err != nil {
+		return nil, nil, nil, NewUnknownCryptoServiceError(err)
+	}
+	if err := checkSignatureNonces(sigs, dlcData); err != nil {
+		return nil, nil, nil, err
+	}
+	return sigs, decomposedValue, newPriceProvenance(record, dlcData, config), nil
+}
+
+// newPriceProvenance returns the provenance of the value attested for the event from the datafeed price record
+// and the settlement method used to compute it
+func newPriceProvenance(record *datafeed.PriceRecord, dlcData *entity.EventData, config AssetConfig) *entity.PriceProvenance {
+	method, window := config.Settlement()
+	sourcePrices := make([]entity.SourcePrice, 0, len(record.Sources)+len(record.Rejected))
+	for _, p := range record.Sources {
+		sourcePrices = append(sourcePrices, entity.SourcePrice{Source: p.Source, Price: p.Price, Timestamp: p.Timestamp, Stale: p.Stale})
+	}
+	for _, p := range record.Rejected {
+		sourcePrices = append(sourcePrices, entity.SourcePrice{Source: p.Source, Price: p.Price, Timestamp: p.Timestamp, Rejected: true, Stale: p.Stale})
+	}
+	return &entity.PriceProvenance{
+		RawValue:        record.Price,
+		Source:          record.Source,
+		SourceTimestamp: record.Timestamp,
+		Reference:       record.Reference,
+		Stale:           record.Stale,
+		Method:          method,
+		Window:          window,
+		RoundedValue:    dlccrypto.RoundValue(record.Price, dlcData.Base, dlcData.NbDigits(), dlcData.IsSigned, dlcData.Precision),
+		Precision:       dlcData.Precision,
+		SourcePrices:    sourcePrices,
+	}
+}
+
+func signOutcome(feed datafeed.DataFeed, dlcData *entity.EventData, oracleInstance *oracle.Oracle) ([]string, []string, error) {
+	outcome, err := feed.FindPastOutcome(dlcData.AssetID, dlcData.PublishedDate, dlcData.Outcomes)
+	if err != nil {
+		return nil, nil, NewDataFeedError(err)
+	}
+	key, err := oracleInstance.EventKey(dlcData.OraclePublicKey)
+	if err != nil {
+		return nil, nil, NewUnknownCryptoServiceError(err)
+	}
+	nonces, err := eventNonces(dlcData, oracleInstance.KvalueKeyring)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer zeroEventNonces(nonces)
+
+	// the outcome is signed with the single nonce of the event
+	sig, err := dlccrypto.GetEnumOutcomeSignature(
+		*outcome,
+		dlcData.Outcomes,
+		key.Signer,
+		&nonces[0])
+	if err != nil {
+		return nil, nil, NewUnknownCryptoServiceError(err)
+	}
+	if err := checkSignatureNonces([]string{sig}, dlcData); err != nil {
+		return nil, nil, err
+	}
+	return []string{sig}, []string{*outcome}, nil
+}
+
+// eventPublicKey returns the public key of the oracle key which announced the event
+func eventPublicKey(oracleInstance *oracle.Oracle, dlcData *entity.EventData) (*dlccrypto.SchnorrPublicKey, error) {
+	key, err := oracleInstance.EventKey(dlcData.OraclePublicKey)
+	if err != nil {
+		return nil, NewUnknownCryptoServiceError(err)
+	}
+	return key.PublicKey, nil
+}
+
+// eventNonces returns the nonces of the event, their one time signing keys being either derived
+// from the oracle key by the signer or stored (encrypted) with the event if it was created before
+// the nonces were derived, in which case the keys must be zeroed after signing (see zeroEventNonces)
+func eventNonces(dlcData *entity.EventData, keyring *envelope.Keyring) ([]dlccrypto.EventNonce, error) {
+	nonces := make([]dlccrypto.EventNonce, len(dlcData.Nonces))
+	for i := range dlcData.Nonces {
+		nonces[i] = dlccrypto.EventNonce{
+			AssetID:       dlcData.AssetID,
+			EventMaturity: uint32(dlcData.PublishedDate.Unix()),
+			Index:         i,
+		}
+	}
+	if !dlcData.HasStoredKvalues() {
+		return nonces, nil
+	}
+
+	kvalues, err := dlcData.OpenKvalues(keyring)
+	if err != nil {
+		return nil, NewUnknownCryptoServiceError(err)
+	}
+	defer func() {
+		for _, kvalue := range kvalues {
+			envelope.Zero(kvalue)
+		}
+	}()
+	for i, kvalue := range kvalues {
+		nonces[i].Kvalue, err = dlccrypto.NewPrivateKeyFromBytes(kvalue)
+		if err != nil {
+			zeroEventNonces(nonces)
+			return nil, NewUnknownCryptoServiceError(err)
+		}
+	}
+	return nonces, nil
+}
+
+// zeroEventNonces zeroes the stored one time signing keys of the nonces once they are used
+func zeroEventNonces(nonces []dlccrypto.EventNonce) {
+	for _, nonce := range nonces {
+		if nonce.Kvalue != nil {
+			nonce.Kvalue.Zero()
 		}
 	}
+}
 
-	c.JSON(http.StatusOK, NewOracleAttestation(dlcData))
+// checkSignatureNonces checks that the signatures were made with the announced nonces
+// (the oracle key might have changed since the event was announced)
+func checkSignatureNonces(sigs []string, dlcData *entity.EventData) error {
+	for i, sig := range sigs {
+		if sig[:64] != dlcData.Nonces[i] {
+			cause := errors.Errorf("Nonce %d of the signature does not match the announced one", i)
+			return NewUnknownCryptoServiceError(cause)
+		}
+	}
+	return nil
 }
 
-func (ct *AssetController) findOrCreateDLCData(logger *logrus.Entry, db *gorm.DB, cryptoService dlccrypto.CryptoService, assetID string, publishDate time.Time, config AssetConfig, oracleInstance *oracle.Oracle) (*entity.EventData, error) {
+func (ct *AssetController) findOrCreateDLCData(logger *logrus.Entry, db *gorm.DB, assetID string, publishDate time.Time, config AssetConfig, oracleInstance *oracle.Oracle) (*entity.EventData, error) {
 	dlcData, err := entity.FindDLCDataPublishedAt(db, assetID, publishDate)
 	if err == nil {
 		logger.Debug("Found a matching DLC Data in db")
@@ -177,40 +725,61 @@ func (ct *AssetController) findOrCreateDLCData(logger *logrus.Entry, db *gorm.DB
 	if err != nil {
 		// if record is not found, need to create the record in db
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			res, _ := ct.rValuesMutMap.LoadOrStore(publishDate.String(), &sync.Mutex{})
-			mut, _ := res.(*sync.Mutex)
-			mut.Lock()
-			defer mut.Unlock()
-			defer ct.rValuesMutMap.Delete(publishDate.String())
+			unlock, lockErr := ct.locker.Lock(eventLockKey("announcement", assetID, publishDate))
+			if lockErr != nil {
+				return nil, NewUnknownDBError(lockErr)
+			}
+			defer unlock()
 			// try again after getting lock.
 			dlcData, err = entity.FindDLCDataPublishedAt(db, assetID, publishDate)
 			if err == nil {
 				logger.Debug("Found a matching DLC Data in db")
 			} else if errors.Is(err, gorm.ErrRecordNotFound) {
 				logger.Debug("Generating new DLC data Rvalue")
-				kValues := make([]string, config.SignConfig.NbDigits)
-				rValues := make([]string, config.SignConfig.NbDigits)
-				rValuesRaw := make([]dlccrypto.SchnorrPublicKey, config.SignConfig.NbDigits)
-				for i := 0; i < config.SignConfig.NbDigits; i++ {
-					signingK, rvalue, err := cryptoService.GenerateSchnorrKeyPair()
+				nbNonces := config.SignConfig.NbDigits
+				// an additional nonce is needed to sign the sign of the outcome
+				if config.SignConfig.IsSigned {
+					nbNonces++
+				}
+				// an enum event outcome is signed with a single nonce
+				if config.IsEnum() {
+					nbNonces = 1
+				}
+				// new events are announced with the currently active oracle key
+				key := oracleInstance.ActiveKey(time.Now().UTC())
+				rValues := make([]string, nbNonces)
+				rValuesRaw := make([]dlccrypto.SchnorrPublicKey, nbNonces)
+				for i := 0; i < nbNonces; i++ {
+					// the signing k is derived again when attesting the event so that it is never stored
+					rvalue, err := key.Signer.DeriveSchnorrNonce(assetID, uint32(publishDate.Unix()), i)
 					if err != nil {
 						return nil, NewUnknownCryptoServiceError(err)
 					}
-					kValues[i] = signingK.EncodeToString()
 					rValues[i] = rvalue.EncodeToString()
 					rValuesRaw[i] = *rvalue
 				}
 				eventID := entity.ComputeEventEventID(assetID, &publishDate)
-				eventSignature, err := dlccrypto.GenerateEventSignature(oracleInstance.PrivateKey, rValuesRaw, uint32(publishDate.Unix()), uint16(ct.config.SignConfig.Base), ct.config.SignConfig.IsSigned, ct.config.Unit, int32(ct.config.SignConfig.Precision), uint16(ct.config.SignConfig.NbDigits), eventID, cryptoService)
+				var eventSignature string
+				if config.IsEnum() {
+					eventSignature, err = dlccrypto.GenerateEnumEventSignature(key.Signer, rValuesRaw, uint32(publishDate.Unix()), config.Outcomes, eventID)
+				} else {
+					eventSignature, err = dlccrypto.GenerateEventSignature(key.Signer, rValuesRaw, uint32(publishDate.Unix()), uint16(ct.config.SignConfig.Base), ct.config.SignConfig.IsSigned, ct.config.Unit, int32(ct.config.SignConfig.Precision), uint16(ct.config.SignConfig.NbDigits), eventID)
+				}
+				if err != nil {
+					return nil, NewUnknownCryptoServiceError(err)
+				}
 				dlcData, err = entity.CreateEventData(
 					db,
 					assetID,
 					publishDate,
-					kValues,
 					rValues,
 					ct.config.SignConfig.Base,
+					ct.config.SignConfig.IsSigned,
+					ct.config.SignConfig.Precision,
 					ct.config.Unit,
-					eventSignature)
+					ct.config.Outcomes,
+					eventSignature,
+					key.PublicKey.EncodeToString())
 				if err != nil {
 					return nil, NewUnknownDBError(err)
 				}
@@ -224,6 +793,11 @@ func (ct *AssetController) findOrCreateDLCData(logger *logrus.Entry, db *gorm.DB
 	return dlcData, nil
 }
 
+// eventLockKey returns the key of the lock serializing the given operation on the event
+func eventLockKey(operation string, assetID string, publishDate time.Time) string {
+	return operation + "/" + assetID + "/" + strconv.FormatInt(publishDate.Unix(), 10)
+}
+
 func validateAssetAndTime(c *gin.Context, assetID string) (*entity.Asset, *time.Time, error) {
 	timestampStr := c.Param(URLParamTagTime)
 	db := c.MustGet(ContextIDOrm).(*orm.ORM).GetDB()
@@ -1,6 +1,8 @@
 package api_test
 
 import (
+	"bytes"
+	"encoding/hex"
 	"encoding/json"
 	"math"
 	"math/rand"
@@ -12,6 +14,7 @@ import (
 	"p2pderivatives-oracle/internal/datafeed"
 	"p2pderivatives-oracle/internal/decompose"
 	"p2pderivatives-oracle/internal/dlccrypto"
+	"p2pderivatives-oracle/internal/envelope"
 	"p2pderivatives-oracle/internal/oracle"
 	"p2pderivatives-oracle/test"
 	mock_datafeed "p2pderivatives-oracle/test/mock/datafeed"
@@ -20,6 +23,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/cryptogarageinc/server-common-go/pkg/database/orm"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 	"github.com/golang/mock/gomock"
 
 	"github.com/gin-gonic/gin"
@@ -103,18 +108,27 @@ func SetupMockValues() ([]*dlccrypto.PrivateKey, []*dlccrypto.SchnorrPublicKey,
 }
 
 func SetupAssetEngine(recorder *httptest.ResponseRecorder, o *oracle.Oracle, crypto dlccrypto.CryptoService, feed datafeed.DataFeed) (*gin.Context, *gin.Engine) {
-	assetController := api.NewAssetController(TestAsset.AssetID, *TestAssetConfig)
-	orm := test.NewOrm(&entity.Asset{}, &entity.EventData{})
-	orm.GetDB().Create(TestAsset)
-	orm.GetDB().Create(InDbDLCData)
+	return SetupAssetEngineWithConfig(recorder, TestAssetConfig, o, crypto, feed)
+}
+
+func SetupAssetEngineWithConfig(recorder *httptest.ResponseRecorder, config *api.AssetConfig, o *oracle.Oracle, crypto dlccrypto.CryptoService, feed datafeed.DataFeed) (*gin.Context, *gin.Engine) {
+	c, r, _ := SetupAssetEngineWithOrm(recorder, config, o, crypto, feed)
+	return c, r
+}
+
+func SetupAssetEngineWithOrm(recorder *httptest.ResponseRecorder, config *api.AssetConfig, o *oracle.Oracle, crypto dlccrypto.CryptoService, feed datafeed.DataFeed) (*gin.Context, *gin.Engine, *orm.ORM) {
+	assetController := api.NewAssetController(TestAsset.AssetID, *config)
+	ormInstance := test.NewOrm(&entity.Asset{}, &entity.EventData{}, &entity.PriceProvenance{}, &entity.NonceSignature{}, &entity.StreamEntry{})
+	ormInstance.GetDB().Create(TestAsset)
+	ormInstance.GetDB().Create(InDbDLCData)
 	setup := func(c *gin.Context) {
 		c.Set(api.ContextIDOracle, o)
 		c.Set(api.ContextIDCryptoService, crypto)
 		c.Set(api.ContextIDDataFeed, feed)
-		c.Set(api.ContextIDOrm, orm)
+		c.Set(api.ContextIDOrm, ormInstance)
 	}
 	c, r := SetupEngine(recorder, assetController, api.ErrorHandler(), setup)
-	return c, r
+	return c, r, ormInstance
 }
 
 func TestAssetController_GetConfiguration(t *testing.T) {
@@ -124,9 +138,10 @@ func TestAssetController_GetConfiguration(t *testing.T) {
 	r.ServeHTTP(resp, c.Request)
 	if assert.Equal(t, http.StatusOK, resp.Code) {
 		expected := &api.AssetConfigResponse{
-			StartDate: TestAssetConfig.StartDate,
-			Frequency: "PT1H",
-			RangeD:    "P2DT",
+			StartDate:  TestAssetConfig.StartDate,
+			Frequency:  "PT1H",
+			RangeD:     "P2DT",
+			Settlement: &api.SettlementResponse{Method: datafeed.MethodSpot},
 		}
 		actual := &api.AssetConfigResponse{}
 		err := json.Unmarshal([]byte(resp.Body.String()), actual)
@@ -178,7 +193,7 @@ func TestAssetController_GetAssetAnnouncement_WithExactValidDateInDB_ReturnsCorr
 
 	// assert
 	if assert.Equal(t, http.StatusOK, resp.Code) {
-		expected := api.NewOracleAnnouncement(oracleService.PublicKey, InDbDLCData)
+		expected := api.NewOracleAnnouncement(oracleService.PublicKey(), InDbDLCData)
 		actual := &api.OracleAnnouncement{}
 		err := json.Unmarshal([]byte(resp.Body.String()), actual)
 		if assert.NoError(t, err) {
@@ -209,7 +224,7 @@ func TestAssetController_GetAssetAnnouncement_WithNearValidDateInDB_ReturnsCorre
 
 	// assert
 	if assert.Equal(t, http.StatusOK, resp.Code) {
-		expected := api.NewOracleAnnouncement(oracleService.PublicKey, InDbDLCData)
+		expected := api.NewOracleAnnouncement(oracleService.PublicKey(), InDbDLCData)
 		actual := &api.OracleAnnouncement{}
 		err := json.Unmarshal([]byte(resp.Body.String()), actual)
 		if assert.NoError(t, err) {
@@ -221,10 +236,13 @@ func TestAssetController_GetAssetAnnouncement_WithNearValidDateInDB_ReturnsCorre
 func TestAssetController_GetAssetAnnouncement_NotInDB_ReturnsCorrectValue(t *testing.T) {
 	// parameters
 	date := InDbDLCData.PublishedDate.Add((30 * time.Minute) + (2 * time.Second))
-	oracleService, err := NewTestOracleService()
+	ctrl := gomock.NewController(t)
+	crypto := mock_dlccrypto.NewMockCryptoService(ctrl)
+	oracleService, err := NewTestOracleServiceWithCrypto(crypto)
 	if err != nil {
 		t.Error(err)
 	}
+	oracleKey, _ := dlccrypto.NewPrivateKey(OraclePrivateKey)
 
 	// expected
 	updatedDlcData := entity.EventData{
@@ -238,22 +256,22 @@ func TestAssetController_GetAssetAnnouncement_NotInDB_ReturnsCorrectValue(t *tes
 		Precision:             InDbDLCData.Precision,
 		Unit:                  TestAssetConfig.Unit,
 	}
+	updatedDlcData.EventID = entity.ComputeEventEventID(updatedDlcData.AssetID, &updatedDlcData.PublishedDate)
 
-	expected := api.NewOracleAnnouncement(oracleService.PublicKey, &updatedDlcData)
+	expected := api.NewOracleAnnouncement(oracleService.PublicKey(), &updatedDlcData)
 	// setup mocks
-	ctrl := gomock.NewController(t)
 	kvalue, rvalue, _, _, err := SetupMockValues()
 	if !assert.NoError(t, err) {
 		t.Fail()
 	}
 
-	crypto := mock_dlccrypto.NewMockCryptoService(ctrl)
+	maturity := uint32(updatedDlcData.PublishedDate.Unix())
 	for i := 0; i < len(kvalue); i++ {
-		crypto.EXPECT().GenerateSchnorrKeyPair().Return(kvalue[i], rvalue[i], nil)
+		crypto.EXPECT().DeriveSchnorrNonce(oracleKey, TestAsset.AssetID, maturity, i).Return(kvalue[i], rvalue[i], nil)
 	}
 
 	expectedSig, _ := dlccrypto.NewSignature(TestResponseValues.AnnouncementSignature)
-	crypto.EXPECT().ComputeSchnorrSignature(oracleService.PrivateKey, gomock.Any()).Return(expectedSig, nil)
+	crypto.EXPECT().ComputeSchnorrSignature(oracleKey, gomock.Any()).Return(expectedSig, nil)
 
 	resp := httptest.NewRecorder()
 	c, r := SetupAssetEngine(resp, oracleService, crypto, nil)
@@ -287,31 +305,35 @@ func TestAssetController_GetAssetAttestation_NotInDB_ReturnsCorrectValue(t *test
 		Asset:                 InDbDLCData.Asset,
 		AnnouncementSignature: TestResponseValues.AnnouncementSignature,
 	}
+	updatedDlcData.EventID = entity.ComputeEventEventID(updatedDlcData.AssetID, &updatedDlcData.PublishedDate)
 	expected := api.NewOracleAttestation(updatedDlcData)
 
-	oracleInstance, err := NewTestOracleService()
+	ctrl := gomock.NewController(t)
+	crypto := mock_dlccrypto.NewMockCryptoService(ctrl)
+	oracleInstance, err := NewTestOracleServiceWithCrypto(crypto)
+	oracleKey, _ := dlccrypto.NewPrivateKey(OraclePrivateKey)
 	if assert.NoError(t, err) {
 		// setup mocks
-		ctrl := gomock.NewController(t)
 		kvalues, rvalues, sigs, sigValue, err := SetupMockValues()
 		if err != nil {
 			t.Error(err)
 		}
 		// mock datafeed
 		feed := mock_datafeed.NewMockDataFeed(ctrl)
-		feed.EXPECT().FindPastAssetPrice("btcusd", expectedDate).Return(sigValue, nil)
+		feed.EXPECT().FindPastAssetPriceRecord("btcusd", expectedDate).Return(
+			&datafeed.PriceRecord{Price: *sigValue, Source: datafeed.DummySource, Timestamp: expectedDate}, nil)
 		// mock crypto
-		crypto := mock_dlccrypto.NewMockCryptoService(ctrl)
 		for i := 0; i < len(kvalues); i++ {
-			crypto.EXPECT().GenerateSchnorrKeyPair().Return(kvalues[i], rvalues[i], nil)
+			// derived when announcing and again when attesting
+			crypto.EXPECT().DeriveSchnorrNonce(oracleKey, TestAsset.AssetID, uint32(expectedDate.Unix()), i).Return(kvalues[i], rvalues[i], nil).Times(2)
 			crypto.EXPECT().ComputeSchnorrSignatureFixedK(
-				oracleInstance.PrivateKey,
+				oracleKey,
 				kvalues[i],
 				TestResponseValues.Values[i]).Return(sigs[i], nil)
 		}
 
 		expectedSig, _ := dlccrypto.NewSignature(TestResponseValues.AnnouncementSignature)
-		crypto.EXPECT().ComputeSchnorrSignature(oracleInstance.PrivateKey, gomock.Any()).Return(expectedSig, nil)
+		crypto.EXPECT().ComputeSchnorrSignature(oracleKey, gomock.Any()).Return(expectedSig, nil)
 
 		resp := httptest.NewRecorder()
 		c, r := SetupAssetEngine(resp, oracleInstance, crypto, feed)
@@ -436,3 +458,756 @@ func TestAssetController_GetAssetAttestation_HasValidAnnouncementSignature(t *te
 	valid, _ := crypto.VerifySchnorrSignatureRaw(pubkey, sig, ser)
 	assert.True(t, valid)
 }
+
+func TestAssetController_GetAssetAnnouncement_WithTLVFormat_ReturnsHexTLV(t *testing.T) {
+	oracleInstance, _ := NewTestOracleService()
+	crypto := cfddlccrypto.NewCfdgoCryptoService()
+	date := time.Now().Add(1 * time.Hour)
+	resp := httptest.NewRecorder()
+	c, r := SetupAssetEngine(resp, oracleInstance, crypto, nil)
+	route := GetRouteWithTimeParam(api.RouteGETAssetAnnouncement, date)
+	c.Request, _ = http.NewRequest(http.MethodGet, route, nil)
+	r.ServeHTTP(resp, c.Request)
+	var announcement api.OracleAnnouncement
+	err := json.Unmarshal(resp.Body.Bytes(), &announcement)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tlvResp := httptest.NewRecorder()
+	c.Request, _ = http.NewRequest(http.MethodGet, route+"?"+api.QueryParamFormat+"="+api.FormatTLV, nil)
+	r.ServeHTTP(tlvResp, c.Request)
+
+	if assert.Equal(t, http.StatusOK, tlvResp.Code) {
+		actual, err := hex.DecodeString(tlvResp.Body.String())
+		assert.NoError(t, err)
+		assert.Equal(t, SerializeAnnouncementResponse(t, &announcement), actual)
+	}
+}
+
+func TestAssetController_GetAssetAnnouncement_WithOctetStreamAccept_ReturnsRawTLV(t *testing.T) {
+	oracleInstance, _ := NewTestOracleService()
+	crypto := cfddlccrypto.NewCfdgoCryptoService()
+	date := time.Now().Add(1 * time.Hour)
+	resp := httptest.NewRecorder()
+	c, r := SetupAssetEngine(resp, oracleInstance, crypto, nil)
+	route := GetRouteWithTimeParam(api.RouteGETAssetAnnouncement, date)
+	c.Request, _ = http.NewRequest(http.MethodGet, route, nil)
+	r.ServeHTTP(resp, c.Request)
+	var announcement api.OracleAnnouncement
+	err := json.Unmarshal(resp.Body.Bytes(), &announcement)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tlvResp := httptest.NewRecorder()
+	c.Request, _ = http.NewRequest(http.MethodGet, route, nil)
+	c.Request.Header.Set("Accept", api.MIMEOctetStream)
+	r.ServeHTTP(tlvResp, c.Request)
+
+	if assert.Equal(t, http.StatusOK, tlvResp.Code) {
+		assert.Equal(t, api.MIMEOctetStream, tlvResp.Header().Get("Content-Type"))
+		assert.Equal(t, SerializeAnnouncementResponse(t, &announcement), tlvResp.Body.Bytes())
+	}
+}
+
+func GetAttestationWithFormat(t *testing.T, header string, query string) (*api.OracleAttestation, *httptest.ResponseRecorder) {
+	oracleInstance, _ := NewTestOracleService()
+	crypto := cfddlccrypto.NewCfdgoCryptoService()
+	ctrl := gomock.NewController(t)
+	feed := mock_datafeed.NewMockDataFeed(ctrl)
+	publishDate := InDbDLCData.PublishedDate.Add(2 * TestAssetConfig.Frequency)
+	feed.EXPECT().FindPastAssetPriceRecord(TestAsset.AssetID, publishDate).Return(
+		&datafeed.PriceRecord{Price: datafeedValue, Source: datafeed.DummySource, Timestamp: publishDate}, nil)
+	resp := httptest.NewRecorder()
+	c, r := SetupAssetEngine(resp, oracleInstance, crypto, feed)
+	route := GetRouteWithTimeParam(api.RouteGETAssetAttestation, publishDate)
+	c.Request, _ = http.NewRequest(http.MethodGet, route, nil)
+	r.ServeHTTP(resp, c.Request)
+	attestation := &api.OracleAttestation{}
+	err := json.Unmarshal(resp.Body.Bytes(), attestation)
+	if !assert.NoError(t, err, resp.Body.String()) {
+		return nil, nil
+	}
+
+	tlvResp := httptest.NewRecorder()
+	c.Request, _ = http.NewRequest(http.MethodGet, route+query, nil)
+	if header != "" {
+		c.Request.Header.Set("Accept", header)
+	}
+	r.ServeHTTP(tlvResp, c.Request)
+	return attestation, tlvResp
+}
+
+func SerializeAttestationResponse(t *testing.T, attestation *api.OracleAttestation) []byte {
+	pubkey, err := dlccrypto.NewSchnorrPublicKey(OraclePublicKey)
+	assert.NoError(t, err)
+	sigs := make([]dlccrypto.Signature, 0)
+	for _, s := range attestation.Signatures {
+		sig, err := dlccrypto.NewSignature(s)
+		assert.NoError(t, err)
+		sigs = append(sigs, *sig)
+	}
+	return dlccrypto.SerializeAttestation(attestation.EventID, pubkey, sigs, attestation.Values)
+}
+
+func TestAssetController_GetAssetAttestation_WithTLVFormat_ReturnsHexTLV(t *testing.T) {
+	attestation, tlvResp := GetAttestationWithFormat(t, "", "?"+api.QueryParamFormat+"="+api.FormatTLV)
+	if attestation == nil {
+		return
+	}
+
+	if assert.Equal(t, http.StatusOK, tlvResp.Code) {
+		actual, err := hex.DecodeString(tlvResp.Body.String())
+		assert.NoError(t, err)
+		assert.Equal(t, SerializeAttestationResponse(t, attestation), actual)
+	}
+}
+
+func TestAssetController_GetAssetAttestation_WithOctetStreamAccept_ReturnsRawTLV(t *testing.T) {
+	attestation, tlvResp := GetAttestationWithFormat(t, api.MIMEOctetStream, "")
+	if attestation == nil {
+		return
+	}
+
+	if assert.Equal(t, http.StatusOK, tlvResp.Code) {
+		assert.Equal(t, api.MIMEOctetStream, tlvResp.Header().Get("Content-Type"))
+		assert.Equal(t, SerializeAttestationResponse(t, attestation), tlvResp.Body.Bytes())
+	}
+}
+
+func SerializeEventResponse(t *testing.T, event *api.OracleEvent) []byte {
+	nonces := make([]dlccrypto.SchnorrPublicKey, 0)
+	for _, s := range event.Nonces {
+		k, err := dlccrypto.NewSchnorrPublicKey(s)
+		assert.NoError(t, err)
+		nonces = append(nonces, *k)
+	}
+	if event.EventDescriptor.EnumEventDescriptor != nil {
+		return dlccrypto.SerializeEnumEvent(
+			nonces,
+			uint32(event.EventMaturityEpoch),
+			event.EventDescriptor.EnumEventDescriptor.Outcomes,
+			event.EventID,
+		)
+	}
+	descriptor := event.EventDescriptor.DigitDecompositionDescriptor
+	return dlccrypto.SerializeEvent(
+		nonces,
+		uint32(event.EventMaturityEpoch),
+		uint16(descriptor.Base),
+		descriptor.IsSigned,
+		descriptor.Unit,
+		int32(descriptor.Precision),
+		uint16(descriptor.NbDigits),
+		event.EventID,
+	)
+}
+
+func SerializeAnnouncementResponse(t *testing.T, announcement *api.OracleAnnouncement) []byte {
+	event := SerializeEventResponse(t, &announcement.OracleEvent)
+	sig, err := dlccrypto.NewSignature(announcement.AnnouncementSignature)
+	assert.NoError(t, err)
+	pubkey, err := dlccrypto.NewSchnorrPublicKey(announcement.OraclePublicKey)
+	assert.NoError(t, err)
+	return dlccrypto.SerializeAnnouncement(sig, pubkey, event)
+}
+
+func TestAssetController_GetAssetAnnouncement_SignedEvent_HasSignNonceAndValidSignature(t *testing.T) {
+	oracleInstance, _ := NewTestOracleService()
+	crypto := cfddlccrypto.NewCfdgoCryptoService()
+	config := *TestAssetConfig
+	config.SignConfig.IsSigned = true
+	config.SignConfig.Precision = -2
+	resp := httptest.NewRecorder()
+	c, r := SetupAssetEngineWithConfig(resp, &config, oracleInstance, crypto, nil)
+	route := GetRouteWithTimeParam(api.RouteGETAssetAnnouncement, time.Now().Add(1*time.Hour))
+	c.Request, _ = http.NewRequest(http.MethodGet, route, nil)
+	r.ServeHTTP(resp, c.Request)
+
+	var announcement api.OracleAnnouncement
+	err := json.Unmarshal(resp.Body.Bytes(), &announcement)
+	if !assert.NoError(t, err) {
+		return
+	}
+	descriptor := announcement.OracleEvent.EventDescriptor.DigitDecompositionDescriptor
+	assert.True(t, descriptor.IsSigned)
+	assert.Equal(t, config.SignConfig.Precision, descriptor.Precision)
+	assert.Equal(t, config.SignConfig.NbDigits, descriptor.NbDigits)
+	assert.Len(t, announcement.OracleEvent.Nonces, config.SignConfig.NbDigits+1)
+
+	pubkey, _ := dlccrypto.NewSchnorrPublicKey(announcement.OraclePublicKey)
+	sig, _ := dlccrypto.NewSignature(announcement.AnnouncementSignature)
+	valid, _ := crypto.VerifySchnorrSignatureRaw(pubkey, sig, SerializeEventResponse(t, &announcement.OracleEvent))
+	assert.True(t, valid)
+}
+
+var TestEnumAssetConfig = &api.AssetConfig{
+	StartDate: TestAssetConfig.StartDate,
+	Frequency: TestAssetConfig.Frequency,
+	RangeD:    TestAssetConfig.RangeD,
+	Outcomes:  []string{"below", "above"},
+}
+
+func TestAssetController_GetAssetAnnouncement_EnumEvent_HasSingleNonceAndValidSignature(t *testing.T) {
+	oracleInstance, _ := NewTestOracleService()
+	crypto := cfddlccrypto.NewCfdgoCryptoService()
+	resp := httptest.NewRecorder()
+	c, r := SetupAssetEngineWithConfig(resp, TestEnumAssetConfig, oracleInstance, crypto, nil)
+	route := GetRouteWithTimeParam(api.RouteGETAssetAnnouncement, time.Now().Add(1*time.Hour))
+	c.Request, _ = http.NewRequest(http.MethodGet, route, nil)
+	r.ServeHTTP(resp, c.Request)
+
+	var announcement api.OracleAnnouncement
+	err := json.Unmarshal(resp.Body.Bytes(), &announcement)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Nil(t, announcement.OracleEvent.EventDescriptor.DigitDecompositionDescriptor)
+	if assert.NotNil(t, announcement.OracleEvent.EventDescriptor.EnumEventDescriptor) {
+		assert.Equal(t, TestEnumAssetConfig.Outcomes, announcement.OracleEvent.EventDescriptor.EnumEventDescriptor.Outcomes)
+	}
+	assert.Len(t, announcement.OracleEvent.Nonces, 1)
+
+	pubkey, _ := dlccrypto.NewSchnorrPublicKey(announcement.OraclePublicKey)
+	sig, _ := dlccrypto.NewSignature(announcement.AnnouncementSignature)
+	valid, _ := crypto.VerifySchnorrSignatureRaw(pubkey, sig, SerializeEventResponse(t, &announcement.OracleEvent))
+	assert.True(t, valid)
+
+	tlvResp := httptest.NewRecorder()
+	c.Request, _ = http.NewRequest(http.MethodGet, route+"?"+api.QueryParamFormat+"="+api.FormatTLV, nil)
+	r.ServeHTTP(tlvResp, c.Request)
+	if assert.Equal(t, http.StatusOK, tlvResp.Code) {
+		actual, err := hex.DecodeString(tlvResp.Body.String())
+		assert.NoError(t, err)
+		assert.Equal(t, SerializeAnnouncementResponse(t, &announcement), actual)
+	}
+}
+
+func TestAssetController_GetAssetAttestation_EnumEvent_SignsOutcome(t *testing.T) {
+	oracleInstance, _ := NewTestOracleService()
+	crypto := cfddlccrypto.NewCfdgoCryptoService()
+	ctrl := gomock.NewController(t)
+	feed := mock_datafeed.NewMockDataFeed(ctrl)
+	publishDate := InDbDLCData.PublishedDate.Add(2 * TestEnumAssetConfig.Frequency)
+	outcome := "above"
+	feed.EXPECT().FindPastOutcome(TestAsset.AssetID, publishDate, TestEnumAssetConfig.Outcomes).Return(&outcome, nil)
+	resp := httptest.NewRecorder()
+	c, r := SetupAssetEngineWithConfig(resp, TestEnumAssetConfig, oracleInstance, crypto, feed)
+	route := GetRouteWithTimeParam(api.RouteGETAssetAttestation, publishDate)
+	c.Request, _ = http.NewRequest(http.MethodGet, route, nil)
+	r.ServeHTTP(resp, c.Request)
+
+	if assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String()) {
+		actual := &api.OracleAttestation{}
+		err := json.Unmarshal(resp.Body.Bytes(), actual)
+		if assert.NoError(t, err) && assert.Len(t, actual.Signatures, 1) {
+			assert.Equal(t, []string{outcome}, actual.Values)
+			sig, _ := dlccrypto.NewSignature(actual.Signatures[0])
+			valid, err := crypto.VerifySchnorrSignature(oracleInstance.PublicKey(), sig, outcome)
+			assert.NoError(t, err)
+			assert.True(t, valid)
+		}
+	}
+}
+
+func TestAssetController_GetAssetAttestation_WithEncryptedKvalues_SignsWithStoredKvalues(t *testing.T) {
+	oracleInstance, _ := NewTestOracleService()
+	keyring, err := envelope.NewKeyring(map[string][]byte{"master": bytes.Repeat([]byte{1}, 32)}, "master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	oracleInstance.KvalueKeyring = keyring
+	crypto := cfddlccrypto.NewCfdgoCryptoService()
+	publishDate := InDbDLCData.PublishedDate.Add(3 * TestAssetConfig.Frequency)
+	ctrl := gomock.NewController(t)
+	feed := mock_datafeed.NewMockDataFeed(ctrl)
+	feed.EXPECT().FindPastAssetPriceRecord(TestAsset.AssetID, publishDate).Return(
+		&datafeed.PriceRecord{Price: datafeedValue, Source: datafeed.DummySource, Timestamp: publishDate}, nil)
+
+	// event announced with stored kvalues, encrypted by the migration
+	orm := test.NewOrm(&entity.Asset{}, &entity.EventData{}, &entity.PriceProvenance{}, &entity.NonceSignature{}, &entity.StreamEntry{})
+	orm.GetDB().Create(TestAsset)
+	orm.GetDB().Create(&entity.EventData{
+		AssetID:       TestAsset.AssetID,
+		PublishedDate: publishDate,
+		Nonces:        TestResponseValues.Rvalues,
+		Kvalues:       TestResponseValues.Kvalues,
+		Base:          10,
+	})
+	_, err = entity.EncryptStoredKvalues(orm.GetDB(), keyring)
+	if err != nil {
+		t.Fatal(err)
+	}
+	setup := func(c *gin.Context) {
+		c.Set(api.ContextIDOracle, oracleInstance)
+		c.Set(api.ContextIDCryptoService, crypto)
+		c.Set(api.ContextIDDataFeed, feed)
+		c.Set(api.ContextIDOrm, orm)
+	}
+	resp := httptest.NewRecorder()
+	c, r := SetupEngine(resp, api.NewAssetController(TestAsset.AssetID, *TestAssetConfig), api.ErrorHandler(), setup)
+	c.Request, _ = http.NewRequest(http.MethodGet, GetRouteWithTimeParam(api.RouteGETAssetAttestation, publishDate), nil)
+	r.ServeHTTP(resp, c.Request)
+
+	if assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String()) {
+		actual := &api.OracleAttestation{}
+		err := json.Unmarshal(resp.Body.Bytes(), actual)
+		if assert.NoError(t, err) {
+			assert.Equal(t, TestResponseValues.Values, actual.Values)
+			assert.Equal(t, TestResponseValues.Signatures, actual.Signatures)
+		}
+		stored, _ := entity.FindDLCDataPublishedAt(orm.GetDB(), TestAsset.AssetID, publishDate)
+		assert.False(t, stored.HasStoredKvalues())
+	}
+}
+
+func TestAssetController_GetAssetAttestation_EnumEvent_UnknownOutcome_ReturnsError(t *testing.T) {
+	oracleInstance, _ := NewTestOracleService()
+	crypto := cfddlccrypto.NewCfdgoCryptoService()
+	ctrl := gomock.NewController(t)
+	feed := mock_datafeed.NewMockDataFeed(ctrl)
+	publishDate := InDbDLCData.PublishedDate.Add(2 * TestEnumAssetConfig.Frequency)
+	outcome := "unknown"
+	feed.EXPECT().FindPastOutcome(TestAsset.AssetID, publishDate, TestEnumAssetConfig.Outcomes).Return(&outcome, nil)
+	resp := httptest.NewRecorder()
+	c, r := SetupAssetEngineWithConfig(resp, TestEnumAssetConfig, oracleInstance, crypto, feed)
+	route := GetRouteWithTimeParam(api.RouteGETAssetAttestation, publishDate)
+	c.Request, _ = http.NewRequest(http.MethodGet, route, nil)
+	r.ServeHTTP(resp, c.Request)
+
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+}
+
+func TestAssetController_GetAssetAttestation_DataFeedUnavailable_ReturnsServiceUnavailable(t *testing.T) {
+	oracleInstance, _ := NewTestOracleService()
+	crypto := cfddlccrypto.NewCfdgoCryptoService()
+	ctrl := gomock.NewController(t)
+	feed := mock_datafeed.NewMockDataFeed(ctrl)
+	publishDate := InDbDLCData.PublishedDate.Add(2 * TestEnumAssetConfig.Frequency)
+	feed.EXPECT().FindPastOutcome(TestAsset.AssetID, publishDate, TestEnumAssetConfig.Outcomes).Return(
+		nil, datafeed.NewHTTPError("source", http.StatusBadGateway, http.Header{}, "bad gateway"))
+	resp := httptest.NewRecorder()
+	c, r := SetupAssetEngineWithConfig(resp, TestEnumAssetConfig, oracleInstance, crypto, feed)
+	route := GetRouteWithTimeParam(api.RouteGETAssetAttestation, publishDate)
+	c.Request, _ = http.NewRequest(http.MethodGet, route, nil)
+	r.ServeHTTP(resp, c.Request)
+
+	if assert.Equal(t, http.StatusServiceUnavailable, resp.Code) {
+		actual := &api.ErrorResponse{}
+		err := json.Unmarshal(resp.Body.Bytes(), actual)
+		assert.NoError(t, err)
+		assert.Equal(t, api.UnavailableDataFeedErrorCode, actual.ErrorCode)
+	}
+}
+
+func TestAssetController_GetAssetAttestation_DataFeedSourceError_ReturnsBadGateway(t *testing.T) {
+	oracleInstance, _ := NewTestOracleService()
+	crypto := cfddlccrypto.NewCfdgoCryptoService()
+	ctrl := gomock.NewController(t)
+	feed := mock_datafeed.NewMockDataFeed(ctrl)
+	publishDate := InDbDLCData.PublishedDate.Add(2 * TestEnumAssetConfig.Frequency)
+	feed.EXPECT().FindPastOutcome(TestAsset.AssetID, publishDate, TestEnumAssetConfig.Outcomes).Return(
+		nil, datafeed.NewAPIError("source", datafeed.ErrorKindAPI, "invalid parameter"))
+	resp := httptest.NewRecorder()
+	c, r := SetupAssetEngineWithConfig(resp, TestEnumAssetConfig, oracleInstance, crypto, feed)
+	route := GetRouteWithTimeParam(api.RouteGETAssetAttestation, publishDate)
+	c.Request, _ = http.NewRequest(http.MethodGet, route, nil)
+	r.ServeHTTP(resp, c.Request)
+
+	if assert.Equal(t, http.StatusBadGateway, resp.Code) {
+		actual := &api.ErrorResponse{}
+		err := json.Unmarshal(resp.Body.Bytes(), actual)
+		assert.NoError(t, err)
+		assert.Equal(t, api.SourceDataFeedErrorCode, actual.ErrorCode)
+	}
+}
+
+func TestAssetController_GetAssetAttestation_NonceUsedForOtherValue_ReturnsErrorAndDoesNotSign(t *testing.T) {
+	oracleInstance, _ := NewTestOracleService()
+	crypto := cfddlccrypto.NewCfdgoCryptoService()
+	ctrl := gomock.NewController(t)
+	feed := mock_datafeed.NewMockDataFeed(ctrl)
+	publishDate := InDbDLCData.PublishedDate.Add(2 * TestEnumAssetConfig.Frequency)
+	outcome := "above"
+	feed.EXPECT().FindPastOutcome(TestAsset.AssetID, publishDate, TestEnumAssetConfig.Outcomes).Return(&outcome, nil)
+	nonce, err := oracleInstance.Keys[0].Signer.DeriveSchnorrNonce(TestAsset.AssetID, uint32(publishDate.Unix()), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the nonce of the event was already used to sign another outcome
+	orm := test.NewOrm(&entity.Asset{}, &entity.EventData{}, &entity.PriceProvenance{}, &entity.NonceSignature{}, &entity.StreamEntry{})
+	orm.GetDB().Create(TestAsset)
+	orm.GetDB().Create(&entity.EventData{
+		AssetID:       TestAsset.AssetID,
+		PublishedDate: publishDate,
+		Nonces:        []string{nonce.EncodeToString()},
+		Outcomes:      TestEnumAssetConfig.Outcomes,
+	})
+	err = entity.RecordNonceSignatures(orm.GetDB(), "other", []string{nonce.EncodeToString() + nonce.EncodeToString()}, []string{"below"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	setup := func(c *gin.Context) {
+		c.Set(api.ContextIDOracle, oracleInstance)
+		c.Set(api.ContextIDCryptoService, crypto)
+		c.Set(api.ContextIDDataFeed, feed)
+		c.Set(api.ContextIDOrm, orm)
+	}
+	resp := httptest.NewRecorder()
+	c, r := SetupEngine(resp, api.NewAssetController(TestAsset.AssetID, *TestEnumAssetConfig), api.ErrorHandler(), setup)
+	c.Request, _ = http.NewRequest(http.MethodGet, GetRouteWithTimeParam(api.RouteGETAssetAttestation, publishDate), nil)
+	r.ServeHTTP(resp, c.Request)
+
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	stored, _ := entity.FindDLCDataPublishedAt(orm.GetDB(), TestAsset.AssetID, publishDate)
+	assert.False(t, stored.HasSignature())
+}
+
+func TestAssetController_GetAssetAttestationProvenance_AfterAttestation_ReturnsProvenance(t *testing.T) {
+	oracleInstance, _ := NewTestOracleService()
+	crypto := cfddlccrypto.NewCfdgoCryptoService()
+	ctrl := gomock.NewController(t)
+	feed := mock_datafeed.NewMockDataFeed(ctrl)
+	publishDate := InDbDLCData.PublishedDate.Add(2 * TestAssetConfig.Frequency)
+	sourceTimestamp := publishDate.Add(-time.Minute)
+	record := &datafeed.PriceRecord{
+		Price:     datafeedValue,
+		Source:    datafeed.AggregatedSource,
+		Timestamp: publishDate,
+		Reference: datafeed.ReferenceClose,
+		Stale:     true,
+		Sources:   []datafeed.SourcePrice{{Source: "a", Price: datafeedValue, Timestamp: sourceTimestamp, Stale: true}},
+		Rejected:  []datafeed.SourcePrice{{Source: "b", Price: 2 * datafeedValue, Timestamp: sourceTimestamp}},
+	}
+	feed.EXPECT().FindPastAssetPriceRecord(TestAsset.AssetID, publishDate).Return(record, nil)
+	resp := httptest.NewRecorder()
+	c, r := SetupAssetEngine(resp, oracleInstance, crypto, feed)
+	c.Request, _ = http.NewRequest(http.MethodGet, GetRouteWithTimeParam(api.RouteGETAssetAttestation, publishDate), nil)
+	r.ServeHTTP(resp, c.Request)
+	assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+	resp = httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, GetRouteWithTimeParam(api.RouteGETAssetAttestationProvenance, publishDate), nil)
+	r.ServeHTTP(resp, req)
+
+	if assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String()) {
+		actual := &api.PriceProvenanceResponse{}
+		err := json.Unmarshal(resp.Body.Bytes(), actual)
+		if assert.NoError(t, err) {
+			assert.Equal(t, entity.ComputeEventEventID(TestAsset.AssetID, &publishDate), actual.EventID)
+			assert.Equal(t, datafeedValue, actual.Value)
+			assert.Equal(t, datafeed.AggregatedSource, actual.Source)
+			assert.True(t, publishDate.Equal(actual.SourceTimestamp))
+			assert.Equal(t, datafeed.ReferenceClose, actual.Reference)
+			assert.True(t, actual.Stale)
+			assert.Equal(t, &api.SettlementResponse{Method: datafeed.MethodSpot}, actual.Settlement)
+			assert.Equal(t, 100, actual.RoundedValue)
+			assert.Equal(t, TestResponseValues.Values, actual.Values)
+			if assert.Len(t, actual.Sources, 2) {
+				assert.Equal(t, "a", actual.Sources[0].Source)
+				assert.False(t, actual.Sources[0].Rejected)
+				assert.True(t, actual.Sources[0].Stale)
+				assert.Equal(t, "b", actual.Sources[1].Source)
+				assert.True(t, actual.Sources[1].Rejected)
+			}
+		}
+	}
+}
+
+func TestAssetController_GetAssetAttestation_TWAPSettlement_SignsAverageOverWindow(t *testing.T) {
+	oracleInstance, _ := NewTestOracleService()
+	crypto := cfddlccrypto.NewCfdgoCryptoService()
+	ctrl := gomock.NewController(t)
+	feed := mock_datafeed.NewMockDataFeed(ctrl)
+	publishDate := InDbDLCData.PublishedDate.Add(2 * TestAssetConfig.Frequency)
+	config := *TestAssetConfig
+	config.SettlementMethod = datafeed.MethodTWAP
+	config.SettlementWindow = 10 * time.Minute
+	config.SettlementMinCoverage = 0.9
+	config.SettlementMinCandles = 2
+	candles := []datafeed.Candle{
+		{Time: publishDate.Add(-10 * time.Minute), Interval: 5 * time.Minute, Close: 99.5},
+		{Time: publishDate.Add(-5 * time.Minute), Interval: 5 * time.Minute, Close: 100.5},
+	}
+	feed.EXPECT().FindPastAssetPriceSeries(TestAsset.AssetID, publishDate.Add(-10*time.Minute), publishDate).Return(candles, nil)
+	resp := httptest.NewRecorder()
+	c, r := SetupAssetEngineWithConfig(resp, &config, oracleInstance, crypto, feed)
+	c.Request, _ = http.NewRequest(http.MethodGet, GetRouteWithTimeParam(api.RouteGETAssetAttestation, publishDate), nil)
+	r.ServeHTTP(resp, c.Request)
+	assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+	resp = httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, GetRouteWithTimeParam(api.RouteGETAssetAttestationProvenance, publishDate), nil)
+	r.ServeHTTP(resp, req)
+
+	if assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String()) {
+		actual := &api.PriceProvenanceResponse{}
+		err := json.Unmarshal(resp.Body.Bytes(), actual)
+		if assert.NoError(t, err) {
+			assert.Equal(t, 100.0, actual.Value)
+			assert.Equal(t, &api.SettlementResponse{Method: datafeed.MethodTWAP, Window: "PT10M"}, actual.Settlement)
+			assert.Equal(t, []string{"1", "0", "0"}, actual.Values)
+		}
+	}
+}
+
+func TestAssetController_GetAssetAttestationProvenance_NotAvailable_ReturnsNotFound(t *testing.T) {
+	tests := []struct {
+		name        string
+		publishDate time.Time
+	}{
+		{name: "not in db", publishDate: InDbDLCData.PublishedDate.Add(TestAssetConfig.Frequency)},
+		{name: "signed without provenance", publishDate: InDbDLCData.PublishedDate},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp := httptest.NewRecorder()
+			c, r := SetupAssetEngine(resp, nil, nil, nil)
+			c.Request, _ = http.NewRequest(http.MethodGet, GetRouteWithTimeParam(api.RouteGETAssetAttestationProvenance, test.publishDate), nil)
+
+			r.ServeHTTP(resp, c.Request)
+
+			assert.Equal(t, http.StatusNotFound, resp.Code, resp.Body.String())
+		})
+	}
+}
+
+func SetupAssetEngineWithAnnouncedEvents(t *testing.T, recorder *httptest.ResponseRecorder, nbEvents int) (*gin.Context, *gin.Engine, []time.Time) {
+	oracleInstance, err := NewTestOracleService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, r := SetupAssetEngine(recorder, oracleInstance, cfddlccrypto.NewCfdgoCryptoService(), nil)
+	next := time.Now().UTC().Truncate(TestAssetConfig.Frequency)
+	dates := make([]time.Time, nbEvents)
+	for i := range dates {
+		dates[i] = next.Add(time.Duration(i+1) * TestAssetConfig.Frequency)
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, GetRouteWithTimeParam(api.RouteGETAssetAnnouncement, dates[i]), nil)
+		r.ServeHTTP(resp, req)
+		if resp.Code != http.StatusOK {
+			t.Fatal(resp.Body.String())
+		}
+	}
+	return c, r, dates
+}
+
+func GetAssetEvents(t *testing.T, r *gin.Engine, query string) (*httptest.ResponseRecorder, *api.EventsResponse) {
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, api.RouteGETAssetEvents+query, nil)
+	r.ServeHTTP(resp, req)
+	actual := &api.EventsResponse{}
+	if resp.Code == http.StatusOK {
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), actual))
+	}
+	return resp, actual
+}
+
+func TestAssetController_GetAssetEvents_ReturnsAllEvents(t *testing.T) {
+	_, r, dates := SetupAssetEngineWithAnnouncedEvents(t, httptest.NewRecorder(), 2)
+
+	resp, actual := GetAssetEvents(t, r, "")
+
+	if assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String()) && assert.Len(t, actual.Events, 3) {
+		assert.Empty(t, actual.NextCursor)
+		assert.Equal(t, InDbDLCData.GetEventID(), actual.Events[0].EventID)
+		assert.Equal(t, api.EventStatusAttested, actual.Events[0].Status)
+		assert.Equal(t, []string(InDbDLCData.Values), actual.Events[0].Attestation.Values)
+		for i, date := range dates {
+			event := actual.Events[i+1]
+			assert.Equal(t, entity.ComputeEventEventID(TestAsset.AssetID, &date), event.EventID)
+			assert.True(t, date.Equal(event.PublishedDate))
+			assert.Equal(t, api.EventStatusAnnounced, event.Status)
+			assert.Len(t, event.Announcement.OracleEvent.Nonces, TestAssetConfig.SignConfig.NbDigits)
+			assert.Nil(t, event.Attestation)
+		}
+	}
+}
+
+func TestAssetController_GetAssetEvents_WithFilters_ReturnsMatchingEvents(t *testing.T) {
+	_, r, dates := SetupAssetEngineWithAnnouncedEvents(t, httptest.NewRecorder(), 3)
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{
+			name:     "attested",
+			query:    "?status=attested",
+			expected: []string{InDbDLCData.GetEventID()},
+		},
+		{
+			name:  "announced",
+			query: "?status=announced",
+			expected: []string{
+				entity.ComputeEventEventID(TestAsset.AssetID, &dates[0]),
+				entity.ComputeEventEventID(TestAsset.AssetID, &dates[1]),
+				entity.ComputeEventEventID(TestAsset.AssetID, &dates[2]),
+			},
+		},
+		{
+			name:  "range",
+			query: "?from=" + dates[1].Format(api.TimeFormatISO8601) + "&to=" + dates[2].Format(api.TimeFormatISO8601),
+			expected: []string{
+				entity.ComputeEventEventID(TestAsset.AssetID, &dates[1]),
+				entity.ComputeEventEventID(TestAsset.AssetID, &dates[2]),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp, actual := GetAssetEvents(t, r, test.query)
+
+			if assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String()) {
+				eventIDs := make([]string, len(actual.Events))
+				for i, event := range actual.Events {
+					eventIDs[i] = event.EventID
+				}
+				assert.Equal(t, test.expected, eventIDs)
+			}
+		})
+	}
+}
+
+func TestAssetController_GetAssetEvents_WithLimit_ReturnsPages(t *testing.T) {
+	_, r, dates := SetupAssetEngineWithAnnouncedEvents(t, httptest.NewRecorder(), 3)
+
+	resp, firstPage := GetAssetEvents(t, r, "?limit=2")
+	if assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String()) && assert.Len(t, firstPage.Events, 2) {
+		assert.Equal(t, InDbDLCData.GetEventID(), firstPage.Events[0].EventID)
+		assert.NotEmpty(t, firstPage.NextCursor)
+	}
+
+	resp, secondPage := GetAssetEvents(t, r, "?limit=2&cursor="+firstPage.NextCursor)
+	if assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String()) && assert.Len(t, secondPage.Events, 2) {
+		assert.Equal(t, entity.ComputeEventEventID(TestAsset.AssetID, &dates[1]), secondPage.Events[0].EventID)
+		assert.Equal(t, entity.ComputeEventEventID(TestAsset.AssetID, &dates[2]), secondPage.Events[1].EventID)
+		assert.Empty(t, secondPage.NextCursor)
+	}
+}
+
+func TestAssetController_GetAssetEvents_InvalidParameter_ReturnsBadRequest(t *testing.T) {
+	tests := []struct {
+		name         string
+		query        string
+		expectedCode int
+	}{
+		{name: "invalid time", query: "?from=yesterday", expectedCode: api.InvalidTimeFormatBadRequestErrorCode},
+		{name: "invalid status", query: "?status=pending", expectedCode: api.InvalidQueryParameterBadRequestErrorCode},
+		{name: "invalid limit", query: "?limit=abc", expectedCode: api.InvalidQueryParameterBadRequestErrorCode},
+		{name: "limit too high", query: "?limit=1001", expectedCode: api.InvalidQueryParameterBadRequestErrorCode},
+		{name: "invalid cursor", query: "?cursor=abc", expectedCode: api.InvalidQueryParameterBadRequestErrorCode},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp := httptest.NewRecorder()
+			c, r := SetupAssetEngine(resp, nil, nil, nil)
+			c.Request, _ = http.NewRequest(http.MethodGet, api.RouteGETAssetEvents+test.query, nil)
+
+			r.ServeHTTP(resp, c.Request)
+
+			if assert.Equal(t, http.StatusBadRequest, resp.Code) {
+				actual := &api.ErrorResponse{}
+				if assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), actual)) {
+					assert.Equal(t, test.expectedCode, actual.ErrorCode)
+				}
+			}
+		})
+	}
+}
+
+func getAnnouncementPoints(t *testing.T, r *gin.Engine, date time.Time) *api.AnnouncementPointsResponse {
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, GetRouteWithTimeParam(api.RouteGETAssetAnnouncementPoints, date), nil)
+	r.ServeHTTP(resp, req)
+	if !assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String()) {
+		return nil
+	}
+	assert.Contains(t, resp.Header().Get("Cache-Control"), "immutable")
+	points := &api.AnnouncementPointsResponse{}
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), points))
+	return points
+}
+
+func TestAssetController_GetAssetAnnouncementPoints_SignedEvent_PointsMatchAttestation(t *testing.T) {
+	oracleInstance, _ := NewTestOracleService()
+	crypto := cfddlccrypto.NewCfdgoCryptoService()
+	ctrl := gomock.NewController(t)
+	feed := mock_datafeed.NewMockDataFeed(ctrl)
+	config := *TestAssetConfig
+	config.SignConfig.IsSigned = true
+	publishDate := InDbDLCData.PublishedDate.Add(2 * TestAssetConfig.Frequency)
+	feed.EXPECT().FindPastAssetPriceRecord(TestAsset.AssetID, publishDate).Return(
+		&datafeed.PriceRecord{Price: -datafeedValue, Source: datafeed.DummySource, Timestamp: publishDate}, nil)
+	resp := httptest.NewRecorder()
+	c, r := SetupAssetEngineWithConfig(resp, &config, oracleInstance, crypto, feed)
+
+	points := getAnnouncementPoints(t, r, publishDate)
+	c.Request, _ = http.NewRequest(http.MethodGet, GetRouteWithTimeParam(api.RouteGETAssetAttestation, publishDate), nil)
+	r.ServeHTTP(resp, c.Request)
+
+	if !assert.NotNil(t, points) || !assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String()) {
+		return
+	}
+	attestation := &api.OracleAttestation{}
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), attestation))
+	assert.Equal(t, attestation.EventID, points.EventID)
+	assert.Equal(t, oracleInstance.PublicKey().EncodeToString(), points.OraclePublicKey)
+	if !assert.Len(t, points.Nonces, config.SignConfig.NbDigits+1) {
+		return
+	}
+	assert.Equal(t, []string{dlccrypto.PositiveSign, dlccrypto.NegativeSign}, points.Nonces[0].Outcomes)
+	assert.Equal(t, []string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9"}, points.Nonces[1].Outcomes)
+	for i, nonce := range points.Nonces {
+		assert.Len(t, nonce.Points, len(nonce.Outcomes))
+		signature := attestation.Signatures[i]
+		assert.Equal(t, nonce.Nonce, signature[:64])
+		// the point of the attested value is the point of its signature s*G
+		s, _ := hex.DecodeString(signature[64:])
+		expected := hex.EncodeToString(secp256k1.PrivKeyFromBytes(s).PubKey().SerializeCompressed())
+		for j, outcome := range nonce.Outcomes {
+			if outcome == attestation.Values[i] {
+				assert.Equal(t, expected, nonce.Points[j])
+			} else {
+				assert.NotEqual(t, expected, nonce.Points[j])
+			}
+		}
+	}
+}
+
+func TestAssetController_GetAssetAnnouncementPoints_EnumEvent_ReturnsOutcomePoints(t *testing.T) {
+	oracleInstance, _ := NewTestOracleService()
+	crypto := cfddlccrypto.NewCfdgoCryptoService()
+	resp := httptest.NewRecorder()
+	_, r := SetupAssetEngineWithConfig(resp, TestEnumAssetConfig, oracleInstance, crypto, nil)
+
+	points := getAnnouncementPoints(t, r, time.Now().Add(1*time.Hour))
+
+	if assert.NotNil(t, points) && assert.Len(t, points.Nonces, 1) {
+		assert.Equal(t, TestEnumAssetConfig.Outcomes, points.Nonces[0].Outcomes)
+		assert.Len(t, points.Nonces[0].Points, len(TestEnumAssetConfig.Outcomes))
+	}
+}
+
+func TestAssetController_GetAssetAnnouncementPoints_SameEvent_ComputedOnce(t *testing.T) {
+	oracleInstance, _ := NewTestOracleService()
+	ctrl := gomock.NewController(t)
+	crypto := mock_dlccrypto.NewMockCryptoService(ctrl)
+	point, _ := dlccrypto.NewPoint("02" + TestResponseValues.Rvalues[0])
+	nbPoints := TestAssetConfig.SignConfig.NbDigits * TestAssetConfig.SignConfig.Base
+	crypto.EXPECT().ComputeSigPoint(gomock.Any(), gomock.Any(), gomock.Any()).Return(point, nil).Times(nbPoints)
+	resp := httptest.NewRecorder()
+	_, r := SetupAssetEngine(resp, oracleInstance, crypto, nil)
+	date := time.Now().Add(1 * time.Hour)
+
+	first := getAnnouncementPoints(t, r, date)
+	second := getAnnouncementPoints(t, r, date)
+
+	assert.Equal(t, first, second)
+}
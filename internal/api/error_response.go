@@ -3,8 +3,10 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"p2pderivatives-oracle/internal/datafeed"
 
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
 )
 
 const (
@@ -33,6 +35,18 @@ const (
 
 	// UnknownCryptoErrorCode represents an error caused by the crypto computation resulting in an unexpected state.
 	UnknownCryptoErrorCode
+
+	// InvalidQueryParameterBadRequestErrorCode represents a query parameter having an invalid value.
+	InvalidQueryParameterBadRequestErrorCode
+	// InvalidBodyBadRequestErrorCode represents a request body which could not be parsed.
+	InvalidBodyBadRequestErrorCode
+
+	// DataFeedErrorCode
+
+	// UnavailableDataFeedErrorCode represents a datafeed source temporarily failing (the request can be retried later).
+	UnavailableDataFeedErrorCode
+	// SourceDataFeedErrorCode represents a datafeed source answering with an error.
+	SourceDataFeedErrorCode
 )
 
 // ErrorResponse represents an error response from the api
@@ -104,6 +118,29 @@ func NewUnknownDataFeedError(cause error) *Error {
 	return NewUnknownInternalError(cause, "Datafeed")
 }
 
+// NewDataFeedError returns a datafeed error, a service unavailable error if the datafeed source failed
+// temporarily or a bad gateway error if it answered with an error
+func NewDataFeedError(cause error) *Error {
+	var sourceError *datafeed.SourceError
+	if !errors.As(cause, &sourceError) {
+		return NewUnknownDataFeedError(cause)
+	}
+	if sourceError.Temporary() {
+		return &Error{
+			HTTPStatusCode: http.StatusServiceUnavailable,
+			ErrorCode:      UnavailableDataFeedErrorCode,
+			ClientMessage:  "Datafeed unavailable: please retry later",
+			Cause:          cause,
+		}
+	}
+	return &Error{
+		HTTPStatusCode: http.StatusBadGateway,
+		ErrorCode:      SourceDataFeedErrorCode,
+		ClientMessage:  "Datafeed error: the datafeed source returned an error",
+		Cause:          cause,
+	}
+}
+
 // NewUnknownCryptoServiceError returns an unknown CryptoService error with default message
 func NewUnknownCryptoServiceError(cause error) *Error {
 	return NewUnknownInternalError(cause, "CryptoService")
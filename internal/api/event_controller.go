@@ -0,0 +1,122 @@
+package api
+
+import (
+	"p2pderivatives-oracle/internal/database/entity"
+	"p2pderivatives-oracle/internal/datafeed"
+	"p2pderivatives-oracle/internal/oracle"
+	"time"
+
+	"github.com/cryptogarageinc/server-common-go/pkg/database/orm"
+
+	ginlogrus "github.com/Bose/go-gin-logrus"
+	"github.com/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	// URLParamTagEventID Tag to use as event ID parameter in route
+	URLParamTagEventID = "eventId"
+	// RouteGETEventAnnouncement relative GET route to retrieve the announcement of an event
+	RouteGETEventAnnouncement = "/:" + URLParamTagEventID + "/announcement"
+	// RouteGETEventAttestation relative GET route to retrieve the attestation of an event
+	RouteGETEventAttestation = "/:" + URLParamTagEventID + "/attestation"
+)
+
+// EventController represents the event api Controller, giving access to the events by their ID
+type EventController struct {
+	// the asset controllers are used to attest the events so that they share the same locks
+	assetControllers map[string]*AssetController
+}
+
+// NewEventController creates a new Controller structure with the given parameters.
+func NewEventController(assetControllers map[string]*AssetController) Controller {
+	return &EventController{
+		assetControllers: assetControllers,
+	}
+}
+
+// Routes list and binds all routes to the router group provided
+func (ct *EventController) Routes(route *gin.RouterGroup) {
+	route.GET(RouteGETEventAnnouncement, ct.GetEventAnnouncement)
+	route.GET(RouteGETEventAttestation, ct.GetEventAttestation)
+}
+
+// GetEventAnnouncement handler returns the announcement of the event with the requested ID
+// (the announcement can be returned as an oracle_announcement TLV, see renderWithFormat)
+func (ct *EventController) GetEventAnnouncement(c *gin.Context) {
+	ginlogrus.SetCtxLoggerHeader(c, "request-header", "Get Event Announcement")
+	dlcData, err := findEvent(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	oracleInstance := c.MustGet(ContextIDOracle).(*oracle.Oracle)
+	oraclePubKey, err := eventPublicKey(oracleInstance, dlcData)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	renderWithFormat(c, NewOracleAnnouncement(oraclePubKey, dlcData), func() ([]byte, error) {
+		return NewOracleAnnouncementTLV(oraclePubKey, dlcData)
+	})
+}
+
+// GetEventAttestation handler returns the attestation of the event with the requested ID,
+// signing its outcome if it has not been signed yet
+// (the attestation can be returned as an oracle_attestation TLV, see renderWithFormat)
+func (ct *EventController) GetEventAttestation(c *gin.Context) {
+	ginlogrus.SetCtxLoggerHeader(c, "request-header", "Get Event Attestation")
+	logger := ginlogrus.GetCtxLogger(c)
+	dlcData, err := findEvent(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	oracleInstance := c.MustGet(ContextIDOracle).(*oracle.Oracle)
+	if !dlcData.HasSignature() {
+		if dlcData.PublishedDate.After(time.Now().UTC()) {
+			cause := errors.Errorf("Oracle cannot sign a value not yet known, retry after %s", dlcData.PublishedDate.String())
+			c.Error(NewBadRequestError(InvalidTimeTooEarlyBadRequestErrorCode, cause, dlcData.GetEventID()))
+			return
+		}
+		assetController, ok := ct.assetControllers[dlcData.AssetID]
+		if !ok {
+			cause := errors.Errorf("Asset %s is not configured anymore", dlcData.AssetID)
+			c.Error(NewRecordNotFoundDBError(cause, dlcData.AssetID))
+			return
+		}
+		db := c.MustGet(ContextIDOrm).(*orm.ORM).GetDB()
+		feed, _ := c.MustGet(ContextIDDataFeed).(datafeed.DataFeed)
+		dlcData, err = assetController.findOrCreateAttestation(logger, db, feed, dlcData.PublishedDate, oracleInstance)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+	}
+
+	oraclePubKey, err := eventPublicKey(oracleInstance, dlcData)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	renderWithFormat(c, NewOracleAttestation(dlcData), func() ([]byte, error) {
+		return NewOracleAttestationTLV(oraclePubKey, dlcData)
+	})
+}
+
+func findEvent(c *gin.Context) (*entity.EventData, error) {
+	eventID := c.Param(URLParamTagEventID)
+	db := c.MustGet(ContextIDOrm).(*orm.ORM).GetDB()
+	dlcData, err := entity.FindDLCDataByEventID(db, eventID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, NewRecordNotFoundDBError(err, eventID)
+		}
+		return nil, NewUnknownDBError(err)
+	}
+	return dlcData, nil
+}
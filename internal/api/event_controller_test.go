@@ -0,0 +1,210 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"p2pderivatives-oracle/internal/api"
+	"p2pderivatives-oracle/internal/cfddlccrypto"
+	"p2pderivatives-oracle/internal/database/entity"
+	"p2pderivatives-oracle/internal/datafeed"
+	"p2pderivatives-oracle/internal/dlccrypto"
+	"p2pderivatives-oracle/internal/oracle"
+	"p2pderivatives-oracle/test"
+	mock_datafeed "p2pderivatives-oracle/test/mock/datafeed"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+var LegacyDLCData = &entity.EventData{
+	PublishedDate: TestAssetConfig.StartDate.Add(5 * TestAssetConfig.Frequency),
+	AssetID:       TestAsset.AssetID,
+	EventID:       "btcusd1577854800",
+	Nonces:        []string{"legacy rvalue"},
+	Signatures:    []string{"legacy signature"},
+	Values:        []string{"legacy value"},
+	Base:          10,
+}
+
+// SetupEventEngine returns an engine serving the event routes under api.EventBaseRoute
+// and the routes of the test asset under api.AssetBaseRoute
+func SetupEventEngine(recorder *httptest.ResponseRecorder, o *oracle.Oracle, feed datafeed.DataFeed) (*gin.Context, *gin.Engine) {
+	assetController := api.NewAssetController(TestAsset.AssetID, *TestAssetConfig).(*api.AssetController)
+	eventController := api.NewEventController(map[string]*api.AssetController{TestAsset.AssetID: assetController})
+	orm := test.NewOrm(&entity.Asset{}, &entity.EventData{}, &entity.PriceProvenance{}, &entity.NonceSignature{}, &entity.StreamEntry{})
+	orm.GetDB().Create(TestAsset)
+	orm.GetDB().Create(&entity.EventData{
+		PublishedDate: LegacyDLCData.PublishedDate,
+		AssetID:       LegacyDLCData.AssetID,
+		EventID:       LegacyDLCData.EventID,
+		Nonces:        LegacyDLCData.Nonces,
+		Signatures:    LegacyDLCData.Signatures,
+		Values:        LegacyDLCData.Values,
+		Base:          LegacyDLCData.Base,
+	})
+	setup := func(c *gin.Context) {
+		c.Set(api.ContextIDOracle, o)
+		c.Set(api.ContextIDCryptoService, cfddlccrypto.NewCfdgoCryptoService())
+		c.Set(api.ContextIDDataFeed, feed)
+		c.Set(api.ContextIDOrm, orm)
+	}
+	gin.SetMode(gin.TestMode)
+	c, r := gin.CreateTestContext(recorder)
+	r.Use(api.ErrorHandler(), setup)
+	assetController.Routes(r.Group(api.AssetBaseRoute + "/" + TestAsset.AssetID))
+	eventController.Routes(r.Group(api.EventBaseRoute))
+	return c, r
+}
+
+func AnnounceTestEvent(t *testing.T, r *gin.Engine, date time.Time) *api.OracleAnnouncement {
+	resp := httptest.NewRecorder()
+	route := api.AssetBaseRoute + "/" + TestAsset.AssetID + GetRouteWithTimeParam(api.RouteGETAssetAnnouncement, date)
+	req, _ := http.NewRequest(http.MethodGet, route, nil)
+	r.ServeHTTP(resp, req)
+	if !assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String()) {
+		t.FailNow()
+	}
+	announcement := &api.OracleAnnouncement{}
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), announcement))
+	return announcement
+}
+
+func GetEventRoute(route string, eventID string) string {
+	return api.EventBaseRoute + "/" + eventID + route[len("/:"+api.URLParamTagEventID):]
+}
+
+func TestEventController_GetEventAnnouncement_ReturnsAnnouncement(t *testing.T) {
+	oracleInstance, _ := NewTestOracleService()
+	resp := httptest.NewRecorder()
+	_, r := SetupEventEngine(resp, oracleInstance, nil)
+	date := time.Now().UTC().Truncate(TestAssetConfig.Frequency).Add(TestAssetConfig.Frequency)
+	expected := AnnounceTestEvent(t, r, date)
+
+	req, _ := http.NewRequest(http.MethodGet, GetEventRoute(api.RouteGETEventAnnouncement, expected.OracleEvent.EventID), nil)
+	r.ServeHTTP(resp, req)
+
+	if assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String()) {
+		actual := &api.OracleAnnouncement{}
+		if assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), actual)) {
+			assert.Equal(t, expected, actual)
+			assert.Equal(t, entity.ComputeEventEventID(TestAsset.AssetID, &date), actual.OracleEvent.EventID)
+		}
+	}
+}
+
+func TestEventController_GetEventAttestation_LegacyEventID_ReturnsAttestation(t *testing.T) {
+	oracleInstance, _ := NewTestOracleService()
+	resp := httptest.NewRecorder()
+	_, r := SetupEventEngine(resp, oracleInstance, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, GetEventRoute(api.RouteGETEventAttestation, LegacyDLCData.EventID), nil)
+	r.ServeHTTP(resp, req)
+
+	if assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String()) {
+		actual := &api.OracleAttestation{}
+		if assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), actual)) {
+			assert.Equal(t, api.NewOracleAttestation(LegacyDLCData), actual)
+		}
+	}
+}
+
+func TestEventController_GetEventAttestation_NotSigned_SignsEvent(t *testing.T) {
+	oracleInstance, _ := NewTestOracleService()
+	ctrl := gomock.NewController(t)
+	feed := mock_datafeed.NewMockDataFeed(ctrl)
+	date := TestAssetConfig.StartDate.Add(20 * TestAssetConfig.Frequency)
+	feed.EXPECT().FindPastAssetPriceRecord(TestAsset.AssetID, date).Return(
+		&datafeed.PriceRecord{Price: datafeedValue, Source: datafeed.DummySource, Timestamp: date}, nil)
+	resp := httptest.NewRecorder()
+	_, r := SetupEventEngine(resp, oracleInstance, feed)
+	announcement := AnnounceTestEvent(t, r, date)
+
+	req, _ := http.NewRequest(http.MethodGet, GetEventRoute(api.RouteGETEventAttestation, announcement.OracleEvent.EventID), nil)
+	r.ServeHTTP(resp, req)
+
+	if assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String()) {
+		actual := &api.OracleAttestation{}
+		if assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), actual)) {
+			assert.Equal(t, announcement.OracleEvent.EventID, actual.EventID)
+			assert.Equal(t, TestResponseValues.Values, actual.Values)
+			assert.Len(t, actual.Signatures, len(announcement.OracleEvent.Nonces))
+		}
+	}
+}
+
+func TestEventController_GetEventAttestation_NotPublished_ReturnsBadRequest(t *testing.T) {
+	oracleInstance, _ := NewTestOracleService()
+	resp := httptest.NewRecorder()
+	_, r := SetupEventEngine(resp, oracleInstance, nil)
+	announcement := AnnounceTestEvent(t, r, time.Now().UTC().Add(TestAssetConfig.Frequency))
+
+	req, _ := http.NewRequest(http.MethodGet, GetEventRoute(api.RouteGETEventAttestation, announcement.OracleEvent.EventID), nil)
+	r.ServeHTTP(resp, req)
+
+	if assert.Equal(t, http.StatusBadRequest, resp.Code) {
+		actual := &api.ErrorResponse{}
+		if assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), actual)) {
+			assert.Equal(t, api.InvalidTimeTooEarlyBadRequestErrorCode, actual.ErrorCode)
+		}
+	}
+}
+
+func TestEventController_UnknownEventID_ReturnsNotFound(t *testing.T) {
+	oracleInstance, _ := NewTestOracleService()
+	for _, route := range []string{api.RouteGETEventAnnouncement, api.RouteGETEventAttestation} {
+		t.Run(route, func(t *testing.T) {
+			resp := httptest.NewRecorder()
+			_, r := SetupEventEngine(resp, oracleInstance, nil)
+			// the legacy ID of the event without separator is not a valid ID for new events
+			date := time.Now().UTC().Truncate(TestAssetConfig.Frequency).Add(TestAssetConfig.Frequency)
+			AnnounceTestEvent(t, r, date)
+
+			req, _ := http.NewRequest(http.MethodGet, GetEventRoute(route, entity.ComputeLegacyEventID(TestAsset.AssetID, &date)), nil)
+			r.ServeHTTP(resp, req)
+
+			assert.Equal(t, http.StatusNotFound, resp.Code)
+		})
+	}
+}
+
+func TestEventController_GetEventAttestation_AfterKeyRotation_SignsWithAnnouncingKey(t *testing.T) {
+	oracleInstance, err := NewTestOracleServiceWithRotatedKey(time.Now().UTC().Add(time.Hour))
+	if !assert.NoError(t, err) {
+		return
+	}
+	initialKey := oracleInstance.Keys[0]
+	rotatedKey := oracleInstance.Keys[1]
+	ctrl := gomock.NewController(t)
+	feed := mock_datafeed.NewMockDataFeed(ctrl)
+	date := TestAssetConfig.StartDate.Add(20 * TestAssetConfig.Frequency)
+	feed.EXPECT().FindPastAssetPriceRecord(TestAsset.AssetID, date).Return(
+		&datafeed.PriceRecord{Price: datafeedValue, Source: datafeed.DummySource, Timestamp: date}, nil)
+	resp := httptest.NewRecorder()
+	_, r := SetupEventEngine(resp, oracleInstance, feed)
+	announcement := AnnounceTestEvent(t, r, date)
+	assert.Equal(t, initialKey.PublicKey.EncodeToString(), announcement.OraclePublicKey)
+
+	// rotate the key after the event was announced
+	rotatedKey.ActivationDate = time.Now().UTC().Add(-time.Hour)
+	newAnnouncement := AnnounceTestEvent(t, r, date.Add(TestAssetConfig.Frequency))
+	assert.Equal(t, rotatedKey.PublicKey.EncodeToString(), newAnnouncement.OraclePublicKey)
+	req, _ := http.NewRequest(http.MethodGet, GetEventRoute(api.RouteGETEventAttestation, announcement.OracleEvent.EventID), nil)
+	r.ServeHTTP(resp, req)
+
+	if assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String()) {
+		actual := &api.OracleAttestation{}
+		if assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), actual)) {
+			crypto := cfddlccrypto.NewCfdgoCryptoService()
+			for i, s := range actual.Signatures {
+				sig, _ := dlccrypto.NewSignature(s)
+				valid, err := crypto.VerifySchnorrSignature(initialKey.PublicKey, sig, actual.Values[i])
+				assert.NoError(t, err)
+				assert.True(t, valid)
+			}
+		}
+	}
+}
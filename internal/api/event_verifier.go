@@ -0,0 +1,97 @@
+package api
+
+import (
+	"bytes"
+	"p2pderivatives-oracle/internal/database/entity"
+	"p2pderivatives-oracle/internal/datafeed"
+	"p2pderivatives-oracle/internal/dlccrypto"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// NewEventVerifier returns a verifier checking the events and outcomes signed by a participant
+// of a threshold oracle against the api configuration and the datafeed of the participant
+func NewEventVerifier(config *Config, feed datafeed.DataFeed) *EventVerifier {
+	return &EventVerifier{
+		config: config,
+		feed:   feed,
+	}
+}
+
+// EventVerifier checks that the announcements and outcomes to sign are the ones the oracle would publish
+type EventVerifier struct {
+	config *Config
+	feed   datafeed.DataFeed
+}
+
+// VerifyEvent checks that the serialized oracle event is the event of the asset at the maturity
+// announced with the given nonces
+func (v *EventVerifier) VerifyEvent(assetID string, eventMaturity uint32, nonces []dlccrypto.SchnorrPublicKey, event []byte) error {
+	config, err := v.assetConfig(assetID)
+	if err != nil {
+		return err
+	}
+	publishDate := time.Unix(int64(eventMaturity), 0).UTC()
+	if publishDate.Sub(config.StartDate)%config.Frequency != 0 {
+		return errors.Errorf("No event of asset %s is published at %s", assetID, publishDate)
+	}
+	eventID := entity.ComputeEventEventID(assetID, &publishDate)
+
+	var expected []byte
+	if config.IsEnum() {
+		expected = dlccrypto.SerializeEnumEvent(nonces, eventMaturity, config.Outcomes, eventID)
+	} else {
+		signConfig := config.SignConfig
+		expected = dlccrypto.SerializeEvent(
+			nonces, eventMaturity, uint16(signConfig.Base), signConfig.IsSigned, config.Unit,
+			int32(signConfig.Precision), uint16(signConfig.NbDigits), eventID)
+	}
+	if !bytes.Equal(expected, event) {
+		return errors.Errorf("The event does not match the configuration of asset %s", assetID)
+	}
+	return nil
+}
+
+// VerifyOutcome checks that the message is the outcome of the event found in the datafeed
+// at the index of the nonce
+func (v *EventVerifier) VerifyOutcome(assetID string, eventMaturity uint32, index int, message string) error {
+	config, err := v.assetConfig(assetID)
+	if err != nil {
+		return err
+	}
+	publishDate := time.Unix(int64(eventMaturity), 0).UTC()
+	if publishDate.After(time.Now()) {
+		return errors.Errorf("The event of asset %s at %s is not matured", assetID, publishDate)
+	}
+
+	var outcomes []string
+	if config.IsEnum() {
+		outcome, err := v.feed.FindPastOutcome(assetID, publishDate, config.Outcomes)
+		if err != nil {
+			return err
+		}
+		outcomes = []string{*outcome}
+	} else {
+		record, err := findSettlementPriceRecord(v.feed, assetID, publishDate, config)
+		if err != nil {
+			return err
+		}
+		signConfig := config.SignConfig
+		outcomes = dlccrypto.RoundAndDecomposeValue(record.Price, signConfig.Base, signConfig.NbDigits, signConfig.IsSigned, signConfig.Precision)
+	}
+	if index < 0 || index >= len(outcomes) || outcomes[index] != message {
+		return errors.Errorf("%q is not the outcome %d of the event of asset %s at %s", message, index, assetID, publishDate)
+	}
+	return nil
+}
+
+func (v *EventVerifier) assetConfig(assetID string) (AssetConfig, error) {
+	if config, ok := v.config.AssetConfigs[assetID]; ok {
+		return config, nil
+	}
+	if config, ok := v.config.EnumAssetConfigs[assetID]; ok {
+		return config.toAssetConfig(), nil
+	}
+	return AssetConfig{}, errors.Errorf("Unknown asset %s", assetID)
+}
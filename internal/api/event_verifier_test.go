@@ -0,0 +1,79 @@
+package api_test
+
+import (
+	"p2pderivatives-oracle/internal/api"
+	"p2pderivatives-oracle/internal/database/entity"
+	"p2pderivatives-oracle/internal/datafeed"
+	"p2pderivatives-oracle/internal/dlccrypto"
+	mock_datafeed "p2pderivatives-oracle/test/mock/datafeed"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestEventVerifier(feed *mock_datafeed.MockDataFeed) *api.EventVerifier {
+	config := &api.Config{
+		AssetConfigs: map[string]api.AssetConfig{TestAsset.AssetID: *TestAssetConfig},
+		EnumAssetConfigs: map[string]api.EnumAssetConfig{
+			"btcusd-strike": {
+				StartDate: TestAssetConfig.StartDate,
+				Frequency: TestAssetConfig.Frequency,
+				RangeD:    TestAssetConfig.RangeD,
+				Outcomes:  []string{"above", "below"},
+			},
+		},
+	}
+	return api.NewEventVerifier(config, feed)
+}
+
+func TestEventVerifier_VerifyEvent_ConfiguredEvent_ReturnsNoError(t *testing.T) {
+	verifier := newTestEventVerifier(nil)
+	publishDate := InDbDLCData.PublishedDate
+	maturity := uint32(publishDate.Unix())
+	nonces := make([]dlccrypto.SchnorrPublicKey, len(TestResponseValues.Rvalues))
+	for i, rvalue := range TestResponseValues.Rvalues {
+		nonce, _ := dlccrypto.NewSchnorrPublicKey(rvalue)
+		nonces[i] = *nonce
+	}
+	eventID := entity.ComputeEventEventID(TestAsset.AssetID, &publishDate)
+	event := dlccrypto.SerializeEvent(nonces, maturity, 10, false, TestAssetConfig.Unit, 0, 3, eventID)
+
+	assert.NoError(t, verifier.VerifyEvent(TestAsset.AssetID, maturity, nonces, event))
+	// another descriptor
+	other := dlccrypto.SerializeEvent(nonces, maturity, 2, false, TestAssetConfig.Unit, 0, 3, eventID)
+	assert.Error(t, verifier.VerifyEvent(TestAsset.AssetID, maturity, nonces, other))
+	// a date at which no event is published
+	assert.Error(t, verifier.VerifyEvent(TestAsset.AssetID, maturity+1, nonces, event))
+	assert.Error(t, verifier.VerifyEvent("unknown", maturity, nonces, event))
+}
+
+func TestEventVerifier_VerifyOutcome_NumericEvent_ChecksDigit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	feed := mock_datafeed.NewMockDataFeed(ctrl)
+	publishDate := InDbDLCData.PublishedDate
+	value := 123.4
+	feed.EXPECT().FindPastAssetPriceRecord(TestAsset.AssetID, publishDate).Return(&datafeed.PriceRecord{Price: value}, nil).AnyTimes()
+	verifier := newTestEventVerifier(feed)
+	maturity := uint32(publishDate.Unix())
+
+	assert.NoError(t, verifier.VerifyOutcome(TestAsset.AssetID, maturity, 0, "1"))
+	assert.NoError(t, verifier.VerifyOutcome(TestAsset.AssetID, maturity, 2, "3"))
+	assert.Error(t, verifier.VerifyOutcome(TestAsset.AssetID, maturity, 2, "4"))
+	assert.Error(t, verifier.VerifyOutcome(TestAsset.AssetID, maturity, 3, "0"))
+}
+
+func TestEventVerifier_VerifyOutcome_EnumEvent_ChecksOutcome(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	feed := mock_datafeed.NewMockDataFeed(ctrl)
+	publishDate := InDbDLCData.PublishedDate
+	outcome := "below"
+	feed.EXPECT().FindPastOutcome("btcusd-strike", publishDate, []string{"above", "below"}).Return(&outcome, nil).AnyTimes()
+	verifier := newTestEventVerifier(feed)
+	maturity := uint32(publishDate.Unix())
+
+	assert.NoError(t, verifier.VerifyOutcome("btcusd-strike", maturity, 0, "below"))
+	assert.Error(t, verifier.VerifyOutcome("btcusd-strike", maturity, 0, "above"))
+}
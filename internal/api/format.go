@@ -0,0 +1,45 @@
+package api
+
+import (
+	"encoding/hex"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+const (
+	// QueryParamFormat query parameter used to select the response format
+	QueryParamFormat = "format"
+	// FormatTLV value of the format query parameter to get a hex encoded TLV response
+	FormatTLV = "tlv"
+	// MIMEOctetStream mime type used to get a raw TLV response
+	MIMEOctetStream = "application/octet-stream"
+)
+
+// renderWithFormat writes obj as JSON, unless the client requested a TLV
+// either with the format query parameter (hex encoded), a text/plain Accept
+// header (hex encoded) or an application/octet-stream Accept header (raw bytes)
+func renderWithFormat(c *gin.Context, obj interface{}, serializeTLV func() ([]byte, error)) {
+	format := c.NegotiateFormat(binding.MIMEJSON, MIMEOctetStream, binding.MIMEPlain)
+	if format == binding.MIMEJSON && c.Query(QueryParamFormat) == FormatTLV {
+		format = binding.MIMEPlain
+	}
+
+	if format == binding.MIMEJSON {
+		c.JSON(http.StatusOK, obj)
+		return
+	}
+
+	tlv, err := serializeTLV()
+	if err != nil {
+		c.Error(NewUnknownInternalError(err, "TLV serialization"))
+		return
+	}
+
+	if format == MIMEOctetStream {
+		c.Data(http.StatusOK, MIMEOctetStream, tlv)
+	} else {
+		c.String(http.StatusOK, hex.EncodeToString(tlv))
+	}
+}
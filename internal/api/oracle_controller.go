@@ -3,14 +3,19 @@ package api
 import (
 	"net/http"
 	"p2pderivatives-oracle/internal/oracle"
+	"time"
 
 	ginlogrus "github.com/Bose/go-gin-logrus"
 
 	"github.com/gin-gonic/gin"
 )
 
-// RouteGETOraclePublicKey route for the GET oracle public key from OracleController
-const RouteGETOraclePublicKey = "/publickey"
+const (
+	// RouteGETOraclePublicKey route for the GET oracle public key from OracleController
+	RouteGETOraclePublicKey = "/publickey"
+	// RouteGETOracleKeys route for the GET oracle keys history from OracleController
+	RouteGETOracleKeys = "/keys"
+)
 
 // OracleController represents the oracle api Controller
 type OracleController struct {
@@ -24,15 +29,24 @@ func NewOracleController() Controller {
 // Routes list and binds all routes to the router group provided
 func (ct *OracleController) Routes(route *gin.RouterGroup) {
 	route.GET(RouteGETOraclePublicKey, ct.GetPublicKey)
+	route.GET(RouteGETOracleKeys, ct.GetKeys)
 }
 
-// GetPublicKey handler returns the Oracle public key
+// GetPublicKey handler returns the public key currently used by the Oracle to announce events
 func (ct *OracleController) GetPublicKey(c *gin.Context) {
 	ginlogrus.SetCtxLoggerHeader(c, "request-header", "Get Oracle Public Key")
 	logger := ginlogrus.GetCtxLogger(c)
 	oracleInstance := c.MustGet(ContextIDOracle).(*oracle.Oracle)
 	logger.Info("Accessing Oracle instance")
 	c.JSON(http.StatusOK, &OraclePublicKeyResponse{
-		PublicKey: oracleInstance.PublicKey.EncodeToString(),
+		PublicKey: oracleInstance.PublicKey().EncodeToString(),
 	})
 }
+
+// GetKeys handler returns the history of the Oracle keys with their activation date
+// (the events are attested with the key which announced them)
+func (ct *OracleController) GetKeys(c *gin.Context) {
+	ginlogrus.SetCtxLoggerHeader(c, "request-header", "Get Oracle Keys")
+	oracleInstance := c.MustGet(ContextIDOracle).(*oracle.Oracle)
+	c.JSON(http.StatusOK, NewOracleKeysResponse(oracleInstance, time.Now().UTC()))
+}
@@ -5,9 +5,11 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"p2pderivatives-oracle/internal/api"
+	"p2pderivatives-oracle/internal/cfddlccrypto"
 	"p2pderivatives-oracle/internal/dlccrypto"
 	"p2pderivatives-oracle/internal/oracle"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
@@ -18,6 +20,11 @@ const OraclePrivateKey = "29cf848088781119018ba61f14b5328c9c299050e61abb9438cd77
 const OraclePublicKey = "c06fd4dee6502848b937840019effbab0856a227d984785367b079969471a6ed"
 
 func NewTestOracleService() (*oracle.Oracle, error) {
+	return NewTestOracleServiceWithCrypto(cfddlccrypto.NewCfdgoCryptoService())
+}
+
+// NewTestOracleServiceWithCrypto returns the test oracle signing with the given crypto service
+func NewTestOracleServiceWithCrypto(crypto dlccrypto.CryptoService) (*oracle.Oracle, error) {
 	priv, err := dlccrypto.NewPrivateKey(OraclePrivateKey)
 	if err != nil {
 		return nil, err
@@ -26,7 +33,27 @@ func NewTestOracleService() (*oracle.Oracle, error) {
 	if err != nil {
 		return nil, err
 	}
-	return oracle.New(priv, pub), nil
+	return oracle.New(dlccrypto.NewKeySigner(priv, pub, crypto)), nil
+}
+
+// NewTestOracleServiceWithRotatedKey returns an oracle with the test key and a generated key
+// which will only be activated at the given date
+func NewTestOracleServiceWithRotatedKey(activationDate time.Time) (*oracle.Oracle, error) {
+	initial, err := NewTestOracleService()
+	if err != nil {
+		return nil, err
+	}
+	crypto := cfddlccrypto.NewCfdgoCryptoService()
+	priv, pub, err := crypto.GenerateSchnorrKeyPair()
+	if err != nil {
+		return nil, err
+	}
+	rotated := &oracle.Key{
+		Signer:         dlccrypto.NewKeySigner(priv, pub, crypto),
+		PublicKey:      pub,
+		ActivationDate: activationDate,
+	}
+	return oracle.NewWithKeys([]*oracle.Key{initial.Keys[0], rotated})
 }
 
 func SetupOracleEngine(recorder *httptest.ResponseRecorder, o *oracle.Oracle) (*gin.Context, *gin.Engine) {
@@ -57,3 +84,27 @@ func TestOracleController_GetPublicKey_ReturnsCorrectValue(t *testing.T) {
 		}
 	}
 }
+
+func TestOracleController_GetKeys_ReturnsKeysHistory(t *testing.T) {
+	activationDate := time.Now().UTC().Add(time.Hour).Truncate(time.Second)
+	oracleService, err := NewTestOracleServiceWithRotatedKey(activationDate)
+	if assert.NoError(t, err) {
+		resp := httptest.NewRecorder()
+		c, r := SetupOracleEngine(resp, oracleService)
+		c.Request, _ = http.NewRequest(http.MethodGet, api.RouteGETOracleKeys, nil)
+		r.ServeHTTP(resp, c.Request)
+		if assert.Equal(t, http.StatusOK, resp.Code) {
+			expected := &api.OracleKeysResponse{
+				Keys: []api.OracleKeyResponse{
+					{PublicKey: OraclePublicKey, ActivationDate: time.Time{}, Active: true},
+					{PublicKey: oracleService.Keys[1].PublicKey.EncodeToString(), ActivationDate: activationDate, Active: false},
+				},
+			}
+			actual := &api.OracleKeysResponse{}
+			err := json.Unmarshal([]byte(resp.Body.String()), &actual)
+			if assert.NoError(t, err) {
+				assert.Equal(t, expected, actual)
+			}
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"container/list"
+	"p2pderivatives-oracle/internal/database/entity"
+	"p2pderivatives-oracle/internal/dlccrypto"
+	"strconv"
+	"sync"
+)
+
+// maxCachedAnnouncementPoints number of events whose anticipation points are kept in memory by an asset controller
+const maxCachedAnnouncementPoints = 1000
+
+// nonceOutcomes returns the outcomes which can be signed with each nonce of the event
+func nonceOutcomes(eventData *entity.EventData) [][]string {
+	if eventData.IsEnum() {
+		return [][]string{eventData.Outcomes}
+	}
+	digits := make([]string, eventData.Base)
+	for i := range digits {
+		digits[i] = strconv.Itoa(i)
+	}
+	outcomes := make([][]string, 0, len(eventData.Nonces))
+	if eventData.IsSigned {
+		outcomes = append(outcomes, []string{dlccrypto.PositiveSign, dlccrypto.NegativeSign})
+	}
+	for len(outcomes) < len(eventData.Nonces) {
+		outcomes = append(outcomes, digits)
+	}
+	return outcomes
+}
+
+// computeAnnouncementPoints computes the signature point of each outcome of each nonce of the event
+func computeAnnouncementPoints(cryptoService dlccrypto.CryptoService, oraclePubKey *dlccrypto.SchnorrPublicKey, eventData *entity.EventData) (*AnnouncementPointsResponse, error) {
+	outcomes := nonceOutcomes(eventData)
+	nonces := make([]NoncePoints, len(eventData.Nonces))
+	for i, encoded := range eventData.Nonces {
+		nonce, err := dlccrypto.NewSchnorrPublicKey(encoded)
+		if err != nil {
+			return nil, NewUnknownCryptoServiceError(err)
+		}
+		points := make([]string, len(outcomes[i]))
+		for j, outcome := range outcomes[i] {
+			point, err := cryptoService.ComputeSigPoint(oraclePubKey, nonce, outcome)
+			if err != nil {
+				return nil, NewUnknownCryptoServiceError(err)
+			}
+			points[j] = point.EncodeToString()
+		}
+		nonces[i] = NoncePoints{
+			Nonce:    encoded,
+			Outcomes: outcomes[i],
+			Points:   points,
+		}
+	}
+	return &AnnouncementPointsResponse{
+		EventID:         eventData.GetEventID(),
+		OraclePublicKey: oraclePubKey.EncodeToString(),
+		Nonces:          nonces,
+	}, nil
+}
+
+// pointsCache keeps the anticipation points of the most recently requested events
+// (the points of an event never change once it is announced)
+type pointsCache struct {
+	mut     sync.Mutex
+	maxSize int
+	// least recently used entries at the back
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+func newPointsCache(maxSize int) *pointsCache {
+	return &pointsCache{
+		maxSize: maxSize,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (p *pointsCache) get(eventID string) (*AnnouncementPointsResponse, bool) {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	element, ok := p.entries[eventID]
+	if !ok {
+		return nil, false
+	}
+	p.order.MoveToFront(element)
+	return element.Value.(*AnnouncementPointsResponse), true
+}
+
+func (p *pointsCache) add(points *AnnouncementPointsResponse) {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	if element, ok := p.entries[points.EventID]; ok {
+		p.order.MoveToFront(element)
+		return
+	}
+	p.entries[points.EventID] = p.order.PushFront(points)
+	if p.order.Len() > p.maxSize {
+		oldest := p.order.Back()
+		p.order.Remove(oldest)
+		delete(p.entries, oldest.Value.(*AnnouncementPointsResponse).EventID)
+	}
+}
@@ -2,22 +2,32 @@ package api
 
 import (
 	"p2pderivatives-oracle/internal/database/entity"
+	"p2pderivatives-oracle/internal/datafeed"
 	"p2pderivatives-oracle/internal/dlccrypto"
+	"p2pderivatives-oracle/internal/oracle"
+	"p2pderivatives-oracle/pkg/oracleclient"
 	"time"
+
+	"github.com/cryptogarageinc/server-common-go/pkg/utils/iso8601"
 )
 
 // NewOracleAnnouncement converts a DLCData structure to an oracle announcement
 func NewOracleAnnouncement(
 	oraclePubKey *dlccrypto.SchnorrPublicKey,
 	eventData *entity.EventData) *OracleAnnouncement {
-	descriptor := DecompositionDescriptor{
-		DigitDecompositionDescriptor: DigitDecompositionDescriptor{
+	descriptor := DecompositionDescriptor{}
+	if eventData.IsEnum() {
+		descriptor.EnumEventDescriptor = &EnumEventDescriptor{
+			Outcomes: eventData.Outcomes,
+		}
+	} else {
+		descriptor.DigitDecompositionDescriptor = &DigitDecompositionDescriptor{
 			Base:      eventData.Base,
 			IsSigned:  eventData.IsSigned,
 			Unit:      eventData.Unit,
 			Precision: eventData.Precision,
-			NbDigits:  len(eventData.Nonces),
-		},
+			NbDigits:  eventData.NbDigits(),
+		}
 	}
 	event := OracleEvent{
 		Nonces:             eventData.Nonces,
@@ -43,6 +53,116 @@ func NewOracleAttestation(eventData *entity.EventData) *OracleAttestation {
 	}
 }
 
+// NewEventSummary creates a new EventSummary structure from the given eventData
+func NewEventSummary(oraclePubKey *dlccrypto.SchnorrPublicKey, eventData *entity.EventData) *EventSummary {
+	summary := &EventSummary{
+		EventID:       eventData.GetEventID(),
+		PublishedDate: eventData.PublishedDate,
+		Status:        EventStatusAnnounced,
+		Announcement:  NewOracleAnnouncement(oraclePubKey, eventData),
+	}
+	if eventData.HasSignature() {
+		summary.Status = EventStatusAttested
+		summary.Attestation = NewOracleAttestation(eventData)
+	}
+	return summary
+}
+
+// NewPriceProvenanceResponse creates a new PriceProvenanceResponse structure from the given eventData
+// and the provenance of its attested value
+func NewPriceProvenanceResponse(eventData *entity.EventData, provenance *entity.PriceProvenance) *PriceProvenanceResponse {
+	sources := make([]SourcePriceResponse, 0, len(provenance.SourcePrices))
+	for _, p := range provenance.SourcePrices {
+		sources = append(sources, SourcePriceResponse{
+			Source:    p.Source,
+			Price:     p.Price,
+			Timestamp: p.Timestamp,
+			Rejected:  p.Rejected,
+			Stale:     p.Stale,
+		})
+	}
+	return &PriceProvenanceResponse{
+		EventID:         eventData.GetEventID(),
+		Settlement:      NewSettlementResponse(provenance.Method, provenance.Window),
+		Value:           provenance.RawValue,
+		Source:          provenance.Source,
+		SourceTimestamp: provenance.SourceTimestamp,
+		Reference:       provenance.Reference,
+		Stale:           provenance.Stale,
+		Precision:       provenance.Precision,
+		RoundedValue:    provenance.RoundedValue,
+		Values:          eventData.Values,
+		Sources:         sources,
+	}
+}
+
+// NewSettlementResponse creates a new SettlementResponse structure from the settlement method
+// and window (the values attested before the method was recorded being spot prices)
+func NewSettlementResponse(method string, window time.Duration) *SettlementResponse {
+	if method == "" {
+		method = datafeed.MethodSpot
+	}
+	res := &SettlementResponse{Method: method}
+	if window > 0 {
+		res.Window = iso8601.EncodeDuration(window)
+	}
+	return res
+}
+
+// NewOracleAnnouncementTLV serializes a DLCData structure as an oracle_announcement TLV
+func NewOracleAnnouncementTLV(
+	oraclePubKey *dlccrypto.SchnorrPublicKey,
+	eventData *entity.EventData) ([]byte, error) {
+	nonces := make([]dlccrypto.SchnorrPublicKey, len(eventData.Nonces))
+	for i, n := range eventData.Nonces {
+		nonce, err := dlccrypto.NewSchnorrPublicKey(n)
+		if err != nil {
+			return nil, err
+		}
+		nonces[i] = *nonce
+	}
+	signature, err := dlccrypto.NewSignature(eventData.AnnouncementSignature)
+	if err != nil {
+		return nil, err
+	}
+	var event []byte
+	if eventData.IsEnum() {
+		event = dlccrypto.SerializeEnumEvent(
+			nonces,
+			uint32(eventData.PublishedDate.Unix()),
+			eventData.Outcomes,
+			eventData.GetEventID())
+	} else {
+		event = dlccrypto.SerializeEvent(
+			nonces,
+			uint32(eventData.PublishedDate.Unix()),
+			uint16(eventData.Base),
+			eventData.IsSigned,
+			eventData.Unit,
+			int32(eventData.Precision),
+			uint16(eventData.NbDigits()),
+			eventData.GetEventID())
+	}
+
+	return dlccrypto.SerializeAnnouncement(signature, oraclePubKey, event), nil
+}
+
+// NewOracleAttestationTLV serializes a DLCData structure as an oracle_attestation TLV
+func NewOracleAttestationTLV(
+	oraclePubKey *dlccrypto.SchnorrPublicKey,
+	eventData *entity.EventData) ([]byte, error) {
+	signatures := make([]dlccrypto.Signature, len(eventData.Signatures))
+	for i, s := range eventData.Signatures {
+		signature, err := dlccrypto.NewSignature(s)
+		if err != nil {
+			return nil, err
+		}
+		signatures[i] = *signature
+	}
+
+	return dlccrypto.SerializeAttestation(eventData.GetEventID(), oraclePubKey, signatures, eventData.Values), nil
+}
+
 // DigitDecompositionDescriptor contains information about a numerical event.
 type DigitDecompositionDescriptor struct {
 	Base      int    `json:"base"`
@@ -52,10 +172,16 @@ type DigitDecompositionDescriptor struct {
 	NbDigits  int    `json:"nbDigits"`
 }
 
+// EnumEventDescriptor contains information about an enumerable event.
+type EnumEventDescriptor struct {
+	Outcomes []string `json:"outcomes"`
+}
+
 // DecompositionDescriptor can contain information about either an enumerable event
-// or a numerical event.
+// or a numerical event (only one of the fields is set).
 type DecompositionDescriptor struct {
-	DigitDecompositionDescriptor DigitDecompositionDescriptor `json:"digitDecompositionEvent"`
+	DigitDecompositionDescriptor *DigitDecompositionDescriptor `json:"digitDecompositionEvent,omitempty"`
+	EnumEventDescriptor          *EnumEventDescriptor          `json:"enumEvent,omitempty"`
 }
 
 // OracleEvent contains information about an event
@@ -81,14 +207,124 @@ type OracleAttestation struct {
 	Values     []string `json:"values"`
 }
 
+// EventSummary contains the announcement of an event and its attestation if it is attested
+type EventSummary struct {
+	EventID       string              `json:"eventId"`
+	PublishedDate time.Time           `json:"publishedDate"`
+	Status        string              `json:"status"`
+	Announcement  *OracleAnnouncement `json:"announcement"`
+	Attestation   *OracleAttestation  `json:"attestation,omitempty"`
+}
+
+// EventsResponse contains a page of events, NextCursor being set if there are more events
+type EventsResponse struct {
+	Events     []EventSummary `json:"events"`
+	NextCursor string         `json:"nextCursor,omitempty"`
+}
+
+// SourcePriceResponse represents the price returned by one of the sources used to compute an attested value
+type SourcePriceResponse struct {
+	Source    string    `json:"source"`
+	Price     float64   `json:"price"`
+	Timestamp time.Time `json:"timestamp"`
+	Rejected  bool      `json:"rejected,omitempty"`
+	Stale     bool      `json:"stale,omitempty"`
+}
+
+// SettlementResponse contains the method used to compute the value attested for an event
+type SettlementResponse struct {
+	// Method spot, twap or vwap
+	Method string `json:"method"`
+	// Window period before the publish date over which the price is averaged (ISO8601 duration)
+	Window string `json:"window,omitempty"`
+}
+
+// PriceProvenanceResponse contains the price data used to compute the value attested for an event
+type PriceProvenanceResponse struct {
+	EventID         string                `json:"eventId"`
+	Value           float64               `json:"value"`
+	Source          string                `json:"source"`
+	SourceTimestamp time.Time             `json:"sourceTimestamp"`
+	Reference       string                `json:"reference,omitempty"`
+	Stale           bool                  `json:"stale,omitempty"`
+	Settlement      *SettlementResponse   `json:"settlement"`
+	Precision       int                   `json:"precision"`
+	RoundedValue    int                   `json:"roundedValue"`
+	Values          []string              `json:"values"`
+	Sources         []SourcePriceResponse `json:"sources,omitempty"`
+}
+
+// VerifyRequest contains the announcement and the attestation of an event to verify
+type VerifyRequest struct {
+	Announcement oracleclient.Announcement `json:"announcement"`
+	Attestation  oracleclient.Attestation  `json:"attestation"`
+}
+
+// VerifyResponse contains the result of the verification of an attestation
+type VerifyResponse struct {
+	// Valid true if the announcement and attestation signatures are valid
+	Valid bool `json:"valid"`
+	// OracleKey true if the event was announced with one of the keys of this oracle
+	OracleKey bool                  `json:"oracleKey"`
+	Outcome   *oracleclient.Outcome `json:"outcome,omitempty"`
+	// Error the reason why the attestation is invalid
+	Error string `json:"error,omitempty"`
+}
+
+// AnnouncementPointsResponse represents the anticipation points of an announcement, which are the signature
+// points of each outcome that can be signed with each nonce of the event
+type AnnouncementPointsResponse struct {
+	EventID         string        `json:"eventId"`
+	OraclePublicKey string        `json:"oraclePublicKey"`
+	Nonces          []NoncePoints `json:"nonces"`
+}
+
+// NoncePoints represents the signature point of each outcome that can be signed with a nonce
+// (the point of an outcome being at the same index as the outcome)
+type NoncePoints struct {
+	Nonce    string   `json:"nonce"`
+	Outcomes []string `json:"outcomes"`
+	Points   []string `json:"points"`
+}
+
 // AssetConfigResponse represents the configuration of an asset api
 type AssetConfigResponse struct {
 	StartDate time.Time `json:"startDate"`
 	Frequency string    `json:"frequency"`
 	RangeD    string    `json:"range"`
+	Outcomes  []string  `json:"outcomes,omitempty"`
+	// Settlement method of the value attested for the numeric events
+	Settlement *SettlementResponse `json:"settlement,omitempty"`
 }
 
 // OraclePublicKeyResponse represents the public key of the oracle
 type OraclePublicKeyResponse struct {
 	PublicKey string `json:"publicKey"`
 }
+
+// OracleKeysResponse represents the history of the oracle keys
+type OracleKeysResponse struct {
+	Keys []OracleKeyResponse `json:"keys"`
+}
+
+// OracleKeyResponse represents one of the oracle keys
+type OracleKeyResponse struct {
+	PublicKey      string    `json:"publicKey"`
+	ActivationDate time.Time `json:"activationDate"`
+	// Active true if the key is used to announce the new events
+	Active bool `json:"active"`
+}
+
+// NewOracleKeysResponse creates the response listing the keys of the oracle at the given date
+func NewOracleKeysResponse(oracleInstance *oracle.Oracle, now time.Time) *OracleKeysResponse {
+	active := oracleInstance.ActiveKey(now)
+	keys := make([]OracleKeyResponse, len(oracleInstance.Keys))
+	for i, key := range oracleInstance.Keys {
+		keys[i] = OracleKeyResponse{
+			PublicKey:      key.PublicKey.EncodeToString(),
+			ActivationDate: key.ActivationDate,
+			Active:         key == active,
+		}
+	}
+	return &OracleKeysResponse{Keys: keys}
+}
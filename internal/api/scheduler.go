@@ -0,0 +1,148 @@
+package api
+
+import (
+	"p2pderivatives-oracle/internal/database/entity"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Scheduler periodically creates the announcements of every configured asset
+// up to the asset range, and signs the events as soon as their publish date has passed.
+// As the state is only kept in the database, running it several times (or after a restart)
+// will only create the missing announcements and attestations.
+type Scheduler struct {
+	config   *SchedulerConfig
+	api      *OracleAPI
+	logger   *logrus.Logger
+	stop     chan struct{}
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+}
+
+// NewScheduler returns a new Scheduler using the services of the api
+func (a *OracleAPI) NewScheduler(config *SchedulerConfig) *Scheduler {
+	return &Scheduler{
+		config: config,
+		api:    a,
+		logger: a.logger.Logger,
+		stop:   make(chan struct{}),
+	}
+}
+
+// Start runs the scheduler in the background, a first run being done immediately
+func (s *Scheduler) Start() {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.config.Interval)
+		defer ticker.Stop()
+		for {
+			s.RunOnce(time.Now().UTC())
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops the scheduler and waits for the current run to finish
+func (s *Scheduler) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+	s.wg.Wait()
+}
+
+// RunOnce creates the missing attestations and announcements of every asset relative to now.
+// All the assets are attested before any announcement is created so that a long announcement
+// backlog cannot delay the attestations, and at most MaxAnnouncements announcements are created
+// per asset in a run. It returns early when the scheduler is stopped.
+func (s *Scheduler) RunOnce(now time.Time) {
+	for assetID, ct := range s.api.assetControllers {
+		if s.stopped() {
+			return
+		}
+		s.attest(s.logger.WithField("assetID", assetID), ct, now)
+	}
+	for assetID, ct := range s.api.assetControllers {
+		if s.stopped() {
+			return
+		}
+		s.announce(s.logger.WithField("assetID", assetID), ct, now)
+	}
+}
+
+func (s *Scheduler) stopped() bool {
+	select {
+	case <-s.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *Scheduler) announce(logger *logrus.Entry, ct *AssetController, now time.Time) {
+	from, err := calculatePublishDate(now, ct.config)
+	if err != nil {
+		logger.Errorf("Could not compute next publish date: %v", err)
+		return
+	}
+	upTo := now.Add(ct.config.RangeD)
+	db := s.api.orm.GetDB()
+	existing, err := entity.FindDLCDataPublishedBetween(db, ct.assetID, *from, upTo)
+	if err != nil {
+		logger.Errorf("Could not retrieve existing announcements: %v", err)
+		return
+	}
+	announced := make(map[int64]bool, len(existing))
+	for _, eventData := range existing {
+		announced[eventData.PublishedDate.Unix()] = true
+	}
+
+	created := 0
+	for publishDate := *from; !publishDate.After(upTo); publishDate = publishDate.Add(ct.config.Frequency) {
+		if announced[publishDate.Unix()] {
+			continue
+		}
+		if created >= s.config.MaxAnnouncements {
+			logger.Infof("Created %d announcements, the ones from %s will be created on next run", created, publishDate.String())
+			return
+		}
+		if s.stopped() {
+			return
+		}
+		_, err := ct.findOrCreateDLCData(logger, db, ct.assetID, publishDate, ct.config, s.api.oracle)
+		if err != nil {
+			logger.Errorf("Could not create announcement for %s: %v", publishDate.String(), err)
+			return
+		}
+		created++
+		logger.Debugf("Created announcement for %s", publishDate.String())
+	}
+}
+
+func (s *Scheduler) attest(logger *logrus.Entry, ct *AssetController, now time.Time) {
+	db := s.api.orm.GetDB()
+	unsigned, err := entity.FindUnsignedDLCDataPublishedBefore(db, ct.assetID, now)
+	if err != nil {
+		logger.Errorf("Could not retrieve unsigned events: %v", err)
+		return
+	}
+	for _, eventData := range unsigned {
+		if s.stopped() {
+			return
+		}
+		publishDate := eventData.PublishedDate.UTC()
+		_, err := ct.findOrCreateAttestation(logger, db, s.api.feed, publishDate, s.api.oracle)
+		if err != nil {
+			// the datafeed might not have the value yet, it will be retried on next run
+			logger.Errorf("Could not create attestation for %s: %v", publishDate.String(), err)
+			continue
+		}
+		logger.Debugf("Created attestation for %s", publishDate.String())
+	}
+}
@@ -0,0 +1,175 @@
+package api_test
+
+import (
+	"p2pderivatives-oracle/internal/api"
+	"p2pderivatives-oracle/internal/cfddlccrypto"
+	"p2pderivatives-oracle/internal/database/entity"
+	"p2pderivatives-oracle/internal/datafeed"
+	"p2pderivatives-oracle/test"
+	mock_datafeed "p2pderivatives-oracle/test/mock/datafeed"
+	"testing"
+	"time"
+
+	"github.com/cryptogarageinc/server-common-go/pkg/database/orm"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+var SchedulerAssetConfig = api.AssetConfig{
+	StartDate: time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC),
+	Frequency: time.Hour,
+	RangeD:    3 * time.Hour,
+	SignConfig: api.SigningConfig{
+		Base:     10,
+		NbDigits: 3,
+	},
+	Unit: "usd/btc",
+}
+
+func SetupTestScheduler(t *testing.T, feed *mock_datafeed.MockDataFeed) (*api.Scheduler, *orm.ORM) {
+	return SetupTestSchedulerWithConfig(t, feed, &api.SchedulerConfig{Enabled: true, Interval: time.Hour, MaxAnnouncements: 100})
+}
+
+func SetupTestSchedulerWithConfig(t *testing.T, feed *mock_datafeed.MockDataFeed, schedulerConfig *api.SchedulerConfig) (*api.Scheduler, *orm.ORM) {
+	oracleInstance, err := NewTestOracleService()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	ormInstance := test.NewOrm(&entity.Asset{}, &entity.EventData{}, &entity.PriceProvenance{}, &entity.NonceSignature{}, &entity.StreamEntry{})
+	ormInstance.GetDB().Create(TestAsset)
+	config := &api.Config{AssetConfigs: map[string]api.AssetConfig{TestAsset.AssetID: SchedulerAssetConfig}}
+	oracleAPI := api.NewOracleAPI(
+		config,
+		test.NewLogger(),
+		oracleInstance,
+		ormInstance,
+		cfddlccrypto.NewCfdgoCryptoService(),
+		feed)
+	return oracleAPI.NewScheduler(schedulerConfig), ormInstance
+}
+
+func TestScheduler_RunOnce_CreatesAnnouncementsInRange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	scheduler, ormInstance := SetupTestScheduler(t, mock_datafeed.NewMockDataFeed(ctrl))
+	now := time.Now().UTC()
+
+	scheduler.RunOnce(now)
+
+	db := ormInstance.GetDB()
+	actual, err := entity.FindDLCDataPublishedBetween(db, TestAsset.AssetID, now, now.Add(SchedulerAssetConfig.RangeD))
+	assert.NoError(t, err)
+	assert.Len(t, actual, 3)
+	for _, eventData := range actual {
+		assert.Len(t, eventData.Nonces, SchedulerAssetConfig.SignConfig.NbDigits)
+		assert.NotEmpty(t, eventData.AnnouncementSignature)
+		assert.False(t, eventData.HasSignature())
+	}
+}
+
+func TestScheduler_RunOnce_Twice_IsIdempotent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	scheduler, ormInstance := SetupTestScheduler(t, mock_datafeed.NewMockDataFeed(ctrl))
+	now := time.Now().UTC()
+	db := ormInstance.GetDB()
+
+	scheduler.RunOnce(now)
+	first, _ := entity.FindDLCDataPublishedBetween(db, TestAsset.AssetID, now, now.Add(SchedulerAssetConfig.RangeD))
+	scheduler.RunOnce(now)
+	second, _ := entity.FindDLCDataPublishedBetween(db, TestAsset.AssetID, now, now.Add(SchedulerAssetConfig.RangeD))
+
+	if assert.Equal(t, len(first), len(second)) {
+		for i := range first {
+			assert.Equal(t, first[i].Nonces, second[i].Nonces)
+		}
+	}
+}
+
+func TestScheduler_RunOnce_SignsPastEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	feed := mock_datafeed.NewMockDataFeed(ctrl)
+	scheduler, ormInstance := SetupTestScheduler(t, feed)
+	now := time.Now().UTC()
+	db := ormInstance.GetDB()
+	// announce an event that will be in the past on the next run
+	scheduler.RunOnce(now)
+	announced, _ := entity.FindDLCDataPublishedBetween(db, TestAsset.AssetID, now, now.Add(SchedulerAssetConfig.RangeD))
+	if !assert.NotEmpty(t, announced) {
+		return
+	}
+	publishDate := announced[0].PublishedDate.UTC()
+	value := 123.0
+	feed.EXPECT().FindPastAssetPriceRecord(TestAsset.AssetID, publishDate).Return(
+		&datafeed.PriceRecord{Price: value, Source: datafeed.DummySource, Timestamp: publishDate}, nil).Times(1)
+
+	scheduler.RunOnce(publishDate.Add(time.Minute))
+
+	actual, err := entity.FindDLCDataPublishedAt(db, TestAsset.AssetID, publishDate)
+	if assert.NoError(t, err) {
+		assert.True(t, actual.HasSignature())
+		assert.Equal(t, []string{"1", "2", "3"}, []string(actual.Values))
+	}
+}
+
+func TestScheduler_RunOnce_MaxAnnouncements_CreatesRemainingOnNextRuns(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	scheduler, ormInstance := SetupTestSchedulerWithConfig(t, mock_datafeed.NewMockDataFeed(ctrl),
+		&api.SchedulerConfig{Enabled: true, Interval: time.Hour, MaxAnnouncements: 2})
+	now := time.Now().UTC()
+	db := ormInstance.GetDB()
+
+	scheduler.RunOnce(now)
+	first, _ := entity.FindDLCDataPublishedBetween(db, TestAsset.AssetID, now, now.Add(SchedulerAssetConfig.RangeD))
+	scheduler.RunOnce(now)
+	second, _ := entity.FindDLCDataPublishedBetween(db, TestAsset.AssetID, now, now.Add(SchedulerAssetConfig.RangeD))
+
+	assert.Len(t, first, 2)
+	assert.Len(t, second, 3)
+}
+
+func TestScheduler_RunOnce_Stopped_CreatesNothing(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	scheduler, ormInstance := SetupTestScheduler(t, mock_datafeed.NewMockDataFeed(ctrl))
+	now := time.Now().UTC()
+	scheduler.Stop()
+
+	scheduler.RunOnce(now)
+
+	actual, err := entity.FindDLCDataPublishedBetween(ormInstance.GetDB(), TestAsset.AssetID, now, now.Add(SchedulerAssetConfig.RangeD))
+	assert.NoError(t, err)
+	assert.Empty(t, actual)
+}
+
+func TestScheduler_StartStop_NoError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	scheduler, _ := SetupTestScheduler(t, mock_datafeed.NewMockDataFeed(ctrl))
+	assert.NotPanics(t, func() {
+		scheduler.Start()
+		scheduler.Stop()
+		scheduler.Stop()
+	})
+}
+
+func TestScheduler_RunOnce_NonceNotDerivedFromOracleKey_DoesNotSign(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	feed := mock_datafeed.NewMockDataFeed(ctrl)
+	scheduler, ormInstance := SetupTestScheduler(t, feed)
+	db := ormInstance.GetDB()
+	crypto := cfddlccrypto.NewCfdgoCryptoService()
+	publishDate := time.Now().UTC().Truncate(time.Hour).Add(-time.Hour)
+	nonces := make([]string, SchedulerAssetConfig.SignConfig.NbDigits)
+	for i := range nonces {
+		_, rvalue, _ := crypto.GenerateSchnorrKeyPair()
+		nonces[i] = rvalue.EncodeToString()
+	}
+	db.Create(&entity.EventData{AssetID: TestAsset.AssetID, PublishedDate: publishDate, Nonces: nonces, Base: 10})
+	value := 123.0
+	feed.EXPECT().FindPastAssetPriceRecord(TestAsset.AssetID, publishDate).Return(
+		&datafeed.PriceRecord{Price: value, Source: datafeed.DummySource, Timestamp: publishDate}, nil).Times(1)
+
+	scheduler.RunOnce(publishDate.Add(time.Minute))
+
+	actual, err := entity.FindDLCDataPublishedAt(db, TestAsset.AssetID, publishDate)
+	if assert.NoError(t, err) {
+		assert.False(t, actual.HasSignature())
+	}
+}
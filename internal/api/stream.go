@@ -0,0 +1,169 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"p2pderivatives-oracle/internal/database/entity"
+	"p2pderivatives-oracle/internal/dlccrypto"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// StreamEventAnnouncement type of the stream events sent when an event is announced
+	StreamEventAnnouncement = "announcement"
+	// StreamEventAttestation type of the stream events sent when an event is attested
+	StreamEventAttestation = "attestation"
+	// MIMEEventStream mime type of the server-sent events stream
+	MIMEEventStream = "text/event-stream"
+	// HeaderLastEventID header sent by server-sent events clients when reconnecting
+	HeaderLastEventID = "Last-Event-ID"
+	// QueryParamLastEventID query parameter to resume a stream from the given event ID
+	QueryParamLastEventID = "lastEventId"
+
+	// StreamKeepAliveInterval interval at which a comment is sent on idle streams so that
+	// the connection is not closed by proxies
+	StreamKeepAliveInterval = 30 * time.Second
+
+	// StreamPollInterval interval at which the streams read the entries recorded by every oracle process
+	StreamPollInterval = time.Second
+	// StreamGraceWindow period before the previous read during which the recorded entries are read again,
+	// it should be greater than the duration of the transactions recording the entries plus the clock skew
+	// between the oracle processes (the clients have to ignore the events whose ID they already received)
+	StreamGraceWindow = 30 * time.Second
+
+	// number of stream entries read at once
+	streamPageSize = 100
+)
+
+// StreamEvent represents an announcement or attestation pushed to the stream clients
+type StreamEvent struct {
+	// ID ID of the stream entry recorded with the announcement or attestation
+	ID uint64
+	// Type either StreamEventAnnouncement or StreamEventAttestation
+	Type    string
+	EventID string
+	// Data the OracleAnnouncement or OracleAttestation of the event
+	Data interface{}
+}
+
+// NewAnnouncementStreamEvent creates the stream event sent when the given event is announced
+// (its ID is the StreamEntryID of the event)
+func NewAnnouncementStreamEvent(oraclePubKey *dlccrypto.SchnorrPublicKey, eventData *entity.EventData) *StreamEvent {
+	return &StreamEvent{
+		ID:      eventData.StreamEntryID,
+		Type:    StreamEventAnnouncement,
+		EventID: eventData.GetEventID(),
+		Data:    NewOracleAnnouncement(oraclePubKey, eventData),
+	}
+}
+
+// NewAttestationStreamEvent creates the stream event sent when the given event is attested
+// (its ID is the StreamEntryID of the event)
+func NewAttestationStreamEvent(eventData *entity.EventData) *StreamEvent {
+	return &StreamEvent{
+		ID:      eventData.StreamEntryID,
+		Type:    StreamEventAttestation,
+		EventID: eventData.GetEventID(),
+		Data:    NewOracleAttestation(eventData),
+	}
+}
+
+// WriteTo writes the event in the server-sent events format, its id being the stream entry ID
+// so that the client can resume the stream after it
+func (e *StreamEvent) WriteTo(w io.Writer) (int64, error) {
+	data, err := json.Marshal(e.Data)
+	if err != nil {
+		return 0, err
+	}
+	n, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+	return int64(n), err
+}
+
+// streamCursor tracks the stream entries of an asset sent to a client. The entries are read from the database
+// so that the events created by every oracle process are streamed. As an entry can be committed after an entry
+// with a greater ID was sent, the entries created during the grace window before the previous read are read again,
+// the ones already sent being skipped.
+type streamCursor struct {
+	assetID string
+	// greatest ID of the sent entries
+	lastID uint64
+	// the entries created since this date are read again
+	since time.Time
+	// creation date of the entries sent since the start of the grace window
+	sent map[uint64]time.Time
+}
+
+// newStreamCursor returns a cursor streaming the entries recorded after the given one,
+// the entries created in the grace window before it being sent again as the client might have missed them
+func newStreamCursor(assetID string, last *entity.StreamEntry) *streamCursor {
+	return &streamCursor{
+		assetID: assetID,
+		lastID:  last.ID,
+		since:   last.CreatedAt.Add(-StreamGraceWindow),
+		sent:    map[uint64]time.Time{last.ID: last.CreatedAt},
+	}
+}
+
+// newLiveStreamCursor returns a cursor streaming the entries recorded from now on,
+// the entries of the grace window being considered as sent
+func newLiveStreamCursor(db *gorm.DB, assetID string, now time.Time) (*streamCursor, error) {
+	lastID, err := entity.FindLastStreamEntryID(db, assetID)
+	if err != nil {
+		return nil, err
+	}
+	cursor := &streamCursor{
+		assetID: assetID,
+		lastID:  lastID,
+		since:   now.Add(-StreamGraceWindow),
+		sent:    make(map[uint64]time.Time),
+	}
+	err = cursor.poll(db, now, func([]entity.StreamEntry) error { return nil })
+	return cursor, err
+}
+
+// poll reads the entries which were not sent yet by pages and passes them to send in the order of their IDs,
+// now being the date at which the poll started
+func (s *streamCursor) poll(db *gorm.DB, now time.Time, send func([]entity.StreamEntry) error) error {
+	var pageAfterID uint64
+	for {
+		entries, err := entity.FindStreamEntriesAfterOrSince(db, s.assetID, pageAfterID, s.lastID, s.since, streamPageSize)
+		if err != nil {
+			return err
+		}
+		unsent := make([]entity.StreamEntry, 0, len(entries))
+		for _, entry := range entries {
+			pageAfterID = entry.ID
+			if _, ok := s.sent[entry.ID]; !ok {
+				unsent = append(unsent, entry)
+			}
+		}
+		if len(unsent) > 0 {
+			if err := send(unsent); err != nil {
+				return err
+			}
+		}
+		for _, entry := range unsent {
+			s.sent[entry.ID] = entry.CreatedAt
+			if entry.ID > s.lastID {
+				s.lastID = entry.ID
+			}
+		}
+		if len(entries) < streamPageSize {
+			break
+		}
+	}
+
+	since := now.Add(-StreamGraceWindow)
+	if since.After(s.since) {
+		s.since = since
+	}
+	for id, createdAt := range s.sent {
+		if createdAt.Before(s.since) {
+			delete(s.sent, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+package api_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"p2pderivatives-oracle/internal/api"
+	"p2pderivatives-oracle/internal/cfddlccrypto"
+	"p2pderivatives-oracle/internal/database/entity"
+	"p2pderivatives-oracle/internal/datafeed"
+	"p2pderivatives-oracle/test"
+	mock_datafeed "p2pderivatives-oracle/test/mock/datafeed"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+type ReceivedStreamEvent struct {
+	ID    string
+	Event string
+	Data  string
+}
+
+func OpenAssetStream(t *testing.T, server *httptest.Server, lastEventID string) (*http.Response, *bufio.Reader, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+api.RouteGETAssetStream, nil)
+	if lastEventID != "" {
+		req.Header.Set(api.HeaderLastEventID, lastEventID)
+	}
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		cancel()
+		t.Fatal(err)
+	}
+	return resp, bufio.NewReader(resp.Body), cancel
+}
+
+func ReadStreamEvent(t *testing.T, reader *bufio.Reader) *ReceivedStreamEvent {
+	event := &ReceivedStreamEvent{}
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && event.Event != "":
+			return event
+		case strings.HasPrefix(line, "id: "):
+			event.ID = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			event.Event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			event.Data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+// ReadNewStreamEvent reads the next stream event whose ID was not received yet, as an event can be sent more than once
+func ReadNewStreamEvent(t *testing.T, reader *bufio.Reader, received map[string]bool) *ReceivedStreamEvent {
+	for {
+		event := ReadStreamEvent(t, reader)
+		if !received[event.ID] {
+			received[event.ID] = true
+			return event
+		}
+	}
+}
+
+func TestAssetController_GetAssetStream_SendsCreatedAnnouncements(t *testing.T) {
+	oracleInstance, _ := NewTestOracleService()
+	_, r := SetupAssetEngine(httptest.NewRecorder(), oracleInstance, cfddlccrypto.NewCfdgoCryptoService(), nil)
+	server := httptest.NewServer(r)
+	defer server.Close()
+	resp, reader, cancel := OpenAssetStream(t, server, "")
+	defer cancel()
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, api.MIMEEventStream, resp.Header.Get("Content-Type"))
+
+	date := time.Now().UTC().Truncate(TestAssetConfig.Frequency).Add(TestAssetConfig.Frequency)
+	announcementResp, err := server.Client().Get(server.URL + GetRouteWithTimeParam(api.RouteGETAssetAnnouncement, date))
+	if assert.NoError(t, err) {
+		announcementResp.Body.Close()
+	}
+
+	actual := ReadStreamEvent(t, reader)
+	assert.Equal(t, api.StreamEventAnnouncement, actual.Event)
+	announcement := &api.OracleAnnouncement{}
+	if assert.NoError(t, json.Unmarshal([]byte(actual.Data), announcement)) {
+		assert.Equal(t, "1", actual.ID)
+		assert.Equal(t, date.Unix(), announcement.OracleEvent.EventMaturityEpoch)
+	}
+}
+
+func AnnounceEvent(t *testing.T, server *httptest.Server, date time.Time) *api.OracleAnnouncement {
+	resp, err := server.Client().Get(server.URL + GetRouteWithTimeParam(api.RouteGETAssetAnnouncement, date))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	announcement := &api.OracleAnnouncement{}
+	if err := json.NewDecoder(resp.Body).Decode(announcement); err != nil {
+		t.Fatal(err)
+	}
+	return announcement
+}
+
+func TestAssetController_GetAssetStream_WithLastEventID_SendsEventsRecordedAfter(t *testing.T) {
+	oracleInstance, _ := NewTestOracleService()
+	_, r, dates := SetupAssetEngineWithAnnouncedEvents(t, httptest.NewRecorder(), 3)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	// the announcement of the first event is the last event seen
+	resp, reader, cancel := OpenAssetStream(t, server, "1")
+	defer cancel()
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	for i, date := range dates[1:] {
+		actual := ReadStreamEvent(t, reader)
+		assert.Equal(t, api.StreamEventAnnouncement, actual.Event)
+		assert.Equal(t, strconv.Itoa(i+2), actual.ID)
+		announcement := &api.OracleAnnouncement{}
+		if assert.NoError(t, json.Unmarshal([]byte(actual.Data), announcement)) {
+			assert.Equal(t, date.Unix(), announcement.OracleEvent.EventMaturityEpoch)
+			assert.Equal(t, oracleInstance.PublicKey().EncodeToString(), announcement.OraclePublicKey)
+		}
+	}
+}
+
+func TestAssetController_GetAssetStream_EarlierEventAttestedAfterLastEventID_SendsAttestationOnly(t *testing.T) {
+	oracleInstance, _ := NewTestOracleService()
+	ctrl := gomock.NewController(t)
+	feed := mock_datafeed.NewMockDataFeed(ctrl)
+	_, r := SetupAssetEngine(httptest.NewRecorder(), oracleInstance, cfddlccrypto.NewCfdgoCryptoService(), feed)
+	server := httptest.NewServer(r)
+	defer server.Close()
+	// an event published before the last event seen, which is attested after it was seen
+	earlier := AnnounceEvent(t, server, InDbDLCData.PublishedDate.Add(-TestAssetConfig.Frequency))
+	next := time.Now().UTC().Truncate(TestAssetConfig.Frequency).Add(TestAssetConfig.Frequency)
+	AnnounceEvent(t, server, next)
+	earlierDate := time.Unix(earlier.OracleEvent.EventMaturityEpoch, 0).UTC()
+	feed.EXPECT().FindPastAssetPriceRecord(TestAsset.AssetID, earlierDate).Return(
+		&datafeed.PriceRecord{Price: datafeedValue, Source: datafeed.DummySource, Timestamp: earlierDate}, nil)
+	attestationResp, err := server.Client().Get(server.URL + GetRouteWithTimeParam(api.RouteGETAssetAttestation, earlierDate))
+	if !assert.NoError(t, err) {
+		return
+	}
+	attestationResp.Body.Close()
+
+	resp, reader, cancel := OpenAssetStream(t, server, "2")
+	defer cancel()
+	defer resp.Body.Close()
+	received := map[string]bool{"1": true, "2": true}
+
+	attestation := ReadNewStreamEvent(t, reader, received)
+	assert.Equal(t, api.StreamEventAttestation, attestation.Event)
+	assert.Equal(t, "3", attestation.ID)
+	assert.Contains(t, attestation.Data, earlier.OracleEvent.EventID)
+	// the pending announcements seen before are not sent again
+	later := AnnounceEvent(t, server, next.Add(TestAssetConfig.Frequency))
+	announcement := ReadNewStreamEvent(t, reader, received)
+	assert.Equal(t, api.StreamEventAnnouncement, announcement.Event)
+	assert.Equal(t, "4", announcement.ID)
+	assert.Contains(t, announcement.Data, later.OracleEvent.EventID)
+}
+
+func TestAssetController_GetAssetStream_EntryCommittedAfterGreaterID_SendsEntry(t *testing.T) {
+	oracleInstance, _ := NewTestOracleService()
+	_, r, ormInstance := SetupAssetEngineWithOrm(
+		httptest.NewRecorder(), TestAssetConfig, oracleInstance, cfddlccrypto.NewCfdgoCryptoService(), nil)
+	server := httptest.NewServer(r)
+	defer server.Close()
+	resp, reader, cancel := OpenAssetStream(t, server, "")
+	defer cancel()
+	defer resp.Body.Close()
+	announcement := AnnounceEvent(t, server, time.Now().UTC().Truncate(TestAssetConfig.Frequency).Add(TestAssetConfig.Frequency))
+	assert.Equal(t, "1", ReadStreamEvent(t, reader).ID)
+
+	// entries recorded by other oracle processes, the one with the lower ID being committed last
+	db := ormInstance.GetDB()
+	db.Create(&entity.StreamEntry{ID: 3, AssetID: TestAsset.AssetID, EventID: announcement.OracleEvent.EventID, Type: entity.StreamEntryAnnouncement})
+	assert.Equal(t, "3", ReadStreamEvent(t, reader).ID)
+	db.Create(&entity.StreamEntry{ID: 2, AssetID: TestAsset.AssetID, EventID: announcement.OracleEvent.EventID, Type: entity.StreamEntryAnnouncement})
+	actual := ReadStreamEvent(t, reader)
+
+	assert.Equal(t, "2", actual.ID)
+	assert.Contains(t, actual.Data, announcement.OracleEvent.EventID)
+}
+
+func TestOracleAPI_CloseStreams_OpenStream_DoesNotBlockShutdown(t *testing.T) {
+	oracleInstance, _ := NewTestOracleService()
+	ormInstance := test.NewOrm(&entity.Asset{}, &entity.EventData{}, &entity.PriceProvenance{}, &entity.NonceSignature{}, &entity.StreamEntry{})
+	config := &api.Config{AssetConfigs: map[string]api.AssetConfig{TestAsset.AssetID: *TestAssetConfig}}
+	oracleAPI := api.NewOracleAPI(config, test.NewLogger(), oracleInstance, ormInstance, cfddlccrypto.NewCfdgoCryptoService(), nil)
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(oracleAPI.GlobalMiddlewares()...)
+	oracleAPI.Routes(r.Group(""))
+	server := httptest.NewUnstartedServer(r)
+	server.Config.RegisterOnShutdown(oracleAPI.CloseStreams)
+	server.Start()
+	defer server.Close()
+	req, _ := http.NewRequest(http.MethodGet, server.URL+api.AssetBaseRoute+"/"+TestAsset.AssetID+api.RouteGETAssetStream, nil)
+	resp, err := server.Client().Do(req)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err = server.Config.Shutdown(ctx)
+
+	assert.NoError(t, err)
+}
+
+func TestAssetController_GetAssetStream_UnknownLastEventID_ReturnsNotFound(t *testing.T) {
+	oracleInstance, _ := NewTestOracleService()
+	_, r := SetupAssetEngine(httptest.NewRecorder(), oracleInstance, nil, nil)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	resp, _, cancel := OpenAssetStream(t, server, "unknown-1610608860")
+	defer cancel()
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"net/http"
+	"p2pderivatives-oracle/internal/oracle"
+	"p2pderivatives-oracle/pkg/oracleclient"
+
+	ginlogrus "github.com/Bose/go-gin-logrus"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// RoutePOSTVerify route for the POST verification of an attestation from VerifyController
+	RoutePOSTVerify = "/verify"
+)
+
+// VerifyController represents the verification api Controller, checking announcements and attestations
+// on behalf of the clients (see the oracleclient package to verify them locally)
+type VerifyController struct {
+}
+
+// NewVerifyController creates a new Controller structure with the given parameters.
+func NewVerifyController() Controller {
+	return &VerifyController{}
+}
+
+// Routes list and binds all routes to the router group provided
+func (ct *VerifyController) Routes(route *gin.RouterGroup) {
+	route.POST(RoutePOSTVerify, ct.PostVerify)
+}
+
+// PostVerify handler verifies the announcement signature and the signature of each attested value,
+// returning the outcome of the event if they are valid
+func (ct *VerifyController) PostVerify(c *gin.Context) {
+	ginlogrus.SetCtxLoggerHeader(c, "request-header", "Post Verify")
+	request := &VerifyRequest{}
+	if err := c.ShouldBindJSON(request); err != nil {
+		c.Error(NewBadRequestError(InvalidBodyBadRequestErrorCode, err, "body"))
+		return
+	}
+
+	oracleInstance := c.MustGet(ContextIDOracle).(*oracle.Oracle)
+	outcome, err := oracleclient.VerifyAttestation(&request.Announcement, &request.Attestation)
+	if err != nil {
+		c.JSON(http.StatusOK, &VerifyResponse{Error: err.Error()})
+		return
+	}
+	_, err = oracleInstance.EventKey(request.Announcement.OraclePublicKey)
+	c.JSON(http.StatusOK, &VerifyResponse{
+		Valid:     true,
+		OracleKey: err == nil,
+		Outcome:   outcome,
+	})
+}
@@ -0,0 +1,138 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"p2pderivatives-oracle/internal/api"
+	"p2pderivatives-oracle/internal/cfddlccrypto"
+	"p2pderivatives-oracle/internal/dlccrypto"
+	"p2pderivatives-oracle/internal/oracle"
+	"p2pderivatives-oracle/pkg/oracleclient"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func SetupVerifyEngine(recorder *httptest.ResponseRecorder, o *oracle.Oracle) (*gin.Context, *gin.Engine) {
+	setup := func(c *gin.Context) {
+		c.Set(api.ContextIDOracle, o)
+	}
+	return SetupEngine(recorder, api.NewVerifyController(), api.ErrorHandler(), setup)
+}
+
+// newTestVerifyRequest returns the announcement and attestation of a 4 digits base 10 event signed by the oracle
+func newTestVerifyRequest(t *testing.T, o *oracle.Oracle, value float64) *api.VerifyRequest {
+	signer := o.Keys[0].Signer
+	maturity := uint32(1600000000)
+	eventID := "btcusd-1600000000"
+	nonces := make([]dlccrypto.SchnorrPublicKey, 4)
+	eventNonces := make([]dlccrypto.EventNonce, 4)
+	encodedNonces := make([]string, 4)
+	for i := range nonces {
+		nonce, err := signer.DeriveSchnorrNonce("btcusd", maturity, i)
+		require.NoError(t, err)
+		nonces[i] = *nonce
+		eventNonces[i] = dlccrypto.EventNonce{AssetID: "btcusd", EventMaturity: maturity, Index: i}
+		encodedNonces[i] = nonce.EncodeToString()
+	}
+	event := dlccrypto.SerializeEvent(nonces, maturity, 10, false, "usd/btc", 0, 4, eventID)
+	announcementSig, err := signer.ComputeSchnorrSignature(event)
+	require.NoError(t, err)
+	sigs, values, err := dlccrypto.GetRoundedDecomposedSignaturesForValue(value, 10, 4, false, 0, signer, eventNonces)
+	require.NoError(t, err)
+
+	return &api.VerifyRequest{
+		Announcement: oracleclient.Announcement{
+			AnnouncementSignature: announcementSig.EncodeToString(),
+			OraclePublicKey:       signer.PublicKey().EncodeToString(),
+			OracleEvent: oracleclient.Event{
+				Nonces:             encodedNonces,
+				EventMaturityEpoch: int64(maturity),
+				EventDescriptor: oracleclient.EventDescriptor{
+					DigitDecompositionDescriptor: &oracleclient.DigitDecompositionDescriptor{
+						Base:     10,
+						Unit:     "usd/btc",
+						NbDigits: 4,
+					},
+				},
+				EventID: eventID,
+			},
+		},
+		Attestation: oracleclient.Attestation{EventID: eventID, Signatures: sigs, Values: values},
+	}
+}
+
+func postVerify(t *testing.T, o *oracle.Oracle, body []byte) (*httptest.ResponseRecorder, *api.VerifyResponse) {
+	resp := httptest.NewRecorder()
+	c, r := SetupVerifyEngine(resp, o)
+	c.Request, _ = http.NewRequest(http.MethodPost, api.RoutePOSTVerify, bytes.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(resp, c.Request)
+	actual := &api.VerifyResponse{}
+	json.Unmarshal(resp.Body.Bytes(), actual)
+	return resp, actual
+}
+
+func TestVerifyController_PostVerify_ValidAttestation_ReturnsOutcome(t *testing.T) {
+	oracleService, err := NewTestOracleService()
+	require.NoError(t, err)
+	body, _ := json.Marshal(newTestVerifyRequest(t, oracleService, 1234))
+
+	resp, actual := postVerify(t, oracleService, body)
+
+	if assert.Equal(t, http.StatusOK, resp.Code) {
+		assert.True(t, actual.Valid)
+		assert.True(t, actual.OracleKey)
+		assert.Empty(t, actual.Error)
+		if assert.NotNil(t, actual.Outcome) {
+			assert.Equal(t, int64(1234), actual.Outcome.Value)
+			assert.Equal(t, "1234", actual.Outcome.Outcome)
+		}
+	}
+}
+
+func TestVerifyController_PostVerify_OtherOracle_ReturnsNotOracleKey(t *testing.T) {
+	oracleService, err := NewTestOracleService()
+	require.NoError(t, err)
+	crypto := cfddlccrypto.NewCfdgoCryptoService()
+	priv, pub, err := crypto.GenerateSchnorrKeyPair()
+	require.NoError(t, err)
+	signingOracle := oracle.New(dlccrypto.NewKeySigner(priv, pub, crypto))
+	body, _ := json.Marshal(newTestVerifyRequest(t, signingOracle, 1234))
+
+	resp, actual := postVerify(t, oracleService, body)
+
+	if assert.Equal(t, http.StatusOK, resp.Code) {
+		assert.True(t, actual.Valid)
+		assert.False(t, actual.OracleKey)
+	}
+}
+
+func TestVerifyController_PostVerify_InvalidAttestation_ReturnsNotValid(t *testing.T) {
+	oracleService, err := NewTestOracleService()
+	require.NoError(t, err)
+	request := newTestVerifyRequest(t, oracleService, 1234)
+	request.Attestation.Values[3] = "5"
+	body, _ := json.Marshal(request)
+
+	resp, actual := postVerify(t, oracleService, body)
+
+	if assert.Equal(t, http.StatusOK, resp.Code) {
+		assert.False(t, actual.Valid)
+		assert.NotEmpty(t, actual.Error)
+		assert.Nil(t, actual.Outcome)
+	}
+}
+
+func TestVerifyController_PostVerify_InvalidBody_ReturnsBadRequest(t *testing.T) {
+	oracleService, err := NewTestOracleService()
+	require.NoError(t, err)
+
+	resp, _ := postVerify(t, oracleService, []byte("{"))
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
@@ -41,6 +41,22 @@ func (o *CfdgoCryptoService) GenerateSchnorrKeyPair() (*dlccrypto.PrivateKey, *d
 	return privkey, pubkey, nil
 }
 
+// DeriveSchnorrNonce returns the one time signing key and nonce of an event derived from the oracle private key
+// (see dlccrypto.DeriveNonceKey)
+func (o *CfdgoCryptoService) DeriveSchnorrNonce(privateKey *dlccrypto.PrivateKey, assetID string, eventMaturity uint32, index int) (*dlccrypto.PrivateKey, *dlccrypto.SchnorrPublicKey, error) {
+	kvalue, err := dlccrypto.DeriveNonceKey(privateKey, assetID, eventMaturity, index)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "Error while deriving nonce")
+	}
+
+	rvalue, err := o.SchnorrPublicKeyFromPrivateKey(kvalue)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return kvalue, rvalue, nil
+}
+
 // SchnorrPublicKeyFromPrivateKey computes a Schnorr public key from a private key
 func (o *CfdgoCryptoService) SchnorrPublicKeyFromPrivateKey(privateKey *dlccrypto.PrivateKey) (*dlccrypto.SchnorrPublicKey, error) {
 	bs, err := o.schnorrUtil.GetPubkeyFromPrivkey(*cfdgo.NewByteDataFromHexIgnoreError(privateKey.EncodeToString()))
@@ -109,3 +125,16 @@ func (o *CfdgoCryptoService) VerifySchnorrSignatureRaw(publicKey *dlccrypto.Schn
 	}
 	return ok, nil
 }
+
+// ComputeSigPoint computes the point s*G of the signature of the given message (will be hashed with sha256)
+// with the given nonce, without the private key (the anticipation point of the message)
+func (o *CfdgoCryptoService) ComputeSigPoint(publicKey *dlccrypto.SchnorrPublicKey, nonce *dlccrypto.SchnorrPublicKey, message string) (*dlccrypto.Point, error) {
+	hash := sha256.Sum256([]byte(message))
+	bs, err := o.schnorrUtil.ComputeSigPoint(cfdgo.NewByteData(hash[:]),
+		*cfdgo.NewByteDataFromHexIgnoreError(nonce.EncodeToString()),
+		*cfdgo.NewByteDataFromHexIgnoreError(publicKey.EncodeToString()))
+	if err != nil {
+		return nil, errors.WithMessage(err, "Error while computing signature point")
+	}
+	return dlccrypto.NewPoint(bs.ToHex())
+}
@@ -1,9 +1,13 @@
 package cfddlccrypto_test
 
 import (
+	"fmt"
 	"math/rand"
 	"p2pderivatives-oracle/internal/cfddlccrypto"
 	"p2pderivatives-oracle/internal/dlccrypto"
+	"p2pderivatives-oracle/internal/godlccrypto"
+	"p2pderivatives-oracle/test"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -146,3 +150,117 @@ func Test_CfdgoCryptoService_VerifySignature(t *testing.T) {
 		assert.True(t, check)
 	}
 }
+
+func Test_CfdgoCryptoService_DeriveSchnorrNonce_IsDeterministicAndUsedBySignature(t *testing.T) {
+	crypto := cfddlccrypto.NewCfdgoCryptoService()
+	oracleKey, err := dlccrypto.NewPrivateKey(TestOracleKeyPair.PrivateKey)
+	assert.NoError(t, err)
+
+	kvalue, rvalue, err := crypto.DeriveSchnorrNonce(oracleKey, "btcusd", 1623133104, 2)
+	assert.NoError(t, err)
+	kvalueAgain, rvalueAgain, err := crypto.DeriveSchnorrNonce(oracleKey, "btcusd", 1623133104, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, kvalue.EncodeToString(), kvalueAgain.EncodeToString())
+	assert.Equal(t, rvalue.EncodeToString(), rvalueAgain.EncodeToString())
+
+	sig, err := crypto.ComputeSchnorrSignatureFixedK(oracleKey, kvalue, TestMessage[0])
+	assert.NoError(t, err)
+	assert.Equal(t, rvalue.EncodeToString(), sig.EncodeToString()[:64])
+}
+
+func Test_CfdgoCryptoService_MatchesGoCryptoService(t *testing.T) {
+	cfdCrypto := cfddlccrypto.NewCfdgoCryptoService()
+	goCrypto := godlccrypto.NewGoCryptoService()
+	oracleKey, err := dlccrypto.NewPrivateKey(TestOracleKeyPair.PrivateKey)
+	assert.NoError(t, err)
+
+	for _, keypair := range TestKeyPairs {
+		privKey, err := dlccrypto.NewPrivateKey(keypair.PrivateKey)
+		assert.NoError(t, err)
+		expected, err := cfdCrypto.SchnorrPublicKeyFromPrivateKey(privKey)
+		assert.NoError(t, err)
+		actual, err := goCrypto.SchnorrPublicKeyFromPrivateKey(privKey)
+		assert.NoError(t, err)
+		assert.Equal(t, expected.EncodeToString(), actual.EncodeToString())
+	}
+
+	for i, message := range TestMessage {
+		expectedK, expectedR, err := cfdCrypto.DeriveSchnorrNonce(oracleKey, "btcusd", 1623133104, i)
+		assert.NoError(t, err)
+		actualK, actualR, err := goCrypto.DeriveSchnorrNonce(oracleKey, "btcusd", 1623133104, i)
+		assert.NoError(t, err)
+		assert.Equal(t, expectedK.EncodeToString(), actualK.EncodeToString())
+		assert.Equal(t, expectedR.EncodeToString(), actualR.EncodeToString())
+
+		expectedSig, err := cfdCrypto.ComputeSchnorrSignatureFixedK(oracleKey, expectedK, message)
+		assert.NoError(t, err)
+		actualSig, err := goCrypto.ComputeSchnorrSignatureFixedK(oracleKey, actualK, message)
+		assert.NoError(t, err)
+		assert.Equal(t, expectedSig.EncodeToString(), actualSig.EncodeToString())
+
+		oraclePubKey, err := dlccrypto.NewSchnorrPublicKey(TestOracleKeyPair.PublicKey)
+		assert.NoError(t, err)
+		expectedPoint, err := cfdCrypto.ComputeSigPoint(oraclePubKey, expectedR, message)
+		assert.NoError(t, err)
+		actualPoint, err := goCrypto.ComputeSigPoint(oraclePubKey, actualR, message)
+		assert.NoError(t, err)
+		assert.Equal(t, expectedPoint.EncodeToString(), actualPoint.EncodeToString())
+
+		// signatures with random nonces differ but must be accepted by both implementations
+		sig, err := goCrypto.ComputeSchnorrSignature(oracleKey, []byte(message))
+		assert.NoError(t, err)
+		isValid, err := cfdCrypto.VerifySchnorrSignature(oraclePubKey, sig, message)
+		assert.NoError(t, err)
+		assert.True(t, isValid)
+	}
+}
+
+func Test_CfdgoCryptoService_MatchesGoCryptoService_DigitAndEventSignatures(t *testing.T) {
+	cfdCrypto := cfddlccrypto.NewCfdgoCryptoService()
+	goCrypto := godlccrypto.NewGoCryptoService()
+	passwords := []string{
+		"wKeEhq0DP/rNtcD8u/NxLyJYKmyKqOzklgOamGJlbSA=",
+		"z6Re1aGzRaVewoIX+3HHsR6dELtLL8aR4LFKLLCypJc=",
+		"om9fTkErrpZfF7Q85sC6AM+hjeYh9zxN7yi2iD7IWRQ=",
+		"svMKJcSUkpXVrrwhiznzs7fWm7i3h9WwsdzCazB3NkA=",
+	}
+	nbDigits := 20
+	for i, password := range passwords {
+		path := filepath.Join(test.VectorsDirectoryPath, "keys", fmt.Sprintf("key_%d.pem", i))
+		oracleKey, err := dlccrypto.ReadPemKeyFile(path, []byte(password))
+		assert.NoError(t, err)
+		oraclePubKey, err := goCrypto.SchnorrPublicKeyFromPrivateKey(oracleKey)
+		assert.NoError(t, err)
+
+		cfdSigner := dlccrypto.NewKeySigner(oracleKey, oraclePubKey, cfdCrypto)
+		goSigner := dlccrypto.NewKeySigner(oracleKey, oraclePubKey, goCrypto)
+
+		eventNonces := make([]dlccrypto.EventNonce, nbDigits)
+		nonces := make([]dlccrypto.SchnorrPublicKey, nbDigits)
+		for j := 0; j < nbDigits; j++ {
+			rvalue, err := goSigner.DeriveSchnorrNonce("btcusd", 1623133104, j)
+			assert.NoError(t, err)
+			eventNonces[j] = dlccrypto.EventNonce{AssetID: "btcusd", EventMaturity: 1623133104, Index: j}
+			nonces[j] = *rvalue
+		}
+
+		expectedSigs, expectedValues, err := dlccrypto.GetRoundedDecomposedSignaturesForValue(
+			31234.56, 2, nbDigits, false, 0, cfdSigner, eventNonces)
+		assert.NoError(t, err)
+		actualSigs, actualValues, err := dlccrypto.GetRoundedDecomposedSignaturesForValue(
+			31234.56, 2, nbDigits, false, 0, goSigner, eventNonces)
+		assert.NoError(t, err)
+		assert.Equal(t, expectedValues, actualValues)
+		assert.Equal(t, expectedSigs, actualSigs)
+
+		// event signatures use random nonces, each implementation must accept the other's
+		ser := dlccrypto.SerializeEvent(nonces, 1623133104, 2, false, "usd/btc", 0, uint16(nbDigits), "btcusd-1623133104")
+		for _, pair := range [][2]dlccrypto.CryptoService{{cfdCrypto, goCrypto}, {goCrypto, cfdCrypto}} {
+			sig, err := pair[0].ComputeSchnorrSignature(oracleKey, ser)
+			assert.NoError(t, err)
+			isValid, err := pair[1].VerifySchnorrSignatureRaw(oraclePubKey, sig, ser)
+			assert.NoError(t, err)
+			assert.True(t, isValid)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package cryptocompare
 
 import (
+	"encoding/json"
 	"fmt"
 	"p2pderivatives-oracle/internal/datafeed"
 	"strings"
@@ -13,11 +14,16 @@ import (
 	"github.com/go-resty/resty/v2"
 )
 
+// Source source name of the prices returned by the client
+const Source = "cryptocompare"
+
 const (
 	priceRoute           = "/price"
 	pricePastHourRoute   = "/v2/histohour"
 	pricePastMinuteRoute = "/v2/histominute"
 	limitPastResponse    = 1
+	// maximum number of candles returned by the history routes
+	limitPastSeriesResponse = 2000
 )
 
 // NewClient returns a new CryptoCompare Client (not initialized)
@@ -29,6 +35,12 @@ func NewClient(l *log.Log, config *Config) *Client {
 	}
 }
 
+// apiErrorResponse error payload returned by cryptocompare with a 200 status code
+type apiErrorResponse struct {
+	Response string `json:"Response"`
+	Message  string `json:"Message"`
+}
+
 type apiPriceResponse map[string]float64
 type apiPastPriceResponse struct {
 	Data struct {
@@ -37,8 +49,13 @@ type apiPastPriceResponse struct {
 		// Data data response with time (like one element for each minute/hour)
 		// the requested time should be the last element
 		Data []struct {
-			Time  int64   `json:"time"`
-			Close float64 `json:"close"`
+			Time       int64   `json:"time"`
+			Open       float64 `json:"open"`
+			High       float64 `json:"high"`
+			Low        float64 `json:"low"`
+			Close      float64 `json:"close"`
+			VolumeFrom float64 `json:"volumefrom"`
+			VolumeTo   float64 `json:"volumeto"`
 		} `json:"Data"`
 	} `json:"Data"`
 }
@@ -50,6 +67,7 @@ type Client struct {
 	httpClient  *resty.Client
 	initialized bool
 	log         *log.Log
+	timeout     time.Duration
 }
 
 // Initialize initializes the http client
@@ -60,9 +78,20 @@ func (c *Client) Initialize() {
 	if c.config.APIKey != "" {
 		c.httpClient.SetHeader("authorization", "Apikey "+c.config.APIKey)
 	}
+	if c.timeout > 0 {
+		c.httpClient.SetTimeout(c.timeout)
+	}
 	c.initialized = true
 }
 
+// SetTimeout sets the timeout of the requests sent to the CryptoCompare API
+func (c *Client) SetTimeout(timeout time.Duration) {
+	c.timeout = timeout
+	if c.httpClient != nil {
+		c.httpClient.SetTimeout(timeout)
+	}
+}
+
 // IsInitialized returns true if the Client has been initialized
 func (c *Client) IsInitialized() bool {
 	return c.initialized
@@ -75,12 +104,11 @@ func (c *Client) FindCurrentAssetPrice(assetID string) (*float64, error) {
 		return nil, errors.New(fmt.Sprintf("Could not find config for asset %v", assetID))
 	}
 	route := fmt.Sprintf(priceRoute+"?fsym=%s&tsyms=%s", assetConfig.Fsym, assetConfig.Tsym)
-	resp, err := c.getAssetPrice(route, apiPriceResponse{})
-	if err != nil {
+	res := apiPriceResponse{}
+	if err := c.getAssetPrice(route, &res); err != nil {
 		return nil, err
 	}
 
-	res := *(resp.Result().(*apiPriceResponse))
 	val, ok := res[strings.ToUpper(assetConfig.Tsym)]
 
 	// it should not happened if the request was well formed
@@ -93,16 +121,27 @@ func (c *Client) FindCurrentAssetPrice(assetID string) (*float64, error) {
 
 // FindPastAssetPrice sends a GET request to the CryptoCompare API to retrieve a past price of an asset
 func (c *Client) FindPastAssetPrice(assetID string, date time.Time) (*float64, error) {
+	record, err := c.FindPastAssetPriceRecord(assetID, date)
+	if err != nil {
+		return nil, err
+	}
+	return &record.Price, nil
+}
+
+// FindPastAssetPriceRecord sends a GET request to the CryptoCompare API to retrieve a past price of an asset
+// along with the candle used (the close price of the candle is used)
+func (c *Client) FindPastAssetPriceRecord(assetID string, date time.Time) (*datafeed.PriceRecord, error) {
 	now := time.Now()
 	if now.Before(date) {
 		return nil, errors.New("date should be before now")
 	}
-	var precisionRoute string
-	// before seven days (minute precision are stored only seven days in cryptocompare)
+	precisionRoute := pricePastMinuteRoute
+	interval := time.Minute
+	// before seven days (minute precision are stored only seven days in cryptocompare),
+	// the returned hourly candle is only exact for dates on the hour (see datafeed.NewSettlementDataFeed)
 	if date.Before(now.Add(-time.Hour * 168)) {
 		precisionRoute = pricePastHourRoute
-	} else {
-		precisionRoute = pricePastMinuteRoute
+		interval = time.Hour
 	}
 	var assetConfig, ok = c.config.AssetsConfig[assetID]
 	if !ok {
@@ -114,34 +153,124 @@ func (c *Client) FindPastAssetPrice(assetID string, date time.Time) (*float64, e
 		assetConfig.Tsym,
 		date.Unix(),
 		limitPastResponse)
-	resp, err := c.getAssetPrice(route, apiPastPriceResponse{})
-	if err != nil {
+	res := &apiPastPriceResponse{}
+	if err := c.getAssetPrice(route, res); err != nil {
 		return nil, err
 	}
 
-	res := resp.Result().(*apiPastPriceResponse)
-
 	// should not happen
 	if len(res.Data.Data) != limitPastResponse+1 {
-		c.log.Logger.Errorln("Unexpected data for route ", route, "got response ", resp)
+		c.log.Logger.Errorln("Unexpected data for route ", route, "got response ", res)
 		return nil, errors.New("cryptocompare response did not contain the requested element")
 	}
 
-	// limitPastResponse should be the last element
-	value := res.Data.Data[limitPastResponse].Close
-	return &value, nil
+	// limitPastResponse should be the last element, which is the candle containing the date
+	// unless cryptocompare does not have it yet
+	candle := res.candle(limitPastResponse, interval)
+	return &datafeed.PriceRecord{
+		Price:     candle.Close,
+		Source:    fmt.Sprintf("%s%s?fsym=%s&tsym=%s", Source, precisionRoute, assetConfig.Fsym, assetConfig.Tsym),
+		Timestamp: candle.Time,
+		Candle:    &candle,
+	}, nil
+}
+
+// FindPastAssetPriceSeries sends a GET request to the CryptoCompare API to retrieve the candles
+// of an asset within a period (hourly candles if the period starts more than seven days ago)
+func (c *Client) FindPastAssetPriceSeries(assetID string, from time.Time, to time.Time) ([]datafeed.Candle, error) {
+	now := time.Now()
+	if now.Before(to) {
+		return nil, errors.New("date should be before now")
+	}
+	if !from.Before(to) {
+		return nil, errors.New("start of the period should be before its end")
+	}
+	precisionRoute := pricePastMinuteRoute
+	interval := time.Minute
+	if from.Before(now.Add(-time.Hour * 168)) {
+		precisionRoute = pricePastHourRoute
+		interval = time.Hour
+	}
+	limit := int((to.Sub(from) + interval - 1) / interval)
+	if limit > limitPastSeriesResponse {
+		return nil, errors.Errorf("cryptocompare cannot return more than %d candles", limitPastSeriesResponse)
+	}
+	var assetConfig, ok = c.config.AssetsConfig[assetID]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("No config found for asset %v", assetID))
+	}
+	// the last element is the candle containing toTs, so the one ending at the end of the period
+	route := fmt.Sprintf(
+		precisionRoute+"?fsym=%s&tsym=%s&toTs=%d&limit=%d",
+		assetConfig.Fsym,
+		assetConfig.Tsym,
+		to.Add(-time.Second).Unix(),
+		limit)
+	res := &apiPastPriceResponse{}
+	if err := c.getAssetPrice(route, res); err != nil {
+		return nil, err
+	}
+
+	candles := make([]datafeed.Candle, len(res.Data.Data))
+	for i := range res.Data.Data {
+		candles[i] = res.candle(i, interval)
+	}
+	return datafeed.CandlesWithin(candles, from, to), nil
+}
+
+// candle returns the i-th candle of the response
+func (r *apiPastPriceResponse) candle(i int, interval time.Duration) datafeed.Candle {
+	data := r.Data.Data[i]
+	candle := datafeed.Candle{
+		Time:     time.Unix(data.Time, 0).UTC(),
+		Interval: interval,
+		Open:     data.Open,
+		High:     data.High,
+		Low:      data.Low,
+		Close:    data.Close,
+		Volume:   data.VolumeFrom,
+	}
+	if data.VolumeFrom > 0 {
+		candle.VWAP = data.VolumeTo / data.VolumeFrom
+	}
+	return candle
+}
+
+// FindPastOutcome is not supported by CryptoCompare which only provides prices
+// (see datafeed.NewStrikeOutcomeFeed to resolve an enumerated event from a price)
+func (c *Client) FindPastOutcome(assetID string, date time.Time, outcomes []string) (*string, error) {
+	return nil, errors.New(fmt.Sprintf("cryptocompare cannot resolve outcome of asset %v", assetID))
 }
 
-func (c *Client) getAssetPrice(route string, resultType interface{}) (*resty.Response, error) {
+// getAssetPrice sends a GET request to the CryptoCompare API and decodes the response in result,
+// the error responses (error status code or error payload) being returned as datafeed.SourceError
+func (c *Client) getAssetPrice(route string, result interface{}) error {
 	if !c.IsInitialized() {
-		return nil, errors.New("crypto compare client is not initialized")
+		return errors.New("crypto compare client is not initialized")
 	}
-	req := c.httpClient.R()
-	req.SetResult(resultType)
-	resp, err := req.Get(route)
+	resp, err := c.httpClient.R().Get(route)
 	if err != nil {
-		return nil, errors.WithMessagef(err, "error while sending a request to cryptocompare api %v", resp.String())
+		return datafeed.NewUnavailableError(Source, err)
 	}
+	if resp.IsError() {
+		return datafeed.NewHTTPError(Source, resp.StatusCode(), resp.Header(), resp.String())
+	}
+
+	apiError := &apiErrorResponse{}
+	if err := json.Unmarshal(resp.Body(), apiError); err == nil && apiError.Response == "Error" {
+		return newAPIError(apiError.Message)
+	}
+	if err := json.Unmarshal(resp.Body(), result); err != nil {
+		return errors.WithMessagef(err, "invalid cryptocompare response %v", resp.String())
+	}
+	return nil
+}
 
-	return resp, nil
+// newAPIError returns the error corresponding to the message of a cryptocompare error payload
+func newAPIError(message string) error {
+	kind := datafeed.ErrorKindAPI
+	if strings.Contains(strings.ToLower(message), "rate limit") {
+		kind = datafeed.ErrorKindRateLimited
+	}
+	return datafeed.NewAPIError(Source, kind, message)
 }
@@ -0,0 +1,102 @@
+package cryptocompare_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"p2pderivatives-oracle/internal/cryptocompare"
+	"p2pderivatives-oracle/internal/datafeed"
+	"p2pderivatives-oracle/test"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRecordedResponseClient returns a client whose requests are answered with a recorded response
+func newRecordedResponseClient(t *testing.T, status int, fixture string) *cryptocompare.Client {
+	body, err := ioutil.ReadFile(filepath.Join(test.VectorsDirectoryPath, "cryptocompare", fixture))
+	require.NoError(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	client := cryptocompare.NewClient(test.NewLogger(), &cryptocompare.Config{
+		APIBaseURL:   server.URL,
+		AssetsConfig: map[string]cryptocompare.CCAssetConfig{"btcusd": {Fsym: "btc", Tsym: "usd"}},
+	})
+	client.Initialize()
+	return client
+}
+
+func findPastPriceError(t *testing.T, status int, fixture string) *datafeed.SourceError {
+	client := newRecordedResponseClient(t, status, fixture)
+	_, err := client.FindPastAssetPriceRecord("btcusd", time.Now().Add(-time.Hour))
+	sourceErr := &datafeed.SourceError{}
+	require.True(t, errors.As(err, &sourceErr), err)
+	return sourceErr
+}
+
+func TestClient_FindPastAssetPriceRecord_ReturnsLastCandle(t *testing.T) {
+	client := newRecordedResponseClient(t, http.StatusOK, "histominute.json")
+
+	record, err := client.FindPastAssetPriceRecord("btcusd", time.Now().Add(-time.Hour))
+
+	require.NoError(t, err)
+	assert.Equal(t, 36521.48, record.Price)
+	assert.Equal(t, time.Unix(1622534400, 0).UTC(), record.Timestamp)
+	assert.Equal(t, time.Minute, record.Candle.Interval)
+	assert.Equal(t, 36508.15, record.Candle.Open)
+	assert.InDelta(t, 360540.11/9.87, record.Candle.VWAP, 1e-6)
+}
+
+func TestClient_FindPastAssetPriceSeries_ReturnsCandlesWithinPeriod(t *testing.T) {
+	client := newRecordedResponseClient(t, http.StatusOK, "histohour.json")
+	from := time.Unix(1622523600, 0).UTC()
+
+	candles, err := client.FindPastAssetPriceSeries("btcusd", from, from.Add(3*time.Hour))
+
+	require.NoError(t, err)
+	// the first candle of the response is before the period
+	require.Len(t, candles, 3)
+	assert.Equal(t, from, candles[0].Time)
+	assert.Equal(t, time.Hour, candles[0].Interval)
+	assert.Equal(t, 36551.34, candles[0].Close)
+	assert.Equal(t, 874.52, candles[0].Volume)
+	assert.Equal(t, 36508.15, candles[2].Close)
+}
+
+func TestClient_RateLimitPayload_ReturnsRateLimitedError(t *testing.T) {
+	sourceErr := findPastPriceError(t, http.StatusOK, "rate_limit_error.json")
+
+	assert.Equal(t, datafeed.ErrorKindRateLimited, sourceErr.Kind)
+	assert.True(t, sourceErr.Temporary())
+}
+
+func TestClient_ErrorPayload_ReturnsAPIError(t *testing.T) {
+	sourceErr := findPastPriceError(t, http.StatusOK, "market_error.json")
+
+	assert.Equal(t, datafeed.ErrorKindAPI, sourceErr.Kind)
+	assert.Equal(t, "fsym param seems to be missing.", sourceErr.Message)
+	assert.False(t, sourceErr.Temporary())
+}
+
+func TestClient_ErrorStatusCode_ReturnsHTTPError(t *testing.T) {
+	sourceErr := findPastPriceError(t, http.StatusServiceUnavailable, "market_error.json")
+
+	assert.Equal(t, datafeed.ErrorKindUnavailable, sourceErr.Kind)
+	assert.Equal(t, http.StatusServiceUnavailable, sourceErr.StatusCode)
+}
+
+func TestClient_FindCurrentAssetPrice_RateLimitPayload_ReturnsRateLimitedError(t *testing.T) {
+	client := newRecordedResponseClient(t, http.StatusOK, "rate_limit_error.json")
+
+	_, err := client.FindCurrentAssetPrice("btcusd")
+
+	assert.True(t, datafeed.IsTemporary(err))
+}
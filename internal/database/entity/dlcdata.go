@@ -2,13 +2,16 @@ package entity
 
 import (
 	"database/sql/driver"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"p2pderivatives-oracle/internal/envelope"
 	"strconv"
 	"strings"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
 )
 
@@ -17,6 +20,7 @@ type EventData struct {
 	Timestamp
 	PublishedDate         time.Time   `gorm:"primary_key"`
 	AssetID               string      `gorm:"primary_key"`
+	EventID               string      `gorm:"uniqueIndex"`
 	Nonces                StringArray `gorm:"not null"`
 	Signatures            StringArray
 	Values                StringArray
@@ -26,18 +30,50 @@ type EventData struct {
 	Unit                  string
 	IsSigned              bool
 	Precision             int
+	Outcomes              StringArray
+	// OraclePublicKey public key of the oracle key which announced the event
+	OraclePublicKey string
 
-	// TODO should be stored somewhere secure
-	Kvalues StringArray `gorm:"not null" json:"-"`
+	// Kvalues are only set for events created before the nonces were derived from the oracle key,
+	// they are removed once the event is signed.
+	// They are stored encrypted in EncryptedKvalues once migrated (see EncryptStoredKvalues).
+	Kvalues StringArray `json:"-"`
+	// EncryptedKvalues the stored kvalues sealed by the kvalue keyring (see OpenKvalues)
+	EncryptedKvalues string `json:"-"`
+
+	// StreamEntryID ID of the stream entry recorded when the event was created or signed
+	// by CreateEventData or UpdateDLCDataSignatureAndValue (not stored)
+	StreamEntryID uint64 `gorm:"-" json:"-"`
 }
 
+// EventIDSeparator separates the asset ID from the publish date in the event IDs
+const EventIDSeparator = "-"
+
 // GetEventID returns the event ID for the given eventData structure
 func (eventData *EventData) GetEventID() string {
-	return ComputeEventEventID(eventData.AssetID, &eventData.PublishedDate)
+	if eventData.EventID == "" {
+		// not migrated yet, such events can only have been created with the legacy format
+		return ComputeLegacyEventID(eventData.AssetID, &eventData.PublishedDate)
+	}
+	return eventData.EventID
+}
+
+// BeforeCreate sets the event ID of the new events
+func (eventData *EventData) BeforeCreate(tx *gorm.DB) error {
+	if eventData.EventID == "" {
+		eventData.EventID = ComputeEventEventID(eventData.AssetID, &eventData.PublishedDate)
+	}
+	return nil
 }
 
-// ComputeEventID computes the event ID from the given parameters
+// ComputeEventEventID computes the event ID from the given parameters
 func ComputeEventEventID(assetID string, publishedDate *time.Time) string {
+	return assetID + EventIDSeparator + strconv.FormatInt(publishedDate.Unix(), 10)
+}
+
+// ComputeLegacyEventID computes the event ID of the events created before the separator was added
+// (the ID is part of the signed announcement so it cannot be changed for existing events)
+func ComputeLegacyEventID(assetID string, publishedDate *time.Time) string {
 	return assetID + strconv.FormatInt(publishedDate.Unix(), 10)
 }
 
@@ -69,6 +105,71 @@ func (StringArray) GormDataType() string {
 	return "text"
 }
 
+// NbDigits returns the number of digits of the event outcome
+// (if the event is signed, the first nonce is used for the sign and is not counted)
+func (eventData *EventData) NbDigits() int {
+	if eventData.IsSigned {
+		return len(eventData.Nonces) - 1
+	}
+	return len(eventData.Nonces)
+}
+
+// IsEnum returns true if the event is an enumerated outcome event
+// (in which case a single nonce is used to sign one of the Outcomes)
+func (eventData *EventData) IsEnum() bool {
+	return len(eventData.Outcomes) > 0
+}
+
+// HasStoredKvalues returns true if the one time signing keys are stored with the event
+// (events created before the nonces were derived from the oracle key)
+func (eventData *EventData) HasStoredKvalues() bool {
+	return len(eventData.Kvalues) > 0 || eventData.EncryptedKvalues != ""
+}
+
+// OpenKvalues returns the one time signing keys stored with the event, decrypting them with
+// the keyring if they are encrypted. The returned keys share the same buffer which should be
+// zeroed (see envelope.Zero) once the keys are used.
+func (eventData *EventData) OpenKvalues(keyring *envelope.Keyring) ([][]byte, error) {
+	var buf []byte
+	var err error
+	if eventData.EncryptedKvalues != "" {
+		buf, err = keyring.Open(eventData.EncryptedKvalues, []byte(eventData.GetEventID()))
+	} else {
+		// not migrated yet
+		buf, err = encodeKvalues(eventData.Kvalues)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) != len(eventData.Nonces)*kvalueSize {
+		envelope.Zero(buf)
+		return nil, errors.New("Stored kvalues do not match the event nonces")
+	}
+	kvalues := make([][]byte, len(eventData.Nonces))
+	for i := range kvalues {
+		kvalues[i] = buf[i*kvalueSize : (i+1)*kvalueSize]
+	}
+	return kvalues, nil
+}
+
+// size of a kvalue in bytes
+const kvalueSize = 32
+
+// encodeKvalues concatenates the bytes of the hex encoded kvalues
+func encodeKvalues(kvalues []string) ([]byte, error) {
+	buf := make([]byte, 0, len(kvalues)*kvalueSize)
+	for _, kvalue := range kvalues {
+		b, err := hex.DecodeString(kvalue)
+		if err != nil || len(b) != kvalueSize {
+			envelope.Zero(buf)
+			return nil, errors.New("Invalid stored kvalue")
+		}
+		buf = append(buf, b...)
+		envelope.Zero(b)
+	}
+	return buf, nil
+}
+
 // HasSignature returns true if the Signature is set
 func (eventData *EventData) HasSignature() bool {
 	return len(eventData.Signatures) > 0
@@ -76,26 +177,39 @@ func (eventData *EventData) HasSignature() bool {
 
 // CreateEventData will try to create a DLCData with a new Rvalue corresponding to an asset and publishDate
 // if already in db, it will return the value found with no error
-func CreateEventData(db *gorm.DB, assetID string, publishDate time.Time, signingks []string, rvalues []string, base int, unit string, announcementSignature string) (*EventData, error) {
+// (the stream entry of the announcement is recorded in the same transaction)
+func CreateEventData(db *gorm.DB, assetID string, publishDate time.Time, rvalues []string, base int, isSigned bool, precision int, unit string, outcomes []string, announcementSignature string, oraclePublicKey string) (*EventData, error) {
 	tx := db.Begin()
 
 	newDLCData := &EventData{
 		PublishedDate:         publishDate,
 		AssetID:               assetID,
-		Kvalues:               signingks,
 		Nonces:                rvalues,
 		Base:                  base,
+		IsSigned:              isSigned,
+		Precision:             precision,
+		Outcomes:              outcomes,
 		AnnouncementSignature: announcementSignature,
 		Unit:                  unit,
+		OraclePublicKey:       oraclePublicKey,
 	}
 
-	tx = tx.Create(newDLCData)
+	if err := tx.Create(newDLCData).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	entry, err := RecordStreamEntry(tx, assetID, newDLCData.GetEventID(), StreamEntryAnnouncement)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
 
-	err := tx.Commit().Error
+	err = tx.Commit().Error
 	if err != nil {
 		return nil, err
 	}
 
+	newDLCData.StreamEntryID = entry.ID
 	return newDLCData, nil
 }
 
@@ -136,6 +250,108 @@ func FindDLCDataPublishedBefore(db *gorm.DB, assetID string, nearTime time.Time)
 	return dlcData, nil
 }
 
+// FindDLCDataPublishedBetween will retrieve all the dlcData of an asset which are published between
+// from and to (both included) ordered by publish date
+func FindDLCDataPublishedBetween(db *gorm.DB, assetID string, from time.Time, to time.Time) ([]EventData, error) {
+	dlcData := []EventData{}
+	filterCondition := &EventData{
+		AssetID: assetID,
+	}
+	req := db.Where(filterCondition)
+	req = req.Where("published_date BETWEEN ? AND ?", from, to)
+	req = req.Order("published_date ASC")
+	err := req.Find(&dlcData).Error
+	if err != nil {
+		return nil, err
+	}
+	return dlcData, nil
+}
+
+// EventDataFilter filters the events listed by FindDLCDataPage (nil fields are ignored)
+type EventDataFilter struct {
+	// From only lists the events published at or after this date
+	From *time.Time
+	// To only lists the events published at or before this date
+	To *time.Time
+	// IsSigned only lists the signed (or unsigned) events
+	IsSigned *bool
+	// After only lists the events published strictly after this date (used as pagination cursor)
+	After *time.Time
+	// Limit maximum number of events to return
+	Limit int
+}
+
+// FindDLCDataPage will retrieve the dlcData of an asset matching the given filter ordered by publish date
+func FindDLCDataPage(db *gorm.DB, assetID string, filter *EventDataFilter) ([]EventData, error) {
+	dlcData := []EventData{}
+	filterCondition := &EventData{
+		AssetID: assetID,
+	}
+	req := db.Where(filterCondition)
+	if filter.From != nil {
+		req = req.Where("published_date >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		req = req.Where("published_date <= ?", *filter.To)
+	}
+	if filter.After != nil {
+		req = req.Where("published_date > ?", *filter.After)
+	}
+	if filter.IsSigned != nil {
+		if *filter.IsSigned {
+			req = req.Where("signatures IS NOT NULL")
+		} else {
+			req = req.Where("signatures IS NULL")
+		}
+	}
+	if filter.Limit > 0 {
+		req = req.Limit(filter.Limit)
+	}
+	req = req.Order("published_date ASC")
+	err := req.Find(&dlcData).Error
+	if err != nil {
+		return nil, err
+	}
+	return dlcData, nil
+}
+
+// FindUnsignedDLCDataPublishedBefore will retrieve all the dlcData of an asset which are not signed
+// yet and have been published before (or at) a specific date ordered by publish date
+func FindUnsignedDLCDataPublishedBefore(db *gorm.DB, assetID string, date time.Time) ([]EventData, error) {
+	dlcData := []EventData{}
+	filterCondition := &EventData{
+		AssetID: assetID,
+	}
+	req := db.Where(filterCondition)
+	req = req.Where("published_date <= ? AND signatures IS NULL", date)
+	req = req.Order("published_date ASC")
+	err := req.Find(&dlcData).Error
+	if err != nil {
+		return nil, err
+	}
+	return dlcData, nil
+}
+
+// FindDLCDataByEventID will try to retrieve the dlcData with the given event ID from database
+func FindDLCDataByEventID(db *gorm.DB, eventID string) (*EventData, error) {
+	dlcData := &EventData{}
+	err := db.Where(&EventData{EventID: eventID}).First(dlcData).Error
+	if err != nil {
+		return nil, err
+	}
+	return dlcData, nil
+}
+
+// FindDLCDataByEventIDs will retrieve the dlcData with the given event IDs from database
+func FindDLCDataByEventIDs(db *gorm.DB, eventIDs []string) ([]EventData, error) {
+	dlcData := []EventData{}
+	err := db.Where("event_id IN ?", eventIDs).Find(&dlcData).Error
+	if err != nil {
+		return nil, err
+	}
+	return dlcData, nil
+}
+
 // FindDLCDataPublishedAt will try to retrieve asset dlcData at specific publish date
 // from database
 func FindDLCDataPublishedAt(db *gorm.DB, assetID string, publishDate time.Time) (*EventData, error) {
@@ -152,34 +368,195 @@ func FindDLCDataPublishedAt(db *gorm.DB, assetID string, publishDate time.Time)
 }
 
 // UpdateDLCDataSignatureAndValue will try to update signature and value of the DLCData if it exists
-// and if the DLCdata is not already signed
-func UpdateDLCDataSignatureAndValue(db *gorm.DB, assetID string, publishDate time.Time, sigs []string, values []string) (*EventData, error) {
+// and if the DLCdata is not already signed, the stored kvalues (if any) are removed as they must not
+// be kept along with the signatures.
+// If provenance is not nil, it is stored in the same transaction, as is the stream entry of the attestation.
+func UpdateDLCDataSignatureAndValue(db *gorm.DB, assetID string, publishDate time.Time, sigs []string, values []string, provenance *PriceProvenance) (*EventData, error) {
 	filterCondition := &EventData{
 		AssetID:       assetID,
 		PublishedDate: publishDate,
 	}
 	db.Logger.LogMode(logger.Info)
-	tx := db.Begin()
-	tx = tx.Where(filterCondition)
+	root := db.Begin()
 
 	var old EventData
-	tx.First(&old)
+	if err := root.Where(filterCondition).First(&old).Error; err != nil {
+		root.Rollback()
+		return nil, err
+	}
 
 	if old.Signatures != nil || old.Values != nil {
-		tx.Rollback()
+		root.Rollback()
 		return nil, errors.New("Already signed or assigned values")
 	}
 
-	tx = tx.Updates(EventData{Signatures: sigs, Values: values})
+	// the nonces are recorded in the same transaction so that no process (e.g. another oracle replica)
+	// can sign other values with them
+	if err := RecordNonceSignatures(root, old.GetEventID(), sigs, values); err != nil {
+		root.Rollback()
+		return nil, err
+	}
+
+	tx := root.Model(&EventData{}).Where(filterCondition).Where("signatures IS NULL").Updates(map[string]interface{}{
+		"signatures":        StringArray(sigs),
+		"values":            StringArray(values),
+		"kvalues":           nil,
+		"encrypted_kvalues": nil,
+	})
+	if tx.Error != nil {
+		root.Rollback()
+		return nil, tx.Error
+	}
 
+	var entry *StreamEntry
 	if tx.RowsAffected == 0 {
-		tx.Rollback()
+		root.Rollback()
 	} else {
-		err := tx.Commit().Error
+		if provenance != nil {
+			provenance.AssetID = assetID
+			provenance.PublishedDate = publishDate
+			if err := root.Create(provenance).Error; err != nil {
+				root.Rollback()
+				return nil, err
+			}
+		}
+		var err error
+		entry, err = RecordStreamEntry(root, assetID, old.GetEventID(), StreamEntryAttestation)
 		if err != nil {
+			root.Rollback()
 			return nil, err
 		}
+		err = root.Commit().Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	updated, err := FindDLCDataPublishedAt(db, assetID, publishDate)
+	if err != nil {
+		return nil, err
+	}
+	if entry != nil {
+		updated.StreamEntryID = entry.ID
+	}
+	return updated, nil
+}
+
+// ClearSignedEventKvalues removes the kvalues still stored for events that are already signed
+// and returns the number of updated events
+func ClearSignedEventKvalues(db *gorm.DB) (int64, error) {
+	req := db.Model(&EventData{}).Where("signatures IS NOT NULL AND (kvalues IS NOT NULL OR encrypted_kvalues <> '')")
+	req = req.Updates(map[string]interface{}{
+		"kvalues":           nil,
+		"encrypted_kvalues": nil,
+	})
+	return req.RowsAffected, req.Error
+}
+
+// EncryptStoredKvalues encrypts the plaintext kvalues still stored with the unsigned events
+// using the keyring and returns the number of updated events
+// (the keyring must have a master key if such events exist)
+func EncryptStoredKvalues(db *gorm.DB, keyring *envelope.Keyring) (int64, error) {
+	events := []EventData{}
+	err := db.Where("kvalues IS NOT NULL").Find(&events).Error
+	if err != nil {
+		return 0, err
+	}
+	if len(events) > 0 && !keyring.IsEnabled() {
+		return 0, errors.New("A kvalue master key is required to encrypt the stored kvalues")
+	}
+	var nb int64
+	for _, event := range events {
+		buf, err := encodeKvalues(event.Kvalues)
+		if err != nil {
+			return nb, err
+		}
+		sealed, err := keyring.Seal(buf, []byte(event.GetEventID()))
+		envelope.Zero(buf)
+		if err != nil {
+			return nb, err
+		}
+		req := db.Model(&EventData{}).Where(&EventData{AssetID: event.AssetID, PublishedDate: event.PublishedDate})
+		req = req.Updates(map[string]interface{}{
+			"kvalues":           nil,
+			"encrypted_kvalues": sealed,
+		})
+		if req.Error != nil {
+			return nb, req.Error
+		}
+		nb += req.RowsAffected
+	}
+	return nb, nil
+}
+
+// RewrapStoredKvalues re-encrypts the data keys of the encrypted kvalues with the active master key
+// of the keyring (after a master key rollover) and returns the number of updated events
+func RewrapStoredKvalues(db *gorm.DB, keyring *envelope.Keyring) (int64, error) {
+	events := []EventData{}
+	err := db.Where("encrypted_kvalues <> ''").Find(&events).Error
+	if err != nil {
+		return 0, err
+	}
+	var nb int64
+	for _, event := range events {
+		id, err := envelope.MasterKeyID(event.EncryptedKvalues)
+		if err != nil {
+			return nb, err
+		}
+		if id == keyring.ActiveMasterKeyID() {
+			continue
+		}
+		sealed, err := keyring.Rewrap(event.EncryptedKvalues)
+		if err != nil {
+			return nb, err
+		}
+		req := db.Model(&EventData{}).Where(&EventData{AssetID: event.AssetID, PublishedDate: event.PublishedDate})
+		req = req.Update("encrypted_kvalues", sealed)
+		if req.Error != nil {
+			return nb, req.Error
+		}
+		nb += req.RowsAffected
+	}
+	return nb, nil
+}
+
+// MigrateEventIDs stores the legacy event ID of the events created before the event ID was stored
+// and returns the number of updated events
+func MigrateEventIDs(db *gorm.DB) (int64, error) {
+	events := []EventData{}
+	err := db.Where("event_id IS NULL OR event_id = ''").Find(&events).Error
+	if err != nil {
+		return 0, err
+	}
+	var nb int64
+	for _, event := range events {
+		req := db.Model(&EventData{}).Where(&EventData{AssetID: event.AssetID, PublishedDate: event.PublishedDate})
+		req = req.Update("event_id", ComputeLegacyEventID(event.AssetID, &event.PublishedDate))
+		if req.Error != nil {
+			return nb, req.Error
+		}
+		nb += req.RowsAffected
 	}
+	return nb, nil
+}
 
-	return FindDLCDataPublishedAt(db, assetID, publishDate)
+// MigrateOraclePublicKeys sets the given oracle public key on the events created before the key
+// which announced them was recorded, returning the number of migrated events
+func MigrateOraclePublicKeys(db *gorm.DB, publicKey string) (int64, error) {
+	req := db.Model(&EventData{}).Where("oracle_public_key IS NULL OR oracle_public_key = ''")
+	req = req.Update("oracle_public_key", publicKey)
+	return req.RowsAffected, req.Error
+}
+
+// MigrateDerivedNonces migrates the event data table created when kvalues were mandatory
+// and removes the kvalues of the events that are already signed
+func MigrateDerivedNonces(db *gorm.DB) (int64, error) {
+	// sqlite is only used in memory so its tables are always created from the current model
+	if db.Dialector.Name() == "postgres" {
+		err := db.Exec("ALTER TABLE ? ALTER COLUMN kvalues DROP NOT NULL", clause.Table{Name: db.NamingStrategy.TableName("EventData")}).Error
+		if err != nil {
+			return 0, err
+		}
+	}
+	return ClearSignedEventKvalues(db)
 }
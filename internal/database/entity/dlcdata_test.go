@@ -1,7 +1,10 @@
 package entity_test
 
 import (
+	"bytes"
+	"encoding/hex"
 	"p2pderivatives-oracle/internal/database/entity"
+	"p2pderivatives-oracle/internal/envelope"
 	"p2pderivatives-oracle/test"
 	"testing"
 	"time"
@@ -11,19 +14,52 @@ import (
 )
 
 func GetInitializedDB() *gorm.DB {
-	db := test.NewOrm(&entity.Asset{}, &entity.EventData{}).GetDB()
+	db := test.NewOrm(&entity.Asset{}, &entity.EventData{}, &entity.PriceProvenance{}, &entity.NonceSignature{}, &entity.StreamEntry{}).GetDB()
 	db.Create(&entity.Asset{AssetID: "test"})
 	return db
 }
 
 func Test_CreateDLCData_NotPresent_ReturnsCorrectValue(t *testing.T) {
+	// arrange
+	db := GetInitializedDB()
+	expected := &entity.EventData{
+		PublishedDate:   time.Now().UTC(),
+		AssetID:         "test",
+		Nonces:          []string{"rvalue", "rvalue"},
+		IsSigned:        true,
+		Precision:       -2,
+		OraclePublicKey: "oraclepubkey",
+	}
+
+	// act
+	actual, err := entity.CreateEventData(
+		db,
+		expected.AssetID,
+		expected.PublishedDate,
+		expected.Nonces,
+		2,
+		expected.IsSigned,
+		expected.Precision,
+		"btc",
+		nil,
+		"e7d5da6e6193a8161437a860d41efe8af7c4c9073a1e75913e663ad59c092b0e0263942a600984f3352de5d089e4769b9448f63f279559408d3e3b089ddbdbc0",
+		"oraclepubkey",
+	)
+
+	// assert
+	assertSub := assert.New(t)
+	assertSub.NoError(err)
+	assertDLCDataEqual(assertSub, expected, actual)
+}
+
+func Test_CreateDLCData_Enum_ReturnsCorrectValue(t *testing.T) {
 	// arrange
 	db := GetInitializedDB()
 	expected := &entity.EventData{
 		PublishedDate: time.Now().UTC(),
 		AssetID:       "test",
 		Nonces:        []string{"rvalue"},
-		Kvalues:       []string{"kvalue"},
+		Outcomes:      []string{"yes", "no"},
 	}
 
 	// act
@@ -31,17 +67,24 @@ func Test_CreateDLCData_NotPresent_ReturnsCorrectValue(t *testing.T) {
 		db,
 		expected.AssetID,
 		expected.PublishedDate,
-		expected.Kvalues,
 		expected.Nonces,
-		2,
-		"btc",
+		0,
+		false,
+		0,
+		"",
+		expected.Outcomes,
 		"e7d5da6e6193a8161437a860d41efe8af7c4c9073a1e75913e663ad59c092b0e0263942a600984f3352de5d089e4769b9448f63f279559408d3e3b089ddbdbc0",
+		"",
 	)
 
 	// assert
 	assertSub := assert.New(t)
 	assertSub.NoError(err)
 	assertDLCDataEqual(assertSub, expected, actual)
+	inDB, err := entity.FindDLCDataPublishedAt(db, expected.AssetID, expected.PublishedDate)
+	assertSub.NoError(err)
+	assertSub.Equal(expected.Outcomes, inDB.Outcomes)
+	assertSub.True(inDB.IsEnum())
 }
 
 func Test_CreateDLCData_Present_ReturnsError(t *testing.T) {
@@ -49,7 +92,7 @@ func Test_CreateDLCData_Present_ReturnsError(t *testing.T) {
 	now := time.Now().UTC()
 	inDB := &entity.EventData{AssetID: "test", PublishedDate: now, Kvalues: []string{"kvalue1"}, Nonces: []string{"rvalue2"}}
 	db.Create(inDB)
-	_, err := entity.CreateEventData(db, inDB.AssetID, inDB.PublishedDate, inDB.Kvalues, inDB.Nonces, 2, "btc", "e7d5da6e6193a8161437a860d41efe8af7c4c9073a1e75913e663ad59c092b0e0263942a600984f3352de5d089e4769b9448f63f279559408d3e3b089ddbdbc0")
+	_, err := entity.CreateEventData(db, inDB.AssetID, inDB.PublishedDate, inDB.Nonces, 2, false, 0, "btc", nil, "e7d5da6e6193a8161437a860d41efe8af7c4c9073a1e75913e663ad59c092b0e0263942a600984f3352de5d089e4769b9448f63f279559408d3e3b089ddbdbc0", "")
 	assert.Error(t, err)
 }
 
@@ -93,6 +136,77 @@ func Test_FindDLCDataPublishedBefore_Present_ReturnsCorrectValue(t *testing.T) {
 	assert.True(t, expected.PublishedDate.Equal(actual.PublishedDate))
 }
 
+func Test_FindDLCDataPublishedBetween_ReturnsValuesInRange(t *testing.T) {
+	db := GetInitializedDB()
+	now := time.Now().UTC()
+	for i := -1; i < 4; i++ {
+		db.Create(&entity.EventData{AssetID: "test", PublishedDate: now.Add(time.Duration(i) * time.Hour), Kvalues: []string{""}, Nonces: []string{""}})
+	}
+	actual, err := entity.FindDLCDataPublishedBetween(db, "test", now, now.Add(2*time.Hour))
+	assert.NoError(t, err)
+	if assert.Len(t, actual, 3) {
+		assert.True(t, now.Equal(actual[0].PublishedDate))
+		assert.True(t, now.Add(2*time.Hour).Equal(actual[2].PublishedDate))
+	}
+}
+
+func Test_FindUnsignedDLCDataPublishedBefore_ReturnsOnlyUnsignedPastValues(t *testing.T) {
+	db := GetInitializedDB()
+	now := time.Now().UTC()
+	unsigned := &entity.EventData{AssetID: "test", PublishedDate: now.Add(-2 * time.Hour), Kvalues: []string{""}, Nonces: []string{""}}
+	signed := &entity.EventData{AssetID: "test", PublishedDate: now.Add(-1 * time.Hour), Kvalues: []string{""}, Nonces: []string{""}, Signatures: []string{"sig"}, Values: []string{"1"}}
+	future := &entity.EventData{AssetID: "test", PublishedDate: now.Add(time.Hour), Kvalues: []string{""}, Nonces: []string{""}}
+	db.Create(unsigned)
+	db.Create(signed)
+	db.Create(future)
+	actual, err := entity.FindUnsignedDLCDataPublishedBefore(db, "test", now)
+	assert.NoError(t, err)
+	if assert.Len(t, actual, 1) {
+		assert.True(t, unsigned.PublishedDate.Equal(actual[0].PublishedDate))
+	}
+}
+
+func Test_FindDLCDataPage_ReturnsFilteredValues(t *testing.T) {
+	db := GetInitializedDB()
+	now := time.Now().UTC()
+	for i := 0; i < 5; i++ {
+		data := &entity.EventData{AssetID: "test", PublishedDate: now.Add(time.Duration(i) * time.Hour), Nonces: []string{""}}
+		if i%2 == 0 {
+			data.Signatures = []string{"sig"}
+			data.Values = []string{"1"}
+		}
+		db.Create(data)
+	}
+	signed := true
+	unsigned := false
+	from := now.Add(time.Hour)
+	to := now.Add(3 * time.Hour)
+
+	tests := []struct {
+		name     string
+		filter   *entity.EventDataFilter
+		expected []int
+	}{
+		{name: "no filter", filter: &entity.EventDataFilter{}, expected: []int{0, 1, 2, 3, 4}},
+		{name: "range", filter: &entity.EventDataFilter{From: &from, To: &to}, expected: []int{1, 2, 3}},
+		{name: "signed", filter: &entity.EventDataFilter{IsSigned: &signed}, expected: []int{0, 2, 4}},
+		{name: "unsigned", filter: &entity.EventDataFilter{IsSigned: &unsigned}, expected: []int{1, 3}},
+		{name: "after with limit", filter: &entity.EventDataFilter{After: &from, Limit: 2}, expected: []int{2, 3}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := entity.FindDLCDataPage(db, "test", test.filter)
+			assert.NoError(t, err)
+			if assert.Len(t, actual, len(test.expected)) {
+				for i, hour := range test.expected {
+					assert.True(t, now.Add(time.Duration(hour)*time.Hour).Equal(actual[i].PublishedDate))
+				}
+			}
+		})
+	}
+}
+
 func Test_FindDLCDataPublishedAt_NotPresent_ReturnsRecordNotFoundError(t *testing.T) {
 	db := GetInitializedDB()
 	now := time.Now()
@@ -124,13 +238,15 @@ func Test_UpdateDLCDataSignatureAndValue_Present_ReturnsUpdated(t *testing.T) {
 		Values:        nil,
 	}
 	db.Create(expected)
+	// stored kvalues are removed on update
+	expected.Kvalues = nil
 
 	// act
 	actual, err := entity.UpdateDLCDataSignatureAndValue(
 		db,
 		expected.AssetID,
 		expected.PublishedDate,
-		expected.Signatures, expected.Values)
+		expected.Signatures, expected.Values, nil)
 
 	// assert
 	assertSub := assert.New(t)
@@ -145,4 +261,195 @@ func assertDLCDataEqual(assertSub *assert.Assertions, expected *entity.EventData
 	assertSub.Equal(expected.Nonces, actual.Nonces)
 	assertSub.Equal(expected.Signatures, actual.Signatures)
 	assertSub.Equal(expected.Values, actual.Values)
+	assertSub.Equal(expected.IsSigned, actual.IsSigned)
+	assertSub.Equal(expected.Precision, actual.Precision)
+	assertSub.Equal(expected.Outcomes, actual.Outcomes)
+	assertSub.Equal(expected.OraclePublicKey, actual.OraclePublicKey)
+}
+
+func Test_EventData_NbDigits_ReturnsCorrectValue(t *testing.T) {
+	unsigned := &entity.EventData{Nonces: []string{"r1", "r2", "r3"}}
+	signed := &entity.EventData{Nonces: []string{"r1", "r2", "r3"}, IsSigned: true}
+	assert.Equal(t, 3, unsigned.NbDigits())
+	assert.Equal(t, 2, signed.NbDigits())
+}
+
+func Test_UpdateDLCDataSignatureAndValue_WithStoredKvalues_RemovesKvalues(t *testing.T) {
+	db := GetInitializedDB()
+	now := time.Now().UTC()
+	legacy := &entity.EventData{AssetID: "test", PublishedDate: now, Kvalues: []string{"kvalue"}, Nonces: []string{"rvalue"}}
+	other := &entity.EventData{AssetID: "test", PublishedDate: now.Add(time.Hour), Kvalues: []string{"kvalue"}, Nonces: []string{"rvalue"}}
+	db.Create(legacy)
+	db.Create(other)
+
+	actual, err := entity.UpdateDLCDataSignatureAndValue(db, legacy.AssetID, legacy.PublishedDate, []string{fakeSignature(1)}, []string{"1"}, nil)
+
+	assert.NoError(t, err)
+	assert.False(t, actual.HasStoredKvalues())
+	assert.Equal(t, entity.StringArray{fakeSignature(1)}, actual.Signatures)
+	assert.Equal(t, entity.StringArray{"1"}, actual.Values)
+	otherInDB, _ := entity.FindDLCDataPublishedAt(db, other.AssetID, other.PublishedDate)
+	assert.True(t, otherInDB.HasStoredKvalues())
+	assert.False(t, otherInDB.HasSignature())
+}
+
+func Test_ClearSignedEventKvalues_OnlyClearsSignedEvents(t *testing.T) {
+	db := GetInitializedDB()
+	now := time.Now().UTC()
+	signed := &entity.EventData{AssetID: "test", PublishedDate: now, Kvalues: []string{"kvalue"}, Nonces: []string{"rvalue"}, Signatures: []string{"sig"}, Values: []string{"1"}}
+	unsigned := &entity.EventData{AssetID: "test", PublishedDate: now.Add(time.Hour), Kvalues: []string{"kvalue"}, Nonces: []string{"rvalue"}}
+	db.Create(signed)
+	db.Create(unsigned)
+
+	nb, err := entity.MigrateDerivedNonces(db)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), nb)
+	signedInDB, _ := entity.FindDLCDataPublishedAt(db, signed.AssetID, signed.PublishedDate)
+	assert.False(t, signedInDB.HasStoredKvalues())
+	unsignedInDB, _ := entity.FindDLCDataPublishedAt(db, unsigned.AssetID, unsigned.PublishedDate)
+	assert.True(t, unsignedInDB.HasStoredKvalues())
+}
+
+func Test_CreateEventData_SetsEventIDWithSeparator(t *testing.T) {
+	db := GetInitializedDB()
+	publishDate := time.Unix(1610608860, 0).UTC()
+
+	actual, err := entity.CreateEventData(db, "test1", publishDate, []string{"rvalue"}, 2, false, 0, "", nil, "sig", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "test1-1610608860", actual.GetEventID())
+	found, err := entity.FindDLCDataByEventID(db, "test1-1610608860")
+	if assert.NoError(t, err) {
+		assert.True(t, publishDate.Equal(found.PublishedDate))
+	}
+}
+
+func Test_MigrateEventIDs_SetsLegacyEventIDs(t *testing.T) {
+	db := GetInitializedDB()
+	publishDate := time.Unix(1610608860, 0).UTC()
+	db.Create(&entity.EventData{AssetID: "test", PublishedDate: publishDate, Nonces: []string{"rvalue"}})
+	db.Create(&entity.EventData{AssetID: "test", PublishedDate: publishDate.Add(time.Hour), Nonces: []string{"rvalue"}})
+	// simulate an event created before the event ID was stored
+	db.Model(&entity.EventData{}).Where("published_date = ?", publishDate).Update("event_id", nil)
+	legacy, _ := entity.FindDLCDataPublishedAt(db, "test", publishDate)
+	assert.Equal(t, "test1610608860", legacy.GetEventID())
+
+	nb, err := entity.MigrateEventIDs(db)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), nb)
+	migrated, err := entity.FindDLCDataByEventID(db, "test1610608860")
+	if assert.NoError(t, err) {
+		assert.True(t, publishDate.Equal(migrated.PublishedDate))
+	}
+	other, _ := entity.FindDLCDataPublishedAt(db, "test", publishDate.Add(time.Hour))
+	assert.Equal(t, "test-1610612460", other.GetEventID())
+}
+
+func Test_MigrateOraclePublicKeys_SetsKeyOfLegacyEvents(t *testing.T) {
+	db := GetInitializedDB()
+	publishDate := time.Unix(1610608860, 0).UTC()
+	db.Create(&entity.EventData{AssetID: "test", PublishedDate: publishDate, Nonces: []string{"rvalue"}})
+	db.Create(&entity.EventData{AssetID: "test", PublishedDate: publishDate.Add(time.Hour), Nonces: []string{"rvalue"}, OraclePublicKey: "newkey"})
+
+	nb, err := entity.MigrateOraclePublicKeys(db, "firstkey")
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), nb)
+	legacy, _ := entity.FindDLCDataPublishedAt(db, "test", publishDate)
+	assert.Equal(t, "firstkey", legacy.OraclePublicKey)
+	other, _ := entity.FindDLCDataPublishedAt(db, "test", publishDate.Add(time.Hour))
+	assert.Equal(t, "newkey", other.OraclePublicKey)
+}
+
+func newTestKeyring(t *testing.T, activeID string) *envelope.Keyring {
+	keyring, err := envelope.NewKeyring(map[string][]byte{
+		"key1": bytes.Repeat([]byte{1}, 32),
+		"key2": bytes.Repeat([]byte{2}, 32),
+	}, activeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyring
+}
+
+var testKvalues = []string{
+	"af1e8c793ee16165ff653310a83964f2dac9bc2f831b2c687f0463b6c6f6ae38",
+	"cf9c8213904b85f3002b1c4f34a788e707f08e6e387c29510b63ca15dbb7955f",
+}
+
+func Test_EncryptStoredKvalues_EncryptsPlaintextKvalues(t *testing.T) {
+	db := GetInitializedDB()
+	now := time.Now().UTC()
+	legacy := &entity.EventData{AssetID: "test", PublishedDate: now, Kvalues: testKvalues, Nonces: []string{"rvalue1", "rvalue2"}}
+	derived := &entity.EventData{AssetID: "test", PublishedDate: now.Add(time.Hour), Nonces: []string{"rvalue1", "rvalue2"}}
+	db.Create(legacy)
+	db.Create(derived)
+	keyring := newTestKeyring(t, "key1")
+
+	nb, err := entity.EncryptStoredKvalues(db, keyring)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), nb)
+	legacyInDB, _ := entity.FindDLCDataPublishedAt(db, legacy.AssetID, legacy.PublishedDate)
+	assert.Nil(t, legacyInDB.Kvalues)
+	assert.NotContains(t, legacyInDB.EncryptedKvalues, testKvalues[0])
+	assert.True(t, legacyInDB.HasStoredKvalues())
+	kvalues, err := legacyInDB.OpenKvalues(keyring)
+	if assert.NoError(t, err) && assert.Len(t, kvalues, 2) {
+		assert.Equal(t, testKvalues[0], hex.EncodeToString(kvalues[0]))
+		assert.Equal(t, testKvalues[1], hex.EncodeToString(kvalues[1]))
+	}
+	derivedInDB, _ := entity.FindDLCDataPublishedAt(db, derived.AssetID, derived.PublishedDate)
+	assert.False(t, derivedInDB.HasStoredKvalues())
+}
+
+func Test_EncryptStoredKvalues_WithoutMasterKey_ReturnsError(t *testing.T) {
+	db := GetInitializedDB()
+	db.Create(&entity.EventData{AssetID: "test", PublishedDate: time.Now().UTC(), Kvalues: testKvalues, Nonces: []string{"rvalue1", "rvalue2"}})
+
+	_, err := entity.EncryptStoredKvalues(db, nil)
+
+	assert.Error(t, err)
+}
+
+func Test_RewrapStoredKvalues_UsesActiveMasterKey(t *testing.T) {
+	db := GetInitializedDB()
+	legacy := &entity.EventData{AssetID: "test", PublishedDate: time.Now().UTC(), Kvalues: testKvalues, Nonces: []string{"rvalue1", "rvalue2"}}
+	db.Create(legacy)
+	_, err := entity.EncryptStoredKvalues(db, newTestKeyring(t, "key1"))
+	assert.NoError(t, err)
+	keyring := newTestKeyring(t, "key2")
+
+	nb, err := entity.RewrapStoredKvalues(db, keyring)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), nb)
+	legacyInDB, _ := entity.FindDLCDataPublishedAt(db, legacy.AssetID, legacy.PublishedDate)
+	id, _ := envelope.MasterKeyID(legacyInDB.EncryptedKvalues)
+	assert.Equal(t, "key2", id)
+	// the previous master key is not needed anymore
+	key2, _ := envelope.NewKeyring(map[string][]byte{"key2": bytes.Repeat([]byte{2}, 32)}, "key2")
+	kvalues, err := legacyInDB.OpenKvalues(key2)
+	if assert.NoError(t, err) {
+		assert.Equal(t, testKvalues[1], hex.EncodeToString(kvalues[1]))
+	}
+	nb, err = entity.RewrapStoredKvalues(db, keyring)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), nb)
+}
+
+func Test_UpdateDLCDataSignatureAndValue_WithEncryptedKvalues_RemovesKvalues(t *testing.T) {
+	db := GetInitializedDB()
+	legacy := &entity.EventData{AssetID: "test", PublishedDate: time.Now().UTC(), Kvalues: testKvalues, Nonces: []string{"rvalue1", "rvalue2"}}
+	db.Create(legacy)
+	_, err := entity.EncryptStoredKvalues(db, newTestKeyring(t, "key1"))
+	assert.NoError(t, err)
+
+	actual, err := entity.UpdateDLCDataSignatureAndValue(db, legacy.AssetID, legacy.PublishedDate, []string{fakeSignature(1), fakeSignature(2)}, []string{"1", "2"}, nil)
+
+	assert.NoError(t, err)
+	assert.False(t, actual.HasStoredKvalues())
+	assert.Empty(t, actual.EncryptedKvalues)
 }
@@ -0,0 +1,91 @@
+package entity
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrNonceReused is returned when a nonce was already used to sign another message
+var ErrNonceReused = errors.New("Nonce already used to sign another message")
+
+// NonceSignature represents the db model of a nonce used by the oracle to sign an event outcome,
+// recording the message signed with it so that no other message is ever signed with the same nonce
+// (which would reveal the oracle private key), whichever oracle process computes the signature
+type NonceSignature struct {
+	Timestamp
+	// Nonce x-only public nonce of the signature (hex), which is the first half of the signature
+	Nonce string `gorm:"primary_key"`
+	// MessageHash sha256 of the message signed with the nonce (hex)
+	MessageHash string `gorm:"not null"`
+	EventID     string
+}
+
+// RecordNonceSignatures records the nonce of each signature with the message it signs, returning ErrNonceReused
+// if one of the nonces was already recorded with another message (recording the same message again is allowed).
+// The signatures must not be released if an error is returned.
+func RecordNonceSignatures(db *gorm.DB, eventID string, sigs []string, messages []string) error {
+	if len(sigs) != len(messages) {
+		return errors.New("The number of signatures and messages do not match")
+	}
+	for i, sig := range sigs {
+		if len(sig) < 64 {
+			return fmt.Errorf("Invalid signature %s", sig)
+		}
+		hash := sha256.Sum256([]byte(messages[i]))
+		record := &NonceSignature{
+			Nonce:       sig[:64],
+			MessageHash: hex.EncodeToString(hash[:]),
+			EventID:     eventID,
+		}
+		// the primary key makes the check and the insertion atomic, concurrent insertions of the same nonce
+		// waiting for each other
+		res := db.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected > 0 {
+			continue
+		}
+		recorded, err := FindNonceSignature(db, record.Nonce)
+		if err != nil {
+			return err
+		}
+		if recorded.MessageHash != record.MessageHash {
+			return fmt.Errorf("%w: nonce %s of event %s was used for event %s", ErrNonceReused, record.Nonce, eventID, recorded.EventID)
+		}
+	}
+	return nil
+}
+
+// FindNonceSignature returns the recorded signature of the given nonce
+func FindNonceSignature(db *gorm.DB, nonce string) (*NonceSignature, error) {
+	record := &NonceSignature{}
+	err := db.Where(&NonceSignature{Nonce: nonce}).First(record).Error
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// MigrateNonceSignatures records the nonces of the events signed before the nonces were recorded,
+// returning the number of recorded events (ErrNonceReused is returned if a nonce was already used twice)
+func MigrateNonceSignatures(db *gorm.DB) (int64, error) {
+	events := []EventData{}
+	err := db.Where("signatures IS NOT NULL").Find(&events).Error
+	if err != nil {
+		return 0, err
+	}
+	var nb int64
+	for _, event := range events {
+		if err := RecordNonceSignatures(db, event.GetEventID(), event.Signatures, event.Values); err != nil {
+			return nb, err
+		}
+		nb++
+	}
+	return nb, nil
+}
@@ -0,0 +1,71 @@
+package entity_test
+
+import (
+	"errors"
+	"fmt"
+	"p2pderivatives-oracle/internal/database/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSignature returns a signature whose nonce is the given number
+func fakeSignature(nonce int) string {
+	return fmt.Sprintf("%064x%064x", nonce, 0)
+}
+
+func Test_RecordNonceSignatures_SameMessage_ReturnsNoError(t *testing.T) {
+	db := GetInitializedDB()
+
+	err := entity.RecordNonceSignatures(db, "test-1", []string{fakeSignature(1), fakeSignature(2)}, []string{"1", "0"})
+	assert.NoError(t, err)
+	err = entity.RecordNonceSignatures(db, "test-1", []string{fakeSignature(1), fakeSignature(2)}, []string{"1", "0"})
+	assert.NoError(t, err)
+
+	record, err := entity.FindNonceSignature(db, fakeSignature(2)[:64])
+	if assert.NoError(t, err) {
+		assert.Equal(t, "test-1", record.EventID)
+	}
+}
+
+func Test_RecordNonceSignatures_OtherMessage_ReturnsNonceReused(t *testing.T) {
+	db := GetInitializedDB()
+	err := entity.RecordNonceSignatures(db, "test-1", []string{fakeSignature(1)}, []string{"1"})
+	assert.NoError(t, err)
+
+	err = entity.RecordNonceSignatures(db, "test-2", []string{fakeSignature(2), fakeSignature(1)}, []string{"0", "0"})
+
+	assert.True(t, errors.Is(err, entity.ErrNonceReused))
+}
+
+func Test_UpdateDLCDataSignatureAndValue_NonceUsedByOtherEvent_ReturnsErrorAndDoesNotSign(t *testing.T) {
+	db := GetInitializedDB()
+	now := time.Now().UTC()
+	first := &entity.EventData{AssetID: "test", PublishedDate: now, Nonces: []string{fakeSignature(1)[:64]}}
+	second := &entity.EventData{AssetID: "test", PublishedDate: now.Add(time.Hour), Nonces: []string{fakeSignature(1)[:64]}}
+	db.Create(first)
+	db.Create(second)
+	_, err := entity.UpdateDLCDataSignatureAndValue(db, first.AssetID, first.PublishedDate, []string{fakeSignature(1)}, []string{"1"}, nil)
+	assert.NoError(t, err)
+
+	_, err = entity.UpdateDLCDataSignatureAndValue(db, second.AssetID, second.PublishedDate, []string{fakeSignature(1)}, []string{"0"}, nil)
+
+	assert.True(t, errors.Is(err, entity.ErrNonceReused))
+	actual, _ := entity.FindDLCDataPublishedAt(db, second.AssetID, second.PublishedDate)
+	assert.False(t, actual.HasSignature())
+}
+
+func Test_MigrateNonceSignatures_RecordsSignedEvents(t *testing.T) {
+	db := GetInitializedDB()
+	now := time.Now().UTC()
+	db.Create(&entity.EventData{AssetID: "test", PublishedDate: now, Nonces: []string{"r"}, Signatures: []string{fakeSignature(1)}, Values: []string{"1"}})
+	db.Create(&entity.EventData{AssetID: "test", PublishedDate: now.Add(time.Hour), Nonces: []string{"r"}})
+
+	nb, err := entity.MigrateNonceSignatures(db)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), nb)
+	err = entity.RecordNonceSignatures(db, "test-other", []string{fakeSignature(1)}, []string{"0"})
+	assert.True(t, errors.Is(err, entity.ErrNonceReused))
+}
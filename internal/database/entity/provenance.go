@@ -0,0 +1,88 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PriceProvenance represents the db model of the data used to compute the value attested for an event
+type PriceProvenance struct {
+	Timestamp
+	PublishedDate   time.Time `gorm:"primary_key"`
+	AssetID         string    `gorm:"primary_key"`
+	RawValue        float64
+	Source          string
+	SourceTimestamp time.Time
+	// Reference settlement reference of the price in the source candle (close, open or vwap)
+	Reference string
+	// Stale true if the source timestamp is outside of the settlement tolerance
+	Stale bool
+	// Method settlement method of the value (spot, twap or vwap) and Window the period averaged before the publish date
+	Method       string
+	Window       time.Duration
+	RoundedValue int
+	Precision    int
+	SourcePrices SourcePriceArray
+}
+
+// SourcePrice represents the price returned by one of the sources used to compute an attested value
+type SourcePrice struct {
+	Source    string    `json:"source"`
+	Price     float64   `json:"price"`
+	Timestamp time.Time `json:"timestamp"`
+	Rejected  bool      `json:"rejected,omitempty"`
+	Stale     bool      `json:"stale,omitempty"`
+}
+
+// SourcePriceArray is an alias type for an array of source prices stored as json
+type SourcePriceArray []SourcePrice
+
+// Scan implements the Scanner interface for gorm custom types
+func (s *SourcePriceArray) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("Failed to unmarshal source prices value: %v", value)
+	}
+	return json.Unmarshal(data, s)
+}
+
+// Value implements the Valuer interface for gorm custom types
+func (s SourcePriceArray) Value() (driver.Value, error) {
+	if len(s) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// GormDataType implements the GormDataTypeInterface for gorm custom types
+func (SourcePriceArray) GormDataType() string {
+	return "text"
+}
+
+// FindPriceProvenance will try to retrieve the provenance of the value attested for the event
+// of an asset at specific publish date
+func FindPriceProvenance(db *gorm.DB, assetID string, publishDate time.Time) (*PriceProvenance, error) {
+	provenance := &PriceProvenance{}
+	filterCondition := &PriceProvenance{
+		AssetID:       assetID,
+		PublishedDate: publishDate,
+	}
+	err := db.Where(filterCondition).First(provenance).Error
+	if err != nil {
+		return nil, err
+	}
+	return provenance, nil
+}
@@ -0,0 +1,60 @@
+package entity_test
+
+import (
+	"p2pderivatives-oracle/internal/database/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func Test_UpdateDLCDataSignatureAndValue_WithProvenance_StoresProvenance(t *testing.T) {
+	// arrange
+	db := GetInitializedDB()
+	now := time.Now().UTC()
+	db.Create(&entity.EventData{AssetID: "test", PublishedDate: now, Nonces: []string{"rvalue"}})
+	sourceTimestamp := now.Add(-time.Minute)
+	provenance := &entity.PriceProvenance{
+		RawValue:        100.4,
+		Source:          "aggregated",
+		SourceTimestamp: sourceTimestamp,
+		RoundedValue:    100,
+		SourcePrices: []entity.SourcePrice{
+			{Source: "a", Price: 100.4, Timestamp: sourceTimestamp},
+			{Source: "b", Price: 150, Timestamp: sourceTimestamp, Rejected: true},
+		},
+	}
+
+	// act
+	_, err := entity.UpdateDLCDataSignatureAndValue(db, "test", now, []string{fakeSignature(1)}, []string{"1"}, provenance)
+
+	// assert
+	assertSub := assert.New(t)
+	assertSub.NoError(err)
+	actual, err := entity.FindPriceProvenance(db, "test", now)
+	if assertSub.NoError(err) {
+		assertSub.Equal(provenance.RawValue, actual.RawValue)
+		assertSub.Equal(provenance.Source, actual.Source)
+		assertSub.True(sourceTimestamp.Equal(actual.SourceTimestamp))
+		assertSub.Equal(provenance.RoundedValue, actual.RoundedValue)
+		assertSub.Len(actual.SourcePrices, 2)
+		assertSub.Equal("b", actual.SourcePrices[1].Source)
+		assertSub.True(actual.SourcePrices[1].Rejected)
+	}
+}
+
+func Test_UpdateDLCDataSignatureAndValue_AlreadySigned_DoesNotStoreProvenance(t *testing.T) {
+	// arrange
+	db := GetInitializedDB()
+	now := time.Now().UTC()
+	db.Create(&entity.EventData{AssetID: "test", PublishedDate: now, Nonces: []string{"rvalue"}, Signatures: []string{"sig"}, Values: []string{"1"}})
+
+	// act
+	_, err := entity.UpdateDLCDataSignatureAndValue(db, "test", now, []string{"sig"}, []string{"1"}, &entity.PriceProvenance{RawValue: 1})
+
+	// assert
+	assert.Error(t, err)
+	_, err = entity.FindPriceProvenance(db, "test", now)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
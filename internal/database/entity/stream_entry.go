@@ -0,0 +1,76 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// StreamEntryAnnouncement type of the entries recorded when an event is announced
+	StreamEntryAnnouncement = "announcement"
+	// StreamEntryAttestation type of the entries recorded when an event is attested
+	StreamEntryAttestation = "attestation"
+)
+
+// StreamEntry represents the db model of an announcement or attestation in the order it was recorded,
+// its auto incremented ID being the cursor from which the stream of the asset events is resumed
+// (the publish dates cannot be used as an event can be attested long after the later events are announced).
+// As the IDs are assigned in concurrent transactions, an entry can be committed after an entry with a greater ID,
+// so the entries are also read by creation date to find the ones committed late.
+type StreamEntry struct {
+	ID        uint64    `gorm:"primary_key;autoIncrement"`
+	CreatedAt time.Time `gorm:"index"`
+	AssetID   string    `gorm:"index;not null"`
+	EventID   string    `gorm:"not null"`
+	// Type either StreamEntryAnnouncement or StreamEntryAttestation
+	Type string `gorm:"not null"`
+}
+
+// RecordStreamEntry records an entry of the given type for the event
+// (it should be called in the transaction creating or signing the event)
+func RecordStreamEntry(db *gorm.DB, assetID string, eventID string, entryType string) (*StreamEntry, error) {
+	entry := &StreamEntry{
+		AssetID: assetID,
+		EventID: eventID,
+		Type:    entryType,
+	}
+	if err := db.Create(entry).Error; err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// FindStreamEntry will try to retrieve the stream entry with the given ID from database
+func FindStreamEntry(db *gorm.DB, id uint64) (*StreamEntry, error) {
+	entry := &StreamEntry{}
+	err := db.Where(&StreamEntry{ID: id}).First(entry).Error
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// FindLastStreamEntryID returns the ID of the last stream entry of an asset, or 0 if it has none
+func FindLastStreamEntryID(db *gorm.DB, assetID string) (uint64, error) {
+	var lastID uint64
+	err := db.Model(&StreamEntry{}).Where(&StreamEntry{AssetID: assetID}).Select("COALESCE(MAX(id), 0)").Scan(&lastID).Error
+	if err != nil {
+		return 0, err
+	}
+	return lastID, nil
+}
+
+// FindStreamEntriesAfterOrSince will retrieve at most limit stream entries of an asset with an ID greater than pageAfterID
+// which either have an ID greater than afterID or were created since the given date, ordered by ID
+func FindStreamEntriesAfterOrSince(db *gorm.DB, assetID string, pageAfterID uint64, afterID uint64, since time.Time, limit int) ([]StreamEntry, error) {
+	entries := []StreamEntry{}
+	req := db.Where(&StreamEntry{AssetID: assetID}).Where("id > ?", pageAfterID)
+	req = req.Where("id > ? OR created_at >= ?", afterID, since)
+	req = req.Order("id ASC").Limit(limit)
+	err := req.Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,79 @@
+package entity_test
+
+import (
+	"p2pderivatives-oracle/internal/database/entity"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func Test_CreateAndUpdateEventData_RecordStreamEntries(t *testing.T) {
+	db := GetInitializedDB()
+	date := time.Now().UTC()
+	created, err := entity.CreateEventData(db, "test", date, []string{"rvalue"}, 2, false, 0, "btc", nil, "sig", "oraclepubkey")
+	if !assert.NoError(t, err) {
+		return
+	}
+	updated, err := entity.UpdateDLCDataSignatureAndValue(db, "test", date, []string{strings.Repeat("ab", 64)}, []string{"1"}, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	entries, err := entity.FindStreamEntriesAfterOrSince(db, "test", 0, 0, time.Time{}, 10)
+
+	if assert.NoError(t, err) && assert.Len(t, entries, 2) {
+		assert.Equal(t, created.StreamEntryID, entries[0].ID)
+		assert.Equal(t, entity.StreamEntryAnnouncement, entries[0].Type)
+		assert.Equal(t, updated.StreamEntryID, entries[1].ID)
+		assert.Equal(t, entity.StreamEntryAttestation, entries[1].Type)
+		assert.Equal(t, created.GetEventID(), entries[1].EventID)
+	}
+}
+
+func RecordTestStreamEntries(t *testing.T) *gorm.DB {
+	db := GetInitializedDB()
+	for i := 0; i < 4; i++ {
+		_, err := entity.RecordStreamEntry(db, "test", "event", entity.StreamEntryAnnouncement)
+		assert.NoError(t, err)
+		_, err = entity.RecordStreamEntry(db, "other", "event", entity.StreamEntryAnnouncement)
+		assert.NoError(t, err)
+	}
+	return db
+}
+
+func Test_FindStreamEntriesAfterOrSince_CreatedSince_ReturnsPageOfAssetEntries(t *testing.T) {
+	db := RecordTestStreamEntries(t)
+
+	entries, err := entity.FindStreamEntriesAfterOrSince(db, "test", 1, 7, time.Now().Add(-time.Hour), 2)
+
+	if assert.NoError(t, err) && assert.Len(t, entries, 2) {
+		assert.Equal(t, uint64(3), entries[0].ID)
+		assert.Equal(t, uint64(5), entries[1].ID)
+	}
+}
+
+func Test_FindStreamEntriesAfterOrSince_CreatedBefore_ReturnsEntriesAfterID(t *testing.T) {
+	db := RecordTestStreamEntries(t)
+
+	entries, err := entity.FindStreamEntriesAfterOrSince(db, "test", 0, 3, time.Now().Add(time.Hour), 10)
+
+	if assert.NoError(t, err) && assert.Len(t, entries, 2) {
+		assert.Equal(t, uint64(5), entries[0].ID)
+		assert.Equal(t, uint64(7), entries[1].ID)
+	}
+}
+
+func Test_FindLastStreamEntryID(t *testing.T) {
+	db := RecordTestStreamEntries(t)
+
+	actual, err := entity.FindLastStreamEntryID(db, "test")
+	none, noneErr := entity.FindLastStreamEntryID(db, "none")
+
+	if assert.NoError(t, err) && assert.NoError(t, noneErr) {
+		assert.Equal(t, uint64(7), actual)
+		assert.Equal(t, uint64(0), none)
+	}
+}
@@ -0,0 +1,82 @@
+package entity
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrThresholdNonceUsed is returned when a threshold nonce was already used to sign another message
+var ErrThresholdNonceUsed = errors.New("Threshold nonce already used to sign another message")
+
+// ThresholdNonce represents the db model of a nonce shared by the participants of a threshold oracle,
+// recording the group nonce agreed on and the message signed with it so that a participant never
+// computes two partial signatures with the same nonce share (which would reveal its key share)
+type ThresholdNonce struct {
+	Timestamp
+	NonceID string `gorm:"primary_key"`
+	// AssetID, EventMaturity and NonceIndex are only set for the nonces of events
+	AssetID       string `gorm:"index:idx_threshold_nonces_event"`
+	EventMaturity uint32 `gorm:"index:idx_threshold_nonces_event"`
+	NonceIndex    int
+	// GroupNonce compressed group nonce point (hex)
+	GroupNonce string `gorm:"not null"`
+	// MessageHash sha256 of the message signed with the nonce (hex), empty until the nonce is used
+	MessageHash string
+}
+
+// CommitThresholdNonce records the nonce if it does not exist yet and returns the recorded one,
+// whose group nonce might differ from the given one
+func CommitThresholdNonce(db *gorm.DB, nonce *ThresholdNonce) (*ThresholdNonce, error) {
+	err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(nonce).Error
+	if err != nil {
+		return nil, err
+	}
+	return FindThresholdNonce(db, nonce.NonceID)
+}
+
+// FindThresholdNonce returns the recorded nonce with the given ID
+func FindThresholdNonce(db *gorm.DB, nonceID string) (*ThresholdNonce, error) {
+	nonce := &ThresholdNonce{}
+	err := db.Where(&ThresholdNonce{NonceID: nonceID}).First(nonce).Error
+	if err != nil {
+		return nil, err
+	}
+	return nonce, nil
+}
+
+// FindEventThresholdNonces returns the recorded nonces of an event ordered by index
+func FindEventThresholdNonces(db *gorm.DB, assetID string, eventMaturity uint32) ([]ThresholdNonce, error) {
+	var nonces []ThresholdNonce
+	err := db.Where(&ThresholdNonce{AssetID: assetID, EventMaturity: eventMaturity}).
+		Order("nonce_index").
+		Find(&nonces).Error
+	if err != nil {
+		return nil, err
+	}
+	return nonces, nil
+}
+
+// UseThresholdNonce records the hash of the message signed with the nonce, returning ErrThresholdNonceUsed
+// if another message was already signed with it (signing the same message again is allowed)
+func UseThresholdNonce(db *gorm.DB, nonceID string, messageHash string) error {
+	// the condition makes the check and the update atomic
+	res := db.Model(&ThresholdNonce{}).
+		Where("nonce_id = ? AND (message_hash = '' OR message_hash IS NULL OR message_hash = ?)", nonceID, messageHash).
+		Update("message_hash", messageHash)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		nonce, err := FindThresholdNonce(db, nonceID)
+		if err != nil {
+			return err
+		}
+		// some databases do not count the rows updated with the same value
+		if nonce.MessageHash != messageHash {
+			return ErrThresholdNonceUsed
+		}
+	}
+	return nil
+}
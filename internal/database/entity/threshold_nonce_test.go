@@ -0,0 +1,80 @@
+package entity_test
+
+import (
+	"p2pderivatives-oracle/internal/database/entity"
+	"p2pderivatives-oracle/test"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func getThresholdNonceDB() *gorm.DB {
+	return test.NewOrm(&entity.ThresholdNonce{}).GetDB()
+}
+
+func Test_CommitThresholdNonce_AlreadyCommitted_ReturnsRecordedNonce(t *testing.T) {
+	// arrange
+	db := getThresholdNonceDB()
+	nonce := &entity.ThresholdNonce{NonceID: "id", AssetID: "btcusd", EventMaturity: 10, GroupNonce: "nonce"}
+	_, err := entity.CommitThresholdNonce(db, nonce)
+	assert.NoError(t, err)
+
+	// act
+	actual, err := entity.CommitThresholdNonce(db, &entity.ThresholdNonce{NonceID: "id", GroupNonce: "other nonce"})
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, "nonce", actual.GroupNonce)
+		assert.Equal(t, "btcusd", actual.AssetID)
+	}
+}
+
+func Test_FindEventThresholdNonces_ReturnsNoncesOrderedByIndex(t *testing.T) {
+	// arrange
+	db := getThresholdNonceDB()
+	for _, index := range []int{1, 0, 2} {
+		entity.CommitThresholdNonce(db, &entity.ThresholdNonce{
+			NonceID:       string(rune('a' + index)),
+			AssetID:       "btcusd",
+			EventMaturity: 10,
+			NonceIndex:    index,
+			GroupNonce:    "nonce",
+		})
+	}
+	entity.CommitThresholdNonce(db, &entity.ThresholdNonce{NonceID: "d", AssetID: "btcusd", EventMaturity: 20, GroupNonce: "nonce"})
+
+	// act
+	actual, err := entity.FindEventThresholdNonces(db, "btcusd", 10)
+
+	// assert
+	if assert.NoError(t, err) && assert.Len(t, actual, 3) {
+		for i, nonce := range actual {
+			assert.Equal(t, i, nonce.NonceIndex)
+		}
+	}
+}
+
+func Test_UseThresholdNonce_OtherMessage_ReturnsError(t *testing.T) {
+	// arrange
+	db := getThresholdNonceDB()
+	entity.CommitThresholdNonce(db, &entity.ThresholdNonce{NonceID: "id", GroupNonce: "nonce"})
+
+	// act
+	err := entity.UseThresholdNonce(db, "id", "hash")
+	errSame := entity.UseThresholdNonce(db, "id", "hash")
+	errOther := entity.UseThresholdNonce(db, "id", "other hash")
+
+	// assert
+	assert.NoError(t, err)
+	assert.NoError(t, errSame)
+	assert.Equal(t, entity.ErrThresholdNonceUsed, errOther)
+}
+
+func Test_UseThresholdNonce_NotCommitted_ReturnsNotFound(t *testing.T) {
+	db := getThresholdNonceDB()
+
+	err := entity.UseThresholdNonce(db, "id", "hash")
+
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
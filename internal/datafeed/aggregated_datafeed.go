@@ -0,0 +1,279 @@
+package datafeed
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cryptogarageinc/server-common-go/pkg/log"
+	"github.com/pkg/errors"
+)
+
+// AggregatedSource source name of the prices returned by the aggregated datafeed
+const AggregatedSource = "aggregated"
+
+// SourcePrice represents the price returned by one source
+type SourcePrice struct {
+	Source    string
+	Price     float64
+	Timestamp time.Time
+	Reference string
+	Stale     bool
+}
+
+// NewAggregatedDataFeed returns a datafeed querying all the sources concurrently
+// and returning the median of their prices
+func NewAggregatedDataFeed(l *log.Log, sources map[string]AssetPriceFeed, config *AggregatorConfig) DataFeed {
+	return &aggregatedDataFeed{
+		log:     l,
+		sources: sources,
+		config:  config,
+	}
+}
+
+type aggregatedDataFeed struct {
+	log     *log.Log
+	sources map[string]AssetPriceFeed
+	config  *AggregatorConfig
+}
+
+func (a *aggregatedDataFeed) FindCurrentAssetPrice(assetID string) (*float64, error) {
+	now := time.Now().UTC()
+	record, err := a.aggregate(assetID, now, func(source AssetPriceFeed) (*SourcePrice, error) {
+		price, err := source.FindCurrentAssetPrice(assetID)
+		if err != nil || price == nil {
+			return nil, err
+		}
+		return &SourcePrice{Price: *price, Timestamp: now}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &record.Price, nil
+}
+
+func (a *aggregatedDataFeed) FindPastAssetPrice(assetID string, date time.Time) (*float64, error) {
+	record, err := a.FindPastAssetPriceRecord(assetID, date)
+	if err != nil {
+		return nil, err
+	}
+	return &record.Price, nil
+}
+
+func (a *aggregatedDataFeed) FindPastAssetPriceRecord(assetID string, date time.Time) (*PriceRecord, error) {
+	return a.aggregate(assetID, date, func(source AssetPriceFeed) (*SourcePrice, error) {
+		record, err := source.FindPastAssetPriceRecord(assetID, date)
+		if err != nil || record == nil {
+			return nil, err
+		}
+		return &SourcePrice{
+			Price:     record.Price,
+			Timestamp: record.Timestamp,
+			Reference: record.Reference,
+			Stale:     record.Stale,
+		}, nil
+	})
+}
+
+// FindPastAssetPriceSeries returns for each period the candle of the median prices of the sources
+// providing it, the volume being the total volume of the sources. Like the spot prices, the candles whose close
+// or VWAP is too far from the median of the period are rejected, and a quorum of sources has to agree on each period.
+func (a *aggregatedDataFeed) FindPastAssetPriceSeries(assetID string, from time.Time, to time.Time) ([]Candle, error) {
+	var wg sync.WaitGroup
+	var mut sync.Mutex
+	// the candles of the sources are aggregated by period
+	type period struct {
+		time     time.Time
+		interval time.Duration
+	}
+	candlesByPeriod := make(map[period][]Candle)
+	nbSeriesSources := 0
+	for name, source := range a.sources {
+		seriesFeed, ok := source.(AssetPriceSeriesFeed)
+		if !ok {
+			continue
+		}
+		nbSeriesSources++
+		wg.Add(1)
+		go func(name string, source AssetPriceSeriesFeed) {
+			defer wg.Done()
+			candles, err := source.FindPastAssetPriceSeries(assetID, from, to)
+			if err != nil {
+				if a.log != nil {
+					a.log.Logger.Warnf("Source %s could not provide prices of asset %s: %v", name, assetID, err)
+				}
+				return
+			}
+			mut.Lock()
+			defer mut.Unlock()
+			for _, candle := range candles {
+				key := period{time: candle.Time, interval: candle.Interval}
+				candlesByPeriod[key] = append(candlesByPeriod[key], candle)
+			}
+		}(name, seriesFeed)
+	}
+	wg.Wait()
+
+	res := make([]Candle, 0, len(candlesByPeriod))
+	for key, candles := range candlesByPeriod {
+		candles, rejected := a.rejectOutlierCandles(candles)
+		if len(rejected) > 0 && a.log != nil {
+			a.log.Logger.Warnf("Rejected outlier candles of asset %s at %s: %v", assetID, key.time.String(), rejected)
+		}
+		if len(candles) < a.config.Quorum {
+			continue
+		}
+		candle := Candle{
+			Time:     key.time,
+			Interval: key.interval,
+			Open:     medianCandleValue(candles, func(c Candle) float64 { return c.Open }),
+			High:     medianCandleValue(candles, func(c Candle) float64 { return c.High }),
+			Low:      medianCandleValue(candles, func(c Candle) float64 { return c.Low }),
+			Close:    medianCandleValue(candles, func(c Candle) float64 { return c.Close }),
+			VWAP:     medianCandleValue(candles, func(c Candle) float64 { return c.VWAP }),
+		}
+		for _, c := range candles {
+			candle.Volume += c.Volume
+		}
+		res = append(res, candle)
+	}
+	if len(res) == 0 {
+		return nil, errors.Errorf(
+			"No candle of asset %s was returned by at least %d of the %d source(s)", assetID, a.config.Quorum, nbSeriesSources)
+	}
+	return CandlesWithin(res, from, to), nil
+}
+
+// rejectOutlierCandles splits the candles of a period between the ones whose close and VWAP (if provided)
+// are close enough to the median of the period and the rejected ones
+func (a *aggregatedDataFeed) rejectOutlierCandles(candles []Candle) ([]Candle, []Candle) {
+	medianClose := medianCandleValue(candles, func(c Candle) float64 { return c.Close })
+	medianVWAP := medianCandleValue(candles, func(c Candle) float64 { return c.VWAP })
+	isOutlier := func(value float64, median float64) bool {
+		return math.Abs(value-median) > math.Abs(median)*a.config.MaxDeviationPercent/100
+	}
+	kept := make([]Candle, 0, len(candles))
+	var rejected []Candle
+	for _, c := range candles {
+		if isOutlier(c.Close, medianClose) || (c.VWAP != 0 && isOutlier(c.VWAP, medianVWAP)) {
+			rejected = append(rejected, c)
+		} else {
+			kept = append(kept, c)
+		}
+	}
+	return kept, rejected
+}
+
+func (a *aggregatedDataFeed) FindPastOutcome(assetID string, date time.Time, outcomes []string) (*string, error) {
+	return nil, errors.Errorf("Aggregated datafeed cannot resolve outcome of asset %s", assetID)
+}
+
+func (a *aggregatedDataFeed) aggregate(assetID string, date time.Time, query func(source AssetPriceFeed) (*SourcePrice, error)) (*PriceRecord, error) {
+	prices := a.queryAll(assetID, query)
+	if len(prices) < a.config.Quorum {
+		return nil, errors.Errorf(
+			"Only %d of the %d source(s) returned a price for asset %s, %d required",
+			len(prices), len(a.sources), assetID, a.config.Quorum)
+	}
+
+	// reject the sources too far from the median of all the prices
+	median := medianPrice(prices)
+	res := &PriceRecord{Source: AggregatedSource, Timestamp: date}
+	for _, p := range prices {
+		if math.Abs(p.Price-median) > math.Abs(median)*a.config.MaxDeviationPercent/100 {
+			res.Rejected = append(res.Rejected, p)
+		} else {
+			res.Sources = append(res.Sources, p)
+		}
+	}
+	if len(res.Rejected) > 0 && a.log != nil {
+		a.log.Logger.Warnf("Rejected outlier prices for asset %s: %v", assetID, res.Rejected)
+	}
+	if len(res.Sources) < a.config.Quorum {
+		return nil, errors.Errorf(
+			"Only %d of the %d source(s) agree on the price of asset %s, %d required",
+			len(res.Sources), len(a.sources), assetID, a.config.Quorum)
+	}
+
+	res.Price = medianPrice(res.Sources)
+	res.Reference = res.Sources[0].Reference
+	for _, p := range res.Sources {
+		res.Stale = res.Stale || p.Stale
+		if p.Reference != res.Reference {
+			res.Reference = ""
+		}
+	}
+	return res, nil
+}
+
+// queryAll queries all the sources concurrently and returns the prices ordered by source
+func (a *aggregatedDataFeed) queryAll(assetID string, query func(source AssetPriceFeed) (*SourcePrice, error)) []SourcePrice {
+	var wg sync.WaitGroup
+	var mut sync.Mutex
+	prices := make([]SourcePrice, 0, len(a.sources))
+	for name, source := range a.sources {
+		wg.Add(1)
+		go func(name string, source AssetPriceFeed) {
+			defer wg.Done()
+			price, err := query(source)
+			if err == nil && price == nil {
+				err = errors.New("no price returned")
+			}
+			if err != nil {
+				if a.log != nil {
+					a.log.Logger.Warnf("Source %s could not provide price of asset %s: %v", name, assetID, err)
+				}
+				return
+			}
+			mut.Lock()
+			defer mut.Unlock()
+			price.Source = name
+			prices = append(prices, *price)
+		}(name, source)
+	}
+	wg.Wait()
+
+	sort.Slice(prices, func(i, j int) bool {
+		return prices[i].Source < prices[j].Source
+	})
+	return prices
+}
+
+func medianPrice(prices []SourcePrice) float64 {
+	values := make([]float64, len(prices))
+	for i, p := range prices {
+		values[i] = p.Price
+	}
+	sort.Float64s(values)
+	middle := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[middle-1] + values[middle]) / 2
+	}
+	return values[middle]
+}
+
+// medianCandleValue returns the median of the value of the candles, ignoring the candles without the value
+// (e.g. the vwap is not provided by all the sources)
+func medianCandleValue(candles []Candle, value func(c Candle) float64) float64 {
+	prices := make([]SourcePrice, 0, len(candles))
+	for _, c := range candles {
+		if v := value(c); v != 0 {
+			prices = append(prices, SourcePrice{Price: v})
+		}
+	}
+	if len(prices) == 0 {
+		return 0
+	}
+	return medianPrice(prices)
+}
+
+// AggregatorConfig configuration of the aggregated datafeed
+type AggregatorConfig struct {
+	// Sources names of the datafeeds to aggregate
+	Sources []string `configkey:"sources" validate:"required"`
+	// Quorum minimum number of sources that have to agree on a price
+	Quorum int `configkey:"quorum" validate:"min=1" default:"2"`
+	// MaxDeviationPercent maximum deviation from the median of all the prices for a source to be used
+	MaxDeviationPercent float64 `configkey:"maxDeviationPercent" validate:"gt=0" default:"2"`
+}
@@ -0,0 +1,261 @@
+package datafeed_test
+
+import (
+	"errors"
+	"p2pderivatives-oracle/internal/datafeed"
+	mock_datafeed "p2pderivatives-oracle/test/mock/datafeed"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+var testAggregatorConfig = &datafeed.AggregatorConfig{
+	Quorum:              2,
+	MaxDeviationPercent: 1,
+}
+
+var testAggregatorDate = time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+func newMockSources(ctrl *gomock.Controller, prices map[string]*float64) map[string]datafeed.AssetPriceFeed {
+	sources := make(map[string]datafeed.AssetPriceFeed, len(prices))
+	for name, price := range prices {
+		source := mock_datafeed.NewMockDataFeed(ctrl)
+		if price == nil {
+			source.EXPECT().FindPastAssetPriceRecord("btcusd", testAggregatorDate).Return(nil, errors.New("unavailable"))
+		} else {
+			source.EXPECT().FindPastAssetPriceRecord("btcusd", testAggregatorDate).Return(
+				&datafeed.PriceRecord{Price: *price, Source: name, Timestamp: testAggregatorDate}, nil)
+		}
+		sources[name] = source
+	}
+	return sources
+}
+
+func price(p float64) *float64 {
+	return &p
+}
+
+func TestAggregatedDataFeed_FindPastAssetPriceRecord_ReturnsMedian(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	sources := newMockSources(ctrl, map[string]*float64{"a": price(100), "b": price(100.5), "c": price(99.8)})
+	feed := datafeed.NewAggregatedDataFeed(nil, sources, testAggregatorConfig)
+
+	actual, err := feed.FindPastAssetPriceRecord("btcusd", testAggregatorDate)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, 100.0, actual.Price)
+		assert.Equal(t, datafeed.AggregatedSource, actual.Source)
+		assert.Equal(t, testAggregatorDate, actual.Timestamp)
+		assert.Equal(t, []datafeed.SourcePrice{
+			{Source: "a", Price: 100, Timestamp: testAggregatorDate},
+			{Source: "b", Price: 100.5, Timestamp: testAggregatorDate},
+			{Source: "c", Price: 99.8, Timestamp: testAggregatorDate},
+		}, actual.Sources)
+		assert.Empty(t, actual.Rejected)
+	}
+}
+
+func TestAggregatedDataFeed_FindPastAssetPriceRecord_EvenNumberOfSources_ReturnsMiddleAverage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	sources := newMockSources(ctrl, map[string]*float64{"a": price(100), "b": price(100.5)})
+	feed := datafeed.NewAggregatedDataFeed(nil, sources, testAggregatorConfig)
+
+	actual, err := feed.FindPastAssetPrice("btcusd", testAggregatorDate)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, 100.25, *actual)
+	}
+}
+
+func TestAggregatedDataFeed_FindPastAssetPriceRecord_RejectsOutliers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	sources := newMockSources(ctrl, map[string]*float64{"a": price(100), "b": price(100.4), "c": price(150)})
+	feed := datafeed.NewAggregatedDataFeed(nil, sources, testAggregatorConfig)
+
+	actual, err := feed.FindPastAssetPriceRecord("btcusd", testAggregatorDate)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, 100.2, actual.Price)
+		assert.Equal(t, []datafeed.SourcePrice{{Source: "c", Price: 150, Timestamp: testAggregatorDate}}, actual.Rejected)
+		assert.Len(t, actual.Sources, 2)
+	}
+}
+
+func TestAggregatedDataFeed_FindPastAssetPriceRecord_IgnoresFailingSources(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	sources := newMockSources(ctrl, map[string]*float64{"a": price(100), "b": price(100.4), "c": nil})
+	feed := datafeed.NewAggregatedDataFeed(nil, sources, testAggregatorConfig)
+
+	actual, err := feed.FindPastAssetPriceRecord("btcusd", testAggregatorDate)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, 100.2, actual.Price)
+	}
+}
+
+func TestAggregatedDataFeed_FindPastAssetPriceRecord_NoQuorum_ReturnsError(t *testing.T) {
+	tests := []struct {
+		name     string
+		prices   map[string]*float64
+		expected string
+	}{
+		{
+			name:     "not enough prices",
+			prices:   map[string]*float64{"a": price(100), "b": nil},
+			expected: "Only 1 of the 2 source(s) returned a price for asset btcusd, 2 required",
+		},
+		{
+			name:     "not enough agreeing prices",
+			prices:   map[string]*float64{"a": price(100), "b": price(200)},
+			expected: "Only 0 of the 2 source(s) agree on the price of asset btcusd, 2 required",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			feed := datafeed.NewAggregatedDataFeed(nil, newMockSources(ctrl, test.prices), testAggregatorConfig)
+
+			_, err := feed.FindPastAssetPriceRecord("btcusd", testAggregatorDate)
+
+			assert.EqualError(t, err, test.expected)
+		})
+	}
+}
+
+func TestAggregatedDataFeed_FindPastAssetPriceRecord_StaleSource_FlagsStalePrice(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	sources := make(map[string]datafeed.AssetPriceFeed, 2)
+	for name, stale := range map[string]bool{"a": false, "b": true} {
+		source := mock_datafeed.NewMockDataFeed(ctrl)
+		source.EXPECT().FindPastAssetPriceRecord("btcusd", testAggregatorDate).Return(&datafeed.PriceRecord{
+			Price:     100,
+			Source:    name,
+			Timestamp: testAggregatorDate,
+			Reference: datafeed.ReferenceClose,
+			Stale:     stale,
+		}, nil)
+		sources[name] = source
+	}
+	feed := datafeed.NewAggregatedDataFeed(nil, sources, testAggregatorConfig)
+
+	actual, err := feed.FindPastAssetPriceRecord("btcusd", testAggregatorDate)
+
+	if assert.NoError(t, err) {
+		assert.True(t, actual.Stale)
+		assert.Equal(t, datafeed.ReferenceClose, actual.Reference)
+		assert.True(t, actual.Sources[1].Stale)
+	}
+}
+
+func TestAggregatedDataFeed_FindPastAssetPriceSeries_ReturnsMedianCandlesWithQuorum(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	to := testAggregatorDate.Add(2 * time.Minute)
+	candle := func(minute int, close float64, vwap float64) datafeed.Candle {
+		return datafeed.Candle{
+			Time:     testAggregatorDate.Add(time.Duration(minute) * time.Minute),
+			Interval: time.Minute,
+			Close:    close,
+			VWAP:     vwap,
+			Volume:   1,
+		}
+	}
+	series := map[string][]datafeed.Candle{
+		"a": {candle(0, 100, 100.2), candle(1, 101, 0)},
+		"b": {candle(0, 102, 0), candle(1, 103, 0)},
+		// only provides the first candle
+		"c": {candle(0, 99, 99.6)},
+	}
+	sources := make(map[string]datafeed.AssetPriceFeed, len(series))
+	for name, candles := range series {
+		source := mock_datafeed.NewMockDataFeed(ctrl)
+		source.EXPECT().FindPastAssetPriceSeries("btcusd", testAggregatorDate, to).Return(candles, nil)
+		sources[name] = source
+	}
+	feed := datafeed.NewAggregatedDataFeed(nil, sources, &datafeed.AggregatorConfig{Quorum: 3, MaxDeviationPercent: 5})
+
+	actual, err := feed.FindPastAssetPriceSeries("btcusd", testAggregatorDate, to)
+
+	if assert.NoError(t, err) && assert.Len(t, actual, 1) {
+		assert.Equal(t, testAggregatorDate, actual[0].Time)
+		assert.Equal(t, 100.0, actual[0].Close)
+		assert.Equal(t, 99.9, actual[0].VWAP)
+		assert.Equal(t, 3.0, actual[0].Volume)
+	}
+}
+
+func TestAggregatedDataFeed_FindPastAssetPriceSeries_NoQuorum_ReturnsError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	to := testAggregatorDate.Add(time.Minute)
+	sources := make(map[string]datafeed.AssetPriceFeed, 3)
+	for i, name := range []string{"a", "b", "c"} {
+		source := mock_datafeed.NewMockDataFeed(ctrl)
+		// every source returns a different period
+		source.EXPECT().FindPastAssetPriceSeries("btcusd", testAggregatorDate, to).Return([]datafeed.Candle{{
+			Time:     testAggregatorDate.Add(time.Duration(i) * time.Second),
+			Interval: time.Minute,
+			Close:    100,
+		}}, nil)
+		sources[name] = source
+	}
+	feed := datafeed.NewAggregatedDataFeed(nil, sources, testAggregatorConfig)
+
+	_, err := feed.FindPastAssetPriceSeries("btcusd", testAggregatorDate, to)
+
+	assert.EqualError(t, err, "No candle of asset btcusd was returned by at least 2 of the 3 source(s)")
+}
+
+func TestAggregatedDataFeed_FindPastAssetPriceSeries_RejectsOutliers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	to := testAggregatorDate.Add(time.Minute)
+	series := map[string]datafeed.Candle{
+		"a": {Close: 100, VWAP: 100},
+		"b": {Close: 100.5, VWAP: 100.5},
+		// close too far from the median
+		"c": {Close: 150, VWAP: 100.2},
+		// VWAP too far from the median
+		"d": {Close: 99.8, VWAP: 50},
+	}
+	sources := make(map[string]datafeed.AssetPriceFeed, len(series))
+	for name, candle := range series {
+		candle.Time = testAggregatorDate
+		candle.Interval = time.Minute
+		candle.Volume = 1
+		source := mock_datafeed.NewMockDataFeed(ctrl)
+		source.EXPECT().FindPastAssetPriceSeries("btcusd", testAggregatorDate, to).Return([]datafeed.Candle{candle}, nil)
+		sources[name] = source
+	}
+	feed := datafeed.NewAggregatedDataFeed(nil, sources, testAggregatorConfig)
+
+	actual, err := feed.FindPastAssetPriceSeries("btcusd", testAggregatorDate, to)
+
+	if assert.NoError(t, err) && assert.Len(t, actual, 1) {
+		assert.Equal(t, 100.25, actual[0].Close)
+		assert.Equal(t, 100.25, actual[0].VWAP)
+		assert.Equal(t, 2.0, actual[0].Volume)
+	}
+}
+
+func TestAggregatedDataFeed_FindPastAssetPriceSeries_OutliersBelowQuorum_ReturnsError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	to := testAggregatorDate.Add(time.Minute)
+	sources := make(map[string]datafeed.AssetPriceFeed, 3)
+	for name, price := range map[string]float64{"a": 100, "b": 120, "c": 140} {
+		source := mock_datafeed.NewMockDataFeed(ctrl)
+		source.EXPECT().FindPastAssetPriceSeries("btcusd", testAggregatorDate, to).Return([]datafeed.Candle{{
+			Time:     testAggregatorDate,
+			Interval: time.Minute,
+			Close:    price,
+		}}, nil)
+		sources[name] = source
+	}
+	// a spot only source is not counted
+	sources["spot"] = mock_datafeed.NewMockAssetPriceFeed(ctrl)
+	feed := datafeed.NewAggregatedDataFeed(nil, sources, testAggregatorConfig)
+
+	_, err := feed.FindPastAssetPriceSeries("btcusd", testAggregatorDate, to)
+
+	assert.EqualError(t, err, "No candle of asset btcusd was returned by at least 2 of the 3 source(s)")
+}
@@ -0,0 +1,89 @@
+package datafeed
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// MethodSpot settles on the price at the event date
+	MethodSpot = "spot"
+	// MethodTWAP settles on the time weighted average price over a window before the event date
+	MethodTWAP = "twap"
+	// MethodVWAP settles on the volume weighted average price over a window before the event date
+	MethodVWAP = "vwap"
+)
+
+// FindAveragePriceRecord returns the time or volume weighted average price of the asset over the window
+// ending at the date, computed from the candles of the feed within the window. A stale price error is
+// returned if fewer than minCandles candles are returned or if they cover less than the minCoverage
+// fraction of the window (e.g. when the source is missing candles).
+func FindAveragePriceRecord(
+	feed AssetPriceSeriesFeed, assetID string, method string, date time.Time, window time.Duration,
+	minCoverage float64, minCandles int) (*PriceRecord, error) {
+	candles, err := feed.FindPastAssetPriceSeries(assetID, date.Add(-window), date)
+	if err != nil {
+		return nil, err
+	}
+	var covered time.Duration
+	for _, candle := range candles {
+		covered += candle.Interval
+	}
+	if len(candles) < minCandles || float64(covered) < minCoverage*float64(window) {
+		return nil, &SourceError{
+			Source: method,
+			Kind:   ErrorKindStale,
+			Message: fmt.Sprintf(
+				"%d candles of asset %s cover %s of the %s window before %s, %d candles and %.0f%% of the window required",
+				len(candles), assetID, covered, window, date.Format(time.RFC3339), minCandles, minCoverage*100),
+		}
+	}
+	price, err := AveragePrice(candles, method)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "Could not compute the %s of asset %s at %s", method, assetID, date)
+	}
+	return &PriceRecord{
+		Price:     price,
+		Source:    fmt.Sprintf("%s of %d candles", method, len(candles)),
+		Timestamp: date,
+	}, nil
+}
+
+// AveragePrice returns the time weighted average of the close of the candles, or their
+// volume weighted average price (using the vwap of the candles if provided, their close otherwise)
+func AveragePrice(candles []Candle, method string) (float64, error) {
+	if len(candles) == 0 {
+		return 0, errors.New("no candle")
+	}
+	switch method {
+	case MethodTWAP:
+		var sum float64
+		var duration time.Duration
+		for _, candle := range candles {
+			sum += candle.Close * float64(candle.Interval)
+			duration += candle.Interval
+		}
+		if duration == 0 {
+			return 0, errors.New("candles without duration")
+		}
+		return sum / float64(duration), nil
+	case MethodVWAP:
+		var sum, volume float64
+		for _, candle := range candles {
+			price := candle.VWAP
+			if price == 0 {
+				price = candle.Close
+			}
+			sum += price * candle.Volume
+			volume += candle.Volume
+		}
+		if volume == 0 {
+			return 0, errors.New("no volume traded")
+		}
+		return sum / volume, nil
+	default:
+		return 0, errors.Errorf("Unknown average method %s", method)
+	}
+}
@@ -0,0 +1,108 @@
+package datafeed_test
+
+import (
+	"errors"
+	"p2pderivatives-oracle/internal/datafeed"
+	mock_datafeed "p2pderivatives-oracle/test/mock/datafeed"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testAverageDate = time.Date(2021, time.June, 1, 8, 0, 0, 0, time.UTC)
+
+func minuteCandle(minutesBefore int, close float64, vwap float64, volume float64) datafeed.Candle {
+	return datafeed.Candle{
+		Time:     testAverageDate.Add(-time.Duration(minutesBefore) * time.Minute),
+		Interval: time.Minute,
+		Close:    close,
+		VWAP:     vwap,
+		Volume:   volume,
+	}
+}
+
+func TestAveragePrice_TWAP_ReturnsAverageCloseWeightedByInterval(t *testing.T) {
+	candles := []datafeed.Candle{
+		minuteCandle(3, 100, 0, 1),
+		minuteCandle(2, 102, 0, 1),
+		{Time: testAverageDate.Add(-time.Minute), Interval: 2 * time.Minute, Close: 105},
+	}
+
+	price, err := datafeed.AveragePrice(candles, datafeed.MethodTWAP)
+
+	require.NoError(t, err)
+	assert.Equal(t, 103.0, price)
+}
+
+func TestAveragePrice_VWAP_ReturnsAveragePriceWeightedByVolume(t *testing.T) {
+	candles := []datafeed.Candle{
+		minuteCandle(2, 100, 101, 3),
+		// the close is used for the candles without vwap
+		minuteCandle(1, 105, 0, 1),
+	}
+
+	price, err := datafeed.AveragePrice(candles, datafeed.MethodVWAP)
+
+	require.NoError(t, err)
+	assert.Equal(t, 102.0, price)
+}
+
+func TestAveragePrice_NoCandleOrVolume_ReturnsError(t *testing.T) {
+	_, err := datafeed.AveragePrice(nil, datafeed.MethodTWAP)
+	assert.Error(t, err)
+	_, err = datafeed.AveragePrice([]datafeed.Candle{minuteCandle(1, 100, 100, 0)}, datafeed.MethodVWAP)
+	assert.Error(t, err)
+	_, err = datafeed.AveragePrice([]datafeed.Candle{minuteCandle(1, 100, 100, 1)}, datafeed.MethodSpot)
+	assert.Error(t, err)
+}
+
+func TestFindAveragePriceRecord_ReturnsAverageOfWindowBeforeDate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	feed := mock_datafeed.NewMockAssetPriceSeriesFeed(ctrl)
+	feed.EXPECT().FindPastAssetPriceSeries("btcusd", testAverageDate.Add(-2*time.Minute), testAverageDate).Return(
+		[]datafeed.Candle{minuteCandle(2, 100, 0, 1), minuteCandle(1, 101, 0, 1)}, nil)
+
+	record, err := datafeed.FindAveragePriceRecord(feed, "btcusd", datafeed.MethodTWAP, testAverageDate, 2*time.Minute, 0.9, 2)
+
+	require.NoError(t, err)
+	assert.Equal(t, 100.5, record.Price)
+	assert.Equal(t, testAverageDate, record.Timestamp)
+}
+
+func TestFindAveragePriceRecord_MissingCandles_ReturnsStaleError(t *testing.T) {
+	tests := []struct {
+		name       string
+		candles    []datafeed.Candle
+		minCandles int
+	}{
+		{
+			name:       "window not covered",
+			candles:    []datafeed.Candle{minuteCandle(10, 100, 0, 1), minuteCandle(1, 101, 0, 1)},
+			minCandles: 2,
+		},
+		{
+			name:       "not enough candles",
+			candles:    []datafeed.Candle{{Time: testAverageDate.Add(-10 * time.Minute), Interval: 10 * time.Minute, Close: 100}},
+			minCandles: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			feed := mock_datafeed.NewMockAssetPriceSeriesFeed(ctrl)
+			feed.EXPECT().FindPastAssetPriceSeries("btcusd", testAverageDate.Add(-10*time.Minute), testAverageDate).Return(test.candles, nil)
+
+			_, err := datafeed.FindAveragePriceRecord(
+				feed, "btcusd", datafeed.MethodTWAP, testAverageDate, 10*time.Minute, 0.9, test.minCandles)
+
+			var sourceError *datafeed.SourceError
+			if assert.True(t, errors.As(err, &sourceError), "%v", err) {
+				assert.Equal(t, datafeed.ErrorKindStale, sourceError.Kind)
+			}
+		})
+	}
+}
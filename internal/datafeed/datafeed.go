@@ -1,14 +1,100 @@
 package datafeed
 
-import "time"
+import (
+	"sort"
+	"time"
+)
 
 // DataFeed interface represents a datafeed with any sorts of data
 type DataFeed interface {
 	AssetPriceFeed
+	AssetPriceSeriesFeed
+	OutcomeFeed
 }
 
 // AssetPriceFeed interface represents a datafeed which implemented price related services
 type AssetPriceFeed interface {
 	FindCurrentAssetPrice(assetID string) (*float64, error)
 	FindPastAssetPrice(assetID string, date time.Time) (*float64, error)
+	FindPastAssetPriceRecord(assetID string, date time.Time) (*PriceRecord, error)
+}
+
+// AssetPriceSeriesFeed interface represents a datafeed which can return the candles of an asset over a period
+type AssetPriceSeriesFeed interface {
+	// FindPastAssetPriceSeries returns the candles of the asset within the period (opened at or after from,
+	// closed at or before to) ordered by time
+	FindPastAssetPriceSeries(assetID string, from time.Time, to time.Time) ([]Candle, error)
+}
+
+// PriceRecord represents a price along with information on where it comes from
+type PriceRecord struct {
+	Price  float64
+	Source string
+	// Timestamp of the price used by the source (e.g. the candle timestamp)
+	Timestamp time.Time
+	// Candle of the source the price was taken from, if any
+	Candle *Candle
+	// Reference settlement reference of the price in the candle (close, open or vwap), if settled
+	Reference string
+	// Stale true if the timestamp of the price is outside of the settlement tolerance
+	Stale bool
+	// for aggregated prices, the prices used and the ones rejected as outliers
+	Sources  []SourcePrice
+	Rejected []SourcePrice
+}
+
+// Candle represents the OHLC candle of a source
+type Candle struct {
+	// Time opening time of the candle
+	Time     time.Time
+	Interval time.Duration
+	Open     float64
+	High     float64
+	Low      float64
+	Close    float64
+	// VWAP volume weighted average price of the candle, zero if not provided by the source
+	VWAP float64
+	// Volume traded during the candle (in base asset)
+	Volume float64
+}
+
+// End returns the closing time of the candle
+func (c *Candle) End() time.Time {
+	return c.Time.Add(c.Interval)
+}
+
+// CandlesWithin returns the candles opened at or after from and closed at or before to
+func CandlesWithin(candles []Candle, from time.Time, to time.Time) []Candle {
+	res := make([]Candle, 0, len(candles))
+	for _, candle := range candles {
+		if !candle.Time.Before(from) && !candle.End().After(to) {
+			res = append(res, candle)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Time.Before(res[j].Time)
+	})
+	return res
+}
+
+// OutcomeFeed interface represents a datafeed which can resolve the outcome of an enumerated event,
+// the returned outcome being one of the given outcomes
+type OutcomeFeed interface {
+	FindPastOutcome(assetID string, date time.Time, outcomes []string) (*string, error)
+}
+
+const (
+	// TypeDummy type of the datafeed always returning the configured value
+	TypeDummy = "dummy"
+	// TypeAggregator type of the datafeed aggregating the prices of several sources
+	TypeAggregator = "aggregator"
+	// TypeFile type of the datafeed reading the prices from a file
+	TypeFile = "file"
+)
+
+// Config contains the configuration of the datafeed
+type Config struct {
+	// Type either dummy, aggregator, file or the name of a price source (e.g. cryptocompare, kraken).
+	// If empty, the type is deduced from the configuration for backward compatibility.
+	Type string `configkey:"type"`
 }
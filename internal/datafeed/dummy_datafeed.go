@@ -2,8 +2,13 @@ package datafeed
 
 import (
 	"time"
+
+	"github.com/pkg/errors"
 )
 
+// DummySource source name of the prices returned by the dummy datafeed
+const DummySource = "dummy"
+
 // NewDummyDataFeed returns a dummy datafeed !
 func NewDummyDataFeed(config *DummyConfig) DataFeed {
 	return &dummyDataFeed{
@@ -25,6 +30,35 @@ func (d *dummyDataFeed) FindPastAssetPrice(assetID string, date time.Time) (*flo
 	return &f, nil
 }
 
+func (d *dummyDataFeed) FindPastAssetPriceRecord(assetID string, date time.Time) (*PriceRecord, error) {
+	return &PriceRecord{
+		Price:     d.config.ReturnValue,
+		Source:    DummySource,
+		Timestamp: date,
+	}, nil
+}
+
+// FindPastAssetPriceSeries returns minute candles of the dummy value
+func (d *dummyDataFeed) FindPastAssetPriceSeries(assetID string, from time.Time, to time.Time) ([]Candle, error) {
+	candles := []Candle{}
+	v := d.config.ReturnValue
+	for t := from.Truncate(time.Minute); !t.Add(time.Minute).After(to); t = t.Add(time.Minute) {
+		if t.Before(from) {
+			continue
+		}
+		candles = append(candles, Candle{Time: t, Interval: time.Minute, Open: v, High: v, Low: v, Close: v, VWAP: v, Volume: 1})
+	}
+	return candles, nil
+}
+
+func (d *dummyDataFeed) FindPastOutcome(assetID string, date time.Time, outcomes []string) (*string, error) {
+	if len(outcomes) == 0 {
+		return nil, errors.Errorf("No outcome for asset %s", assetID)
+	}
+	o := outcomes[0]
+	return &o, nil
+}
+
 // DummyConfig configuration for the dummy Datafeed
 type DummyConfig struct {
 	ReturnValue float64 `configkey:"dummy.returnValue" validate:"required"`
@@ -0,0 +1,129 @@
+package datafeed
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrorKind classifies the errors returned by the datafeed sources
+type ErrorKind int
+
+const (
+	// ErrorKindUnavailable the source could not be reached or failed to answer (network error, 5xx)
+	ErrorKindUnavailable ErrorKind = iota + 1
+	// ErrorKindTimeout the source did not answer in time
+	ErrorKindTimeout
+	// ErrorKindRateLimited the source refused the request because of its rate limit
+	ErrorKindRateLimited
+	// ErrorKindRejected the source rejected the request (4xx)
+	ErrorKindRejected
+	// ErrorKindAPI the source answered with an error payload
+	ErrorKindAPI
+	// ErrorKindCircuitOpen the source was not queried after too many consecutive failures
+	ErrorKindCircuitOpen
+	// ErrorKindStale the price returned by the source is outside of the settlement tolerance
+	ErrorKindStale
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case ErrorKindUnavailable:
+		return "unavailable"
+	case ErrorKindTimeout:
+		return "timeout"
+	case ErrorKindRateLimited:
+		return "rate limited"
+	case ErrorKindRejected:
+		return "rejected"
+	case ErrorKindAPI:
+		return "api error"
+	case ErrorKindCircuitOpen:
+		return "circuit open"
+	case ErrorKindStale:
+		return "stale price"
+	default:
+		return "unknown"
+	}
+}
+
+// SourceError represents an error returned by a datafeed source
+type SourceError struct {
+	Source string
+	Kind   ErrorKind
+	// StatusCode http status code of the response, if any
+	StatusCode int
+	// RetryAfter delay requested by the source before sending another request, if any
+	RetryAfter time.Duration
+	Message    string
+	Cause      error
+}
+
+func (e *SourceError) Error() string {
+	message := fmt.Sprintf("%s datafeed %s", e.Source, e.Kind)
+	if e.StatusCode != 0 {
+		message = fmt.Sprintf("%s (%d)", message, e.StatusCode)
+	}
+	if e.Message != "" {
+		message = message + ": " + e.Message
+	}
+	if e.Cause != nil {
+		message = message + ": " + e.Cause.Error()
+	}
+	return message
+}
+
+// Unwrap returns the cause of the error
+func (e *SourceError) Unwrap() error {
+	return e.Cause
+}
+
+// Temporary returns true if the same request may succeed later
+func (e *SourceError) Temporary() bool {
+	switch e.Kind {
+	case ErrorKindUnavailable, ErrorKindTimeout, ErrorKindRateLimited, ErrorKindCircuitOpen:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsTemporary returns true if the error is a temporary error of a datafeed source
+func IsTemporary(err error) bool {
+	var sourceError *SourceError
+	return errors.As(err, &sourceError) && sourceError.Temporary()
+}
+
+// NewUnavailableError returns the error of a request which could not be sent to the source
+func NewUnavailableError(source string, cause error) *SourceError {
+	return &SourceError{Source: source, Kind: ErrorKindUnavailable, Cause: cause}
+}
+
+// NewAPIError returns the error of a source answering with an error payload
+func NewAPIError(source string, kind ErrorKind, message string) *SourceError {
+	return &SourceError{Source: source, Kind: kind, Message: message}
+}
+
+// NewHTTPError returns the error of a source answering with an error status code,
+// classified from the status code
+func NewHTTPError(source string, statusCode int, header http.Header, body string) *SourceError {
+	err := &SourceError{Source: source, StatusCode: statusCode, Message: body}
+	switch {
+	case statusCode == http.StatusTooManyRequests:
+		err.Kind = ErrorKindRateLimited
+	case statusCode == http.StatusRequestTimeout || statusCode == http.StatusGatewayTimeout:
+		err.Kind = ErrorKindTimeout
+	case statusCode >= http.StatusInternalServerError:
+		err.Kind = ErrorKindUnavailable
+	default:
+		err.Kind = ErrorKindRejected
+	}
+	// only the delay in seconds is supported
+	if seconds, parseErr := strconv.Atoi(header.Get("Retry-After")); parseErr == nil && seconds > 0 {
+		err.RetryAfter = time.Duration(seconds) * time.Second
+	}
+	return err
+}
@@ -0,0 +1,48 @@
+package datafeed_test
+
+import (
+	"net/http"
+	"p2pderivatives-oracle/internal/datafeed"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHTTPError_ClassifiesStatusCode(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		kind       datafeed.ErrorKind
+		temporary  bool
+	}{
+		{statusCode: http.StatusBadRequest, kind: datafeed.ErrorKindRejected, temporary: false},
+		{statusCode: http.StatusUnauthorized, kind: datafeed.ErrorKindRejected, temporary: false},
+		{statusCode: http.StatusTooManyRequests, kind: datafeed.ErrorKindRateLimited, temporary: true},
+		{statusCode: http.StatusInternalServerError, kind: datafeed.ErrorKindUnavailable, temporary: true},
+		{statusCode: http.StatusServiceUnavailable, kind: datafeed.ErrorKindUnavailable, temporary: true},
+		{statusCode: http.StatusGatewayTimeout, kind: datafeed.ErrorKindTimeout, temporary: true},
+	}
+	for _, tt := range tests {
+		err := datafeed.NewHTTPError("source", tt.statusCode, http.Header{}, "body")
+		assert.Equal(t, tt.kind, err.Kind, tt.statusCode)
+		assert.Equal(t, tt.temporary, err.Temporary(), tt.statusCode)
+	}
+}
+
+func TestNewHTTPError_WithRetryAfter_ReturnsDelay(t *testing.T) {
+	header := http.Header{}
+	header.Set("Retry-After", "3")
+
+	err := datafeed.NewHTTPError("source", http.StatusTooManyRequests, header, "body")
+
+	assert.Equal(t, 3*time.Second, err.RetryAfter)
+}
+
+func TestIsTemporary_WrappedSourceError_ReturnsTrue(t *testing.T) {
+	err := errors.WithMessage(datafeed.NewUnavailableError("source", errors.New("connection refused")), "wrapped")
+
+	assert.True(t, datafeed.IsTemporary(err))
+	assert.False(t, datafeed.IsTemporary(errors.New("other")))
+	assert.False(t, datafeed.IsTemporary(datafeed.NewAPIError("source", datafeed.ErrorKindAPI, "error")))
+}
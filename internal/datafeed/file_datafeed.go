@@ -0,0 +1,419 @@
+package datafeed
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cryptogarageinc/server-common-go/pkg/log"
+	"github.com/pkg/errors"
+)
+
+const (
+	// FileSource source name of the prices read from the samples file
+	FileSource = "file"
+	// FileOverrideSource source name of the prices read from the overrides file
+	FileOverrideSource = "file override"
+)
+
+const (
+	// MatchPreceding answers with the last sample at or before the requested date
+	MatchPreceding = "preceding"
+	// MatchNearest answers with the sample closest to the requested date
+	MatchNearest = "nearest"
+)
+
+const (
+	formatCSV   = "csv"
+	formatJSONL = "jsonl"
+)
+
+// NewFileDataFeed returns a datafeed answering with the timestamped prices of the assets loaded from
+// a CSV or JSONL file, the entries of the overrides file replacing the samples at the same date.
+// The files are reloaded when modified, checked at most once per reload interval.
+func NewFileDataFeed(l *log.Log, config *FileConfig) (DataFeed, error) {
+	feed := &fileDataFeed{
+		log:    l,
+		config: config,
+	}
+	if err := feed.load(); err != nil {
+		return nil, err
+	}
+	return feed, nil
+}
+
+type fileDataFeed struct {
+	log    *log.Log
+	config *FileConfig
+
+	mut     sync.RWMutex
+	samples map[string][]priceSample
+	// modification times of the loaded files, and last time they were checked
+	modTimes  []time.Time
+	checkedAt time.Time
+}
+
+// priceSample represents the price of an asset at a date
+type priceSample struct {
+	Time     time.Time
+	Price    float64
+	Volume   float64
+	Override bool
+}
+
+func (f *fileDataFeed) FindCurrentAssetPrice(assetID string) (*float64, error) {
+	return f.FindPastAssetPrice(assetID, time.Now())
+}
+
+func (f *fileDataFeed) FindPastAssetPrice(assetID string, date time.Time) (*float64, error) {
+	record, err := f.FindPastAssetPriceRecord(assetID, date)
+	if err != nil {
+		return nil, err
+	}
+	return &record.Price, nil
+}
+
+func (f *fileDataFeed) FindPastAssetPriceRecord(assetID string, date time.Time) (*PriceRecord, error) {
+	samples, err := f.assetSamples(assetID)
+	if err != nil {
+		return nil, err
+	}
+	// index of the first sample after the date
+	i := sort.Search(len(samples), func(i int) bool { return samples[i].Time.After(date) })
+	var sample *priceSample
+	if i > 0 {
+		sample = &samples[i-1]
+	}
+	if f.config.Match == MatchNearest && i < len(samples) {
+		if sample == nil || samples[i].Time.Sub(date) < date.Sub(sample.Time) {
+			sample = &samples[i]
+		}
+	}
+	if sample == nil {
+		return nil, errors.Errorf("No sample of asset %s at or before %s", assetID, date.Format(time.RFC3339))
+	}
+
+	if distance := sample.Time.Sub(date); f.config.MaxDistance > 0 && (distance > f.config.MaxDistance || -distance > f.config.MaxDistance) {
+		message := fmt.Sprintf(
+			"sample of asset %s at %s is %s away from %s",
+			assetID, sample.Time.Format(time.RFC3339), distance, date.Format(time.RFC3339))
+		return nil, &SourceError{Source: FileSource, Kind: ErrorKindStale, Message: message}
+	}
+	source := FileSource
+	if sample.Override {
+		source = FileOverrideSource
+	}
+	return &PriceRecord{
+		Price:     sample.Price,
+		Source:    source,
+		Timestamp: sample.Time,
+	}, nil
+}
+
+// FindPastAssetPriceSeries returns a candle for each sample within the period, lasting until the next sample
+// (the price of the sample preceding the period being used from its start if it is within the maximum distance),
+// so that the time weighted average of the candles is the average of the prices over the period
+func (f *fileDataFeed) FindPastAssetPriceSeries(assetID string, from time.Time, to time.Time) ([]Candle, error) {
+	samples, err := f.assetSamples(assetID)
+	if err != nil {
+		return nil, err
+	}
+	candles := []Candle{}
+	// index of the first sample at or after from
+	i := sort.Search(len(samples), func(i int) bool { return !samples[i].Time.Before(from) })
+	startsAtFrom := i < len(samples) && samples[i].Time.Equal(from)
+	if i > 0 && !startsAtFrom && (f.config.MaxDistance <= 0 || from.Sub(samples[i-1].Time) <= f.config.MaxDistance) {
+		// the volume of the preceding sample was traded before the period
+		candles = append(candles, sampleCandle(priceSample{Time: from, Price: samples[i-1].Price}))
+	}
+	for ; i < len(samples) && samples[i].Time.Before(to); i++ {
+		candles = append(candles, sampleCandle(samples[i]))
+	}
+	if len(candles) == 0 {
+		return nil, errors.Errorf(
+			"No sample of asset %s from %s to %s", assetID, from.Format(time.RFC3339), to.Format(time.RFC3339))
+	}
+	for j := range candles {
+		end := to
+		if j+1 < len(candles) {
+			end = candles[j+1].Time
+		}
+		candles[j].Interval = end.Sub(candles[j].Time)
+	}
+	return candles, nil
+}
+
+func (f *fileDataFeed) FindPastOutcome(assetID string, date time.Time, outcomes []string) (*string, error) {
+	return nil, errors.Errorf("File datafeed cannot resolve outcome of asset %s", assetID)
+}
+
+func sampleCandle(sample priceSample) Candle {
+	return Candle{
+		Time:   sample.Time,
+		Open:   sample.Price,
+		High:   sample.Price,
+		Low:    sample.Price,
+		Close:  sample.Price,
+		Volume: sample.Volume,
+	}
+}
+
+// assetSamples returns the samples of the asset ordered by time, reloading the files if they were modified
+func (f *fileDataFeed) assetSamples(assetID string) ([]priceSample, error) {
+	f.reloadIfModified()
+	f.mut.RLock()
+	defer f.mut.RUnlock()
+	samples, ok := f.samples[assetID]
+	if !ok {
+		return nil, errors.Errorf("No sample found for asset %s", assetID)
+	}
+	return samples, nil
+}
+
+// reloadIfModified reloads the files if their modification time changed since they were loaded,
+// keeping the loaded samples if the files cannot be read
+func (f *fileDataFeed) reloadIfModified() {
+	if f.config.ReloadInterval <= 0 {
+		return
+	}
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	if time.Since(f.checkedAt) < f.config.ReloadInterval {
+		return
+	}
+	f.checkedAt = time.Now()
+	modTimes, err := f.statFiles()
+	if err != nil {
+		f.log.Logger.Errorf("Could not check the datafeed files: %v", err)
+		return
+	}
+	if equalTimes(modTimes, f.modTimes) {
+		return
+	}
+	samples, err := f.readSamples()
+	if err != nil {
+		f.log.Logger.Errorf("Could not reload the datafeed files, keeping the previous samples: %v", err)
+		return
+	}
+	f.samples = samples
+	f.modTimes = modTimes
+	f.log.Logger.Infof("Reloaded the datafeed files %v", f.config.paths())
+}
+
+func (f *fileDataFeed) load() error {
+	modTimes, err := f.statFiles()
+	if err != nil {
+		return err
+	}
+	samples, err := f.readSamples()
+	if err != nil {
+		return err
+	}
+	f.samples = samples
+	f.modTimes = modTimes
+	f.checkedAt = time.Now()
+	return nil
+}
+
+// statFiles returns the modification times of the samples file and overrides file (if any)
+func (f *fileDataFeed) statFiles() ([]time.Time, error) {
+	paths := f.config.paths()
+	modTimes := make([]time.Time, len(paths))
+	for i, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		modTimes[i] = info.ModTime()
+	}
+	return modTimes, nil
+}
+
+// readSamples reads the samples of each asset ordered by time, the overrides replacing the samples at the same date
+func (f *fileDataFeed) readSamples() (map[string][]priceSample, error) {
+	samplesByAsset := make(map[string]map[time.Time]priceSample)
+	for i, path := range f.config.paths() {
+		override := i > 0
+		err := readSampleFile(path, f.config.Format, func(assetID string, sample priceSample) {
+			if _, ok := samplesByAsset[assetID]; !ok {
+				samplesByAsset[assetID] = make(map[time.Time]priceSample)
+			}
+			sample.Override = override
+			samplesByAsset[assetID][sample.Time] = sample
+		})
+		if err != nil {
+			return nil, errors.WithMessagef(err, "Could not read datafeed file %s", path)
+		}
+	}
+
+	res := make(map[string][]priceSample, len(samplesByAsset))
+	for assetID, samplesByTime := range samplesByAsset {
+		samples := make([]priceSample, 0, len(samplesByTime))
+		for _, sample := range samplesByTime {
+			samples = append(samples, sample)
+		}
+		sort.Slice(samples, func(i, j int) bool {
+			return samples[i].Time.Before(samples[j].Time)
+		})
+		res[assetID] = samples
+	}
+	return res, nil
+}
+
+// readSampleFile reads the samples of a CSV file (with an asset, time, price and optional volume header)
+// or JSONL file (an object with the same fields on each line), the format being deduced from the file
+// extension if not given
+func readSampleFile(path string, format string, add func(assetID string, sample priceSample)) error {
+	if format == "" {
+		format = formatCSV
+		if ext := strings.ToLower(filepath.Ext(path)); ext == ".jsonl" || ext == ".ndjson" {
+			format = formatJSONL
+		}
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if format == formatJSONL {
+		return readJSONLSamples(file, add)
+	}
+	return readCSVSamples(file, add)
+}
+
+func readCSVSamples(r io.Reader, add func(assetID string, sample priceSample)) error {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+	header, err := reader.Read()
+	if err != nil {
+		return errors.WithMessage(err, "invalid csv header")
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"asset", "time", "price"} {
+		if _, ok := columns[name]; !ok {
+			return errors.Errorf("missing %s column", name)
+		}
+	}
+	volumeColumn, hasVolume := columns["volume"]
+	for entry := 1; ; entry++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		volume := ""
+		if hasVolume {
+			volume = row[volumeColumn]
+		}
+		assetID, sample, err := parseSample(row[columns["asset"]], row[columns["time"]], row[columns["price"]], volume)
+		if err != nil {
+			return errors.WithMessagef(err, "entry %d", entry)
+		}
+		add(assetID, *sample)
+	}
+}
+
+func readJSONLSamples(r io.Reader, add func(assetID string, sample priceSample)) error {
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		entry := struct {
+			Asset  string      `json:"asset"`
+			Time   string      `json:"time"`
+			Price  json.Number `json:"price"`
+			Volume json.Number `json:"volume"`
+		}{}
+		if err := json.Unmarshal([]byte(text), &entry); err != nil {
+			return errors.WithMessagef(err, "line %d", line)
+		}
+		assetID, sample, err := parseSample(entry.Asset, entry.Time, entry.Price.String(), entry.Volume.String())
+		if err != nil {
+			return errors.WithMessagef(err, "line %d", line)
+		}
+		add(assetID, *sample)
+	}
+	return scanner.Err()
+}
+
+// parseSample parses a sample whose time is either a RFC3339 date or a unix timestamp in seconds
+func parseSample(assetID string, timeValue string, priceValue string, volumeValue string) (string, *priceSample, error) {
+	assetID = strings.TrimSpace(assetID)
+	if assetID == "" {
+		return "", nil, errors.New("missing asset")
+	}
+	sample := &priceSample{}
+	timeValue = strings.TrimSpace(timeValue)
+	if seconds, err := strconv.ParseInt(timeValue, 10, 64); err == nil {
+		sample.Time = time.Unix(seconds, 0).UTC()
+	} else if date, err := time.Parse(time.RFC3339, timeValue); err == nil {
+		sample.Time = date.UTC()
+	} else {
+		return "", nil, errors.Errorf("invalid time %q", timeValue)
+	}
+	price, err := strconv.ParseFloat(strings.TrimSpace(priceValue), 64)
+	if err != nil {
+		return "", nil, errors.Errorf("invalid price %q", priceValue)
+	}
+	sample.Price = price
+	if volumeValue = strings.TrimSpace(volumeValue); volumeValue != "" {
+		volume, err := strconv.ParseFloat(volumeValue, 64)
+		if err != nil {
+			return "", nil, errors.Errorf("invalid volume %q", volumeValue)
+		}
+		sample.Volume = volume
+	}
+	return assetID, sample, nil
+}
+
+func equalTimes(a []time.Time, b []time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// FileConfig configuration of the file datafeed
+type FileConfig struct {
+	// Path of the CSV or JSONL file containing the samples
+	Path string `configkey:"file.path" validate:"required"`
+	// OverridesPath optional file (same format) whose entries replace the samples at the same date
+	OverridesPath string `configkey:"file.overridesPath"`
+	// Format csv or jsonl, deduced from the file extension if empty (.jsonl and .ndjson for jsonl)
+	Format string `configkey:"file.format" validate:"omitempty,oneof=csv jsonl"`
+	// Match preceding (last sample at or before the date) or nearest sample
+	Match string `configkey:"file.match" validate:"oneof=preceding nearest" default:"preceding"`
+	// MaxDistance maximum difference between the requested date and the date of the sample (no limit if zero)
+	MaxDistance time.Duration `configkey:"file.maxDistance,duration,iso8601" default:"PT1H"`
+	// ReloadInterval minimum interval between two checks of the modification of the files (no reload if zero)
+	ReloadInterval time.Duration `configkey:"file.reloadInterval,duration,iso8601" default:"PT10S"`
+}
+
+func (c *FileConfig) paths() []string {
+	if c.OverridesPath == "" {
+		return []string{c.Path}
+	}
+	return []string{c.Path, c.OverridesPath}
+}
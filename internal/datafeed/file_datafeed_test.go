@@ -0,0 +1,224 @@
+package datafeed_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"p2pderivatives-oracle/internal/datafeed"
+	"p2pderivatives-oracle/test"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testFileDate = time.Date(2021, time.June, 1, 8, 0, 0, 0, time.UTC)
+
+func newFileConfig(fixture string) *datafeed.FileConfig {
+	return &datafeed.FileConfig{
+		Path:  filepath.Join(test.VectorsDirectoryPath, "file", fixture),
+		Match: datafeed.MatchPreceding,
+	}
+}
+
+func newFileDataFeed(t *testing.T, config *datafeed.FileConfig) datafeed.DataFeed {
+	feed, err := datafeed.NewFileDataFeed(test.NewLogger(), config)
+	require.NoError(t, err)
+	return feed
+}
+
+func TestFileDataFeed_FindPastAssetPriceRecord_ReturnsMatchingSample(t *testing.T) {
+	tests := []struct {
+		name      string
+		fixture   string
+		match     string
+		date      time.Time
+		expected  float64
+		timestamp time.Time
+	}{
+		{
+			name:      "csv preceding",
+			fixture:   "prices.csv",
+			match:     datafeed.MatchPreceding,
+			date:      testFileDate.Add(2*time.Minute + 50*time.Second),
+			expected:  36520,
+			timestamp: testFileDate.Add(time.Minute),
+		},
+		{
+			name:      "csv nearest",
+			fixture:   "prices.csv",
+			match:     datafeed.MatchNearest,
+			date:      testFileDate.Add(2*time.Minute + 50*time.Second),
+			expected:  36560,
+			timestamp: testFileDate.Add(3 * time.Minute),
+		},
+		{
+			name:      "jsonl exact date",
+			fixture:   "prices.jsonl",
+			match:     datafeed.MatchPreceding,
+			date:      testFileDate.Add(time.Minute),
+			expected:  36520,
+			timestamp: testFileDate.Add(time.Minute),
+		},
+		{
+			name:      "jsonl nearest before first sample",
+			fixture:   "prices.jsonl",
+			match:     datafeed.MatchNearest,
+			date:      testFileDate.Add(-time.Minute),
+			expected:  36500,
+			timestamp: testFileDate,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := newFileConfig(tt.fixture)
+			config.Match = tt.match
+			feed := newFileDataFeed(t, config)
+
+			record, err := feed.FindPastAssetPriceRecord("btcusd", tt.date)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, record.Price)
+			assert.Equal(t, tt.timestamp, record.Timestamp)
+			assert.Equal(t, datafeed.FileSource, record.Source)
+		})
+	}
+}
+
+func TestFileDataFeed_UnixTimestamp_ReturnsSample(t *testing.T) {
+	feed := newFileDataFeed(t, newFileConfig("prices.csv"))
+
+	price, err := feed.FindPastAssetPrice("btcjpy", testFileDate.Add(time.Hour))
+
+	require.NoError(t, err)
+	assert.Equal(t, 4012500.0, *price)
+}
+
+func TestFileDataFeed_NoPrecedingSampleOrUnknownAsset_ReturnsError(t *testing.T) {
+	feed := newFileDataFeed(t, newFileConfig("prices.csv"))
+
+	_, err := feed.FindPastAssetPrice("btcusd", testFileDate.Add(-time.Second))
+	assert.Error(t, err)
+	_, err = feed.FindPastAssetPrice("ethusd", testFileDate)
+	assert.Error(t, err)
+}
+
+func TestFileDataFeed_SampleFurtherThanMaxDistance_ReturnsStaleError(t *testing.T) {
+	config := newFileConfig("prices.csv")
+	config.MaxDistance = time.Minute
+	feed := newFileDataFeed(t, config)
+
+	_, err := feed.FindPastAssetPriceRecord("btcusd", testFileDate.Add(5*time.Minute))
+
+	sourceErr := &datafeed.SourceError{}
+	if assert.True(t, errors.As(err, &sourceErr)) {
+		assert.Equal(t, datafeed.ErrorKindStale, sourceErr.Kind)
+	}
+}
+
+func TestFileDataFeed_Overrides_ReplaceSamples(t *testing.T) {
+	config := newFileConfig("prices.csv")
+	config.OverridesPath = filepath.Join(test.VectorsDirectoryPath, "file", "overrides.csv")
+	feed := newFileDataFeed(t, config)
+
+	replaced, err := feed.FindPastAssetPriceRecord("btcusd", testFileDate.Add(time.Minute))
+	require.NoError(t, err)
+	added, err := feed.FindPastAssetPriceRecord("btcusd", testFileDate.Add(2*time.Minute+30*time.Second))
+	require.NoError(t, err)
+	kept, err := feed.FindPastAssetPriceRecord("btcusd", testFileDate)
+	require.NoError(t, err)
+
+	assert.Equal(t, 36000.0, replaced.Price)
+	assert.Equal(t, datafeed.FileOverrideSource, replaced.Source)
+	assert.Equal(t, 36100.0, added.Price)
+	assert.Equal(t, 36500.0, kept.Price)
+	assert.Equal(t, datafeed.FileSource, kept.Source)
+}
+
+func TestFileDataFeed_FindPastAssetPriceSeries_ReturnsSamplesUntilNextSample(t *testing.T) {
+	feed := newFileDataFeed(t, newFileConfig("prices.csv"))
+	from := testFileDate.Add(30 * time.Second)
+	to := testFileDate.Add(4 * time.Minute)
+
+	candles, err := feed.FindPastAssetPriceSeries("btcusd", from, to)
+
+	require.NoError(t, err)
+	require.Len(t, candles, 3)
+	assert.Equal(t, from, candles[0].Time)
+	assert.Equal(t, 30*time.Second, candles[0].Interval)
+	assert.Equal(t, 36500.0, candles[0].Close)
+	assert.Equal(t, 0.0, candles[0].Volume)
+	assert.Equal(t, 2*time.Minute, candles[1].Interval)
+	assert.Equal(t, to, candles[2].End())
+	twap, err := datafeed.AveragePrice(candles, datafeed.MethodTWAP)
+	require.NoError(t, err)
+	assert.InDelta(t, (36500*0.5+36520*2+36560*1)/3.5, twap, 1e-9)
+}
+
+func TestFileDataFeed_FindPastAssetPriceSeries_SampleAtStart_KeepsItsVolume(t *testing.T) {
+	feed := newFileDataFeed(t, newFileConfig("prices.csv"))
+	to := testFileDate.Add(2 * time.Minute)
+
+	candles, err := feed.FindPastAssetPriceSeries("btcusd", testFileDate, to)
+
+	require.NoError(t, err)
+	require.Len(t, candles, 2)
+	assert.Equal(t, testFileDate, candles[0].Time)
+	assert.Equal(t, time.Minute, candles[0].Interval)
+	assert.Equal(t, 2.0, candles[0].Volume)
+	assert.Equal(t, 1.0, candles[1].Volume)
+	vwap, err := datafeed.AveragePrice(candles, datafeed.MethodVWAP)
+	require.NoError(t, err)
+	assert.InDelta(t, (36500*2+36520*1)/3.0, vwap, 1e-9)
+}
+
+func TestFileDataFeed_FindPastAssetPriceSeries_PrecedingSampleFurtherThanMaxDistance_IsNotUsed(t *testing.T) {
+	config := newFileConfig("prices.csv")
+	config.MaxDistance = time.Minute
+	feed := newFileDataFeed(t, config)
+
+	_, err := feed.FindPastAssetPriceSeries("btcusd", testFileDate.Add(5*time.Minute), testFileDate.Add(10*time.Minute))
+	assert.Error(t, err)
+
+	candles, err := feed.FindPastAssetPriceSeries("btcusd", testFileDate.Add(2*time.Minute+30*time.Second), testFileDate.Add(4*time.Minute))
+	require.NoError(t, err)
+	require.Len(t, candles, 1)
+	assert.Equal(t, testFileDate.Add(3*time.Minute), candles[0].Time)
+}
+
+func TestFileDataFeed_ModifiedFile_ReloadsSamples(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prices.csv")
+	require.NoError(t, ioutil.WriteFile(path, []byte("asset,time,price\nbtcusd,2021-06-01T08:00:00Z,36500\n"), 0600))
+	config := &datafeed.FileConfig{Path: path, Match: datafeed.MatchPreceding, ReloadInterval: time.Nanosecond}
+	feed := newFileDataFeed(t, config)
+
+	require.NoError(t, ioutil.WriteFile(path, []byte("asset,time,price\nbtcusd,2021-06-01T08:00:00Z,37000\n"), 0600))
+	modTime := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+	price, err := feed.FindPastAssetPrice("btcusd", testFileDate)
+	require.NoError(t, err)
+	assert.Equal(t, 37000.0, *price)
+
+	// an invalid file is not loaded
+	require.NoError(t, ioutil.WriteFile(path, []byte("asset,time,price\nbtcusd,invalid,38000\n"), 0600))
+	modTime = modTime.Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+	price, err = feed.FindPastAssetPrice("btcusd", testFileDate)
+	require.NoError(t, err)
+	assert.Equal(t, 37000.0, *price)
+}
+
+func TestNewFileDataFeed_InvalidFile_ReturnsError(t *testing.T) {
+	dir := t.TempDir()
+	missingColumn := filepath.Join(dir, "prices.csv")
+	require.NoError(t, ioutil.WriteFile(missingColumn, []byte("asset,time\nbtcusd,2021-06-01T08:00:00Z\n"), 0600))
+	invalidLine := filepath.Join(dir, "prices.jsonl")
+	require.NoError(t, ioutil.WriteFile(invalidLine, []byte("{\"asset\":\"btcusd\",\"time\":\"2021-06-01T08:00:00Z\",\"price\":1}\n{\n"), 0600))
+
+	for _, path := range []string{filepath.Join(dir, "missing.csv"), missingColumn, invalidLine} {
+		_, err := datafeed.NewFileDataFeed(test.NewLogger(), &datafeed.FileConfig{Path: path})
+		assert.Error(t, err, path)
+	}
+}
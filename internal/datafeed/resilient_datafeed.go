@@ -0,0 +1,241 @@
+package datafeed
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/cryptogarageinc/server-common-go/pkg/log"
+	"github.com/pkg/errors"
+)
+
+// NewResilientDataFeed returns a datafeed sending the requests to the given source with a timeout,
+// retrying them on temporary errors with an exponential backoff, and failing fast without querying
+// the source after too many consecutive failures (circuit breaker)
+func NewResilientDataFeed(l *log.Log, source string, feed DataFeed, config *ResilienceConfig) DataFeed {
+	if setter, ok := feed.(timeoutSetter); ok && config.Timeout > 0 {
+		setter.SetTimeout(config.Timeout)
+	}
+	return &resilientDataFeed{
+		log:    l,
+		source: source,
+		feed:   feed,
+		config: config,
+		breaker: &circuitBreaker{
+			threshold:    config.FailureThreshold,
+			openDuration: config.OpenDuration,
+		},
+	}
+}
+
+// timeoutSetter is implemented by the sources able to abort their own requests after a timeout
+// (otherwise the request of a timed out call completes in the background)
+type timeoutSetter interface {
+	SetTimeout(timeout time.Duration)
+}
+
+type resilientDataFeed struct {
+	log     *log.Log
+	source  string
+	feed    DataFeed
+	config  *ResilienceConfig
+	breaker *circuitBreaker
+}
+
+func (r *resilientDataFeed) FindCurrentAssetPrice(assetID string) (*float64, error) {
+	res, err := r.call(func() (interface{}, error) {
+		return r.feed.FindCurrentAssetPrice(assetID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.(*float64), nil
+}
+
+func (r *resilientDataFeed) FindPastAssetPrice(assetID string, date time.Time) (*float64, error) {
+	res, err := r.call(func() (interface{}, error) {
+		return r.feed.FindPastAssetPrice(assetID, date)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.(*float64), nil
+}
+
+func (r *resilientDataFeed) FindPastAssetPriceRecord(assetID string, date time.Time) (*PriceRecord, error) {
+	res, err := r.call(func() (interface{}, error) {
+		return r.feed.FindPastAssetPriceRecord(assetID, date)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.(*PriceRecord), nil
+}
+
+func (r *resilientDataFeed) FindPastAssetPriceSeries(assetID string, from time.Time, to time.Time) ([]Candle, error) {
+	res, err := r.call(func() (interface{}, error) {
+		return r.feed.FindPastAssetPriceSeries(assetID, from, to)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.([]Candle), nil
+}
+
+func (r *resilientDataFeed) FindPastOutcome(assetID string, date time.Time, outcomes []string) (*string, error) {
+	res, err := r.call(func() (interface{}, error) {
+		return r.feed.FindPastOutcome(assetID, date, outcomes)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.(*string), nil
+}
+
+// call sends the request until it succeeds, fails with an error which is not temporary
+// or the maximum number of attempts is reached
+func (r *resilientDataFeed) call(request func() (interface{}, error)) (interface{}, error) {
+	var err error
+	for attempt := 1; ; attempt++ {
+		if !r.breaker.allow() {
+			return nil, &SourceError{
+				Source:  r.source,
+				Kind:    ErrorKindCircuitOpen,
+				Message: "too many consecutive failures",
+				Cause:   err,
+			}
+		}
+		var res interface{}
+		res, err = r.callWithTimeout(request)
+		// the source answered if the error is not temporary, which does not count as a failure
+		temporary := IsTemporary(err)
+		if r.breaker.record(temporary) {
+			r.log.Logger.Warnf(
+				"Datafeed source %s not queried for %s after %d consecutive failures",
+				r.source, r.config.OpenDuration, r.config.FailureThreshold)
+		}
+		if !temporary {
+			return res, err
+		}
+		if attempt >= r.config.MaxAttempts {
+			return nil, err
+		}
+		delay, ok := r.backoff(attempt, err)
+		if !ok {
+			return nil, err
+		}
+		r.log.Logger.Warnf(
+			"Datafeed source %s request failed (attempt %d/%d), retrying in %s: %v",
+			r.source, attempt, r.config.MaxAttempts, delay, err)
+		time.Sleep(delay)
+	}
+}
+
+func (r *resilientDataFeed) callWithTimeout(request func() (interface{}, error)) (interface{}, error) {
+	if r.config.Timeout <= 0 {
+		return request()
+	}
+	type result struct {
+		value interface{}
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		value, err := request()
+		done <- result{value: value, err: err}
+	}()
+	timer := time.NewTimer(r.config.Timeout)
+	defer timer.Stop()
+	select {
+	case res := <-done:
+		return res.value, res.err
+	case <-timer.C:
+		return nil, &SourceError{
+			Source:  r.source,
+			Kind:    ErrorKindTimeout,
+			Message: fmt.Sprintf("no answer after %s", r.config.Timeout),
+		}
+	}
+}
+
+// backoff returns the delay before the given retry, a random duration between half and all of the
+// exponential backoff, or the delay requested by the source if longer (no retry is done if
+// the source requests a delay longer than the maximum backoff)
+func (r *resilientDataFeed) backoff(retry int, err error) (time.Duration, bool) {
+	backoff := r.config.InitialBackoff
+	for i := 1; i < retry && backoff < r.config.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > r.config.MaxBackoff {
+		backoff = r.config.MaxBackoff
+	}
+	delay := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+
+	var sourceError *SourceError
+	if errors.As(err, &sourceError) && sourceError.RetryAfter > delay {
+		if sourceError.RetryAfter > r.config.MaxBackoff {
+			return 0, false
+		}
+		delay = sourceError.RetryAfter
+	}
+	return delay, true
+}
+
+// circuitBreaker opens after consecutive failures, then lets a single request through once
+// the open duration elapsed to check whether the source recovered
+type circuitBreaker struct {
+	mut          sync.Mutex
+	threshold    int
+	openDuration time.Duration
+	failures     int
+	openedAt     time.Time
+	// a request checking whether the source recovered is pending
+	trial bool
+}
+
+func (b *circuitBreaker) allow() bool {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	if b.failures < b.threshold {
+		return true
+	}
+	if b.trial || time.Since(b.openedAt) < b.openDuration {
+		return false
+	}
+	b.trial = true
+	return true
+}
+
+// record records the result of a request, returning true if the circuit was opened by this failure
+func (b *circuitBreaker) record(failed bool) bool {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	b.trial = false
+	if !failed {
+		b.failures = 0
+		return false
+	}
+	b.failures++
+	if b.failures < b.threshold {
+		return false
+	}
+	b.openedAt = time.Now()
+	return b.failures == b.threshold
+}
+
+// ResilienceConfig configuration of the timeout, retries and circuit breaker of each datafeed source
+type ResilienceConfig struct {
+	// Timeout maximum duration of a request to a source
+	Timeout time.Duration `configkey:"resilience.timeout,duration,iso8601" default:"PT10S"`
+	// MaxAttempts maximum number of requests sent to a source for a call, only temporary errors being retried
+	MaxAttempts int `configkey:"resilience.maxAttempts" validate:"min=1" default:"3"`
+	// InitialBackoff backoff before the first retry, doubled for each following retry
+	InitialBackoff time.Duration `configkey:"resilience.initialBackoff,duration,iso8601" default:"PT0.5S"`
+	// MaxBackoff maximum backoff between two requests
+	MaxBackoff time.Duration `configkey:"resilience.maxBackoff,duration,iso8601" default:"PT5S"`
+	// FailureThreshold number of consecutive failed requests after which the source is not queried anymore
+	FailureThreshold int `configkey:"resilience.failureThreshold" validate:"min=1" default:"5"`
+	// OpenDuration duration during which the source is not queried after the failure threshold was reached
+	OpenDuration time.Duration `configkey:"resilience.openDuration,duration,iso8601" default:"PT30S"`
+}
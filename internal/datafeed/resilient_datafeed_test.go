@@ -0,0 +1,145 @@
+package datafeed_test
+
+import (
+	"errors"
+	"net/http"
+	"p2pderivatives-oracle/internal/datafeed"
+	"p2pderivatives-oracle/test"
+	mock_datafeed "p2pderivatives-oracle/test/mock/datafeed"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+var testResilienceConfig = &datafeed.ResilienceConfig{
+	Timeout:          50 * time.Millisecond,
+	MaxAttempts:      3,
+	InitialBackoff:   time.Millisecond,
+	MaxBackoff:       5 * time.Millisecond,
+	FailureThreshold: 5,
+	OpenDuration:     50 * time.Millisecond,
+}
+
+var testResilienceDate = time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+func newUnavailableError() error {
+	return datafeed.NewHTTPError("source", http.StatusServiceUnavailable, http.Header{}, "unavailable")
+}
+
+func TestResilientDataFeed_TemporaryErrors_RetriesUntilSuccess(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	source := mock_datafeed.NewMockDataFeed(ctrl)
+	record := &datafeed.PriceRecord{Price: 10000, Source: "source", Timestamp: testResilienceDate}
+	gomock.InOrder(
+		source.EXPECT().FindPastAssetPriceRecord("btcusd", testResilienceDate).Return(nil, newUnavailableError()),
+		source.EXPECT().FindPastAssetPriceRecord("btcusd", testResilienceDate).Return(nil, newUnavailableError()),
+		source.EXPECT().FindPastAssetPriceRecord("btcusd", testResilienceDate).Return(record, nil),
+	)
+	feed := datafeed.NewResilientDataFeed(test.NewLogger(), "source", source, testResilienceConfig)
+
+	actual, err := feed.FindPastAssetPriceRecord("btcusd", testResilienceDate)
+
+	assert.NoError(t, err)
+	assert.Equal(t, record, actual)
+}
+
+func TestResilientDataFeed_TemporaryErrors_ReturnsErrorAfterMaxAttempts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	source := mock_datafeed.NewMockDataFeed(ctrl)
+	source.EXPECT().FindPastAssetPrice("btcusd", testResilienceDate).Return(nil, newUnavailableError()).Times(3)
+	feed := datafeed.NewResilientDataFeed(test.NewLogger(), "source", source, testResilienceConfig)
+
+	_, err := feed.FindPastAssetPrice("btcusd", testResilienceDate)
+
+	assert.Error(t, err)
+	assert.True(t, datafeed.IsTemporary(err))
+}
+
+func TestResilientDataFeed_PermanentError_DoesNotRetry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	source := mock_datafeed.NewMockDataFeed(ctrl)
+	sourceErr := datafeed.NewHTTPError("source", http.StatusBadRequest, http.Header{}, "invalid pair")
+	source.EXPECT().FindPastAssetPrice("btcusd", testResilienceDate).Return(nil, sourceErr).Times(1)
+	feed := datafeed.NewResilientDataFeed(test.NewLogger(), "source", source, testResilienceConfig)
+
+	_, err := feed.FindPastAssetPrice("btcusd", testResilienceDate)
+
+	assert.Equal(t, sourceErr, err)
+}
+
+func TestResilientDataFeed_UntypedError_DoesNotRetry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	source := mock_datafeed.NewMockDataFeed(ctrl)
+	source.EXPECT().FindCurrentAssetPrice("btcusd").Return(nil, errors.New("No config found")).Times(1)
+	feed := datafeed.NewResilientDataFeed(test.NewLogger(), "source", source, testResilienceConfig)
+
+	_, err := feed.FindCurrentAssetPrice("btcusd")
+
+	assert.Error(t, err)
+	assert.False(t, datafeed.IsTemporary(err))
+}
+
+func TestResilientDataFeed_RetryAfterLongerThanMaxBackoff_DoesNotRetry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	source := mock_datafeed.NewMockDataFeed(ctrl)
+	header := http.Header{}
+	header.Set("Retry-After", "60")
+	sourceErr := datafeed.NewHTTPError("source", http.StatusTooManyRequests, header, "rate limit")
+	source.EXPECT().FindPastAssetPrice("btcusd", testResilienceDate).Return(nil, sourceErr).Times(1)
+	feed := datafeed.NewResilientDataFeed(test.NewLogger(), "source", source, testResilienceConfig)
+
+	_, err := feed.FindPastAssetPrice("btcusd", testResilienceDate)
+
+	assert.Equal(t, sourceErr, err)
+}
+
+func TestResilientDataFeed_SlowSource_ReturnsTimeoutError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	source := mock_datafeed.NewMockDataFeed(ctrl)
+	config := *testResilienceConfig
+	config.MaxAttempts = 1
+	source.EXPECT().FindPastAssetPrice("btcusd", testResilienceDate).DoAndReturn(
+		func(assetID string, date time.Time) (*float64, error) {
+			time.Sleep(2 * config.Timeout)
+			return price(10000), nil
+		})
+	feed := datafeed.NewResilientDataFeed(test.NewLogger(), "source", source, &config)
+
+	_, err := feed.FindPastAssetPrice("btcusd", testResilienceDate)
+
+	sourceErr := &datafeed.SourceError{}
+	if assert.True(t, errors.As(err, &sourceErr)) {
+		assert.Equal(t, datafeed.ErrorKindTimeout, sourceErr.Kind)
+	}
+	// let the mock be called before the end of the test
+	time.Sleep(2 * config.Timeout)
+}
+
+func TestResilientDataFeed_ConsecutiveFailures_OpensCircuitUntilOpenDurationElapsed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	source := mock_datafeed.NewMockDataFeed(ctrl)
+	config := *testResilienceConfig
+	config.MaxAttempts = 1
+	config.FailureThreshold = 2
+	gomock.InOrder(
+		source.EXPECT().FindPastAssetPrice("btcusd", testResilienceDate).Return(nil, newUnavailableError()).Times(2),
+		source.EXPECT().FindPastAssetPrice("btcusd", testResilienceDate).Return(price(10000), nil),
+	)
+	feed := datafeed.NewResilientDataFeed(test.NewLogger(), "source", source, &config)
+
+	feed.FindPastAssetPrice("btcusd", testResilienceDate)
+	feed.FindPastAssetPrice("btcusd", testResilienceDate)
+	_, err := feed.FindPastAssetPrice("btcusd", testResilienceDate)
+
+	sourceErr := &datafeed.SourceError{}
+	if assert.True(t, errors.As(err, &sourceErr)) {
+		assert.Equal(t, datafeed.ErrorKindCircuitOpen, sourceErr.Kind)
+	}
+
+	time.Sleep(config.OpenDuration)
+	actual, err := feed.FindPastAssetPrice("btcusd", testResilienceDate)
+	assert.NoError(t, err)
+	assert.Equal(t, 10000.0, *actual)
+}
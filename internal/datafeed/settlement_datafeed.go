@@ -0,0 +1,122 @@
+package datafeed
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cryptogarageinc/server-common-go/pkg/log"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ReferenceClose settles on the close of the candle ending at the event date
+	ReferenceClose = "close"
+	// ReferenceOpen settles on the open of the candle starting at the event date
+	ReferenceOpen = "open"
+	// ReferenceVWAP settles on the volume weighted average price of the candle ending at the event date
+	ReferenceVWAP = "vwap"
+)
+
+const (
+	// StalePolicyReject returns an error for the prices outside of the tolerance
+	StalePolicyReject = "reject"
+	// StalePolicyFlag returns the prices outside of the tolerance flagged as stale
+	StalePolicyFlag = "flag"
+)
+
+// NewSettlementDataFeed returns a datafeed settling the past prices of the given source on the configured
+// reference of the candle at the requested date, the prices whose timestamp is not within the tolerance
+// of the requested date being rejected or flagged as stale
+func NewSettlementDataFeed(l *log.Log, source string, feed DataFeed, config *SettlementConfig) DataFeed {
+	return &settlementDataFeed{
+		DataFeed: feed,
+		log:      l,
+		source:   source,
+		config:   config,
+	}
+}
+
+type settlementDataFeed struct {
+	DataFeed
+	log    *log.Log
+	source string
+	config *SettlementConfig
+}
+
+func (s *settlementDataFeed) FindPastAssetPrice(assetID string, date time.Time) (*float64, error) {
+	record, err := s.FindPastAssetPriceRecord(assetID, date)
+	if err != nil {
+		return nil, err
+	}
+	return &record.Price, nil
+}
+
+func (s *settlementDataFeed) FindPastAssetPriceRecord(assetID string, date time.Time) (*PriceRecord, error) {
+	reference := s.config.Reference(assetID)
+	// the sources return the candle containing the requested date, so the candle ending
+	// at the date is the one containing the second before it
+	candleDate := date
+	if reference != ReferenceOpen {
+		candleDate = date.Add(-time.Second)
+	}
+	record, err := s.DataFeed.FindPastAssetPriceRecord(assetID, candleDate)
+	if err != nil {
+		return nil, err
+	}
+	candle := record.Candle
+	if candle == nil {
+		return nil, errors.Errorf("%s did not return the candle of the price of asset %s", s.source, assetID)
+	}
+
+	res := *record
+	res.Reference = reference
+	switch reference {
+	case ReferenceOpen:
+		res.Price = candle.Open
+		res.Timestamp = candle.Time
+	case ReferenceVWAP:
+		if candle.VWAP == 0 {
+			return nil, errors.Errorf("%s does not provide the vwap of asset %s", s.source, assetID)
+		}
+		res.Price = candle.VWAP
+		res.Timestamp = candle.End()
+	default:
+		res.Price = candle.Close
+		res.Timestamp = candle.End()
+	}
+
+	if offset := res.Timestamp.Sub(date); offset > s.config.Tolerance || -offset > s.config.Tolerance {
+		message := fmt.Sprintf(
+			"%s of the candle of asset %s at %s is %s away from %s",
+			reference, assetID, candle.Time.Format(time.RFC3339), offset, date.Format(time.RFC3339))
+		if s.config.StalePolicy != StalePolicyFlag {
+			return nil, &SourceError{Source: s.source, Kind: ErrorKindStale, Message: message}
+		}
+		s.log.Logger.Warnf("Stale price of %s: %s", s.source, message)
+		res.Stale = true
+	}
+	return &res, nil
+}
+
+// SettlementConfig configuration of the prices used to settle the events
+type SettlementConfig struct {
+	// Tolerance maximum difference between the event date and the timestamp of the price
+	Tolerance time.Duration `configkey:"settlement.tolerance,duration,iso8601" default:"PT0S"`
+	// StalePolicy either reject or flag the prices outside of the tolerance
+	StalePolicy string `configkey:"settlement.stalePolicy" validate:"oneof=reject flag" default:"reject"`
+	// Assets settlement reference of each asset (close if not configured)
+	Assets map[string]SettlementAssetConfig `configkey:"settlement.assets"`
+}
+
+// SettlementAssetConfig settlement configuration of an asset
+type SettlementAssetConfig struct {
+	Reference string `configkey:"reference" validate:"oneof=close open vwap"`
+}
+
+// Reference returns the settlement reference of the asset
+func (c *SettlementConfig) Reference(assetID string) string {
+	if assetConfig, ok := c.Assets[assetID]; ok {
+		return assetConfig.Reference
+	}
+	return ReferenceClose
+}
@@ -0,0 +1,172 @@
+package datafeed_test
+
+import (
+	"errors"
+	"p2pderivatives-oracle/internal/datafeed"
+	"p2pderivatives-oracle/test"
+	mock_datafeed "p2pderivatives-oracle/test/mock/datafeed"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSettlementDate = time.Date(2021, time.June, 1, 8, 0, 0, 0, time.UTC)
+
+func newSettlementConfig(reference string, policy string) *datafeed.SettlementConfig {
+	return &datafeed.SettlementConfig{
+		StalePolicy: policy,
+		Assets:      map[string]datafeed.SettlementAssetConfig{"btcusd": {Reference: reference}},
+	}
+}
+
+func minuteCandleRecord(start time.Time) *datafeed.PriceRecord {
+	return &datafeed.PriceRecord{
+		Price:     36515.02,
+		Source:    "source",
+		Timestamp: start,
+		Candle: &datafeed.Candle{
+			Time:     start,
+			Interval: time.Minute,
+			Open:     36495.01,
+			High:     36541.3,
+			Low:      36488.5,
+			Close:    36515.02,
+			VWAP:     36512.7,
+		},
+	}
+}
+
+func TestSettlementDataFeed_FindPastAssetPriceRecord_ReturnsReferenceOfCandleAtDate(t *testing.T) {
+	tests := []struct {
+		reference string
+		// date requested to the source
+		candleDate time.Time
+		candle     time.Time
+		expected   float64
+		timestamp  time.Time
+	}{
+		{
+			reference:  datafeed.ReferenceClose,
+			candleDate: testSettlementDate.Add(-time.Second),
+			candle:     testSettlementDate.Add(-time.Minute),
+			expected:   36515.02,
+			timestamp:  testSettlementDate,
+		},
+		{
+			reference:  datafeed.ReferenceOpen,
+			candleDate: testSettlementDate,
+			candle:     testSettlementDate,
+			expected:   36495.01,
+			timestamp:  testSettlementDate,
+		},
+		{
+			reference:  datafeed.ReferenceVWAP,
+			candleDate: testSettlementDate.Add(-time.Second),
+			candle:     testSettlementDate.Add(-time.Minute),
+			expected:   36512.7,
+			timestamp:  testSettlementDate,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.reference, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			source := mock_datafeed.NewMockDataFeed(ctrl)
+			source.EXPECT().FindPastAssetPriceRecord("btcusd", tt.candleDate).Return(minuteCandleRecord(tt.candle), nil)
+			feed := datafeed.NewSettlementDataFeed(
+				test.NewLogger(), "source", source, newSettlementConfig(tt.reference, datafeed.StalePolicyReject))
+
+			record, err := feed.FindPastAssetPriceRecord("btcusd", testSettlementDate)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, record.Price)
+			assert.Equal(t, tt.timestamp, record.Timestamp)
+			assert.Equal(t, tt.reference, record.Reference)
+			assert.Equal(t, tt.candle, record.Candle.Time)
+			assert.False(t, record.Stale)
+		})
+	}
+}
+
+func TestSettlementDataFeed_NotConfiguredAsset_SettlesOnClose(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	source := mock_datafeed.NewMockDataFeed(ctrl)
+	source.EXPECT().FindPastAssetPriceRecord("btcjpy", testSettlementDate.Add(-time.Second)).Return(
+		minuteCandleRecord(testSettlementDate.Add(-time.Minute)), nil)
+	feed := datafeed.NewSettlementDataFeed(
+		test.NewLogger(), "source", source, newSettlementConfig(datafeed.ReferenceOpen, datafeed.StalePolicyReject))
+
+	price, err := feed.FindPastAssetPrice("btcjpy", testSettlementDate)
+
+	require.NoError(t, err)
+	assert.Equal(t, 36515.02, *price)
+}
+
+func TestSettlementDataFeed_HourlyCandleOutsideTolerance_ReturnsStaleError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	source := mock_datafeed.NewMockDataFeed(ctrl)
+	date := testSettlementDate.Add(30 * time.Minute)
+	record := minuteCandleRecord(testSettlementDate)
+	record.Candle.Interval = time.Hour
+	source.EXPECT().FindPastAssetPriceRecord("btcusd", date.Add(-time.Second)).Return(record, nil)
+	feed := datafeed.NewSettlementDataFeed(
+		test.NewLogger(), "source", source, newSettlementConfig(datafeed.ReferenceClose, datafeed.StalePolicyReject))
+
+	_, err := feed.FindPastAssetPriceRecord("btcusd", date)
+
+	sourceErr := &datafeed.SourceError{}
+	if assert.True(t, errors.As(err, &sourceErr)) {
+		assert.Equal(t, datafeed.ErrorKindStale, sourceErr.Kind)
+		assert.False(t, sourceErr.Temporary())
+	}
+}
+
+func TestSettlementDataFeed_PreviousCandle_FlagsStalePrice(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	source := mock_datafeed.NewMockDataFeed(ctrl)
+	// the source does not have the candle ending at the date yet
+	source.EXPECT().FindPastAssetPriceRecord("btcusd", testSettlementDate.Add(-time.Second)).Return(
+		minuteCandleRecord(testSettlementDate.Add(-2*time.Minute)), nil)
+	feed := datafeed.NewSettlementDataFeed(
+		test.NewLogger(), "source", source, newSettlementConfig(datafeed.ReferenceClose, datafeed.StalePolicyFlag))
+
+	record, err := feed.FindPastAssetPriceRecord("btcusd", testSettlementDate)
+
+	require.NoError(t, err)
+	assert.True(t, record.Stale)
+	assert.Equal(t, testSettlementDate.Add(-time.Minute), record.Timestamp)
+}
+
+func TestSettlementDataFeed_PreviousCandleWithinTolerance_ReturnsPrice(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	source := mock_datafeed.NewMockDataFeed(ctrl)
+	source.EXPECT().FindPastAssetPriceRecord("btcusd", testSettlementDate.Add(-time.Second)).Return(
+		minuteCandleRecord(testSettlementDate.Add(-2*time.Minute)), nil)
+	config := newSettlementConfig(datafeed.ReferenceClose, datafeed.StalePolicyReject)
+	config.Tolerance = time.Minute
+	feed := datafeed.NewSettlementDataFeed(test.NewLogger(), "source", source, config)
+
+	record, err := feed.FindPastAssetPriceRecord("btcusd", testSettlementDate)
+
+	require.NoError(t, err)
+	assert.False(t, record.Stale)
+}
+
+func TestSettlementDataFeed_MissingCandleOrVWAP_ReturnsError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	source := mock_datafeed.NewMockDataFeed(ctrl)
+	withoutVWAP := minuteCandleRecord(testSettlementDate.Add(-time.Minute))
+	withoutVWAP.Candle.VWAP = 0
+	source.EXPECT().FindPastAssetPriceRecord("btcusd", testSettlementDate.Add(-time.Second)).Return(withoutVWAP, nil)
+	source.EXPECT().FindPastAssetPriceRecord("btcusd", testSettlementDate.Add(-time.Second)).Return(
+		&datafeed.PriceRecord{Price: 36515.02, Source: "source", Timestamp: testSettlementDate}, nil)
+	feed := datafeed.NewSettlementDataFeed(
+		test.NewLogger(), "source", source, newSettlementConfig(datafeed.ReferenceVWAP, datafeed.StalePolicyFlag))
+
+	_, err := feed.FindPastAssetPriceRecord("btcusd", testSettlementDate)
+	assert.Error(t, err)
+	_, err = feed.FindPastAssetPriceRecord("btcusd", testSettlementDate)
+	assert.Error(t, err)
+}
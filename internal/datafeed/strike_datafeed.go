@@ -0,0 +1,53 @@
+package datafeed
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// NewStrikeOutcomeFeed returns a datafeed resolving the outcome of the configured enumerated events
+// by comparing the price of an asset to a strike, other requests being forwarded to the given feed
+func NewStrikeOutcomeFeed(feed DataFeed, config *StrikeConfig) DataFeed {
+	return &strikeOutcomeFeed{
+		DataFeed: feed,
+		config:   config,
+	}
+}
+
+type strikeOutcomeFeed struct {
+	DataFeed
+	config *StrikeConfig
+}
+
+// FindPastOutcome returns the first outcome if the price is strictly below the strike,
+// the second one otherwise
+func (s *strikeOutcomeFeed) FindPastOutcome(assetID string, date time.Time, outcomes []string) (*string, error) {
+	strike, ok := s.config.Strikes[assetID]
+	if !ok {
+		return s.DataFeed.FindPastOutcome(assetID, date, outcomes)
+	}
+	if len(outcomes) != 2 {
+		return nil, errors.Errorf("Strike event %s should have exactly two outcomes, got %d", assetID, len(outcomes))
+	}
+	price, err := s.DataFeed.FindPastAssetPrice(strike.PriceAssetID, date)
+	if err != nil {
+		return nil, err
+	}
+	outcome := outcomes[1]
+	if *price < strike.Strike {
+		outcome = outcomes[0]
+	}
+	return &outcome, nil
+}
+
+// StrikeConfig configuration of the enumerated events resolved using a strike
+type StrikeConfig struct {
+	Strikes map[string]StrikeAssetConfig `configkey:"strikes"`
+}
+
+// StrikeAssetConfig configuration of one enumerated event resolved using a strike
+type StrikeAssetConfig struct {
+	PriceAssetID string  `configkey:"priceAssetId" validate:"required"`
+	Strike       float64 `configkey:"strike" validate:"required"`
+}
@@ -0,0 +1,71 @@
+package datafeed_test
+
+import (
+	"p2pderivatives-oracle/internal/datafeed"
+	mock_datafeed "p2pderivatives-oracle/test/mock/datafeed"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+var testStrikeConfig = &datafeed.StrikeConfig{
+	Strikes: map[string]datafeed.StrikeAssetConfig{
+		"btcusd50k": {PriceAssetID: "btcusd", Strike: 50000},
+	},
+}
+
+func TestStrikeOutcomeFeed_FindPastOutcome_ComparesPriceToStrike(t *testing.T) {
+	tests := []struct {
+		name     string
+		price    float64
+		expected string
+	}{
+		{name: "below strike", price: 49999.99, expected: "below"},
+		{name: "at strike", price: 50000, expected: "above"},
+		{name: "above strike", price: 60000, expected: "above"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			date := time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+			price := test.price
+			inner := mock_datafeed.NewMockDataFeed(ctrl)
+			inner.EXPECT().FindPastAssetPrice("btcusd", date).Return(&price, nil)
+			feed := datafeed.NewStrikeOutcomeFeed(inner, testStrikeConfig)
+
+			actual, err := feed.FindPastOutcome("btcusd50k", date, []string{"below", "above"})
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, test.expected, *actual)
+			}
+		})
+	}
+}
+
+func TestStrikeOutcomeFeed_FindPastOutcome_NotStrikeAsset_Delegates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	date := time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+	outcomes := []string{"yes", "no"}
+	expected := "yes"
+	inner := mock_datafeed.NewMockDataFeed(ctrl)
+	inner.EXPECT().FindPastOutcome("etf", date, outcomes).Return(&expected, nil)
+	feed := datafeed.NewStrikeOutcomeFeed(inner, testStrikeConfig)
+
+	actual, err := feed.FindPastOutcome("etf", date, outcomes)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, expected, *actual)
+	}
+}
+
+func TestStrikeOutcomeFeed_FindPastOutcome_InvalidOutcomes_ReturnsError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	feed := datafeed.NewStrikeOutcomeFeed(mock_datafeed.NewMockDataFeed(ctrl), testStrikeConfig)
+
+	_, err := feed.FindPastOutcome("btcusd50k", time.Now(), []string{"below", "between", "above"})
+
+	assert.Error(t, err)
+}
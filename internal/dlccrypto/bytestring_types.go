@@ -10,6 +10,7 @@ const (
 	sizePrivateKey = 32
 	sizePublicKey  = 32
 	sizeSignature  = 64
+	sizePoint      = 33
 )
 
 // ErrInvalidBytestringSize represents a bytestring of wrong length for the struct type used
@@ -46,11 +47,26 @@ func NewPrivateKey(bytestring string) (*PrivateKey, error) {
 	return &PrivateKey{*bt}, nil
 }
 
+// NewPrivateKeyFromBytes returns a new PrivateKey instance holding a copy of the given bytes
+func NewPrivateKeyFromBytes(b []byte) (*PrivateKey, error) {
+	if len(b) != sizePrivateKey {
+		return nil, invalidSizeError("PrivateKey", sizePrivateKey)
+	}
+	return &PrivateKey{ByteString{bytes: append([]byte(nil), b...)}}, nil
+}
+
 // PrivateKey represents a private key
 type PrivateKey struct {
 	ByteString
 }
 
+// Zero overwrites the private key bytes with zeros, the key must not be used afterwards
+func (p *PrivateKey) Zero() {
+	for i := range p.bytes {
+		p.bytes[i] = 0
+	}
+}
+
 // NewSchnorrPublicKey returns a new PublicKey instance
 func NewSchnorrPublicKey(bytestring string) (*SchnorrPublicKey, error) {
 	bt, err := NewByteString(bytestring)
@@ -86,6 +102,23 @@ type Signature struct {
 	ByteString
 }
 
+// NewPoint returns a new Point instance
+func NewPoint(bytestring string) (*Point, error) {
+	bt, err := NewByteString(bytestring)
+	if err != nil {
+		return nil, err
+	}
+	if len(bt.bytes) != sizePoint {
+		return nil, invalidSizeError("Point", sizePoint)
+	}
+	return &Point{*bt}, nil
+}
+
+// Point represents a compressed secp256k1 point (e.g. the signature point of an outcome)
+type Point struct {
+	ByteString
+}
+
 func invalidSizeError(name string, size int) error {
 	return errors.WithMessagef(
 		ErrInvalidBytestringSize,
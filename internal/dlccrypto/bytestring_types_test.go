@@ -1,7 +1,9 @@
 package dlccrypto_test
 
 import (
+	"encoding/hex"
 	"p2pderivatives-oracle/internal/dlccrypto"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -47,6 +49,22 @@ func TestNewPrivateKey_WithValidSizeBytestring_ReturnsNoError(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestNewPrivateKeyFromBytes_CopiesBytes(t *testing.T) {
+	b, _ := hex.DecodeString(validPrivateKey)
+	key, err := dlccrypto.NewPrivateKeyFromBytes(b)
+	assert.NoError(t, err)
+	b[0] = 0
+	assert.Equal(t, validPrivateKey, key.EncodeToString())
+	_, err = dlccrypto.NewPrivateKeyFromBytes(b[1:])
+	assert.Error(t, err)
+}
+
+func TestPrivateKey_Zero_ClearsKey(t *testing.T) {
+	key, _ := dlccrypto.NewPrivateKey(validPrivateKey)
+	key.Zero()
+	assert.Equal(t, strings.Repeat("00", 32), key.EncodeToString())
+}
+
 func TestNewPublicKey_WithInvalidSizeBytestring_ReturnsError(t *testing.T) {
 	bs, err := dlccrypto.NewSchnorrPublicKey(invalidPublicKey)
 	assert.Error(t, err)
@@ -3,9 +3,11 @@ package dlccrypto
 // CryptoService interface for an utility crypto service
 type CryptoService interface {
 	GenerateSchnorrKeyPair() (*PrivateKey, *SchnorrPublicKey, error)
+	DeriveSchnorrNonce(privateKey *PrivateKey, assetID string, eventMaturity uint32, index int) (*PrivateKey, *SchnorrPublicKey, error)
 	SchnorrPublicKeyFromPrivateKey(privateKey *PrivateKey) (*SchnorrPublicKey, error)
 	ComputeSchnorrSignatureFixedK(privateKey *PrivateKey, oneTimeSigningK *PrivateKey, message string) (*Signature, error)
 	ComputeSchnorrSignature(privateKey *PrivateKey, message []byte) (*Signature, error)
 	VerifySchnorrSignature(publicKey *SchnorrPublicKey, signature *Signature, message string) (bool, error)
 	VerifySchnorrSignatureRaw(publicKey *SchnorrPublicKey, signature *Signature, message []byte) (bool, error)
+	ComputeSigPoint(publicKey *SchnorrPublicKey, nonce *SchnorrPublicKey, message string) (*Point, error)
 }
@@ -2,7 +2,8 @@ package dlccrypto
 
 import (
 	"crypto/x509"
-	"encoding/hex"
+	"crypto/x509/pkix"
+	"encoding/asn1"
 	"encoding/pem"
 	"io/ioutil"
 	"os"
@@ -10,6 +11,50 @@ import (
 	"github.com/pkg/errors"
 )
 
+const (
+	// KeyFormatSEC1 format of the "EC PRIVATE KEY" pem files (RFC 5915), generated by `openssl ecparam -genkey`
+	KeyFormatSEC1 = "SEC1"
+	// KeyFormatPKCS8 format of the "PRIVATE KEY" and "ENCRYPTED PRIVATE KEY" pem files (RFC 5208 and RFC 5958)
+	KeyFormatPKCS8 = "PKCS#8"
+
+	pemTypeSEC1           = "EC PRIVATE KEY"
+	pemTypePKCS8          = "PRIVATE KEY"
+	pemTypeEncryptedPKCS8 = "ENCRYPTED PRIVATE KEY"
+)
+
+var (
+	oidPublicKeyECDSA = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidSecp256k1      = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+)
+
+// ecPrivateKey ASN.1 structure of a SEC1 private key (RFC 5915)
+type ecPrivateKey struct {
+	Version       int
+	PrivateKey    []byte
+	NamedCurveOID asn1.ObjectIdentifier `asn1:"optional,explicit,tag:0"`
+	PublicKey     asn1.BitString        `asn1:"optional,explicit,tag:1"`
+}
+
+// pkcs8 ASN.1 structure of an unencrypted PKCS#8 private key (RFC 5208)
+type pkcs8 struct {
+	Version    int
+	Algo       pkix.AlgorithmIdentifier
+	PrivateKey []byte
+}
+
+// KeyFileInfo describes the format and encryption of a pem key file
+type KeyFileInfo struct {
+	// Format either KeyFormatSEC1 or KeyFormatPKCS8
+	Format string
+	// Encryption describes how the key is encrypted (empty if the key is not encrypted)
+	Encryption string
+}
+
+// IsEncrypted returns true if a password is required to read the key
+func (i *KeyFileInfo) IsEncrypted() bool {
+	return i.Encryption != ""
+}
+
 // ReadPemKeyFile returns an secp256k1 private key from a (encrypted) pem file.
 // If no password necessary, use nil
 func ReadPemKeyFile(filePath string, pass []byte) (*PrivateKey, error) {
@@ -21,24 +66,186 @@ func ReadPemKeyFile(filePath string, pass []byte) (*PrivateKey, error) {
 	if err != nil {
 		return nil, err
 	}
+	return ParsePemKey(content, pass)
+}
 
-	pemBlock, _ := pem.Decode(content)
-	var keyDer []byte
-	if pemBlock == nil {
-		return nil, errors.New("The file is not of PEM format")
+// ParsePemKey returns an secp256k1 private key from a SEC1 or PKCS#8 pem block, which is
+// decrypted with the password if it is encrypted
+func ParsePemKey(content []byte, pass []byte) (*PrivateKey, error) {
+	pemBlock, err := decodePem(content)
+	if err != nil {
+		return nil, err
 	}
-	if pass != nil {
-		keyDer, err = x509.DecryptPEMBlock(pemBlock, pass)
+
+	switch pemBlock.Type {
+	case pemTypeSEC1:
+		keyDer := pemBlock.Bytes
+		// legacy (deprecated) pem encryption of the keys generated with `openssl ec -aes256`,
+		// only supported for reading
+		if x509.IsEncryptedPEMBlock(pemBlock) {
+			if pass == nil {
+				return nil, errors.New("The key is encrypted but no password was provided")
+			}
+			keyDer, err = x509.DecryptPEMBlock(pemBlock, pass)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return parseSEC1PrivateKey(keyDer)
+	case pemTypePKCS8:
+		return parsePKCS8PrivateKey(pemBlock.Bytes)
+	case pemTypeEncryptedPKCS8:
+		if pass == nil {
+			return nil, errors.New("The key is encrypted but no password was provided")
+		}
+		keyDer, err := decryptPKCS8(pemBlock.Bytes, pass)
 		if err != nil {
 			return nil, err
 		}
-	} else {
-		keyDer = pemBlock.Bytes
+		return parsePKCS8PrivateKey(keyDer)
+	default:
+		return nil, errors.Errorf("Unsupported pem block type %s", pemBlock.Type)
 	}
+}
 
-	priv := make([]byte, 32)
-	copy(priv, keyDer[7:39])
-	bs := hex.EncodeToString(priv)
+// InspectPemKey returns the format and encryption of a pem key without decrypting it
+func InspectPemKey(content []byte) (*KeyFileInfo, error) {
+	pemBlock, err := decodePem(content)
+	if err != nil {
+		return nil, err
+	}
 
-	return NewPrivateKey(bs)
+	switch pemBlock.Type {
+	case pemTypeSEC1:
+		info := &KeyFileInfo{Format: KeyFormatSEC1}
+		if x509.IsEncryptedPEMBlock(pemBlock) {
+			info.Encryption = "legacy pem " + pemBlock.Headers["DEK-Info"]
+		}
+		return info, nil
+	case pemTypePKCS8:
+		return &KeyFileInfo{Format: KeyFormatPKCS8}, nil
+	case pemTypeEncryptedPKCS8:
+		encryption, err := describePKCS8Encryption(pemBlock.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return &KeyFileInfo{Format: KeyFormatPKCS8, Encryption: encryption}, nil
+	default:
+		return nil, errors.Errorf("Unsupported pem block type %s", pemBlock.Type)
+	}
+}
+
+// MarshalEncryptedPemKey returns the private key as an encrypted PKCS#8 pem block,
+// the encryption key being derived from the password with the given key derivation function
+// (KDFScrypt or KDFPBKDF2)
+func MarshalEncryptedPemKey(privateKey *PrivateKey, pass []byte, kdf string) ([]byte, error) {
+	if len(pass) == 0 {
+		return nil, errors.New("A password is required to encrypt the key")
+	}
+	keyDer, err := marshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := encryptPKCS8(keyDer, pass, kdf)
+	zero(keyDer)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemTypeEncryptedPKCS8, Bytes: encrypted}), nil
+}
+
+// WritePemKeyFile writes the private key to a new encrypted PKCS#8 pem file (see MarshalEncryptedPemKey)
+// readable only by its owner, an existing file is never overwritten
+func WritePemKeyFile(filePath string, privateKey *PrivateKey, pass []byte, kdf string) error {
+	content, err := MarshalEncryptedPemKey(privateKey, pass, kdf)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func decodePem(content []byte) (*pem.Block, error) {
+	pemBlock, _ := pem.Decode(content)
+	if pemBlock == nil {
+		return nil, errors.New("The file is not of PEM format")
+	}
+	return pemBlock, nil
+}
+
+func parseSEC1PrivateKey(der []byte) (*PrivateKey, error) {
+	var key ecPrivateKey
+	rest, err := asn1.Unmarshal(der, &key)
+	if err != nil {
+		return nil, errors.WithMessage(err, "Invalid SEC1 private key")
+	}
+	if len(rest) > 0 {
+		return nil, errors.New("Invalid SEC1 private key: trailing data")
+	}
+	if key.Version != 1 {
+		return nil, errors.Errorf("Unsupported SEC1 private key version %d", key.Version)
+	}
+	if len(key.NamedCurveOID) > 0 && !key.NamedCurveOID.Equal(oidSecp256k1) {
+		return nil, errors.Errorf("Unsupported curve %s, only secp256k1 keys are supported", key.NamedCurveOID)
+	}
+	if len(key.PrivateKey) > sizePrivateKey {
+		return nil, invalidSizeError("PrivateKey", sizePrivateKey)
+	}
+	// some implementations strip the leading zeros of the key
+	priv := make([]byte, sizePrivateKey)
+	copy(priv[sizePrivateKey-len(key.PrivateKey):], key.PrivateKey)
+	defer zero(priv)
+	return NewPrivateKeyFromBytes(priv)
+}
+
+func parsePKCS8PrivateKey(der []byte) (*PrivateKey, error) {
+	var key pkcs8
+	if _, err := asn1.Unmarshal(der, &key); err != nil {
+		return nil, errors.WithMessage(err, "Invalid PKCS#8 private key")
+	}
+	if !key.Algo.Algorithm.Equal(oidPublicKeyECDSA) {
+		return nil, errors.Errorf("Unsupported PKCS#8 private key algorithm %s", key.Algo.Algorithm)
+	}
+	var curve asn1.ObjectIdentifier
+	if _, err := asn1.Unmarshal(key.Algo.Parameters.FullBytes, &curve); err != nil || !curve.Equal(oidSecp256k1) {
+		return nil, errors.New("Unsupported curve, only secp256k1 keys are supported")
+	}
+	return parseSEC1PrivateKey(key.PrivateKey)
+}
+
+func marshalPKCS8PrivateKey(privateKey *PrivateKey) ([]byte, error) {
+	curve, err := asn1.Marshal(oidSecp256k1)
+	if err != nil {
+		return nil, err
+	}
+	// the curve is only given in the algorithm parameters
+	keyDer, err := asn1.Marshal(ecPrivateKey{
+		Version:    1,
+		PrivateKey: privateKey.bytes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer zero(keyDer)
+	return asn1.Marshal(pkcs8{
+		Algo: pkix.AlgorithmIdentifier{
+			Algorithm:  oidPublicKeyECDSA,
+			Parameters: asn1.RawValue{FullBytes: curve},
+		},
+		PrivateKey: keyDer,
+	})
+}
+
+// zero overwrites the given buffer with zeros
+func zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
 }
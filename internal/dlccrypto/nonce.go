@@ -0,0 +1,62 @@
+package dlccrypto
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"math/big"
+
+	"github.com/pkg/errors"
+)
+
+// NonceDerivationTag tag of the hash used to derive the event nonces from the oracle private key
+const NonceDerivationTag = "P2PDOracle/nonce"
+
+// order of the secp256k1 curve
+var curveOrder, _ = new(big.Int).SetString("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141", 16)
+
+// TaggedHash computes the BIP340 tagged hash of msg: sha256(sha256(tag) || sha256(tag) || msg)
+func TaggedHash(tag string, msg []byte) [32]byte {
+	tagHash := sha256.Sum256([]byte(tag))
+	h := sha256.New()
+	h.Write(tagHash[:])
+	h.Write(tagHash[:])
+	h.Write(msg)
+	var res [32]byte
+	copy(res[:], h.Sum(nil))
+	return res
+}
+
+// DeriveNonceKey deterministically derives the one time signing key used for the nonce at the given index
+// of an event from the oracle private key, the asset ID and the event maturity,
+// so that the key does not need to be stored until the event is attested
+func DeriveNonceKey(privateKey *PrivateKey, assetID string, eventMaturity uint32, index int) (*PrivateKey, error) {
+	data, err := NonceDerivationData(assetID, eventMaturity, index)
+	if err != nil {
+		return nil, err
+	}
+	hash := TaggedHash(NonceDerivationTag, append(append([]byte{}, privateKey.bytes...), data...))
+
+	k := new(big.Int).SetBytes(hash[:])
+	k.Mod(k, curveOrder)
+	if k.Sign() == 0 {
+		// only happens with negligible probability
+		return nil, errors.New("Derived nonce key is zero")
+	}
+	kBytes := make([]byte, sizePrivateKey)
+	k.FillBytes(kBytes)
+	return &PrivateKey{ByteString{bytes: kBytes}}, nil
+}
+
+// NonceDerivationData returns the data hashed after the oracle private key to derive the one time signing key
+// of a nonce (see DeriveNonceKey), which is given to the signers deriving the nonces without exposing the key
+func NonceDerivationData(assetID string, eventMaturity uint32, index int) ([]byte, error) {
+	if index < 0 {
+		return nil, errors.Errorf("Invalid nonce index %d", index)
+	}
+	buf := new(bytes.Buffer)
+	writeString(buf, assetID)
+	binary.Write(buf, binary.BigEndian, eventMaturity)
+	binary.Write(buf, binary.BigEndian, uint32(index))
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,44 @@
+package dlccrypto_test
+
+import (
+	"p2pderivatives-oracle/internal/dlccrypto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const validOraclePrivateKey = "c85c333c73eb6daf3479d0236b261d7512cb5daf6955beea5d66a180d34260ae"
+
+func TestDeriveNonceKey_ReturnsExpectedKeys(t *testing.T) {
+	privKey, _ := dlccrypto.NewPrivateKey(validOraclePrivateKey)
+	expected := []string{
+		"c6a2dfc7369e45f094801d68448ddd0155f326b7ed5f322e0a110e73ad178db8",
+		"9f95cb8d18405b16a9cad1c17eda14766236c5360d4502297592c66044810f71",
+	}
+
+	for i, e := range expected {
+		k, err := dlccrypto.DeriveNonceKey(privKey, "btcusd", 1623133104, i)
+		if assert.NoError(t, err) {
+			assert.Equal(t, e, k.EncodeToString())
+		}
+	}
+}
+
+func TestDeriveNonceKey_DifferentEvents_ReturnsDifferentKeys(t *testing.T) {
+	privKey, _ := dlccrypto.NewPrivateKey(validOraclePrivateKey)
+
+	k, _ := dlccrypto.DeriveNonceKey(privKey, "btcusd", 1623133104, 0)
+	otherAsset, _ := dlccrypto.DeriveNonceKey(privKey, "btcjpy", 1623133104, 0)
+	otherMaturity, _ := dlccrypto.DeriveNonceKey(privKey, "btcusd", 1623133105, 0)
+
+	assert.NotEqual(t, k.EncodeToString(), otherAsset.EncodeToString())
+	assert.NotEqual(t, k.EncodeToString(), otherMaturity.EncodeToString())
+}
+
+func TestDeriveNonceKey_NegativeIndex_ReturnsError(t *testing.T) {
+	privKey, _ := dlccrypto.NewPrivateKey(validOraclePrivateKey)
+
+	_, err := dlccrypto.DeriveNonceKey(privKey, "btcusd", 1623133104, -1)
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,348 @@
+package dlccrypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"hash"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// KDFScrypt derives the encryption key of the PKCS#8 key files with scrypt (RFC 7914)
+	KDFScrypt = "scrypt"
+	// KDFPBKDF2 derives the encryption key of the PKCS#8 key files with PBKDF2-HMAC-SHA256 (RFC 8018)
+	KDFPBKDF2 = "pbkdf2"
+
+	// parameters used when encrypting key files
+	scryptCost          = 1 << 15
+	scryptBlockSize     = 8
+	scryptParallelism   = 1
+	pbkdf2Iterations    = 600000
+	pbkdf2SaltSize      = 16
+	encryptionKeySize   = 32
+	gcmNonceSize        = 12
+	gcmDefaultTagLength = 16
+)
+
+var (
+	oidPBES2          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidScrypt         = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11591, 4, 11}
+	oidHMACWithSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidAES256CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidAES256GCM      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 46}
+)
+
+// encryptedPrivateKeyInfo ASN.1 structure of an encrypted PKCS#8 private key (RFC 5958)
+type encryptedPrivateKeyInfo struct {
+	Algo          pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+// pbes2Params parameters of the PBES2 encryption scheme (RFC 8018)
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+// pbkdf2Params parameters of the PBKDF2 key derivation function (RFC 8018),
+// the pseudo random function being HMAC-SHA1 if not specified
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                      `asn1:"optional"`
+	PRF            pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// scryptParams parameters of the scrypt key derivation function (RFC 7914)
+type scryptParams struct {
+	Salt                     []byte
+	CostParameter            int
+	BlockSize                int
+	ParallelizationParameter int
+	KeyLength                int `asn1:"optional"`
+}
+
+// gcmParams parameters of the AES-GCM encryption (RFC 5084)
+type gcmParams struct {
+	Nonce  []byte
+	ICVLen int `asn1:"default:12"`
+}
+
+// EncryptWithPassword encrypts data the same way as the encrypted PKCS#8 key files (see MarshalEncryptedPemKey),
+// returning the DER encoded PBES2 encrypted structure
+func EncryptWithPassword(data []byte, pass []byte, kdf string) ([]byte, error) {
+	if len(pass) == 0 {
+		return nil, errors.New("A password is required to encrypt the data")
+	}
+	return encryptPKCS8(data, pass, kdf)
+}
+
+// DecryptWithPassword decrypts data encrypted with EncryptWithPassword
+func DecryptWithPassword(der []byte, pass []byte) ([]byte, error) {
+	if len(pass) == 0 {
+		return nil, errors.New("The data is encrypted but no password was provided")
+	}
+	return decryptPKCS8(der, pass)
+}
+
+// encryptPKCS8 encrypts the DER encoded PKCS#8 private key with AES-256-GCM using PBES2,
+// the encryption key being derived from the password with the given key derivation function
+func encryptPKCS8(keyDer []byte, pass []byte, kdf string) ([]byte, error) {
+	salt := make([]byte, pbkdf2SaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	var kdfAlgo pkix.AlgorithmIdentifier
+	var params interface{}
+	switch kdf {
+	case KDFScrypt:
+		kdfAlgo.Algorithm = oidScrypt
+		params = scryptParams{
+			Salt:                     salt,
+			CostParameter:            scryptCost,
+			BlockSize:                scryptBlockSize,
+			ParallelizationParameter: scryptParallelism,
+			KeyLength:                encryptionKeySize,
+		}
+	case KDFPBKDF2:
+		kdfAlgo.Algorithm = oidPBKDF2
+		params = pbkdf2Params{
+			Salt:           salt,
+			IterationCount: pbkdf2Iterations,
+			KeyLength:      encryptionKeySize,
+			PRF:            pkix.AlgorithmIdentifier{Algorithm: oidHMACWithSHA256, Parameters: asn1.NullRawValue},
+		}
+	default:
+		return nil, errors.Errorf("Unknown key derivation function %s", kdf)
+	}
+	var err error
+	kdfAlgo.Parameters.FullBytes, err = asn1.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcmNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	gcmParamsDer, err := asn1.Marshal(gcmParams{Nonce: nonce, ICVLen: gcmDefaultTagLength})
+	if err != nil {
+		return nil, err
+	}
+	scheme := pbes2Params{
+		KeyDerivationFunc: kdfAlgo,
+		EncryptionScheme: pkix.AlgorithmIdentifier{
+			Algorithm:  oidAES256GCM,
+			Parameters: asn1.RawValue{FullBytes: gcmParamsDer},
+		},
+	}
+
+	key, err := deriveKey(&scheme.KeyDerivationFunc, pass, encryptionKeySize)
+	if err != nil {
+		return nil, err
+	}
+	defer zero(key)
+	aead, err := newGCM(key, gcmDefaultTagLength)
+	if err != nil {
+		return nil, err
+	}
+
+	schemeDer, err := asn1.Marshal(scheme)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(encryptedPrivateKeyInfo{
+		Algo: pkix.AlgorithmIdentifier{
+			Algorithm:  oidPBES2,
+			Parameters: asn1.RawValue{FullBytes: schemeDer},
+		},
+		EncryptedData: aead.Seal(nil, nonce, keyDer, nil),
+	})
+}
+
+// decryptPKCS8 decrypts an encrypted PKCS#8 private key (PBES2 with PBKDF2 or scrypt,
+// and AES-256-GCM or AES-256-CBC as used by openssl) returning the DER encoded PKCS#8 private key
+func decryptPKCS8(der []byte, pass []byte) ([]byte, error) {
+	info, scheme, err := parsePBES2(der)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := deriveKey(&scheme.KeyDerivationFunc, pass, encryptionKeySize)
+	if err != nil {
+		return nil, err
+	}
+	defer zero(key)
+
+	switch {
+	case scheme.EncryptionScheme.Algorithm.Equal(oidAES256GCM):
+		var params gcmParams
+		if _, err := asn1.Unmarshal(scheme.EncryptionScheme.Parameters.FullBytes, &params); err != nil {
+			return nil, errors.WithMessage(err, "Invalid AES-GCM parameters")
+		}
+		aead, err := newGCM(key, params.ICVLen)
+		if err != nil {
+			return nil, err
+		}
+		if len(params.Nonce) != aead.NonceSize() {
+			return nil, errors.New("Invalid AES-GCM nonce size")
+		}
+		keyDer, err := aead.Open(nil, params.Nonce, info.EncryptedData, nil)
+		if err != nil {
+			return nil, errors.New("Could not decrypt the key, the password might be invalid")
+		}
+		return keyDer, nil
+	case scheme.EncryptionScheme.Algorithm.Equal(oidAES256CBC):
+		var iv []byte
+		if _, err := asn1.Unmarshal(scheme.EncryptionScheme.Parameters.FullBytes, &iv); err != nil {
+			return nil, errors.WithMessage(err, "Invalid AES-CBC parameters")
+		}
+		return decryptCBC(key, iv, info.EncryptedData)
+	default:
+		return nil, errors.Errorf("Unsupported encryption scheme %s", scheme.EncryptionScheme.Algorithm)
+	}
+}
+
+// describePKCS8Encryption describes the key derivation function and encryption scheme of an encrypted PKCS#8 key
+func describePKCS8Encryption(der []byte) (string, error) {
+	_, scheme, err := parsePBES2(der)
+	if err != nil {
+		return "", err
+	}
+
+	var kdf string
+	params := scheme.KeyDerivationFunc.Parameters.FullBytes
+	switch {
+	case scheme.KeyDerivationFunc.Algorithm.Equal(oidScrypt):
+		var p scryptParams
+		if _, err := asn1.Unmarshal(params, &p); err != nil {
+			return "", errors.WithMessage(err, "Invalid scrypt parameters")
+		}
+		kdf = fmt.Sprintf("scrypt (N=%d, r=%d, p=%d)", p.CostParameter, p.BlockSize, p.ParallelizationParameter)
+	case scheme.KeyDerivationFunc.Algorithm.Equal(oidPBKDF2):
+		var p pbkdf2Params
+		if _, err := asn1.Unmarshal(params, &p); err != nil {
+			return "", errors.WithMessage(err, "Invalid PBKDF2 parameters")
+		}
+		prf := "HMAC-SHA1"
+		if p.PRF.Algorithm.Equal(oidHMACWithSHA256) {
+			prf = "HMAC-SHA256"
+		}
+		kdf = fmt.Sprintf("PBKDF2-%s (%d iterations)", prf, p.IterationCount)
+	default:
+		kdf = scheme.KeyDerivationFunc.Algorithm.String()
+	}
+
+	var encryption string
+	switch {
+	case scheme.EncryptionScheme.Algorithm.Equal(oidAES256GCM):
+		encryption = "AES-256-GCM"
+	case scheme.EncryptionScheme.Algorithm.Equal(oidAES256CBC):
+		encryption = "AES-256-CBC"
+	default:
+		encryption = scheme.EncryptionScheme.Algorithm.String()
+	}
+	return "PBES2 " + kdf + " " + encryption, nil
+}
+
+func parsePBES2(der []byte) (*encryptedPrivateKeyInfo, *pbes2Params, error) {
+	var info encryptedPrivateKeyInfo
+	if _, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, nil, errors.WithMessage(err, "Invalid encrypted PKCS#8 private key")
+	}
+	if !info.Algo.Algorithm.Equal(oidPBES2) {
+		return nil, nil, errors.Errorf("Unsupported PKCS#8 encryption algorithm %s, only PBES2 is supported", info.Algo.Algorithm)
+	}
+	var scheme pbes2Params
+	if _, err := asn1.Unmarshal(info.Algo.Parameters.FullBytes, &scheme); err != nil {
+		return nil, nil, errors.WithMessage(err, "Invalid PBES2 parameters")
+	}
+	return &info, &scheme, nil
+}
+
+// deriveKey derives the encryption key from the password with the given key derivation function
+func deriveKey(kdf *pkix.AlgorithmIdentifier, pass []byte, size int) ([]byte, error) {
+	params := kdf.Parameters.FullBytes
+	switch {
+	case kdf.Algorithm.Equal(oidScrypt):
+		var p scryptParams
+		if _, err := asn1.Unmarshal(params, &p); err != nil {
+			return nil, errors.WithMessage(err, "Invalid scrypt parameters")
+		}
+		if p.KeyLength != 0 && p.KeyLength != size {
+			return nil, errors.New("Unsupported scrypt key length")
+		}
+		return scrypt.Key(pass, p.Salt, p.CostParameter, p.BlockSize, p.ParallelizationParameter, size)
+	case kdf.Algorithm.Equal(oidPBKDF2):
+		var p pbkdf2Params
+		if _, err := asn1.Unmarshal(params, &p); err != nil {
+			return nil, errors.WithMessage(err, "Invalid PBKDF2 parameters")
+		}
+		if p.KeyLength != 0 && p.KeyLength != size {
+			return nil, errors.New("Unsupported PBKDF2 key length")
+		}
+		var prf func() hash.Hash
+		switch {
+		case len(p.PRF.Algorithm) == 0 || p.PRF.Algorithm.Equal(oidHMACWithSHA1):
+			prf = sha1.New
+		case p.PRF.Algorithm.Equal(oidHMACWithSHA256):
+			prf = sha256.New
+		default:
+			return nil, errors.Errorf("Unsupported PBKDF2 pseudo random function %s", p.PRF.Algorithm)
+		}
+		return pbkdf2.Key(pass, p.Salt, p.IterationCount, size, prf), nil
+	default:
+		return nil, errors.Errorf("Unsupported key derivation function %s", kdf.Algorithm)
+	}
+}
+
+func newGCM(key []byte, tagSize int) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCMWithTagSize(block, tagSize)
+}
+
+// decryptCBC decrypts AES-CBC encrypted data with PKCS#7 padding
+func decryptCBC(key []byte, iv []byte, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != block.BlockSize() || len(data) == 0 || len(data)%block.BlockSize() != 0 {
+		return nil, errors.New("Invalid AES-CBC encrypted data")
+	}
+	plaintext := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, data)
+
+	// the padding is checked in constant time as much as possible, an invalid padding
+	// meaning that the password is invalid
+	padding := int(plaintext[len(plaintext)-1])
+	valid := padding > 0 && padding <= block.BlockSize()
+	if valid {
+		expected := make([]byte, padding)
+		for i := range expected {
+			expected[i] = byte(padding)
+		}
+		valid = hmac.Equal(expected, plaintext[len(plaintext)-padding:])
+	}
+	if !valid {
+		zero(plaintext)
+		return nil, errors.New("Could not decrypt the key, the password might be invalid")
+	}
+	return plaintext[:len(plaintext)-padding], nil
+}
@@ -3,9 +3,11 @@ package dlccrypto
 import (
 	"bytes"
 	"encoding/binary"
+	"io"
 	"math"
 	"p2pderivatives-oracle/internal/decompose"
 
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
 
@@ -31,28 +33,85 @@ func (b *bigSize) write(buf *bytes.Buffer) {
 	}
 }
 
-// GetRoundedDecomposedSignaturesForValue rounds and decompose a given value and
-// produces signatures over its digits using the provided private key and nonces.
-func GetRoundedDecomposedSignaturesForValue(
-	value float64, base int, nbDigits int, privKey *PrivateKey, kValues []string, cryptoService CryptoService) ([]string, []string, error) {
+func (b *bigSize) read(r *bytes.Reader) error {
+	prefix, err := r.ReadByte()
+	if err != nil {
+		return err
+	}
+	switch prefix {
+	case 0xFD:
+		var val uint16
+		err = binary.Read(r, binary.BigEndian, &val)
+		b.inner = uint64(val)
+	case 0xFE:
+		var val uint32
+		err = binary.Read(r, binary.BigEndian, &val)
+		b.inner = uint64(val)
+	case 0xFF:
+		err = binary.Read(r, binary.BigEndian, &b.inner)
+	default:
+		b.inner = uint64(prefix)
+	}
+	return err
+}
+
+const (
+	// PositiveSign outcome of the sign digit for positive (or zero) values
+	PositiveSign = "+"
+	// NegativeSign outcome of the sign digit for negative values
+	NegativeSign = "-"
+)
+
+// RoundAndDecomposeValue scales the given value according to the precision
+// (the value represented by the digits multiplied by 10^precision gives back the value),
+// rounds it to the nearest integer and decomposes it in the given base.
+// If isSigned is true, the first element is the sign of the value ("+" or "-").
+// Values outside the range representable with nbDigits are clamped.
+func RoundAndDecomposeValue(value float64, base int, nbDigits int, isSigned bool, precision int) []string {
+	roundedValue := RoundValue(value, base, nbDigits, isSigned, precision)
+	sign := PositiveSign
+	if roundedValue < 0 {
+		sign = NegativeSign
+		roundedValue = -roundedValue
+	}
+	decomposedValue := decompose.Value(roundedValue, base, nbDigits)
+	if isSigned {
+		decomposedValue = append([]string{sign}, decomposedValue...)
+	}
+	return decomposedValue
+}
+
+// RoundValue scales the given value according to the precision and rounds it
+// to the nearest integer, clamping it to the range representable with nbDigits
+// digits in the given base (and to zero for unsigned events).
+func RoundValue(value float64, base int, nbDigits int, isSigned bool, precision int) int {
+	scaledValue := value * math.Pow10(-precision)
 	// round datafeed price to neareast integer
-	roundedValue := int(math.Round(value))
-	// decompose value
+	roundedValue := int(math.Round(scaledValue))
+	if roundedValue < 0 && !isSigned {
+		roundedValue = 0
+	}
 	maxValue := int(math.Pow(float64(base), float64(nbDigits)) - 1)
 	if roundedValue > maxValue {
 		roundedValue = maxValue
+	} else if roundedValue < -maxValue {
+		roundedValue = -maxValue
 	}
-	decomposedValue := decompose.Value(roundedValue, base, nbDigits)
-	if len(decomposedValue) != len(kValues) {
+	return roundedValue
+}
+
+// GetRoundedDecomposedSignaturesForValue rounds and decompose a given value and
+// produces signatures over its digits using the provided signer and nonces.
+// If isSigned is true, the first nonce is used to sign the sign of the value.
+func GetRoundedDecomposedSignaturesForValue(
+	value float64, base int, nbDigits int, isSigned bool, precision int, signer Signer, nonces []EventNonce) ([]string, []string, error) {
+	decomposedValue := RoundAndDecomposeValue(value, base, nbDigits, isSigned, precision)
+	if len(decomposedValue) != len(nonces) {
 		logrus.Panic("Incompatible lengths for decomposed value")
 	}
 	sigs := make([]string, len(decomposedValue))
 	for i, digit := range decomposedValue {
-		kvalue, err := NewPrivateKey(kValues[i])
-		if err != nil {
-			return nil, nil, err
-		}
-		sig, err := cryptoService.ComputeSchnorrSignatureFixedK(privKey, kvalue, digit)
+		sig, err := signer.ComputeSchnorrSignatureWithNonce(&nonces[i], digit)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -63,9 +122,33 @@ func GetRoundedDecomposedSignaturesForValue(
 	return sigs, decomposedValue, nil
 }
 
-// SerializeEvent serializes the given data as an OracleEvent
+// SerializeEvent serializes the given data as an OracleEvent with a digit decomposition descriptor
 func SerializeEvent(
 	nonces []SchnorrPublicKey, eventMaturity uint32, base uint16, isSigned bool, unit string, precision int32, nbDigits uint16, eventId string,
+) []byte {
+	subBuf := new(bytes.Buffer)
+	binary.Write(subBuf, binary.BigEndian, base)
+	binary.Write(subBuf, binary.BigEndian, isSigned)
+	writeString(subBuf, unit)
+	binary.Write(subBuf, binary.BigEndian, precision)
+	binary.Write(subBuf, binary.BigEndian, nbDigits)
+	return serializeEventWithDescriptor(nonces, eventMaturity, DigitDecompositionEventDescriptorTLVType, subBuf.Bytes(), eventId)
+}
+
+// SerializeEnumEvent serializes the given data as an OracleEvent with an enum descriptor
+func SerializeEnumEvent(
+	nonces []SchnorrPublicKey, eventMaturity uint32, outcomes []string, eventId string,
+) []byte {
+	subBuf := new(bytes.Buffer)
+	binary.Write(subBuf, binary.BigEndian, uint16(len(outcomes)))
+	for _, outcome := range outcomes {
+		writeString(subBuf, outcome)
+	}
+	return serializeEventWithDescriptor(nonces, eventMaturity, EnumEventDescriptorTLVType, subBuf.Bytes(), eventId)
+}
+
+func serializeEventWithDescriptor(
+	nonces []SchnorrPublicKey, eventMaturity uint32, descriptorType uint64, descriptor []byte, eventId string,
 ) []byte {
 	buf := new(bytes.Buffer)
 	nbNonces := uint16(len(nonces))
@@ -76,34 +159,85 @@ func SerializeEvent(
 	}
 
 	binary.Write(buf, binary.BigEndian, eventMaturity)
-	digitDecompositionPrefix := bigSize{inner: 55306}
-	digitDecompositionPrefix.write(buf)
-	subBuf := new(bytes.Buffer)
-	binary.Write(subBuf, binary.BigEndian, base)
-	binary.Write(subBuf, binary.BigEndian, isSigned)
-	unitLen := bigSize{inner: uint64(len(unit))}
-	unitLen.write(subBuf)
-	subBuf.Write([]byte(unit))
-	binary.Write(subBuf, binary.BigEndian, precision)
-	binary.Write(subBuf, binary.BigEndian, nbDigits)
-	subBufLen := bigSize{inner: uint64(subBuf.Len())}
-	subBufLen.write(buf)
-	buf.Write(subBuf.Bytes())
-	eventIdLen := bigSize{inner: uint64(len(eventId))}
-	eventIdLen.write(buf)
-	buf.Write([]byte(eventId))
+	writeTLV(buf, descriptorType, descriptor)
+	writeString(buf, eventId)
 	return buf.Bytes()
 }
 
+// Event contains the nonces, maturity and ID of a serialized OracleEvent
+type Event struct {
+	Nonces        []SchnorrPublicKey
+	EventMaturity uint32
+	EventID       string
+}
+
+// ParseEvent parses an OracleEvent serialized by SerializeEvent or SerializeEnumEvent
+// (the event descriptor is not parsed)
+func ParseEvent(ser []byte) (*Event, error) {
+	r := bytes.NewReader(ser)
+	var nbNonces uint16
+	if err := binary.Read(r, binary.BigEndian, &nbNonces); err != nil {
+		return nil, errors.WithMessage(err, "Invalid oracle event")
+	}
+	event := &Event{Nonces: make([]SchnorrPublicKey, nbNonces)}
+	for i := range event.Nonces {
+		nonce := make([]byte, sizePublicKey)
+		if _, err := io.ReadFull(r, nonce); err != nil {
+			return nil, errors.WithMessage(err, "Invalid oracle event nonces")
+		}
+		event.Nonces[i] = SchnorrPublicKey{ByteString{bytes: nonce}}
+	}
+	if err := binary.Read(r, binary.BigEndian, &event.EventMaturity); err != nil {
+		return nil, errors.WithMessage(err, "Invalid oracle event maturity")
+	}
+	var descriptorType, descriptorLength bigSize
+	if err := descriptorType.read(r); err != nil {
+		return nil, errors.WithMessage(err, "Invalid oracle event descriptor")
+	}
+	if err := descriptorLength.read(r); err != nil {
+		return nil, errors.WithMessage(err, "Invalid oracle event descriptor")
+	}
+	if descriptorLength.inner > uint64(r.Len()) {
+		return nil, errors.New("Invalid oracle event descriptor length")
+	}
+	r.Seek(int64(descriptorLength.inner), io.SeekCurrent)
+	var idLength bigSize
+	if err := idLength.read(r); err != nil {
+		return nil, errors.WithMessage(err, "Invalid oracle event ID")
+	}
+	if idLength.inner != uint64(r.Len()) {
+		return nil, errors.New("Invalid oracle event ID length")
+	}
+	eventID := make([]byte, idLength.inner)
+	r.Read(eventID)
+	event.EventID = string(eventID)
+	return event, nil
+}
+
 // GenerateEventSignature serializes the given data to the appropriate format
 // and returns a Schnorr signature over the resulting data
 func GenerateEventSignature(
-	privKey *PrivateKey, nonces []SchnorrPublicKey, eventMaturity uint32, base uint16, isSigned bool, unit string, precision int32, nbDigits uint16, eventId string, cryptoService CryptoService,
+	signer Signer, nonces []SchnorrPublicKey, eventMaturity uint32, base uint16, isSigned bool, unit string, precision int32, nbDigits uint16, eventId string,
 ) (string, error) {
 
 	ser := SerializeEvent(nonces, eventMaturity, base, isSigned, unit, precision, nbDigits, eventId)
 
-	sig, err := cryptoService.ComputeSchnorrSignature(privKey, ser)
+	return signSerializedEvent(signer, ser)
+}
+
+// GenerateEnumEventSignature serializes the given enum event data to the appropriate format
+// and returns a Schnorr signature over the resulting data
+func GenerateEnumEventSignature(
+	signer Signer, nonces []SchnorrPublicKey, eventMaturity uint32, outcomes []string, eventId string,
+) (string, error) {
+
+	ser := SerializeEnumEvent(nonces, eventMaturity, outcomes, eventId)
+
+	return signSerializedEvent(signer, ser)
+}
+
+func signSerializedEvent(signer Signer, ser []byte) (string, error) {
+	sig, err := signer.ComputeSchnorrSignature(ser)
 
 	if err != nil {
 		return "", err
@@ -111,3 +245,24 @@ func GenerateEventSignature(
 
 	return sig.EncodeToString(), nil
 }
+
+// GetEnumOutcomeSignature produces a signature over the outcome of an enum event
+// using the provided signer and nonce, after checking that the outcome is one of the event outcomes.
+func GetEnumOutcomeSignature(
+	outcome string, outcomes []string, signer Signer, nonce *EventNonce) (string, error) {
+	found := false
+	for _, o := range outcomes {
+		if o == outcome {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return "", errors.Errorf("Outcome %s is not one of the event outcomes %v", outcome, outcomes)
+	}
+	sig, err := signer.ComputeSchnorrSignatureWithNonce(nonce, outcome)
+	if err != nil {
+		return "", err
+	}
+	return sig.EncodeToString(), nil
+}
@@ -11,8 +11,9 @@ import (
 )
 
 const (
-	validEventSignature = "319dfb9ced3c34242aad5920e1f2862346accfa19f013726beb1d7d1678737805eccecedea7abbe7296ff94a394043a219d3087d2d47fc3cff95d0b9f5595d92"
-	validSerialization  = "0002abf8f63630a0b1dec98ce8db50e9680f89f3390105454510420048d050aaa05df4a731b0d25a291f7bbc33f391003e87dcfae98a7484e37646453725405f7f3160bf0bb0fdd80a1200020008736174732f73656300000000000a0454657374"
+	validEventSignature    = "319dfb9ced3c34242aad5920e1f2862346accfa19f013726beb1d7d1678737805eccecedea7abbe7296ff94a394043a219d3087d2d47fc3cff95d0b9f5595d92"
+	validSerialization     = "0002abf8f63630a0b1dec98ce8db50e9680f89f3390105454510420048d050aaa05df4a731b0d25a291f7bbc33f391003e87dcfae98a7484e37646453725405f7f3160bf0bb0fdd80a1200020008736174732f73656300000000000a0454657374"
+	validEnumSerialization = "0001abf8f63630a0b1dec98ce8db50e9680f89f3390105454510420048d050aaa05d60bf0bb0fdd80609000203796573026e6f0454657374"
 )
 
 func TestEventSerialization_ReturnsExpectedByteArray(t *testing.T) {
@@ -33,7 +34,118 @@ func TestGenerateEventSignature_ReturnsExpectedSignature(t *testing.T) {
 	nonce1, _ := dlccrypto.NewSchnorrPublicKey("f4a731b0d25a291f7bbc33f391003e87dcfae98a7484e37646453725405f7f31")
 	nonces := []dlccrypto.SchnorrPublicKey{*nonce0, *nonce1}
 
-	bs, err := dlccrypto.GenerateEventSignature(privKey, nonces, 1623133104, 2, false, "sats/sec", 0, 10, "Test", cryptoService)
+	pubKey, _ := cryptoService.SchnorrPublicKeyFromPrivateKey(privKey)
+	signer := dlccrypto.NewKeySigner(privKey, pubKey, cryptoService)
+
+	bs, err := dlccrypto.GenerateEventSignature(signer, nonces, 1623133104, 2, false, "sats/sec", 0, 10, "Test")
 	assert.NoError(t, err)
 	assert.Equal(t, validEventSignature, bs)
 }
+
+func TestRoundAndDecomposeValue_ReturnsExpectedDigits(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     float64
+		isSigned  bool
+		precision int
+		expected  []string
+	}{
+		{name: "rounds to nearest", value: 100.6, expected: []string{"1", "0", "1"}},
+		{name: "clamps to max", value: 1500, expected: []string{"9", "9", "9"}},
+		{name: "negative unsigned clamps to zero", value: -12, expected: []string{"0", "0", "0"}},
+		{name: "applies negative precision", value: 1.2345, precision: -2, expected: []string{"1", "2", "3"}},
+		{name: "applies positive precision", value: 12345, precision: 2, expected: []string{"1", "2", "3"}},
+		{name: "positive signed", value: 12, isSigned: true, expected: []string{"+", "0", "1", "2"}},
+		{name: "zero signed", value: 0, isSigned: true, expected: []string{"+", "0", "0", "0"}},
+		{name: "negative signed", value: -0.29, isSigned: true, precision: -2, expected: []string{"-", "0", "2", "9"}},
+		{name: "negative signed clamps to min", value: -5000, isSigned: true, expected: []string{"-", "9", "9", "9"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := dlccrypto.RoundAndDecomposeValue(test.value, 10, 3, test.isSigned, test.precision)
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestGetRoundedDecomposedSignaturesForValue_Signed_SignsSignWithFirstNonce(t *testing.T) {
+	cryptoService := cfddlccrypto.NewCfdgoCryptoService()
+	privKey, pubKey, _ := cryptoService.GenerateSchnorrKeyPair()
+	signer := dlccrypto.NewKeySigner(privKey, pubKey, cryptoService)
+	nonces := make([]dlccrypto.EventNonce, 3)
+	for i := range nonces {
+		k, _, _ := cryptoService.GenerateSchnorrKeyPair()
+		nonces[i] = dlccrypto.EventNonce{Kvalue: k}
+	}
+
+	sigs, values, err := dlccrypto.GetRoundedDecomposedSignaturesForValue(-1.5, 10, 2, true, -1, signer, nonces)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"-", "1", "5"}, values)
+	for i, s := range sigs {
+		sig, _ := dlccrypto.NewSignature(s)
+		valid, err := cryptoService.VerifySchnorrSignature(pubKey, sig, values[i])
+		assert.NoError(t, err)
+		assert.True(t, valid)
+	}
+}
+
+func TestEnumEventSerialization_ReturnsExpectedByteArray(t *testing.T) {
+	nonce, _ := dlccrypto.NewSchnorrPublicKey("abf8f63630a0b1dec98ce8db50e9680f89f3390105454510420048d050aaa05d")
+
+	ser := dlccrypto.SerializeEnumEvent([]dlccrypto.SchnorrPublicKey{*nonce}, 1623133104, []string{"yes", "no"}, "Test")
+
+	assert.Equal(t, validEnumSerialization, hex.EncodeToString(ser))
+}
+
+func TestGetEnumOutcomeSignature_ReturnsValidSignature(t *testing.T) {
+	cryptoService := cfddlccrypto.NewCfdgoCryptoService()
+	privKey, pubKey, _ := cryptoService.GenerateSchnorrKeyPair()
+	k, _, _ := cryptoService.GenerateSchnorrKeyPair()
+	signer := dlccrypto.NewKeySigner(privKey, pubKey, cryptoService)
+
+	s, err := dlccrypto.GetEnumOutcomeSignature("no", []string{"yes", "no"}, signer, &dlccrypto.EventNonce{Kvalue: k})
+
+	assert.NoError(t, err)
+	sig, _ := dlccrypto.NewSignature(s)
+	valid, err := cryptoService.VerifySchnorrSignature(pubKey, sig, "no")
+	assert.NoError(t, err)
+	assert.True(t, valid)
+}
+
+func TestGetEnumOutcomeSignature_UnknownOutcome_ReturnsError(t *testing.T) {
+	cryptoService := cfddlccrypto.NewCfdgoCryptoService()
+	privKey, pubKey, _ := cryptoService.GenerateSchnorrKeyPair()
+	k, _, _ := cryptoService.GenerateSchnorrKeyPair()
+	signer := dlccrypto.NewKeySigner(privKey, pubKey, cryptoService)
+
+	_, err := dlccrypto.GetEnumOutcomeSignature("maybe", []string{"yes", "no"}, signer, &dlccrypto.EventNonce{Kvalue: k})
+
+	assert.Error(t, err)
+}
+
+func TestParseEvent_ReturnsEventData(t *testing.T) {
+	for _, serialization := range []string{validSerialization, validEnumSerialization} {
+		ser, _ := hex.DecodeString(serialization)
+
+		event, err := dlccrypto.ParseEvent(ser)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, "abf8f63630a0b1dec98ce8db50e9680f89f3390105454510420048d050aaa05d", event.Nonces[0].EncodeToString())
+			assert.Equal(t, uint32(1623133104), event.EventMaturity)
+			assert.Equal(t, "Test", event.EventID)
+		}
+	}
+}
+
+func TestParseEvent_InvalidEvent_ReturnsError(t *testing.T) {
+	ser, _ := hex.DecodeString(validSerialization)
+
+	_, err := dlccrypto.ParseEvent(ser[:len(ser)-1])
+	assert.Error(t, err)
+	_, err = dlccrypto.ParseEvent(append(ser, 0))
+	assert.Error(t, err)
+	_, err = dlccrypto.ParseEvent(ser[:40])
+	assert.Error(t, err)
+}
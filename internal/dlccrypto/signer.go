@@ -0,0 +1,70 @@
+package dlccrypto
+
+// Signer computes the signatures of the oracle, its private key being only accessible to the signer
+type Signer interface {
+	// PublicKey returns the schnorr public key of the oracle
+	PublicKey() *SchnorrPublicKey
+	// DeriveSchnorrNonce returns the nonce of an event derived from the oracle private key (see DeriveNonceKey)
+	DeriveSchnorrNonce(assetID string, eventMaturity uint32, index int) (*SchnorrPublicKey, error)
+	// ComputeSchnorrSignature computes a schnorr signature on the given byte buffer message (will be hashed by sha256)
+	ComputeSchnorrSignature(message []byte) (*Signature, error)
+	// ComputeSchnorrSignatureWithNonce computes a schnorr signature on the given message (will be hashed by sha256)
+	// using the one time signing key of the given event nonce
+	ComputeSchnorrSignatureWithNonce(nonce *EventNonce, message string) (*Signature, error)
+}
+
+// EventNonce identifies the one time signing key of a nonce of an event
+type EventNonce struct {
+	AssetID       string
+	EventMaturity uint32
+	Index         int
+	// Kvalue one time signing key stored with the events announced before the nonces were derived,
+	// the key is derived from the oracle private key if nil
+	Kvalue *PrivateKey
+}
+
+// NewKeySigner returns a signer holding the oracle private key in memory
+func NewKeySigner(privateKey *PrivateKey, publicKey *SchnorrPublicKey, cryptoService CryptoService) Signer {
+	return &KeySigner{
+		privateKey:    privateKey,
+		publicKey:     publicKey,
+		cryptoService: cryptoService,
+	}
+}
+
+// KeySigner signer computing the signatures with the crypto service from the private key held in memory
+type KeySigner struct {
+	privateKey    *PrivateKey
+	publicKey     *SchnorrPublicKey
+	cryptoService CryptoService
+}
+
+// PublicKey returns the schnorr public key of the oracle
+func (s *KeySigner) PublicKey() *SchnorrPublicKey {
+	return s.publicKey
+}
+
+// DeriveSchnorrNonce returns the nonce of an event derived from the oracle private key (see DeriveNonceKey)
+func (s *KeySigner) DeriveSchnorrNonce(assetID string, eventMaturity uint32, index int) (*SchnorrPublicKey, error) {
+	_, rvalue, err := s.cryptoService.DeriveSchnorrNonce(s.privateKey, assetID, eventMaturity, index)
+	return rvalue, err
+}
+
+// ComputeSchnorrSignature computes a schnorr signature on the given byte buffer message (will be hashed by sha256)
+func (s *KeySigner) ComputeSchnorrSignature(message []byte) (*Signature, error) {
+	return s.cryptoService.ComputeSchnorrSignature(s.privateKey, message)
+}
+
+// ComputeSchnorrSignatureWithNonce computes a schnorr signature on the given message (will be hashed by sha256)
+// using the one time signing key of the given event nonce
+func (s *KeySigner) ComputeSchnorrSignatureWithNonce(nonce *EventNonce, message string) (*Signature, error) {
+	kvalue := nonce.Kvalue
+	if kvalue == nil {
+		var err error
+		kvalue, _, err = s.cryptoService.DeriveSchnorrNonce(s.privateKey, nonce.AssetID, nonce.EventMaturity, nonce.Index)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s.cryptoService.ComputeSchnorrSignatureFixedK(s.privateKey, kvalue, message)
+}
@@ -0,0 +1,38 @@
+package dlccrypto_test
+
+import (
+	"p2pderivatives-oracle/internal/cfddlccrypto"
+	"p2pderivatives-oracle/internal/dlccrypto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeySigner_ComputeSchnorrSignatureWithNonce_UsesDerivedNonce(t *testing.T) {
+	cryptoService := cfddlccrypto.NewCfdgoCryptoService()
+	privKey, pubKey, _ := cryptoService.GenerateSchnorrKeyPair()
+	signer := dlccrypto.NewKeySigner(privKey, pubKey, cryptoService)
+
+	rvalue, err := signer.DeriveSchnorrNonce("btcusd", 1623133104, 1)
+	assert.NoError(t, err)
+	sig, err := signer.ComputeSchnorrSignatureWithNonce(
+		&dlccrypto.EventNonce{AssetID: "btcusd", EventMaturity: 1623133104, Index: 1}, "1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, rvalue.EncodeToString(), sig.EncodeToString()[:64])
+	valid, err := cryptoService.VerifySchnorrSignature(pubKey, sig, "1")
+	assert.NoError(t, err)
+	assert.True(t, valid)
+}
+
+func TestKeySigner_ComputeSchnorrSignatureWithNonce_UsesStoredKvalue(t *testing.T) {
+	cryptoService := cfddlccrypto.NewCfdgoCryptoService()
+	privKey, pubKey, _ := cryptoService.GenerateSchnorrKeyPair()
+	k, r, _ := cryptoService.GenerateSchnorrKeyPair()
+	signer := dlccrypto.NewKeySigner(privKey, pubKey, cryptoService)
+
+	sig, err := signer.ComputeSchnorrSignatureWithNonce(&dlccrypto.EventNonce{Kvalue: k}, "1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, r.EncodeToString(), sig.EncodeToString()[:64])
+}
@@ -0,0 +1,68 @@
+package dlccrypto
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+const (
+	// EnumEventDescriptorTLVType type of the enum_event_descriptor TLV (see DLC specifications)
+	EnumEventDescriptorTLVType = 55302
+	// DigitDecompositionEventDescriptorTLVType type of the digit_decomposition_event_descriptor TLV (see DLC specifications)
+	DigitDecompositionEventDescriptorTLVType = 55306
+	// OracleEventTLVType type of the oracle_event TLV (see DLC specifications)
+	OracleEventTLVType = 55330
+	// OracleAnnouncementTLVType type of the oracle_announcement TLV (see DLC specifications)
+	OracleAnnouncementTLVType = 55332
+	// OracleAttestationTLVType type of the oracle_attestation TLV (see DLC specifications)
+	OracleAttestationTLVType = 55400
+)
+
+func writeTLV(buf *bytes.Buffer, tlvType uint64, value []byte) {
+	prefix := bigSize{inner: tlvType}
+	prefix.write(buf)
+	length := bigSize{inner: uint64(len(value))}
+	length.write(buf)
+	buf.Write(value)
+}
+
+func writeString(buf *bytes.Buffer, s string) {
+	length := bigSize{inner: uint64(len(s))}
+	length.write(buf)
+	buf.Write([]byte(s))
+}
+
+// SerializeAnnouncement serializes the given data as an oracle_announcement TLV.
+// The event parameter is expected to be the output of SerializeEvent.
+func SerializeAnnouncement(
+	announcementSignature *Signature, oraclePubKey *SchnorrPublicKey, event []byte,
+) []byte {
+	value := new(bytes.Buffer)
+	value.Write(announcementSignature.bytes)
+	value.Write(oraclePubKey.bytes)
+	writeTLV(value, OracleEventTLVType, event)
+
+	buf := new(bytes.Buffer)
+	writeTLV(buf, OracleAnnouncementTLVType, value.Bytes())
+	return buf.Bytes()
+}
+
+// SerializeAttestation serializes the given data as an oracle_attestation TLV.
+func SerializeAttestation(
+	eventID string, oraclePubKey *SchnorrPublicKey, signatures []Signature, outcomes []string,
+) []byte {
+	value := new(bytes.Buffer)
+	writeString(value, eventID)
+	value.Write(oraclePubKey.bytes)
+	binary.Write(value, binary.BigEndian, uint16(len(signatures)))
+	for _, sig := range signatures {
+		value.Write(sig.bytes)
+	}
+	for _, outcome := range outcomes {
+		writeString(value, outcome)
+	}
+
+	buf := new(bytes.Buffer)
+	writeTLV(buf, OracleAttestationTLVType, value.Bytes())
+	return buf.Bytes()
+}
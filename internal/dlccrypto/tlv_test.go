@@ -0,0 +1,35 @@
+package dlccrypto_test
+
+import (
+	"encoding/hex"
+	"p2pderivatives-oracle/internal/dlccrypto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	validAnnouncementTLV = "fdd824c5319dfb9ced3c34242aad5920e1f2862346accfa19f013726beb1d7d1678737805eccecedea7abbe7296ff94a394043a219d3087d2d47fc3cff95d0b9f5595d92d557c15ea53c46245be38a062e33c22c5880f6b776e36695befc6e28a3934beffdd822610002abf8f63630a0b1dec98ce8db50e9680f89f3390105454510420048d050aaa05df4a731b0d25a291f7bbc33f391003e87dcfae98a7484e37646453725405f7f3160bf0bb0fdd80a1200020008736174732f73656300000000000a0454657374"
+	validAttestationTLV  = "fdd868ab0454657374d557c15ea53c46245be38a062e33c22c5880f6b776e36695befc6e28a3934bef00025a00f102a9a2c789046da82a900b4b1b34fcf73dce5ac1063a653c2bf9b3f5f0c50e5b475b7fefc5deac176a91fde56e1fa8c522661af2e6a6ea60b3f3e4d82ed34fba30e1d6f8e82e37ae34ded1f16aac0e1527257201bbb21025ea49c9bdea536c4b9fceeed308bf06d6b4e006555adc481b1e93995a599b1b98f1ac66b19501310130"
+)
+
+func TestSerializeAnnouncement_ReturnsExpectedByteArray(t *testing.T) {
+	sig, _ := dlccrypto.NewSignature(validEventSignature)
+	pubkey, _ := dlccrypto.NewSchnorrPublicKey(validPublicKey)
+	event, _ := hex.DecodeString(validSerialization)
+
+	ser := dlccrypto.SerializeAnnouncement(sig, pubkey, event)
+
+	assert.Equal(t, validAnnouncementTLV, hex.EncodeToString(ser))
+}
+
+func TestSerializeAttestation_ReturnsExpectedByteArray(t *testing.T) {
+	pubkey, _ := dlccrypto.NewSchnorrPublicKey(validPublicKey)
+	sig0, _ := dlccrypto.NewSignature("5a00f102a9a2c789046da82a900b4b1b34fcf73dce5ac1063a653c2bf9b3f5f0c50e5b475b7fefc5deac176a91fde56e1fa8c522661af2e6a6ea60b3f3e4d82e")
+	sig1, _ := dlccrypto.NewSignature("d34fba30e1d6f8e82e37ae34ded1f16aac0e1527257201bbb21025ea49c9bdea536c4b9fceeed308bf06d6b4e006555adc481b1e93995a599b1b98f1ac66b195")
+	sigs := []dlccrypto.Signature{*sig0, *sig1}
+
+	ser := dlccrypto.SerializeAttestation("Test", pubkey, sigs, []string{"1", "0"})
+
+	assert.Equal(t, validAttestationTLV, hex.EncodeToString(ser))
+}
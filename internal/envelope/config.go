@@ -0,0 +1,17 @@
+package envelope
+
+// Config contains the master keys encrypting the secrets stored in database
+type Config struct {
+	// MasterKeys master keys by ID, the keys of previous rollovers being kept to open the values
+	// which were not re-encrypted yet
+	MasterKeys map[string]MasterKeyConfig `configkey:"kvalues.masterKeys" validate:"omitempty,dive"`
+	// ActiveMasterKey ID of the master key used to encrypt new values
+	ActiveMasterKey string `configkey:"kvalues.activeMasterKey"`
+}
+
+// MasterKeyConfig contains a 32 bytes hex encoded master key given either directly
+// in configuration (environment variable) or from the first line of a file
+type MasterKeyConfig struct {
+	Key  string `configkey:"key" validate:"required_without=File"`
+	File string `configkey:"file" validate:"required_without=Key"`
+}
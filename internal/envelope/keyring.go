@@ -0,0 +1,234 @@
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+
+	"github.com/cryptogarageinc/server-common-go/pkg/utils/file"
+	"github.com/pkg/errors"
+)
+
+const (
+	// size of the master and data keys (AES-256)
+	keySize = 32
+	// separator of the master key ID, wrapped data key and ciphertext in a sealed value
+	sealedSeparator = ":"
+)
+
+// ErrNoMasterKey is returned when sealing a value without any configured master key
+var ErrNoMasterKey = errors.New("No master key configured")
+
+// Keyring holds the master keys wrapping the data keys of the sealed values.
+// Each value is encrypted with its own data key (AES-256-GCM), the data key being stored
+// along with the value after being encrypted with the active master key.
+type Keyring struct {
+	masterKeys map[string][]byte
+	activeID   string
+}
+
+// NewKeyring returns a keyring with the given master keys (by ID), new values being
+// sealed with the active one
+func NewKeyring(masterKeys map[string][]byte, activeID string) (*Keyring, error) {
+	keys := make(map[string][]byte, len(masterKeys))
+	for id, key := range masterKeys {
+		if id == "" || strings.Contains(id, sealedSeparator) {
+			return nil, errors.Errorf("Invalid master key ID %q", id)
+		}
+		if len(key) != keySize {
+			return nil, errors.Errorf("Master key %s must be %d bytes long", id, keySize)
+		}
+		keys[id] = append([]byte(nil), key...)
+	}
+	if len(keys) > 0 {
+		if _, ok := keys[activeID]; !ok {
+			return nil, errors.Errorf("Active master key %q is not configured", activeID)
+		}
+	}
+	return &Keyring{masterKeys: keys, activeID: activeID}, nil
+}
+
+// FromConfig returns the keyring of the configured master keys,
+// the keyring being empty (unable to seal values) if no master key is configured
+func FromConfig(config *Config) (*Keyring, error) {
+	masterKeys := make(map[string][]byte, len(config.MasterKeys))
+	for id, keyConfig := range config.MasterKeys {
+		key, err := readMasterKey(&keyConfig)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "Invalid master key %s", id)
+		}
+		masterKeys[id] = key
+	}
+	keyring, err := NewKeyring(masterKeys, config.ActiveMasterKey)
+	for _, key := range masterKeys {
+		Zero(key)
+	}
+	return keyring, err
+}
+
+func readMasterKey(config *MasterKeyConfig) ([]byte, error) {
+	encoded := config.Key
+	if config.File != "" {
+		var err error
+		encoded, err = file.ReadFirstLineFromFile(config.File)
+		if err != nil {
+			return nil, err
+		}
+		if encoded == "" {
+			return nil, errors.Errorf("Could not read master key from %s", config.File)
+		}
+	}
+	return hex.DecodeString(strings.TrimSpace(encoded))
+}
+
+// IsEnabled returns true if the keyring has a master key to seal values with
+func (k *Keyring) IsEnabled() bool {
+	return k != nil && k.activeID != ""
+}
+
+// ActiveMasterKeyID returns the ID of the master key used to seal new values
+func (k *Keyring) ActiveMasterKeyID() string {
+	if k == nil {
+		return ""
+	}
+	return k.activeID
+}
+
+// Seal encrypts the plaintext with a new data key wrapped by the active master key,
+// the associated data (which is not stored) being required to open the sealed value
+func (k *Keyring) Seal(plaintext []byte, associatedData []byte) (string, error) {
+	if !k.IsEnabled() {
+		return "", ErrNoMasterKey
+	}
+	dataKey := make([]byte, keySize)
+	defer Zero(dataKey)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", errors.WithMessage(err, "Error while generating data key")
+	}
+	ciphertext, err := encrypt(dataKey, plaintext, associatedData)
+	if err != nil {
+		return "", err
+	}
+	return k.wrap(dataKey, ciphertext)
+}
+
+// Open decrypts a sealed value, the caller should Zero the returned plaintext once used
+func (k *Keyring) Open(sealed string, associatedData []byte) ([]byte, error) {
+	dataKey, ciphertext, err := k.unwrap(sealed)
+	if err != nil {
+		return nil, err
+	}
+	defer Zero(dataKey)
+	plaintext, err := decrypt(dataKey, ciphertext, associatedData)
+	if err != nil {
+		return nil, errors.WithMessage(err, "Could not decrypt sealed value")
+	}
+	return plaintext, nil
+}
+
+// Rewrap wraps the data key of a sealed value with the active master key
+// without decrypting the value itself
+func (k *Keyring) Rewrap(sealed string) (string, error) {
+	if !k.IsEnabled() {
+		return "", ErrNoMasterKey
+	}
+	dataKey, ciphertext, err := k.unwrap(sealed)
+	if err != nil {
+		return "", err
+	}
+	defer Zero(dataKey)
+	return k.wrap(dataKey, ciphertext)
+}
+
+// MasterKeyID returns the ID of the master key wrapping the data key of a sealed value
+func MasterKeyID(sealed string) (string, error) {
+	parts := strings.Split(sealed, sealedSeparator)
+	if len(parts) != 3 {
+		return "", errors.New("Invalid sealed value format")
+	}
+	return parts[0], nil
+}
+
+func (k *Keyring) wrap(dataKey []byte, ciphertext []byte) (string, error) {
+	// the master key ID is authenticated so that it cannot be swapped
+	wrappedKey, err := encrypt(k.masterKeys[k.activeID], dataKey, []byte(k.activeID))
+	if err != nil {
+		return "", err
+	}
+	return strings.Join([]string{
+		k.activeID,
+		base64.StdEncoding.EncodeToString(wrappedKey),
+		base64.StdEncoding.EncodeToString(ciphertext),
+	}, sealedSeparator), nil
+}
+
+func (k *Keyring) unwrap(sealed string) ([]byte, []byte, error) {
+	id, err := MasterKeyID(sealed)
+	if err != nil {
+		return nil, nil, err
+	}
+	var masterKey []byte
+	if k != nil {
+		masterKey = k.masterKeys[id]
+	}
+	if masterKey == nil {
+		return nil, nil, errors.Errorf("Master key %s is not configured", id)
+	}
+	parts := strings.Split(sealed, sealedSeparator)
+	wrappedKey, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "Invalid wrapped data key")
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "Invalid ciphertext")
+	}
+	dataKey, err := decrypt(masterKey, wrappedKey, []byte(id))
+	if err != nil {
+		return nil, nil, errors.WithMessagef(err, "Could not unwrap data key with master key %s", id)
+	}
+	return dataKey, ciphertext, nil
+}
+
+// encrypt encrypts the plaintext with AES-GCM, the random nonce being prepended to the ciphertext
+func encrypt(key []byte, plaintext []byte, associatedData []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.WithMessage(err, "Error while generating nonce")
+	}
+	return aead.Seal(nonce, nonce, plaintext, associatedData), nil
+}
+
+func decrypt(key []byte, ciphertext []byte, associatedData []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("Ciphertext is too short")
+	}
+	nonce, ciphertext := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, associatedData)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Zero overwrites the given buffer with zeros
+func Zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
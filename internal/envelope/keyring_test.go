@@ -0,0 +1,144 @@
+package envelope_test
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"p2pderivatives-oracle/internal/envelope"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	masterKey1 = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+	masterKey2 = "1f1e1d1c1b1a191817161514131211100f0e0d0c0b0a09080706050403020100"
+)
+
+func newKeyring(t *testing.T, activeID string) *envelope.Keyring {
+	key1, _ := hex.DecodeString(masterKey1)
+	key2, _ := hex.DecodeString(masterKey2)
+	keyring, err := envelope.NewKeyring(map[string][]byte{"key1": key1, "key2": key2}, activeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyring
+}
+
+func TestKeyring_SealOpen_ReturnsPlaintext(t *testing.T) {
+	keyring := newKeyring(t, "key1")
+	plaintext := []byte("secret")
+
+	sealed, err := keyring.Seal(plaintext, []byte("event"))
+
+	assert.NoError(t, err)
+	assert.NotContains(t, sealed, hex.EncodeToString(plaintext))
+	id, err := envelope.MasterKeyID(sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, "key1", id)
+	actual, err := keyring.Open(sealed, []byte("event"))
+	assert.NoError(t, err)
+	assert.Equal(t, plaintext, actual)
+}
+
+func TestKeyring_Open_WithOtherAssociatedData_ReturnsError(t *testing.T) {
+	keyring := newKeyring(t, "key1")
+	sealed, err := keyring.Seal([]byte("secret"), []byte("event"))
+	assert.NoError(t, err)
+
+	_, err = keyring.Open(sealed, []byte("other event"))
+
+	assert.Error(t, err)
+}
+
+func TestKeyring_Open_WithSwappedMasterKeyID_ReturnsError(t *testing.T) {
+	keyring := newKeyring(t, "key1")
+	sealed, err := keyring.Seal([]byte("secret"), []byte("event"))
+	assert.NoError(t, err)
+
+	_, err = keyring.Open(strings.Replace(sealed, "key1", "key2", 1), []byte("event"))
+
+	assert.Error(t, err)
+}
+
+func TestKeyring_Open_WithoutMasterKey_ReturnsError(t *testing.T) {
+	sealed, err := newKeyring(t, "key1").Seal([]byte("secret"), []byte("event"))
+	assert.NoError(t, err)
+	key2, _ := hex.DecodeString(masterKey2)
+	keyring, err := envelope.NewKeyring(map[string][]byte{"key2": key2}, "key2")
+	assert.NoError(t, err)
+
+	_, err = keyring.Open(sealed, []byte("event"))
+
+	assert.Error(t, err)
+}
+
+func TestKeyring_Rewrap_UsesActiveMasterKey(t *testing.T) {
+	sealed, err := newKeyring(t, "key1").Seal([]byte("secret"), []byte("event"))
+	assert.NoError(t, err)
+	keyring := newKeyring(t, "key2")
+
+	rewrapped, err := keyring.Rewrap(sealed)
+
+	assert.NoError(t, err)
+	id, err := envelope.MasterKeyID(rewrapped)
+	assert.NoError(t, err)
+	assert.Equal(t, "key2", id)
+	// the value itself is not re-encrypted
+	assert.Equal(t, sealed[strings.LastIndex(sealed, ":"):], rewrapped[strings.LastIndex(rewrapped, ":"):])
+	actual, err := keyring.Open(rewrapped, []byte("event"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("secret"), actual)
+}
+
+func TestKeyring_Seal_WithoutMasterKey_ReturnsError(t *testing.T) {
+	keyring, err := envelope.FromConfig(&envelope.Config{})
+	assert.NoError(t, err)
+	assert.False(t, keyring.IsEnabled())
+
+	_, err = keyring.Seal([]byte("secret"), nil)
+
+	assert.Equal(t, envelope.ErrNoMasterKey, err)
+}
+
+func TestNewKeyring_WithInvalidParameters_ReturnsError(t *testing.T) {
+	key, _ := hex.DecodeString(masterKey1)
+	_, err := envelope.NewKeyring(map[string][]byte{"key1": key}, "key2")
+	assert.Error(t, err)
+	_, err = envelope.NewKeyring(map[string][]byte{"key1": key[:16]}, "key1")
+	assert.Error(t, err)
+	_, err = envelope.NewKeyring(map[string][]byte{"key:1": key}, "key:1")
+	assert.Error(t, err)
+}
+
+func TestFromConfig_WithKeyFile_ReturnsKeyring(t *testing.T) {
+	dir, err := ioutil.TempDir("", "envelope")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	keyFile := filepath.Join(dir, "master.key")
+	if err := ioutil.WriteFile(keyFile, []byte(masterKey2+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	config := &envelope.Config{
+		MasterKeys: map[string]envelope.MasterKeyConfig{
+			"key1": {Key: masterKey1},
+			"key2": {File: keyFile},
+		},
+		ActiveMasterKey: "key2",
+	}
+
+	keyring, err := envelope.FromConfig(config)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "key2", keyring.ActiveMasterKeyID())
+		sealed, err := keyring.Seal([]byte("secret"), nil)
+		assert.NoError(t, err)
+		actual, err := newKeyring(t, "key1").Open(sealed, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("secret"), actual)
+	}
+}
@@ -0,0 +1,87 @@
+package exchange
+
+import (
+	"fmt"
+	"p2pderivatives-oracle/internal/datafeed"
+	"time"
+
+	"github.com/cryptogarageinc/server-common-go/pkg/log"
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+)
+
+// BinanceSource source name of the prices returned by the binance client
+const BinanceSource = "binance"
+
+const binanceKlinesRoute = "/api/v3/klines"
+
+const binanceMaxCandles = 1000
+
+// BinanceConfig represents the binance client configuration
+type BinanceConfig struct {
+	APIBaseURL   string                        `configkey:"binance.baseUrl" default:"https://api.binance.com"`
+	AssetsConfig map[string]BinanceAssetConfig `configkey:"binance.assetsConfig" validate:"required"`
+}
+
+// BinanceAssetConfig contains the request parameters to use for an asset
+type BinanceAssetConfig struct {
+	// Symbol binance symbol (e.g. BTCUSDT)
+	Symbol string `configkey:"symbol" validate:"required"`
+}
+
+// NewBinanceClient returns a datafeed retrieving the prices from the binance klines endpoint
+func NewBinanceClient(l *log.Log, config *BinanceConfig) *Client {
+	return newClient(l, BinanceSource, config.APIBaseURL, &binanceAPI{config: config})
+}
+
+type binanceAPI struct {
+	config *BinanceConfig
+}
+
+func (b *binanceAPI) findCandles(httpClient *resty.Client, assetID string, from time.Time, to time.Time, now time.Time) ([]datafeed.Candle, time.Duration, string, error) {
+	assetConfig, ok := b.config.AssetsConfig[assetID]
+	if !ok {
+		return nil, 0, "", missingAssetConfigError(BinanceSource, assetID)
+	}
+	interval := time.Minute
+	source := fmt.Sprintf("%s?symbol=%s&interval=1m", binanceKlinesRoute, assetConfig.Symbol)
+	limit, err := candleCount(BinanceSource, from, to, interval, binanceMaxCandles)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	route := fmt.Sprintf("%s&limit=%d&startTime=%d", source, limit, candleStart(from, interval).Unix()*1000)
+	// [open time (ms), open, high, low, close, volume, close time, quote asset volume, ...], prices being strings
+	rows := [][]interface{}{}
+	if err := get(httpClient, BinanceSource, route, &rows); err != nil {
+		return nil, 0, "", err
+	}
+
+	candles := make([]datafeed.Candle, 0, len(rows))
+	for _, row := range rows {
+		if len(row) < 8 {
+			return nil, 0, "", errors.New("invalid binance candle")
+		}
+		openTime, ok := row[0].(float64)
+		if !ok {
+			return nil, 0, "", errors.Errorf("invalid binance candle time %v", row[0])
+		}
+		// open, high, low, close, volume and quote asset volume
+		prices, err := parsePrices(BinanceSource, row[1], row[2], row[3], row[4], row[5], row[7])
+		if err != nil {
+			return nil, 0, "", err
+		}
+		candle := datafeed.Candle{
+			Time:   time.Unix(int64(openTime)/1000, 0).UTC(),
+			Open:   prices[0],
+			High:   prices[1],
+			Low:    prices[2],
+			Close:  prices[3],
+			Volume: prices[4],
+		}
+		if prices[4] > 0 {
+			candle.VWAP = prices[5] / prices[4]
+		}
+		candles = append(candles, candle)
+	}
+	return candles, interval, source, nil
+}
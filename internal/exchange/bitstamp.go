@@ -0,0 +1,93 @@
+package exchange
+
+import (
+	"fmt"
+	"p2pderivatives-oracle/internal/datafeed"
+	"strconv"
+	"time"
+
+	"github.com/cryptogarageinc/server-common-go/pkg/log"
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+)
+
+// BitstampSource source name of the prices returned by the bitstamp client
+const BitstampSource = "bitstamp"
+
+const bitstampOHLCRoute = "/api/v2/ohlc/%s/"
+
+const bitstampMaxCandles = 1000
+
+// BitstampConfig represents the bitstamp client configuration
+type BitstampConfig struct {
+	APIBaseURL   string                         `configkey:"bitstamp.baseUrl" default:"https://www.bitstamp.net"`
+	AssetsConfig map[string]BitstampAssetConfig `configkey:"bitstamp.assetsConfig" validate:"required"`
+}
+
+// BitstampAssetConfig contains the request parameters to use for an asset
+type BitstampAssetConfig struct {
+	// Pair bitstamp currency pair (e.g. btcusd)
+	Pair string `configkey:"pair" validate:"required"`
+}
+
+// NewBitstampClient returns a datafeed retrieving the prices from the bitstamp OHLC endpoint
+func NewBitstampClient(l *log.Log, config *BitstampConfig) *Client {
+	return newClient(l, BitstampSource, config.APIBaseURL, &bitstampAPI{config: config})
+}
+
+type bitstampAPI struct {
+	config *BitstampConfig
+}
+
+type bitstampOHLCResponse struct {
+	Data struct {
+		Pair string `json:"pair"`
+		OHLC []struct {
+			Timestamp string `json:"timestamp"`
+			Open      string `json:"open"`
+			High      string `json:"high"`
+			Low       string `json:"low"`
+			Close     string `json:"close"`
+			Volume    string `json:"volume"`
+		} `json:"ohlc"`
+	} `json:"data"`
+}
+
+func (b *bitstampAPI) findCandles(httpClient *resty.Client, assetID string, from time.Time, to time.Time, now time.Time) ([]datafeed.Candle, time.Duration, string, error) {
+	assetConfig, ok := b.config.AssetsConfig[assetID]
+	if !ok {
+		return nil, 0, "", missingAssetConfigError(BitstampSource, assetID)
+	}
+	interval := time.Minute
+	source := fmt.Sprintf(bitstampOHLCRoute+"?step=%d", assetConfig.Pair, int(interval.Seconds()))
+	limit, err := candleCount(BitstampSource, from, to, interval, bitstampMaxCandles)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	res := &bitstampOHLCResponse{}
+	route := fmt.Sprintf("%s&limit=%d&start=%d", source, limit, candleStart(from, interval).Unix())
+	if err := get(httpClient, BitstampSource, route, res); err != nil {
+		return nil, 0, "", err
+	}
+
+	candles := make([]datafeed.Candle, 0, len(res.Data.OHLC))
+	for _, row := range res.Data.OHLC {
+		timestamp, err := strconv.ParseInt(row.Timestamp, 10, 64)
+		if err != nil {
+			return nil, 0, "", errors.WithMessage(err, "invalid bitstamp candle time")
+		}
+		prices, err := parsePrices(BitstampSource, row.Open, row.High, row.Low, row.Close, row.Volume)
+		if err != nil {
+			return nil, 0, "", err
+		}
+		candles = append(candles, datafeed.Candle{
+			Time:   time.Unix(timestamp, 0).UTC(),
+			Open:   prices[0],
+			High:   prices[1],
+			Low:    prices[2],
+			Close:  prices[3],
+			Volume: prices[4],
+		})
+	}
+	return candles, interval, source, nil
+}
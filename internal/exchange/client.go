@@ -0,0 +1,171 @@
+package exchange
+
+import (
+	"fmt"
+	"p2pderivatives-oracle/internal/datafeed"
+	"strconv"
+	"time"
+
+	"github.com/cryptogarageinc/server-common-go/pkg/log"
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+)
+
+// exchangeAPI is implemented for each exchange to retrieve its candles
+type exchangeAPI interface {
+	// findCandles returns the candles from the one containing from to the one containing to (if available)
+	// with their duration and the route used as price source
+	findCandles(httpClient *resty.Client, assetID string, from time.Time, to time.Time, now time.Time) ([]datafeed.Candle, time.Duration, string, error)
+}
+
+// Client represents a datafeed retrieving the prices of the assets from the historical OHLC endpoint of an exchange,
+// the price at a date being the close of the candle containing it (like the cryptocompare datafeed)
+type Client struct {
+	name       string
+	api        exchangeAPI
+	httpClient *resty.Client
+	log        *log.Log
+	// clock of the client, only replaced in tests
+	now func() time.Time
+}
+
+func newClient(l *log.Log, name string, baseURL string, api exchangeAPI) *Client {
+	httpClient := resty.New()
+	httpClient.SetHostURL(baseURL)
+	httpClient.SetHeader("Accept", "application/json")
+	return &Client{
+		name:       name,
+		api:        api,
+		httpClient: httpClient,
+		log:        l,
+		now:        time.Now,
+	}
+}
+
+// SetTimeout sets the timeout of the requests sent to the exchange api
+func (c *Client) SetTimeout(timeout time.Duration) {
+	c.httpClient.SetTimeout(timeout)
+}
+
+// FindCurrentAssetPrice returns the close of the current candle of the asset
+func (c *Client) FindCurrentAssetPrice(assetID string) (*float64, error) {
+	record, err := c.findPrice(assetID, c.now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	return &record.Price, nil
+}
+
+// FindPastAssetPrice returns the close of the candle of the asset containing the given date
+func (c *Client) FindPastAssetPrice(assetID string, date time.Time) (*float64, error) {
+	record, err := c.FindPastAssetPriceRecord(assetID, date)
+	if err != nil {
+		return nil, err
+	}
+	return &record.Price, nil
+}
+
+// FindPastAssetPriceRecord returns the close of the candle of the asset containing the given date
+// along with the candle used
+func (c *Client) FindPastAssetPriceRecord(assetID string, date time.Time) (*datafeed.PriceRecord, error) {
+	if c.now().Before(date) {
+		return nil, errors.New("date should be before now")
+	}
+	return c.findPrice(assetID, date)
+}
+
+// FindPastAssetPriceSeries returns the candles of the asset within the period
+func (c *Client) FindPastAssetPriceSeries(assetID string, from time.Time, to time.Time) ([]datafeed.Candle, error) {
+	if c.now().Before(to) {
+		return nil, errors.New("date should be before now")
+	}
+	if !from.Before(to) {
+		return nil, errors.New("start of the period should be before its end")
+	}
+	candles, interval, _, err := c.api.findCandles(c.httpClient, assetID, from, to, c.now())
+	if err != nil {
+		return nil, err
+	}
+	for i := range candles {
+		candles[i].Interval = interval
+	}
+	return datafeed.CandlesWithin(candles, from, to), nil
+}
+
+// FindPastOutcome is not supported by exchanges which only provide prices
+// (see datafeed.NewStrikeOutcomeFeed to resolve an enumerated event from a price)
+func (c *Client) FindPastOutcome(assetID string, date time.Time, outcomes []string) (*string, error) {
+	return nil, errors.Errorf("%s cannot resolve outcome of asset %v", c.name, assetID)
+}
+
+func (c *Client) findPrice(assetID string, date time.Time) (*datafeed.PriceRecord, error) {
+	candles, interval, route, err := c.api.findCandles(c.httpClient, assetID, date, date, c.now())
+	if err != nil {
+		return nil, err
+	}
+	for _, candle := range candles {
+		if !candle.Time.After(date) && date.Before(candle.Time.Add(interval)) {
+			candle.Interval = interval
+			return &datafeed.PriceRecord{
+				Price:     candle.Close,
+				Source:    c.name + route,
+				Timestamp: candle.Time,
+				Candle:    &candle,
+			}, nil
+		}
+	}
+	c.log.Logger.Errorln("No candle containing", date, "for route", route)
+	return nil, errors.Errorf("%s response did not contain the candle of %s", c.name, date.String())
+}
+
+// get sends a GET request to the exchange api, decoding the response in result
+// (the error responses being returned as datafeed.SourceError)
+func get(httpClient *resty.Client, exchange string, route string, result interface{}) error {
+	resp, err := httpClient.R().SetResult(result).Get(route)
+	if err != nil {
+		return datafeed.NewUnavailableError(exchange, err)
+	}
+	if resp.IsError() {
+		return datafeed.NewHTTPError(exchange, resp.StatusCode(), resp.Header(), resp.String())
+	}
+	return nil
+}
+
+// parsePrices parses the prices of a candle returned as strings
+func parsePrices(exchange string, values ...interface{}) ([]float64, error) {
+	prices := make([]float64, len(values))
+	for i, value := range values {
+		str, ok := value.(string)
+		if !ok {
+			return nil, errors.Errorf("invalid %s candle price %v", exchange, value)
+		}
+		price, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "invalid %s candle price", exchange)
+		}
+		prices[i] = price
+	}
+	return prices, nil
+}
+
+// candleStart returns the opening time of the candle of the given duration containing the date
+func candleStart(date time.Time, interval time.Duration) time.Time {
+	return date.Truncate(interval)
+}
+
+// candleCount returns the number of candles of the given duration from the one containing from
+// to the one containing to, which should not exceed the maximum number of candles returned by the exchange
+func candleCount(exchange string, from time.Time, to time.Time, interval time.Duration, max int) (int, error) {
+	count := int((to.Sub(candleStart(from, interval)) + interval - 1) / interval)
+	if count < 1 {
+		count = 1
+	}
+	if count > max {
+		return 0, errors.Errorf("%s cannot return more than %d candles", exchange, max)
+	}
+	return count, nil
+}
+
+func missingAssetConfigError(exchange string, assetID string) error {
+	return errors.New(fmt.Sprintf("No %s config found for asset %v", exchange, assetID))
+}
@@ -0,0 +1,306 @@
+package exchange
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"p2pderivatives-oracle/internal/datafeed"
+	"p2pderivatives-oracle/test"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	// date of the recorded candles
+	fixtureDate      = time.Date(2021, time.June, 1, 8, 0, 30, 0, time.UTC)
+	fixtureCandle    = time.Date(2021, time.June, 1, 8, 0, 0, 0, time.UTC)
+	fixtureTestClock = func() time.Time { return fixtureDate.Add(time.Hour) }
+)
+
+// newFixtureServer returns a server answering the requests on the given path with a recorded response,
+// the query of the last request being stored in query
+func newFixtureServer(t *testing.T, path string, status int, fixture string, query *url.Values) *httptest.Server {
+	body, err := ioutil.ReadFile(filepath.Join(test.VectorsDirectoryPath, "exchange", fixture))
+	require.NoError(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		*query = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestKrakenClient_FindPastAssetPriceRecord_ReturnsCandleContainingDate(t *testing.T) {
+	query := url.Values{}
+	server := newFixtureServer(t, krakenOHLCRoute, http.StatusOK, "kraken_ohlc.json", &query)
+	client := NewKrakenClient(test.NewLogger(), &KrakenConfig{
+		APIBaseURL:   server.URL,
+		AssetsConfig: map[string]KrakenAssetConfig{"btcjpy": {Pair: "XBTJPY"}},
+	})
+	client.now = fixtureTestClock
+
+	record, err := client.FindPastAssetPriceRecord("btcjpy", fixtureDate)
+
+	require.NoError(t, err)
+	assert.Equal(t, 4012500.5, record.Price)
+	assert.Equal(t, fixtureCandle, record.Timestamp)
+	assert.Equal(t, &datafeed.Candle{
+		Time:     fixtureCandle,
+		Interval: time.Minute,
+		Open:     4012345.0,
+		High:     4013000.0,
+		Low:      4011000.0,
+		Close:    4012500.5,
+		VWAP:     4012200.1,
+		Volume:   0.8421,
+	}, record.Candle)
+	assert.Equal(t, "kraken/0/public/OHLC?pair=XBTJPY&interval=1", record.Source)
+	assert.Equal(t, "XBTJPY", query.Get("pair"))
+	assert.Equal(t, "1", query.Get("interval"))
+	assert.Equal(t, "1622534340", query.Get("since"))
+}
+
+func TestKrakenClient_OldDate_UsesLargerInterval(t *testing.T) {
+	tests := []struct {
+		age      time.Duration
+		interval string
+	}{
+		{age: 2 * time.Hour, interval: "1"},
+		{age: 48 * time.Hour, interval: "60"},
+		{age: 100 * 24 * time.Hour, interval: "1440"},
+	}
+	for _, tt := range tests {
+		query := url.Values{}
+		server := newFixtureServer(t, krakenOHLCRoute, http.StatusOK, "kraken_ohlc.json", &query)
+		client := NewKrakenClient(test.NewLogger(), &KrakenConfig{
+			APIBaseURL:   server.URL,
+			AssetsConfig: map[string]KrakenAssetConfig{"btcjpy": {Pair: "XBTJPY"}},
+		})
+		client.now = func() time.Time { return fixtureDate.Add(tt.age) }
+
+		// the recorded candles are minute candles, only their request is checked
+		client.FindPastAssetPriceRecord("btcjpy", fixtureDate)
+
+		assert.Equal(t, tt.interval, query.Get("interval"))
+	}
+}
+
+func TestKrakenClient_DateOlderThanDailyCandles_ReturnsError(t *testing.T) {
+	client := NewKrakenClient(test.NewLogger(), &KrakenConfig{
+		APIBaseURL:   "http://localhost",
+		AssetsConfig: map[string]KrakenAssetConfig{"btcjpy": {Pair: "XBTJPY"}},
+	})
+	client.now = func() time.Time { return fixtureDate.Add(3 * 365 * 24 * time.Hour) }
+
+	_, err := client.FindPastAssetPriceRecord("btcjpy", fixtureDate)
+
+	assert.Error(t, err)
+}
+
+func TestKrakenClient_ErrorPayload_ReturnsError(t *testing.T) {
+	query := url.Values{}
+	server := newFixtureServer(t, krakenOHLCRoute, http.StatusOK, "kraken_error.json", &query)
+	client := NewKrakenClient(test.NewLogger(), &KrakenConfig{
+		APIBaseURL:   server.URL,
+		AssetsConfig: map[string]KrakenAssetConfig{"btcjpy": {Pair: "XBTXXX"}},
+	})
+	client.now = fixtureTestClock
+
+	_, err := client.FindPastAssetPriceRecord("btcjpy", fixtureDate)
+
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Unknown asset pair")
+	}
+}
+
+func TestKrakenClient_RateLimitPayload_ReturnsTemporaryError(t *testing.T) {
+	query := url.Values{}
+	server := newFixtureServer(t, krakenOHLCRoute, http.StatusOK, "kraken_rate_limit.json", &query)
+	client := NewKrakenClient(test.NewLogger(), &KrakenConfig{
+		APIBaseURL:   server.URL,
+		AssetsConfig: map[string]KrakenAssetConfig{"btcjpy": {Pair: "XBTJPY"}},
+	})
+	client.now = fixtureTestClock
+
+	_, err := client.FindPastAssetPriceRecord("btcjpy", fixtureDate)
+
+	assert.True(t, datafeed.IsTemporary(err))
+}
+
+func TestBitstampClient_FindPastAssetPriceRecord_ReturnsCandleContainingDate(t *testing.T) {
+	query := url.Values{}
+	server := newFixtureServer(t, "/api/v2/ohlc/btcusd/", http.StatusOK, "bitstamp_ohlc.json", &query)
+	client := NewBitstampClient(test.NewLogger(), &BitstampConfig{
+		APIBaseURL:   server.URL,
+		AssetsConfig: map[string]BitstampAssetConfig{"btcusd": {Pair: "btcusd"}},
+	})
+	client.now = fixtureTestClock
+
+	record, err := client.FindPastAssetPriceRecord("btcusd", fixtureDate)
+
+	require.NoError(t, err)
+	assert.Equal(t, 36512.34, record.Price)
+	assert.Equal(t, fixtureCandle, record.Timestamp)
+	assert.Equal(t, 36490.0, record.Candle.Open)
+	assert.Equal(t, 36540.17, record.Candle.High)
+	assert.Equal(t, 36481.20, record.Candle.Low)
+	assert.Equal(t, "bitstamp/api/v2/ohlc/btcusd/?step=60", record.Source)
+	assert.Equal(t, "1622534400", query.Get("start"))
+	assert.Equal(t, "1", query.Get("limit"))
+}
+
+func TestCoinbaseClient_FindPastAssetPriceRecord_ReturnsCandleContainingDate(t *testing.T) {
+	query := url.Values{}
+	server := newFixtureServer(t, "/products/BTC-USD/candles", http.StatusOK, "coinbase_candles.json", &query)
+	client := NewCoinbaseClient(test.NewLogger(), &CoinbaseConfig{
+		APIBaseURL:   server.URL,
+		AssetsConfig: map[string]CoinbaseAssetConfig{"btcusd": {ProductID: "BTC-USD"}},
+	})
+	client.now = fixtureTestClock
+
+	record, err := client.FindPastAssetPriceRecord("btcusd", fixtureDate)
+
+	require.NoError(t, err)
+	// the candles are returned from the most recent one
+	assert.Equal(t, 36515.02, record.Price)
+	assert.Equal(t, fixtureCandle, record.Timestamp)
+	assert.Equal(t, 36495.01, record.Candle.Open)
+	assert.Equal(t, 36488.5, record.Candle.Low)
+	assert.Equal(t, "coinbase/products/BTC-USD/candles?granularity=60", record.Source)
+	assert.Equal(t, "2021-06-01T08:00:00Z", query.Get("start"))
+	assert.Equal(t, "2021-06-01T08:01:00Z", query.Get("end"))
+}
+
+func TestBinanceClient_FindPastAssetPriceRecord_ReturnsCandleContainingDate(t *testing.T) {
+	query := url.Values{}
+	server := newFixtureServer(t, binanceKlinesRoute, http.StatusOK, "binance_klines.json", &query)
+	client := NewBinanceClient(test.NewLogger(), &BinanceConfig{
+		APIBaseURL:   server.URL,
+		AssetsConfig: map[string]BinanceAssetConfig{"btcusdt": {Symbol: "BTCUSDT"}},
+	})
+	client.now = fixtureTestClock
+
+	record, err := client.FindPastAssetPriceRecord("btcusdt", fixtureDate)
+
+	require.NoError(t, err)
+	assert.Equal(t, 36520.55, record.Price)
+	assert.Equal(t, fixtureCandle, record.Timestamp)
+	assert.Equal(t, 36498.99, record.Candle.Open)
+	assert.InDelta(t, 1909818.03845371/52.3194, record.Candle.VWAP, 1e-6)
+	assert.Equal(t, "binance/api/v3/klines?symbol=BTCUSDT&interval=1m", record.Source)
+	assert.Equal(t, "1622534400000", query.Get("startTime"))
+}
+
+func TestCoinbaseClient_FindPastAssetPriceSeries_ReturnsCandlesOfPeriodOrderedByTime(t *testing.T) {
+	query := url.Values{}
+	server := newFixtureServer(t, "/products/BTC-USD/candles", http.StatusOK, "coinbase_candles.json", &query)
+	client := NewCoinbaseClient(test.NewLogger(), &CoinbaseConfig{
+		APIBaseURL:   server.URL,
+		AssetsConfig: map[string]CoinbaseAssetConfig{"btcusd": {ProductID: "BTC-USD"}},
+	})
+	client.now = fixtureTestClock
+
+	candles, err := client.FindPastAssetPriceSeries("btcusd", fixtureCandle, fixtureCandle.Add(2*time.Minute))
+
+	require.NoError(t, err)
+	require.Len(t, candles, 2)
+	assert.Equal(t, fixtureCandle, candles[0].Time)
+	assert.Equal(t, time.Minute, candles[0].Interval)
+	assert.Equal(t, 6.89171, candles[0].Volume)
+	assert.Equal(t, 36549.87, candles[1].Close)
+	assert.Equal(t, "2021-06-01T08:00:00Z", query.Get("start"))
+	assert.Equal(t, "2021-06-01T08:02:00Z", query.Get("end"))
+}
+
+func TestClient_FindPastAssetPriceSeries_TooManyCandles_ReturnsError(t *testing.T) {
+	client := NewCoinbaseClient(test.NewLogger(), &CoinbaseConfig{
+		APIBaseURL:   "http://localhost",
+		AssetsConfig: map[string]CoinbaseAssetConfig{"btcusd": {ProductID: "BTC-USD"}},
+	})
+	client.now = fixtureTestClock
+
+	_, err := client.FindPastAssetPriceSeries("btcusd", fixtureCandle.Add(-6*time.Hour), fixtureCandle)
+
+	assert.Error(t, err)
+}
+
+func TestClient_ErrorResponses_ReturnError(t *testing.T) {
+	tests := []struct {
+		name      string
+		path      string
+		fixture   string
+		status    int
+		newClient func(url string) *Client
+	}{
+		{name: "bitstamp", path: "/api/v2/ohlc/btcxxx/", fixture: "bitstamp_error.json", status: http.StatusNotFound,
+			newClient: func(url string) *Client {
+				return NewBitstampClient(test.NewLogger(), &BitstampConfig{
+					APIBaseURL: url, AssetsConfig: map[string]BitstampAssetConfig{"btcusd": {Pair: "btcxxx"}}})
+			}},
+		{name: "coinbase", path: "/products/BTC-XXX/candles", fixture: "coinbase_error.json", status: http.StatusNotFound,
+			newClient: func(url string) *Client {
+				return NewCoinbaseClient(test.NewLogger(), &CoinbaseConfig{
+					APIBaseURL: url, AssetsConfig: map[string]CoinbaseAssetConfig{"btcusd": {ProductID: "BTC-XXX"}}})
+			}},
+		{name: "binance", path: binanceKlinesRoute, fixture: "binance_error.json", status: http.StatusBadRequest,
+			newClient: func(url string) *Client {
+				return NewBinanceClient(test.NewLogger(), &BinanceConfig{
+					APIBaseURL: url, AssetsConfig: map[string]BinanceAssetConfig{"btcusd": {Symbol: "BTCXXX"}}})
+			}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := url.Values{}
+			server := newFixtureServer(t, tt.path, tt.status, tt.fixture, &query)
+			client := tt.newClient(server.URL)
+			client.now = fixtureTestClock
+
+			_, err := client.FindPastAssetPriceRecord("btcusd", fixtureDate)
+
+			if assert.Error(t, err) {
+				assert.False(t, datafeed.IsTemporary(err))
+			}
+		})
+	}
+}
+
+func TestClient_UnknownAssetOrFutureDate_ReturnsError(t *testing.T) {
+	client := NewBinanceClient(test.NewLogger(), &BinanceConfig{
+		APIBaseURL:   "http://localhost",
+		AssetsConfig: map[string]BinanceAssetConfig{"btcusd": {Symbol: "BTCUSDT"}},
+	})
+	client.now = fixtureTestClock
+
+	_, err := client.FindPastAssetPriceRecord("btcjpy", fixtureDate)
+	assert.Error(t, err)
+	_, err = client.FindPastAssetPriceRecord("btcusd", fixtureTestClock().Add(time.Minute))
+	assert.Error(t, err)
+	_, err = client.FindPastOutcome("btcusd", fixtureDate, []string{"below", "above"})
+	assert.Error(t, err)
+}
+
+func TestClient_FindCurrentAssetPrice_ReturnsCurrentCandleClose(t *testing.T) {
+	query := url.Values{}
+	server := newFixtureServer(t, binanceKlinesRoute, http.StatusOK, "binance_klines.json", &query)
+	client := NewBinanceClient(test.NewLogger(), &BinanceConfig{
+		APIBaseURL:   server.URL,
+		AssetsConfig: map[string]BinanceAssetConfig{"btcusdt": {Symbol: "BTCUSDT"}},
+	})
+	client.now = func() time.Time { return fixtureDate }
+
+	price, err := client.FindCurrentAssetPrice("btcusdt")
+
+	require.NoError(t, err)
+	assert.Equal(t, 36520.55, *price)
+}
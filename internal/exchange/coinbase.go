@@ -0,0 +1,76 @@
+package exchange
+
+import (
+	"fmt"
+	"p2pderivatives-oracle/internal/datafeed"
+	"time"
+
+	"github.com/cryptogarageinc/server-common-go/pkg/log"
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+)
+
+// CoinbaseSource source name of the prices returned by the coinbase client
+const CoinbaseSource = "coinbase"
+
+const coinbaseCandlesRoute = "/products/%s/candles"
+
+const coinbaseMaxCandles = 300
+
+// CoinbaseConfig represents the coinbase exchange client configuration
+type CoinbaseConfig struct {
+	APIBaseURL   string                         `configkey:"coinbase.baseUrl" default:"https://api.exchange.coinbase.com"`
+	AssetsConfig map[string]CoinbaseAssetConfig `configkey:"coinbase.assetsConfig" validate:"required"`
+}
+
+// CoinbaseAssetConfig contains the request parameters to use for an asset
+type CoinbaseAssetConfig struct {
+	// ProductID coinbase product (e.g. BTC-USD)
+	ProductID string `configkey:"productId" validate:"required"`
+}
+
+// NewCoinbaseClient returns a datafeed retrieving the prices from the coinbase exchange candles endpoint
+func NewCoinbaseClient(l *log.Log, config *CoinbaseConfig) *Client {
+	return newClient(l, CoinbaseSource, config.APIBaseURL, &coinbaseAPI{config: config})
+}
+
+type coinbaseAPI struct {
+	config *CoinbaseConfig
+}
+
+func (c *coinbaseAPI) findCandles(httpClient *resty.Client, assetID string, from time.Time, to time.Time, now time.Time) ([]datafeed.Candle, time.Duration, string, error) {
+	assetConfig, ok := c.config.AssetsConfig[assetID]
+	if !ok {
+		return nil, 0, "", missingAssetConfigError(CoinbaseSource, assetID)
+	}
+	interval := time.Minute
+	source := fmt.Sprintf(coinbaseCandlesRoute+"?granularity=%d", assetConfig.ProductID, int(interval.Seconds()))
+	count, err := candleCount(CoinbaseSource, from, to, interval, coinbaseMaxCandles)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	start := candleStart(from, interval).UTC()
+	end := start.Add(time.Duration(count) * interval)
+	route := fmt.Sprintf("%s&start=%s&end=%s", source, start.Format(time.RFC3339), end.Format(time.RFC3339))
+	// [time, low, high, open, close, volume] from the most recent candle
+	rows := [][]float64{}
+	if err := get(httpClient, CoinbaseSource, route, &rows); err != nil {
+		return nil, 0, "", err
+	}
+
+	candles := make([]datafeed.Candle, 0, len(rows))
+	for _, row := range rows {
+		if len(row) < 6 {
+			return nil, 0, "", errors.New("invalid coinbase candle")
+		}
+		candles = append(candles, datafeed.Candle{
+			Time:   time.Unix(int64(row[0]), 0).UTC(),
+			Low:    row[1],
+			High:   row[2],
+			Open:   row[3],
+			Close:  row[4],
+			Volume: row[5],
+		})
+	}
+	return candles, interval, source, nil
+}
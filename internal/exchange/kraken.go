@@ -0,0 +1,143 @@
+package exchange
+
+import (
+	"encoding/json"
+	"fmt"
+	"p2pderivatives-oracle/internal/datafeed"
+	"strings"
+	"time"
+
+	"github.com/cryptogarageinc/server-common-go/pkg/log"
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+)
+
+// KrakenSource source name of the prices returned by the kraken client
+const KrakenSource = "kraken"
+
+const krakenOHLCRoute = "/0/public/OHLC"
+
+// kraken only returns the last 720 candles of an interval, so the smallest interval
+// whose candles still include the requested date is used
+const krakenMaxCandles = 720
+
+var krakenIntervals = []time.Duration{time.Minute, time.Hour, 24 * time.Hour}
+
+// KrakenConfig represents the kraken client configuration
+type KrakenConfig struct {
+	APIBaseURL   string                       `configkey:"kraken.baseUrl" default:"https://api.kraken.com"`
+	AssetsConfig map[string]KrakenAssetConfig `configkey:"kraken.assetsConfig" validate:"required"`
+}
+
+// KrakenAssetConfig contains the request parameters to use for an asset
+type KrakenAssetConfig struct {
+	// Pair kraken asset pair (e.g. XBTUSD, XBTJPY)
+	Pair string `configkey:"pair" validate:"required"`
+}
+
+// NewKrakenClient returns a datafeed retrieving the prices from the kraken OHLC endpoint
+func NewKrakenClient(l *log.Log, config *KrakenConfig) *Client {
+	return newClient(l, KrakenSource, config.APIBaseURL, &krakenAPI{config: config})
+}
+
+type krakenAPI struct {
+	config *KrakenConfig
+}
+
+// krakenOHLCResponse result contains the candles under the name of the pair and the "last" candle ID
+type krakenOHLCResponse struct {
+	Error  []string                   `json:"error"`
+	Result map[string]json.RawMessage `json:"result"`
+}
+
+func (k *krakenAPI) findCandles(httpClient *resty.Client, assetID string, from time.Time, to time.Time, now time.Time) ([]datafeed.Candle, time.Duration, string, error) {
+	assetConfig, ok := k.config.AssetsConfig[assetID]
+	if !ok {
+		return nil, 0, "", missingAssetConfigError(KrakenSource, assetID)
+	}
+	interval, err := krakenInterval(from, now)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	if _, err := candleCount(KrakenSource, from, to, interval, krakenMaxCandles); err != nil {
+		return nil, 0, "", err
+	}
+	source := fmt.Sprintf("%s?pair=%s&interval=%d", krakenOHLCRoute, assetConfig.Pair, int(interval.Minutes()))
+	// the candles opened after since are returned
+	since := candleStart(from, interval).Add(-interval).Unix()
+	res := &krakenOHLCResponse{}
+	if err := get(httpClient, KrakenSource, fmt.Sprintf("%s&since=%d", source, since), res); err != nil {
+		return nil, 0, "", err
+	}
+	if len(res.Error) > 0 {
+		return nil, 0, "", newKrakenAPIError(res.Error)
+	}
+
+	candles := []datafeed.Candle{}
+	for name, raw := range res.Result {
+		if name == "last" {
+			continue
+		}
+		// [time, open, high, low, close, vwap, volume, count], prices being strings
+		rows := [][]interface{}{}
+		if err := json.Unmarshal(raw, &rows); err != nil {
+			return nil, 0, "", errors.WithMessage(err, "invalid kraken candles")
+		}
+		for _, row := range rows {
+			c, err := parseKrakenCandle(row)
+			if err != nil {
+				return nil, 0, "", err
+			}
+			candles = append(candles, *c)
+		}
+	}
+	return candles, interval, source, nil
+}
+
+func krakenInterval(date time.Time, now time.Time) (time.Duration, error) {
+	age := now.Sub(date)
+	for _, interval := range krakenIntervals {
+		if age < (krakenMaxCandles-1)*interval {
+			return interval, nil
+		}
+	}
+	return 0, errors.Errorf("kraken does not provide candles for %s", date.String())
+}
+
+func parseKrakenCandle(row []interface{}) (*datafeed.Candle, error) {
+	if len(row) < 7 {
+		return nil, errors.New("invalid kraken candle")
+	}
+	timestamp, ok := row[0].(float64)
+	if !ok {
+		return nil, errors.Errorf("invalid kraken candle time %v", row[0])
+	}
+	prices, err := parsePrices(KrakenSource, row[1:7]...)
+	if err != nil {
+		return nil, err
+	}
+	return &datafeed.Candle{
+		Time:   time.Unix(int64(timestamp), 0).UTC(),
+		Open:   prices[0],
+		High:   prices[1],
+		Low:    prices[2],
+		Close:  prices[3],
+		VWAP:   prices[4],
+		Volume: prices[5],
+	}, nil
+}
+
+// newKrakenAPIError returns the error corresponding to the errors of a kraken response
+// (e.g. EAPI:Rate limit exceeded, EService:Unavailable)
+func newKrakenAPIError(apiErrors []string) error {
+	kind := datafeed.ErrorKindAPI
+	for _, apiError := range apiErrors {
+		switch {
+		case strings.HasPrefix(apiError, "EAPI:Rate limit"):
+			kind = datafeed.ErrorKindRateLimited
+		case strings.HasPrefix(apiError, "EService:"):
+			kind = datafeed.ErrorKindUnavailable
+		}
+	}
+	return datafeed.NewAPIError(KrakenSource, kind, strings.Join(apiErrors, ", "))
+}
@@ -0,0 +1,190 @@
+package godlccrypto
+
+import (
+	"p2pderivatives-oracle/internal/dlccrypto"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/pkg/errors"
+)
+
+// tags of the hashes defined in BIP340
+const (
+	bip340ChallengeTag = "BIP0340/challenge"
+	bip340AuxTag       = "BIP0340/aux"
+	bip340NonceTag     = "BIP0340/nonce"
+)
+
+// parseSecretScalar parses a 32 bytes secret key or nonce, which must be in [1, n-1]
+func parseSecretScalar(b []byte) (*secp256k1.ModNScalar, error) {
+	if len(b) != 32 {
+		return nil, errors.New("Invalid secret size")
+	}
+	s := new(secp256k1.ModNScalar)
+	if overflow := s.SetByteSlice(b); overflow || s.IsZero() {
+		return nil, errors.New("Secret is out of range")
+	}
+	return s, nil
+}
+
+// baseMult returns the affine point k*G
+func baseMult(k *secp256k1.ModNScalar) *secp256k1.JacobianPoint {
+	var p secp256k1.JacobianPoint
+	secp256k1.ScalarBaseMultNonConst(k, &p)
+	p.ToAffine()
+	return &p
+}
+
+// xOnlyPublicKey returns the x coordinate of the public key of the given secret key
+func xOnlyPublicKey(d *secp256k1.ModNScalar) [32]byte {
+	var x [32]byte
+	baseMult(d).X.PutBytes(&x)
+	return x
+}
+
+// liftX returns the point with the given x coordinate and an even y coordinate
+func liftX(xBytes []byte) (*secp256k1.JacobianPoint, error) {
+	var x, y secp256k1.FieldVal
+	if len(xBytes) != 32 || x.SetByteSlice(xBytes) {
+		return nil, errors.New("Invalid public key")
+	}
+	if !secp256k1.DecompressY(&x, false, &y) {
+		return nil, errors.New("Public key is not on the curve")
+	}
+	y.Normalize()
+	p := secp256k1.MakeJacobianPoint(&x, &y, new(secp256k1.FieldVal).SetInt(1))
+	return &p, nil
+}
+
+// challenge computes the BIP340 challenge of the signature
+func challenge(rx []byte, px []byte, hash []byte) *secp256k1.ModNScalar {
+	msg := make([]byte, 0, 96)
+	msg = append(msg, rx...)
+	msg = append(msg, px...)
+	msg = append(msg, hash...)
+	eHash := dlccrypto.TaggedHash(bip340ChallengeTag, msg)
+	e := new(secp256k1.ModNScalar)
+	e.SetByteSlice(eHash[:])
+	return e
+}
+
+// signWithNonce computes the BIP340 signature of the 32 bytes hash using the given secret nonce,
+// the nonce (and secret key) being negated if their point has an odd y coordinate
+func signWithNonce(privateKey []byte, nonce []byte, hash []byte) ([]byte, error) {
+	d, err := parseSecretScalar(privateKey)
+	if err != nil {
+		return nil, errors.WithMessage(err, "Invalid private key")
+	}
+	k, err := parseSecretScalar(nonce)
+	if err != nil {
+		return nil, errors.WithMessage(err, "Invalid nonce")
+	}
+
+	p := baseMult(d)
+	if p.Y.IsOdd() {
+		d.Negate()
+	}
+	r := baseMult(k)
+	if r.Y.IsOdd() {
+		k.Negate()
+	}
+
+	var rx, px [32]byte
+	r.X.PutBytes(&rx)
+	p.X.PutBytes(&px)
+	e := challenge(rx[:], px[:], hash)
+	s := new(secp256k1.ModNScalar).Mul2(e, d).Add(k)
+
+	sig := make([]byte, 64)
+	copy(sig, rx[:])
+	s.PutBytesUnchecked(sig[32:])
+	return sig, nil
+}
+
+// defaultNonce computes the nonce of a BIP340 signature from the auxiliary random data
+// as specified by the default signing algorithm
+func defaultNonce(privateKey []byte, hash []byte, auxRand []byte) ([]byte, error) {
+	d, err := parseSecretScalar(privateKey)
+	if err != nil {
+		return nil, errors.WithMessage(err, "Invalid private key")
+	}
+	p := baseMult(d)
+	if p.Y.IsOdd() {
+		d.Negate()
+	}
+	dBytes := d.Bytes()
+	auxHash := dlccrypto.TaggedHash(bip340AuxTag, auxRand)
+	var t [32]byte
+	for i := range t {
+		t[i] = dBytes[i] ^ auxHash[i]
+	}
+
+	var px [32]byte
+	p.X.PutBytes(&px)
+	msg := make([]byte, 0, 96)
+	msg = append(msg, t[:]...)
+	msg = append(msg, px[:]...)
+	msg = append(msg, hash...)
+	nonceHash := dlccrypto.TaggedHash(bip340NonceTag, msg)
+	k := new(secp256k1.ModNScalar)
+	k.SetByteSlice(nonceHash[:])
+	if k.IsZero() {
+		// only happens with negligible probability
+		return nil, errors.New("Nonce is zero")
+	}
+	kBytes := k.Bytes()
+	return kBytes[:], nil
+}
+
+// verify checks the BIP340 signature of the 32 bytes hash against the x only public key
+func verify(publicKey []byte, hash []byte, sig []byte) bool {
+	if len(sig) != 64 {
+		return false
+	}
+	p, err := liftX(publicKey)
+	if err != nil {
+		return false
+	}
+	var rx secp256k1.FieldVal
+	if rx.SetByteSlice(sig[:32]) {
+		return false
+	}
+	s := new(secp256k1.ModNScalar)
+	if s.SetByteSlice(sig[32:]) {
+		return false
+	}
+
+	// R = s*G - e*P
+	e := challenge(sig[:32], publicKey, hash)
+	e.Negate()
+	var sG, eP, r secp256k1.JacobianPoint
+	secp256k1.ScalarBaseMultNonConst(s, &sG)
+	secp256k1.ScalarMultNonConst(e, p, &eP)
+	secp256k1.AddNonConst(&sG, &eP, &r)
+	if (r.X.IsZero() && r.Y.IsZero()) || r.Z.IsZero() {
+		return false
+	}
+	r.ToAffine()
+	return !r.Y.IsOdd() && r.X.Equals(&rx)
+}
+
+// sigPoint returns the compressed point s*G = R + e*P of the BIP340 signature of the 32 bytes hash
+// with the given x only nonce and public key
+func sigPoint(publicKey []byte, nonce []byte, hash []byte) ([]byte, error) {
+	p, err := liftX(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	r, err := liftX(nonce)
+	if err != nil {
+		return nil, errors.WithMessage(err, "Invalid nonce")
+	}
+	e := challenge(nonce, publicKey, hash)
+	var eP, s secp256k1.JacobianPoint
+	secp256k1.ScalarMultNonConst(e, p, &eP)
+	secp256k1.AddNonConst(r, &eP, &s)
+	if (s.X.IsZero() && s.Y.IsZero()) || s.Z.IsZero() {
+		return nil, errors.New("Signature point is infinity")
+	}
+	s.ToAffine()
+	return secp256k1.NewPublicKey(&s.X, &s.Y).SerializeCompressed(), nil
+}
@@ -0,0 +1,109 @@
+package godlccrypto
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// test vectors from https://github.com/bitcoin/bips/blob/master/bip-0340/test-vectors.csv
+var bip340SignVectors = []struct {
+	secretKey string
+	publicKey string
+	auxRand   string
+	message   string
+	signature string
+}{
+	{
+		secretKey: "0000000000000000000000000000000000000000000000000000000000000003",
+		publicKey: "f9308a019258c31049344f85f89d5229b531c845836f99b08601f113bce036f9",
+		auxRand:   "0000000000000000000000000000000000000000000000000000000000000000",
+		message:   "0000000000000000000000000000000000000000000000000000000000000000",
+		signature: "e907831f80848d1069a5371b402410364bdf1c5f8307b0084c55f1ce2dca821525f66a4a85ea8b71e482a74f382d2ce5ebeee8fdb2172f477df4900d310536c0",
+	},
+	{
+		secretKey: "b7e151628aed2a6abf7158809cf4f3c762e7160f38b4da56a784d9045190cfef",
+		publicKey: "dff1d77f2a671c5f36183726db2341be58feae1da2deced843240f7b502ba659",
+		auxRand:   "0000000000000000000000000000000000000000000000000000000000000001",
+		message:   "243f6a8885a308d313198a2e03707344a4093822299f31d0082efa98ec4e6c89",
+		signature: "6896bd60eeae296db48a229ff71dfe071bde413e6d43f917dc8dcf8c78de33418906d11ac976abccb20b091292bff4ea897efcb639ea871cfa95f6de339e4b0a",
+	},
+	{
+		secretKey: "c90fdaa22168c234c4c6628b80dc1cd129024e088a67cc74020bbea63b14e5c9",
+		publicKey: "dd308afec5777e13121fa72b9cc1b7cc0139715309b086c960e18fd969774eb8",
+		auxRand:   "c87aa53824b4d7ae2eb035a2b5bbbccc080e76cdc6d1692c4b0b62d798e6d906",
+		message:   "7e2d58d8b3bcdf1abadec7829054f90dda9805aab56c77333024b9d0a508b75c",
+		signature: "5831aaeed7b44bb74e5eab94ba9d4294c49bcf2a60728d8b4c200f50dd313c1bab745879a5ad954a72c45a91c3a51d3c7adea98d82f8481e0e1e03674a6f3fb7",
+	},
+	{
+		secretKey: "0b432b2677937381aef05bb02a66ecd012773062cf3fa2549e44f58ed2401710",
+		publicKey: "25d1dff95105f5253c4022f628a996ad3a0d95fbf21d468a1b33f8c160d8f517",
+		auxRand:   "ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+		message:   "ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+		signature: "7eb0509757e246f19449885651611cb965ecc1a187dd51b64fda1edc9637d5ec97582b9cb13db3933705b32ba982af5af25fd78881ebb32771fc5922efc66ea3",
+	},
+}
+
+var bip340VerifyVectors = []struct {
+	publicKey string
+	message   string
+	signature string
+	valid     bool
+}{
+	{
+		publicKey: "d69c3509bb99e412e68b0fe8544e72837dfa30746d8be2aa65975f29d22dc7b9",
+		message:   "4df3c3f68fcc83b27e9d42c90431a72499f17875c81a599b566c9889b9696703",
+		signature: "00000000000000000000003b78ce563f89a0ed9414f5aa28ad0d96d6795f9c6376afb1548af603b3eb45c9f8207dee1060cb71c04e80f593060b07d28308d7f4",
+		valid:     true,
+	},
+	{
+		// public key not on the curve
+		publicKey: "eefdea4cdb677750a420fee807eacf21eb9898ae79b9768766e4faa04a2d4a34",
+		message:   "243f6a8885a308d313198a2e03707344a4093822299f31d0082efa98ec4e6c89",
+		signature: "6cff5c3ba86c69ea4b7376f31a9bcb4f74c1976089b2d9963da2e5543e17776969e89b4c5564d00349106b8497785dd7d1d713a8ae82b32fa79d5f7fc407d39b",
+		valid:     false,
+	},
+	{
+		// R has an odd y coordinate
+		publicKey: "dff1d77f2a671c5f36183726db2341be58feae1da2deced843240f7b502ba659",
+		message:   "243f6a8885a308d313198a2e03707344a4093822299f31d0082efa98ec4e6c89",
+		signature: "fff97bd5755eeea420453a14355235d382f6472f8568a18b2f057a14602975563cc27944640ac607cd107ae10923d9ef7a73c643e166be5ebeafa34b1ac553e2",
+		valid:     false,
+	},
+}
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	assert.NoError(t, err)
+	return b
+}
+
+func Test_BIP340_Sign(t *testing.T) {
+	for _, vector := range bip340SignVectors {
+		seckey := mustDecodeHex(t, vector.secretKey)
+		message := mustDecodeHex(t, vector.message)
+
+		d, err := parseSecretScalar(seckey)
+		assert.NoError(t, err)
+		pubkey := xOnlyPublicKey(d)
+		assert.Equal(t, vector.publicKey, hex.EncodeToString(pubkey[:]))
+
+		nonce, err := defaultNonce(seckey, message, mustDecodeHex(t, vector.auxRand))
+		assert.NoError(t, err)
+		sig, err := signWithNonce(seckey, nonce, message)
+		assert.NoError(t, err)
+		assert.Equal(t, vector.signature, hex.EncodeToString(sig))
+		assert.True(t, verify(pubkey[:], message, sig))
+	}
+}
+
+func Test_BIP340_Verify(t *testing.T) {
+	for _, vector := range bip340VerifyVectors {
+		valid := verify(
+			mustDecodeHex(t, vector.publicKey),
+			mustDecodeHex(t, vector.message),
+			mustDecodeHex(t, vector.signature))
+		assert.Equal(t, vector.valid, valid, vector.signature)
+	}
+}
@@ -0,0 +1,145 @@
+package godlccrypto
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"p2pderivatives-oracle/internal/dlccrypto"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/pkg/errors"
+)
+
+// NewGoCryptoService returns a CryptoService implemented in pure Go (without cgo),
+// producing the same keys, nonces and signatures as the cfd-go implementation
+func NewGoCryptoService() dlccrypto.CryptoService {
+	return &GoCryptoService{}
+}
+
+// GoCryptoService crypto service implementing BIP340 schnorr signatures on top of the
+// pure Go secp256k1 library of dcrd
+type GoCryptoService struct {
+}
+
+// GenerateSchnorrKeyPair returns a freshly generated Schnorr public/private key pair
+func (o *GoCryptoService) GenerateSchnorrKeyPair() (*dlccrypto.PrivateKey, *dlccrypto.SchnorrPublicKey, error) {
+	seckey, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "Error while generating key pair")
+	}
+
+	privkey, err := dlccrypto.NewPrivateKey(hex.EncodeToString(seckey.Serialize()))
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "Error while generating private key")
+	}
+
+	pubkey, err := o.SchnorrPublicKeyFromPrivateKey(privkey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return privkey, pubkey, nil
+}
+
+// DeriveSchnorrNonce returns the one time signing key and nonce of an event derived from the oracle private key
+// (see dlccrypto.DeriveNonceKey)
+func (o *GoCryptoService) DeriveSchnorrNonce(privateKey *dlccrypto.PrivateKey, assetID string, eventMaturity uint32, index int) (*dlccrypto.PrivateKey, *dlccrypto.SchnorrPublicKey, error) {
+	kvalue, err := dlccrypto.DeriveNonceKey(privateKey, assetID, eventMaturity, index)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "Error while deriving nonce")
+	}
+
+	rvalue, err := o.SchnorrPublicKeyFromPrivateKey(kvalue)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return kvalue, rvalue, nil
+}
+
+// SchnorrPublicKeyFromPrivateKey computes a Schnorr public key from a private key
+func (o *GoCryptoService) SchnorrPublicKeyFromPrivateKey(privateKey *dlccrypto.PrivateKey) (*dlccrypto.SchnorrPublicKey, error) {
+	d, err := parseSecretScalar(decodeKey(privateKey))
+	if err != nil {
+		return nil, errors.WithMessage(err, "Error while calculating public key from private key")
+	}
+	x := xOnlyPublicKey(d)
+	return dlccrypto.NewSchnorrPublicKey(hex.EncodeToString(x[:]))
+}
+
+// ComputeSchnorrSignatureFixedK computes a schnorr signature on the given message (will be hashed by sha256)
+// using the given one time signing key
+func (o *GoCryptoService) ComputeSchnorrSignatureFixedK(privateKey *dlccrypto.PrivateKey, kvalue *dlccrypto.PrivateKey, message string) (*dlccrypto.Signature, error) {
+	hash := sha256.Sum256([]byte(message))
+
+	sig, err := signWithNonce(decodeKey(privateKey), decodeKey(kvalue), hash[:])
+	if err != nil {
+		return nil, errors.WithMessage(err, "Error while computing schnorr signature")
+	}
+	return dlccrypto.NewSignature(hex.EncodeToString(sig))
+}
+
+// ComputeSchnorrSignature computes a schnorr signature on the given byte buffer message (will be hashed by sha256)
+func (o *GoCryptoService) ComputeSchnorrSignature(privateKey *dlccrypto.PrivateKey, message []byte) (*dlccrypto.Signature, error) {
+	hash := sha256.Sum256(message)
+
+	auxRand := make([]byte, 32)
+	if _, err := rand.Read(auxRand); err != nil {
+		return nil, errors.WithMessage(err, "Error while generating auxiliary random data")
+	}
+
+	seckey := decodeKey(privateKey)
+	nonce, err := defaultNonce(seckey, hash[:], auxRand)
+	if err != nil {
+		return nil, errors.WithMessage(err, "Error while computing schnorr signature")
+	}
+	sig, err := signWithNonce(seckey, nonce, hash[:])
+	if err != nil {
+		return nil, errors.WithMessage(err, "Error while computing schnorr signature")
+	}
+	return dlccrypto.NewSignature(hex.EncodeToString(sig))
+}
+
+// VerifySchnorrSignature verifies the schnorr signature against a given public key on the given message (will be hashed with sha256)
+func (o *GoCryptoService) VerifySchnorrSignature(publicKey *dlccrypto.SchnorrPublicKey, signature *dlccrypto.Signature, message string) (bool, error) {
+	return o.VerifySchnorrSignatureRaw(publicKey, signature, []byte(message))
+}
+
+// VerifySchnorrSignatureRaw verifies the schnorr signature against a given public key on the given byte buffer message (will be hashed with sha256)
+func (o *GoCryptoService) VerifySchnorrSignatureRaw(publicKey *dlccrypto.SchnorrPublicKey, signature *dlccrypto.Signature, message []byte) (bool, error) {
+	hash := sha256.Sum256(message)
+	pubkey, err := hex.DecodeString(publicKey.EncodeToString())
+	if err != nil {
+		return false, errors.WithMessage(err, "Error while verifying schnorr signature")
+	}
+	sig, err := hex.DecodeString(signature.EncodeToString())
+	if err != nil {
+		return false, errors.WithMessage(err, "Error while verifying schnorr signature")
+	}
+	return verify(pubkey, hash[:], sig), nil
+}
+
+// ComputeSigPoint computes the point s*G of the signature of the given message (will be hashed with sha256)
+// with the given nonce, without the private key (the anticipation point of the message)
+func (o *GoCryptoService) ComputeSigPoint(publicKey *dlccrypto.SchnorrPublicKey, nonce *dlccrypto.SchnorrPublicKey, message string) (*dlccrypto.Point, error) {
+	hash := sha256.Sum256([]byte(message))
+	pubkey, err := hex.DecodeString(publicKey.EncodeToString())
+	if err != nil {
+		return nil, errors.WithMessage(err, "Error while computing signature point")
+	}
+	rvalue, err := hex.DecodeString(nonce.EncodeToString())
+	if err != nil {
+		return nil, errors.WithMessage(err, "Error while computing signature point")
+	}
+	point, err := sigPoint(pubkey, rvalue, hash[:])
+	if err != nil {
+		return nil, errors.WithMessage(err, "Error while computing signature point")
+	}
+	return dlccrypto.NewPoint(hex.EncodeToString(point))
+}
+
+// decodeKey returns the bytes of the key (its hex encoding is always valid)
+func decodeKey(key *dlccrypto.PrivateKey) []byte {
+	b, _ := hex.DecodeString(key.EncodeToString())
+	return b
+}
@@ -0,0 +1,120 @@
+package godlccrypto_test
+
+import (
+	"encoding/hex"
+	"p2pderivatives-oracle/internal/dlccrypto"
+	"p2pderivatives-oracle/internal/godlccrypto"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// same vectors as the cfd-go crypto service tests
+var (
+	TestOracleKeyPair = struct {
+		PublicKey  string
+		PrivateKey string
+	}{PrivateKey: "18e14a7b6a307f426a94f8114701e7c8e774e7f9a47e2c2035db29a206321725", PublicKey: "50863ad64a87ae8a2fe83c1af1a8403cb53f53e486d8511dad8a04887e5b2352"}
+	TestKeyPairs = [...]struct {
+		PublicKey  string
+		PrivateKey string
+	}{
+		{PrivateKey: "dcfbf4fc4f357ac42e038c00dc8a9d4f51f04b5ef7b31ce413b6daad5b9efb69", PublicKey: "2b4ec6a8dff179be54a40e68ba48c2f81a239aaf1dae5b625a371c52dca7e649"},
+		{PrivateKey: "b3b2b54604efa25ad90f04c51a70a415fdb246253cb74f7cce3918ed54f24df1", PublicKey: "fcc2004734a187853e98b62a7715bcf63c44bd8bc3ade6e9c518fe34f1602a22"},
+		{PrivateKey: "4cb24cab7de4b95e1d37164dd1583a720f366b1801549a0e90aceea988932ce6", PublicKey: "c900116a6219b8f24dde0e7828e73b2258cd7fc9475192d013f30cd69c1666c6"},
+	}
+	TestSignatures = [...]struct {
+		k         string
+		message   string
+		signature string
+	}{
+		{k: "d8667a07d8a66cbdeda3a8da8c8ce802bf22493abea287df37f92ee0d7725fb0", message: "1200", signature: "5a00f102a9a2c789046da82a900b4b1b34fcf73dce5ac1063a653c2bf9b3f5f0c50e5b475b7fefc5deac176a91fde56e1fa8c522661af2e6a6ea60b3f3e4d82e"},
+		{k: "2644083242f5cf7ff89331f219cb064ee81f6279face75d96ea5a22b2180fa72", message: "3000", signature: "d34fba30e1d6f8e82e37ae34ded1f16aac0e1527257201bbb21025ea49c9bdea536c4b9fceeed308bf06d6b4e006555adc481b1e93995a599b1b98f1ac66b195"},
+		{k: "7db3f7091798d2d426205bdb194f74401014755e3e58f9303390c1ff0e4bd44a", message: "0", signature: "1fc82267e136cb89bd2ac0c2b0c7e3ef202d895193f071556bd91769bd45c752d69f9d53fdb1a7fb280d718a8bf083cbb5a796c0021fdde34e3280b63d109e6a"},
+	}
+)
+
+func Test_GoCryptoService_PublicKeyFromPrivateKey(t *testing.T) {
+	crypto := godlccrypto.NewGoCryptoService()
+	for _, keypair := range TestKeyPairs {
+		privKey, err := dlccrypto.NewPrivateKey(keypair.PrivateKey)
+		assert.NoError(t, err)
+		pubkey, err := crypto.SchnorrPublicKeyFromPrivateKey(privKey)
+		assert.NoError(t, err)
+		assert.Equal(t, keypair.PublicKey, pubkey.EncodeToString())
+	}
+}
+
+func Test_GoCryptoService_ComputeSchnorrSignatureFixedK(t *testing.T) {
+	crypto := godlccrypto.NewGoCryptoService()
+	oracleKey, err := dlccrypto.NewPrivateKey(TestOracleKeyPair.PrivateKey)
+	assert.NoError(t, err)
+	oraclePubKey, err := dlccrypto.NewSchnorrPublicKey(TestOracleKeyPair.PublicKey)
+	assert.NoError(t, err)
+	for _, sigpair := range TestSignatures {
+		kvalue, err := dlccrypto.NewPrivateKey(sigpair.k)
+		assert.NoError(t, err)
+		sig, err := crypto.ComputeSchnorrSignatureFixedK(oracleKey, kvalue, sigpair.message)
+		assert.NoError(t, err)
+		assert.Equal(t, sigpair.signature, sig.EncodeToString())
+		isValid, err := crypto.VerifySchnorrSignature(oraclePubKey, sig, sigpair.message)
+		assert.NoError(t, err)
+		assert.True(t, isValid)
+	}
+}
+
+func Test_GoCryptoService_SignAndVerify(t *testing.T) {
+	crypto := godlccrypto.NewGoCryptoService()
+	privkey, pubkey, err := crypto.GenerateSchnorrKeyPair()
+	assert.NoError(t, err)
+
+	message := []byte("hello world")
+	sig, err := crypto.ComputeSchnorrSignature(privkey, message)
+	assert.NoError(t, err)
+	isValid, err := crypto.VerifySchnorrSignatureRaw(pubkey, sig, message)
+	assert.NoError(t, err)
+	assert.True(t, isValid)
+
+	isValid, err = crypto.VerifySchnorrSignatureRaw(pubkey, sig, []byte("hello world!"))
+	assert.NoError(t, err)
+	assert.False(t, isValid)
+}
+
+func Test_GoCryptoService_DeriveSchnorrNonce_IsUsedBySignature(t *testing.T) {
+	crypto := godlccrypto.NewGoCryptoService()
+	oracleKey, err := dlccrypto.NewPrivateKey(TestOracleKeyPair.PrivateKey)
+	assert.NoError(t, err)
+
+	kvalue, rvalue, err := crypto.DeriveSchnorrNonce(oracleKey, "btcusd", 1623133104, 2)
+	assert.NoError(t, err)
+	sig, err := crypto.ComputeSchnorrSignatureFixedK(oracleKey, kvalue, "1200")
+	assert.NoError(t, err)
+	assert.Equal(t, rvalue.EncodeToString(), sig.EncodeToString()[:64])
+}
+
+func Test_GoCryptoService_ComputeSigPoint_IsSignaturePoint(t *testing.T) {
+	crypto := godlccrypto.NewGoCryptoService()
+	oracleKey, err := dlccrypto.NewPrivateKey(TestOracleKeyPair.PrivateKey)
+	assert.NoError(t, err)
+	oraclePubKey, err := dlccrypto.NewSchnorrPublicKey(TestOracleKeyPair.PublicKey)
+	assert.NoError(t, err)
+
+	for i, sigpair := range TestSignatures {
+		kvalue, rvalue, err := crypto.DeriveSchnorrNonce(oracleKey, "btcusd", 1623133104, i)
+		assert.NoError(t, err)
+		sig, err := crypto.ComputeSchnorrSignatureFixedK(oracleKey, kvalue, sigpair.message)
+		assert.NoError(t, err)
+		s, err := hex.DecodeString(sig.EncodeToString()[64:])
+		assert.NoError(t, err)
+		expected := secp256k1.PrivKeyFromBytes(s).PubKey().SerializeCompressed()
+
+		point, err := crypto.ComputeSigPoint(oraclePubKey, rvalue, sigpair.message)
+
+		assert.NoError(t, err)
+		assert.Equal(t, hex.EncodeToString(expected), point.EncodeToString())
+		other, err := crypto.ComputeSigPoint(oraclePubKey, rvalue, sigpair.message+"0")
+		assert.NoError(t, err)
+		assert.NotEqual(t, point.EncodeToString(), other.EncodeToString())
+	}
+}
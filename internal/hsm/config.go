@@ -0,0 +1,78 @@
+package hsm
+
+import (
+	"github.com/cryptogarageinc/server-common-go/pkg/utils/file"
+	"github.com/pkg/errors"
+)
+
+// VendorDefinedMechanism first value of the mechanisms defined by the token vendors (CKM_VENDOR_DEFINED)
+const VendorDefinedMechanism = 0x80000000
+
+// Config contains the configuration of the oracle signing with a key held in a PKCS#11 token
+// (only available in binaries built with the pkcs11 tag)
+type Config struct {
+	Enabled bool `configkey:"hsm.enabled"`
+	// Module path of the PKCS#11 library of the token
+	Module string `configkey:"hsm.module"`
+	// TokenLabel label of the token holding the oracle key
+	TokenLabel string `configkey:"hsm.tokenLabel"`
+	// Pin user PIN of the token (read from PinFile if empty)
+	Pin     string `configkey:"hsm.pin"`
+	PinFile string `configkey:"hsm.pinFile"`
+	// KeyLabel label (CKA_LABEL) of the secp256k1 private and public key objects of the oracle
+	KeyLabel   string     `configkey:"hsm.keyLabel"`
+	Mechanisms Mechanisms `configkey:"hsm.mechanisms"`
+}
+
+// Mechanisms contains the vendor defined mechanisms computing the BIP340 signatures and nonces in the token,
+// each of them being used with C_Sign and the oracle private key (see Signer)
+type Mechanisms struct {
+	// Sign signs a sha256 hash with a nonce chosen by the token
+	Sign int `configkey:"sign"`
+	// Nonce returns the x only public nonce derived from the nonce derivation data
+	Nonce int `configkey:"nonce"`
+	// NonceSign signs a sha256 hash with the nonce derived from the nonce derivation data given as mechanism parameter
+	NonceSign int `configkey:"nonceSign"`
+}
+
+// Validate checks that the token, key and mechanisms are configured
+func (c *Config) Validate() error {
+	if c.Module == "" {
+		return errors.New("No PKCS#11 module configured")
+	}
+	if c.TokenLabel == "" {
+		return errors.New("No PKCS#11 token label configured")
+	}
+	if c.KeyLabel == "" {
+		return errors.New("No PKCS#11 key label configured")
+	}
+	mechanisms := map[string]int{
+		"sign":      c.Mechanisms.Sign,
+		"nonce":     c.Mechanisms.Nonce,
+		"nonceSign": c.Mechanisms.NonceSign,
+	}
+	for name, mechanism := range mechanisms {
+		if mechanism < VendorDefinedMechanism {
+			return errors.Errorf("The %s mechanism should be a vendor defined mechanism, got %#x", name, mechanism)
+		}
+	}
+	return nil
+}
+
+// ReadPin returns the user PIN of the token
+func (c *Config) ReadPin() (string, error) {
+	if c.Pin != "" {
+		return c.Pin, nil
+	}
+	if c.PinFile == "" {
+		return "", errors.New("No PKCS#11 PIN or PIN file provided")
+	}
+	pin, err := file.ReadFirstLineFromFile(c.PinFile)
+	if err != nil {
+		return "", err
+	}
+	if pin == "" {
+		return "", errors.Errorf("Could not read PKCS#11 PIN from %s", c.PinFile)
+	}
+	return pin, nil
+}
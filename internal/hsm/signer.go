@@ -0,0 +1,160 @@
+package hsm
+
+import (
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/hex"
+	"p2pderivatives-oracle/internal/dlccrypto"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Token gives access to the oracle key held in a PKCS#11 token
+type Token interface {
+	// ECPoint returns the CKA_EC_POINT attribute of the oracle public key object
+	ECPoint() ([]byte, error)
+	// Sign calls C_Sign with the oracle private key, the given mechanism and mechanism parameter
+	Sign(mechanism uint, parameter []byte, data []byte) ([]byte, error)
+}
+
+// NewSigner returns a signer computing the signatures of the oracle with the key held in the token
+func NewSigner(token Token, mechanisms Mechanisms, cryptoService dlccrypto.CryptoService) (*Signer, error) {
+	ecPoint, err := token.ECPoint()
+	if err != nil {
+		return nil, errors.WithMessage(err, "Could not read the public key of the oracle key")
+	}
+	publicKey, err := parseECPoint(ecPoint)
+	if err != nil {
+		return nil, err
+	}
+	return &Signer{
+		token:         token,
+		mechanisms:    mechanisms,
+		publicKey:     publicKey,
+		cryptoService: cryptoService,
+	}, nil
+}
+
+// Signer signer computing the signatures in a PKCS#11 token, the oracle private key never leaving the token.
+// As the standard mechanisms provide neither BIP340 signatures nor signatures with a given nonce,
+// the token has to implement the vendor defined mechanisms of the configuration:
+//   - Mechanisms.Sign signs the 32 bytes sha256 hash of a message (BIP340, 64 bytes signature)
+//   - Mechanisms.Nonce returns the 32 bytes x only public nonce R = k*G from the nonce derivation data
+//     (see dlccrypto.NonceDerivationData), k being derived like dlccrypto.DeriveNonceKey
+//     (tagged hash of the private key followed by the data)
+//   - Mechanisms.NonceSign signs the 32 bytes sha256 hash of a message with the nonce derived from
+//     the nonce derivation data given as mechanism parameter
+//
+// The signatures returned by the token are verified against the oracle public key and the event nonces.
+type Signer struct {
+	token         Token
+	mechanisms    Mechanisms
+	publicKey     *dlccrypto.SchnorrPublicKey
+	cryptoService dlccrypto.CryptoService
+}
+
+// PublicKey returns the schnorr public key of the oracle
+func (s *Signer) PublicKey() *dlccrypto.SchnorrPublicKey {
+	return s.publicKey
+}
+
+// DeriveSchnorrNonce returns the nonce of an event derived in the token from the oracle private key
+func (s *Signer) DeriveSchnorrNonce(assetID string, eventMaturity uint32, index int) (*dlccrypto.SchnorrPublicKey, error) {
+	data, err := dlccrypto.NonceDerivationData(assetID, eventMaturity, index)
+	if err != nil {
+		return nil, err
+	}
+	rvalue, err := s.token.Sign(uint(s.mechanisms.Nonce), nil, data)
+	if err != nil {
+		return nil, errors.WithMessage(err, "Could not derive nonce in the PKCS#11 token")
+	}
+	return dlccrypto.NewSchnorrPublicKey(hex.EncodeToString(rvalue))
+}
+
+// ComputeSchnorrSignature computes a schnorr signature on the given byte buffer message (will be hashed by sha256)
+func (s *Signer) ComputeSchnorrSignature(message []byte) (*dlccrypto.Signature, error) {
+	hash := sha256.Sum256(message)
+	res, err := s.token.Sign(uint(s.mechanisms.Sign), nil, hash[:])
+	if err != nil {
+		return nil, errors.WithMessage(err, "Could not sign in the PKCS#11 token")
+	}
+	signature, err := dlccrypto.NewSignature(hex.EncodeToString(res))
+	if err != nil {
+		return nil, err
+	}
+	valid, err := s.cryptoService.VerifySchnorrSignatureRaw(s.publicKey, signature, message)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, errors.New("The PKCS#11 token returned an invalid signature")
+	}
+	return signature, nil
+}
+
+// ComputeSchnorrSignatureWithNonce computes a schnorr signature on the given message (will be hashed by sha256)
+// using the nonce of the event derived in the token, the one time signing keys stored with the events
+// being unsupported as the private key is not accessible
+func (s *Signer) ComputeSchnorrSignatureWithNonce(nonce *dlccrypto.EventNonce, message string) (*dlccrypto.Signature, error) {
+	if nonce.Kvalue != nil {
+		return nil, errors.New("Events announced with a stored one time signing key cannot be signed with a PKCS#11 token")
+	}
+	rvalue, err := s.DeriveSchnorrNonce(nonce.AssetID, nonce.EventMaturity, nonce.Index)
+	if err != nil {
+		return nil, err
+	}
+	data, err := dlccrypto.NonceDerivationData(nonce.AssetID, nonce.EventMaturity, nonce.Index)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256([]byte(message))
+	res, err := s.token.Sign(uint(s.mechanisms.NonceSign), data, hash[:])
+	if err != nil {
+		return nil, errors.WithMessage(err, "Could not sign with nonce in the PKCS#11 token")
+	}
+	signature, err := dlccrypto.NewSignature(hex.EncodeToString(res))
+	if err != nil {
+		return nil, err
+	}
+	// the signature has to use the announced nonce
+	if !strings.HasPrefix(signature.EncodeToString(), rvalue.EncodeToString()) {
+		return nil, errors.New("The PKCS#11 token signed with another nonce than the event nonce")
+	}
+	valid, err := s.cryptoService.VerifySchnorrSignature(s.publicKey, signature, message)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, errors.New("The PKCS#11 token returned an invalid signature")
+	}
+	return signature, nil
+}
+
+// parseECPoint returns the x only public key of the CKA_EC_POINT attribute of a secp256k1 key,
+// which is the DER encoding of the point (some tokens returning the point without encoding)
+func parseECPoint(ecPoint []byte) (*dlccrypto.SchnorrPublicKey, error) {
+	point := ecPoint
+	if !isPoint(point) {
+		var decoded []byte
+		if rest, err := asn1.Unmarshal(ecPoint, &decoded); err == nil && len(rest) == 0 {
+			point = decoded
+		}
+	}
+	if !isPoint(point) {
+		return nil, errors.Errorf("Invalid secp256k1 public key point %x", ecPoint)
+	}
+	return dlccrypto.NewSchnorrPublicKey(hex.EncodeToString(point[1:33]))
+}
+
+// isPoint returns whether the bytes are an uncompressed or compressed secp256k1 point
+func isPoint(point []byte) bool {
+	switch {
+	case len(point) == 65 && point[0] == 0x04:
+		return true
+	case len(point) == 33 && (point[0] == 0x02 || point[0] == 0x03):
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,189 @@
+package hsm_test
+
+import (
+	"encoding/asn1"
+	"encoding/hex"
+	"p2pderivatives-oracle/internal/dlccrypto"
+	"p2pderivatives-oracle/internal/godlccrypto"
+	"p2pderivatives-oracle/internal/hsm"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+var testMechanisms = hsm.Mechanisms{
+	Sign:      hsm.VendorDefinedMechanism + 1,
+	Nonce:     hsm.VendorDefinedMechanism + 2,
+	NonceSign: hsm.VendorDefinedMechanism + 3,
+}
+
+// FakeToken implements the vendor mechanisms of the signer with a key held in memory
+type FakeToken struct {
+	privateKey *secp256k1.ModNScalar
+	// raw returns the public key point without DER encoding
+	raw bool
+	// otherNonce signs with another nonce than the derived one
+	otherNonce bool
+}
+
+func NewFakeToken(privateKey *dlccrypto.PrivateKey) *FakeToken {
+	b, _ := hex.DecodeString(privateKey.EncodeToString())
+	d := new(secp256k1.ModNScalar)
+	d.SetByteSlice(b)
+	return &FakeToken{privateKey: d}
+}
+
+func (f *FakeToken) ECPoint() ([]byte, error) {
+	point := secp256k1.NewPrivateKey(f.privateKey).PubKey().SerializeUncompressed()
+	if f.raw {
+		return point, nil
+	}
+	return asn1.Marshal(point)
+}
+
+func (f *FakeToken) Sign(mechanism uint, parameter []byte, data []byte) ([]byte, error) {
+	switch mechanism {
+	case uint(testMechanisms.Sign):
+		return f.sign(f.deriveNonce([]byte("sign")), data), nil
+	case uint(testMechanisms.Nonce):
+		var r secp256k1.JacobianPoint
+		secp256k1.ScalarBaseMultNonConst(f.deriveNonce(data), &r)
+		r.ToAffine()
+		return r.X.Bytes()[:], nil
+	case uint(testMechanisms.NonceSign):
+		if f.otherNonce {
+			parameter = append(parameter, 0)
+		}
+		return f.sign(f.deriveNonce(parameter), data), nil
+	default:
+		return nil, errors.Errorf("mechanism %#x invalid", mechanism)
+	}
+}
+
+// deriveNonce derives the nonce like dlccrypto.DeriveNonceKey from the nonce derivation data
+func (f *FakeToken) deriveNonce(data []byte) *secp256k1.ModNScalar {
+	d := f.privateKey.Bytes()
+	hash := dlccrypto.TaggedHash(dlccrypto.NonceDerivationTag, append(d[:], data...))
+	k := new(secp256k1.ModNScalar)
+	k.SetBytes(&hash)
+	return k
+}
+
+// sign computes the BIP340 signature of the hash with the given nonce
+func (f *FakeToken) sign(k *secp256k1.ModNScalar, hash []byte) []byte {
+	d := new(secp256k1.ModNScalar).Set(f.privateKey)
+	var p, r secp256k1.JacobianPoint
+	secp256k1.ScalarBaseMultNonConst(d, &p)
+	p.ToAffine()
+	if p.Y.IsOdd() {
+		d.Negate()
+	}
+	k = new(secp256k1.ModNScalar).Set(k)
+	secp256k1.ScalarBaseMultNonConst(k, &r)
+	r.ToAffine()
+	if r.Y.IsOdd() {
+		k.Negate()
+	}
+	rx, px := r.X.Bytes(), p.X.Bytes()
+	challenge := dlccrypto.TaggedHash("BIP0340/challenge", append(append(append([]byte{}, rx[:]...), px[:]...), hash...))
+	e := new(secp256k1.ModNScalar)
+	e.SetBytes(&challenge)
+	s := new(secp256k1.ModNScalar).Mul2(e, d).Add(k)
+	sBytes := s.Bytes()
+	return append(rx[:], sBytes[:]...)
+}
+
+func SetupTestSigner(t *testing.T, configure func(token *FakeToken)) (*hsm.Signer, dlccrypto.Signer) {
+	cryptoService := godlccrypto.NewGoCryptoService()
+	privateKey, publicKey, err := cryptoService.GenerateSchnorrKeyPair()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	token := NewFakeToken(privateKey)
+	if configure != nil {
+		configure(token)
+	}
+	signer, err := hsm.NewSigner(token, testMechanisms, cryptoService)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return signer, dlccrypto.NewKeySigner(privateKey, publicKey, cryptoService)
+}
+
+func TestSigner_PublicKey_ReturnsTokenKey(t *testing.T) {
+	for _, raw := range []bool{false, true} {
+		signer, keySigner := SetupTestSigner(t, func(token *FakeToken) { token.raw = raw })
+
+		assert.Equal(t, keySigner.PublicKey().EncodeToString(), signer.PublicKey().EncodeToString())
+	}
+}
+
+func TestSigner_DeriveSchnorrNonce_ReturnsKeySignerNonce(t *testing.T) {
+	signer, keySigner := SetupTestSigner(t, nil)
+
+	actual, err := signer.DeriveSchnorrNonce("btcusd", 1623133104, 1)
+
+	expected, _ := keySigner.DeriveSchnorrNonce("btcusd", 1623133104, 1)
+	if assert.NoError(t, err) {
+		assert.Equal(t, expected.EncodeToString(), actual.EncodeToString())
+	}
+}
+
+func TestSigner_ComputeSchnorrSignatureWithNonce_ReturnsKeySignerSignature(t *testing.T) {
+	signer, keySigner := SetupTestSigner(t, nil)
+	nonce := &dlccrypto.EventNonce{AssetID: "btcusd", EventMaturity: 1623133104, Index: 1}
+
+	actual, err := signer.ComputeSchnorrSignatureWithNonce(nonce, "1")
+
+	expected, _ := keySigner.ComputeSchnorrSignatureWithNonce(nonce, "1")
+	if assert.NoError(t, err) {
+		assert.Equal(t, expected.EncodeToString(), actual.EncodeToString())
+	}
+}
+
+func TestSigner_ComputeSchnorrSignature_ReturnsValidSignature(t *testing.T) {
+	signer, _ := SetupTestSigner(t, nil)
+	message := []byte("announcement")
+
+	actual, err := signer.ComputeSchnorrSignature(message)
+
+	if assert.NoError(t, err) {
+		valid, err := godlccrypto.NewGoCryptoService().VerifySchnorrSignatureRaw(signer.PublicKey(), actual, message)
+		assert.NoError(t, err)
+		assert.True(t, valid)
+	}
+}
+
+func TestSigner_ComputeSchnorrSignatureWithNonce_OtherNonce_ReturnsError(t *testing.T) {
+	signer, _ := SetupTestSigner(t, func(token *FakeToken) { token.otherNonce = true })
+
+	_, err := signer.ComputeSchnorrSignatureWithNonce(&dlccrypto.EventNonce{AssetID: "btcusd", EventMaturity: 1623133104}, "1")
+
+	assert.EqualError(t, err, "The PKCS#11 token signed with another nonce than the event nonce")
+}
+
+func TestSigner_ComputeSchnorrSignatureWithNonce_StoredKvalue_ReturnsError(t *testing.T) {
+	signer, _ := SetupTestSigner(t, nil)
+	kvalue, _, _ := godlccrypto.NewGoCryptoService().GenerateSchnorrKeyPair()
+
+	_, err := signer.ComputeSchnorrSignatureWithNonce(&dlccrypto.EventNonce{Kvalue: kvalue}, "1")
+
+	assert.EqualError(t, err, "Events announced with a stored one time signing key cannot be signed with a PKCS#11 token")
+}
+
+func TestConfig_Validate_StandardMechanism_ReturnsError(t *testing.T) {
+	config := &hsm.Config{
+		Module:     "/usr/lib/softhsm/libsofthsm2.so",
+		TokenLabel: "oracle",
+		KeyLabel:   "oracle",
+		Mechanisms: testMechanisms,
+	}
+	assert.NoError(t, config.Validate())
+
+	// CKM_ECDSA
+	config.Mechanisms.NonceSign = 0x1041
+
+	assert.EqualError(t, config.Validate(), "The nonceSign mechanism should be a vendor defined mechanism, got 0x1041")
+}
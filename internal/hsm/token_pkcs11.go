@@ -0,0 +1,156 @@
+// +build pkcs11,cgo
+
+package hsm
+
+import (
+	"p2pderivatives-oracle/internal/dlccrypto"
+	"strings"
+	"sync"
+
+	"github.com/miekg/pkcs11"
+	"github.com/pkg/errors"
+)
+
+// NewSignerFromConfig opens the configured token and returns a signer computing the signatures with its oracle key
+func NewSignerFromConfig(config *Config, cryptoService dlccrypto.CryptoService) (*Signer, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	token, err := OpenToken(config)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := NewSigner(token, config.Mechanisms, cryptoService)
+	if err != nil {
+		token.Close()
+		return nil, err
+	}
+	return signer, nil
+}
+
+// PKCS11Token token accessed through a PKCS#11 library, the operations being serialized on a single session
+type PKCS11Token struct {
+	mutex      sync.Mutex
+	ctx        *pkcs11.Ctx
+	session    pkcs11.SessionHandle
+	privateKey pkcs11.ObjectHandle
+	publicKey  pkcs11.ObjectHandle
+}
+
+// OpenToken loads the PKCS#11 module, opens a session on the token with the configured label
+// logged in with the user PIN, and finds the oracle private and public keys with the configured label
+func OpenToken(config *Config) (*PKCS11Token, error) {
+	pin, err := config.ReadPin()
+	if err != nil {
+		return nil, err
+	}
+	ctx := pkcs11.New(config.Module)
+	if ctx == nil {
+		return nil, errors.Errorf("Could not load PKCS#11 module %s", config.Module)
+	}
+	if err := ctx.Initialize(); err != nil {
+		ctx.Destroy()
+		return nil, errors.WithMessagef(err, "Could not initialize PKCS#11 module %s", config.Module)
+	}
+	token := &PKCS11Token{ctx: ctx}
+	if err := token.open(config.TokenLabel, pin, config.KeyLabel); err != nil {
+		token.Close()
+		return nil, err
+	}
+	return token, nil
+}
+
+func (t *PKCS11Token) open(tokenLabel string, pin string, keyLabel string) error {
+	slots, err := t.ctx.GetSlotList(true)
+	if err != nil {
+		return errors.WithMessage(err, "Could not list PKCS#11 slots")
+	}
+	found := false
+	var slot uint
+	for _, s := range slots {
+		info, err := t.ctx.GetTokenInfo(s)
+		if err == nil && strings.TrimSpace(info.Label) == tokenLabel {
+			slot, found = s, true
+			break
+		}
+	}
+	if !found {
+		return errors.Errorf("PKCS#11 token %s not found", tokenLabel)
+	}
+	t.session, err = t.ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION)
+	if err != nil {
+		return errors.WithMessage(err, "Could not open PKCS#11 session")
+	}
+	if err := t.ctx.Login(t.session, pkcs11.CKU_USER, pin); err != nil {
+		return errors.WithMessage(err, "Could not log in the PKCS#11 token")
+	}
+	t.privateKey, err = t.findKey(pkcs11.CKO_PRIVATE_KEY, keyLabel)
+	if err != nil {
+		return err
+	}
+	t.publicKey, err = t.findKey(pkcs11.CKO_PUBLIC_KEY, keyLabel)
+	return err
+}
+
+// findKey returns the single elliptic curve key object of the given class with the label
+func (t *PKCS11Token) findKey(class uint, label string) (pkcs11.ObjectHandle, error) {
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_EC),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+	}
+	if err := t.ctx.FindObjectsInit(t.session, template); err != nil {
+		return 0, errors.WithMessage(err, "Could not search PKCS#11 objects")
+	}
+	objects, _, err := t.ctx.FindObjects(t.session, 2)
+	if finalErr := t.ctx.FindObjectsFinal(t.session); err == nil {
+		err = finalErr
+	}
+	if err != nil {
+		return 0, errors.WithMessage(err, "Could not search PKCS#11 objects")
+	}
+	if len(objects) != 1 {
+		return 0, errors.Errorf("Expected a single PKCS#11 key with label %s, found %d", label, len(objects))
+	}
+	return objects[0], nil
+}
+
+// ECPoint returns the CKA_EC_POINT attribute of the oracle public key object
+func (t *PKCS11Token) ECPoint() ([]byte, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	attributes, err := t.ctx.GetAttributeValue(
+		t.session, t.publicKey, []*pkcs11.Attribute{pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil)})
+	if err != nil {
+		return nil, err
+	}
+	return attributes[0].Value, nil
+}
+
+// Sign calls C_Sign with the oracle private key, the given mechanism and mechanism parameter
+func (t *PKCS11Token) Sign(mechanism uint, parameter []byte, data []byte) ([]byte, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	var param interface{}
+	if parameter != nil {
+		param = parameter
+	}
+	err := t.ctx.SignInit(t.session, []*pkcs11.Mechanism{pkcs11.NewMechanism(mechanism, param)}, t.privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return t.ctx.Sign(t.session, data)
+}
+
+// Close logs out and closes the session, and unloads the PKCS#11 module
+func (t *PKCS11Token) Close() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.session != 0 {
+		t.ctx.Logout(t.session)
+		t.ctx.CloseSession(t.session)
+		t.session = 0
+	}
+	t.ctx.Finalize()
+	t.ctx.Destroy()
+}
@@ -0,0 +1,136 @@
+// +build pkcs11,cgo
+
+package hsm_test
+
+import (
+	"fmt"
+	"os"
+	"p2pderivatives-oracle/internal/godlccrypto"
+	"p2pderivatives-oracle/internal/hsm"
+	"strings"
+	"testing"
+
+	"github.com/miekg/pkcs11"
+	"github.com/stretchr/testify/assert"
+)
+
+// the tests use a SoftHSM token, SOFTHSM2_CONF pointing to a configuration whose token directory is writable
+const (
+	envTestModule   = "P2PD_TEST_PKCS11_MODULE"
+	testTokenLabel  = "p2pdoracle-test"
+	testPin         = "1234"
+	testKeyLabel    = "oracle"
+	testSecp256k1ID = "\x06\x05\x2b\x81\x04\x00\x0a"
+)
+
+// number of tokens initialized by the tests, each test using its own token
+var testTokenCount = 0
+
+// SetupSoftHSMToken initializes a token with a secp256k1 key pair and returns its configuration
+func SetupSoftHSMToken(t *testing.T) *hsm.Config {
+	module := os.Getenv(envTestModule)
+	if module == "" {
+		t.Skipf("%s is not set", envTestModule)
+	}
+	testTokenCount++
+	tokenLabel := fmt.Sprintf("%s-%d", testTokenLabel, testTokenCount)
+	ctx := pkcs11.New(module)
+	if ctx == nil || ctx.Initialize() != nil {
+		t.Fatalf("Could not load %s", module)
+	}
+	defer ctx.Destroy()
+	defer ctx.Finalize()
+	slots, err := ctx.GetSlotList(false)
+	if err != nil || len(slots) == 0 {
+		t.Fatal("No PKCS#11 slot")
+	}
+	slot := slots[len(slots)-1]
+	if err := ctx.InitToken(slot, "so-"+testPin, tokenLabel); err != nil {
+		t.Fatal(err)
+	}
+	// the token is assigned to a new slot by SoftHSM once initialized
+	slots, _ = ctx.GetSlotList(true)
+	for _, s := range slots {
+		if info, err := ctx.GetTokenInfo(s); err == nil && strings.TrimSpace(info.Label) == tokenLabel {
+			slot = s
+		}
+	}
+	session, err := ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ctx.CloseSession(session)
+	if err := ctx.Login(session, pkcs11.CKU_SO, "so-"+testPin); err != nil {
+		t.Fatal(err)
+	}
+	if err := ctx.InitPIN(session, testPin); err != nil {
+		t.Fatal(err)
+	}
+	ctx.Logout(session)
+	if err := ctx.Login(session, pkcs11.CKU_USER, testPin); err != nil {
+		t.Fatal(err)
+	}
+	defer ctx.Logout(session)
+	_, _, err = ctx.GenerateKeyPair(session,
+		[]*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_EC_KEY_PAIR_GEN, nil)},
+		[]*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+			pkcs11.NewAttribute(pkcs11.CKA_LABEL, testKeyLabel),
+			pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, []byte(testSecp256k1ID)),
+		},
+		[]*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+			pkcs11.NewAttribute(pkcs11.CKA_LABEL, testKeyLabel),
+			pkcs11.NewAttribute(pkcs11.CKA_SIGN, true),
+			pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, true),
+			pkcs11.NewAttribute(pkcs11.CKA_EXTRACTABLE, false),
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &hsm.Config{
+		Enabled:    true,
+		Module:     module,
+		TokenLabel: tokenLabel,
+		Pin:        testPin,
+		KeyLabel:   testKeyLabel,
+		Mechanisms: testMechanisms,
+	}
+}
+
+func TestOpenToken_SoftHSM_ReturnsOraclePublicKey(t *testing.T) {
+	config := SetupSoftHSMToken(t)
+	token, err := hsm.OpenToken(config)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer token.Close()
+
+	signer, err := hsm.NewSigner(token, config.Mechanisms, godlccrypto.NewGoCryptoService())
+
+	if assert.NoError(t, err) {
+		assert.Len(t, signer.PublicKey().EncodeToString(), 64)
+	}
+}
+
+func TestSigner_SoftHSM_VendorMechanismUnsupported_ReturnsError(t *testing.T) {
+	config := SetupSoftHSMToken(t)
+	signer, err := hsm.NewSignerFromConfig(config, godlccrypto.NewGoCryptoService())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// SoftHSM does not implement the vendor mechanisms
+	_, err = signer.DeriveSchnorrNonce("btcusd", 1623133104, 0)
+
+	assert.Error(t, err)
+}
+
+func TestOpenToken_WrongPin_ReturnsError(t *testing.T) {
+	config := SetupSoftHSMToken(t)
+	config.Pin = "wrong"
+
+	_, err := hsm.OpenToken(config)
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,15 @@
+// +build !pkcs11 !cgo
+
+package hsm
+
+import (
+	"p2pderivatives-oracle/internal/dlccrypto"
+
+	"github.com/pkg/errors"
+)
+
+// NewSignerFromConfig returns an error as the PKCS#11 tokens can only be used
+// in binaries built with the pkcs11 tag and cgo enabled
+func NewSignerFromConfig(config *Config, cryptoService dlccrypto.CryptoService) (*Signer, error) {
+	return nil, errors.New("Signing with a PKCS#11 token requires a build with the pkcs11 tag and cgo enabled")
+}
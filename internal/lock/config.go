@@ -0,0 +1,20 @@
+package lock
+
+import "time"
+
+const (
+	// ProviderLocal provider of the locks only serializing the requests of the oracle process
+	ProviderLocal = "local"
+	// ProviderPostgres provider of the locks serializing the requests of all the oracle processes
+	// sharing the same postgres database (advisory locks)
+	ProviderPostgres = "postgres"
+)
+
+// Config contains the configuration of the locks serializing the creation of the announcement
+// and attestation of an event
+type Config struct {
+	// Provider of the locks, either local (single oracle process) or postgres (several oracle processes)
+	Provider string `configkey:"lock.provider" default:"local"`
+	// Timeout maximum duration to wait for a lock held by another oracle process
+	Timeout time.Duration `configkey:"lock.timeout,duration,iso8601" default:"PT30S"`
+}
@@ -0,0 +1,73 @@
+package lock
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// Locker provides exclusive locks on string keys
+type Locker interface {
+	// Lock blocks until the lock of the key is acquired and returns the function releasing it
+	Lock(key string) (unlock func(), err error)
+}
+
+// NewLocker returns the locker of the configured provider, the postgres locker using the given database
+func NewLocker(config *Config, db *gorm.DB) (Locker, error) {
+	switch config.Provider {
+	case "", ProviderLocal:
+		return NewLocalLocker(), nil
+	case ProviderPostgres:
+		if db.Dialector.Name() != "postgres" {
+			return nil, errors.Errorf("The %s lock provider requires a postgres database", ProviderPostgres)
+		}
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, err
+		}
+		return NewPostgresLocker(sqlDB, config.Timeout), nil
+	default:
+		return nil, errors.Errorf("Unknown lock provider %s", config.Provider)
+	}
+}
+
+// NewLocalLocker returns a locker only serializing the goroutines of the current process
+func NewLocalLocker() Locker {
+	return &localLocker{
+		locks: make(map[string]*localLock),
+	}
+}
+
+type localLocker struct {
+	mut   sync.Mutex
+	locks map[string]*localLock
+}
+
+type localLock struct {
+	sync.Mutex
+	// number of goroutines holding or waiting for the lock, the lock being removed when there is none
+	refs int
+}
+
+func (l *localLocker) Lock(key string) (func(), error) {
+	l.mut.Lock()
+	lock, ok := l.locks[key]
+	if !ok {
+		lock = &localLock{}
+		l.locks[key] = lock
+	}
+	lock.refs++
+	l.mut.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+		l.mut.Lock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(l.locks, key)
+		}
+		l.mut.Unlock()
+	}, nil
+}
@@ -0,0 +1,95 @@
+package lock_test
+
+import (
+	"p2pderivatives-oracle/internal/lock"
+	"p2pderivatives-oracle/test"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalLocker_SameKey_Serialized(t *testing.T) {
+	locker := lock.NewLocalLocker()
+	var wg sync.WaitGroup
+	// not synchronized otherwise, so that the race detector reports concurrent accesses
+	counter := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock, err := locker.Lock("btcusd")
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer unlock()
+			value := counter
+			time.Sleep(time.Millisecond)
+			counter = value + 1
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 20, counter)
+}
+
+func TestLocalLocker_OtherKey_NotBlocked(t *testing.T) {
+	locker := lock.NewLocalLocker()
+	unlock, err := locker.Lock("btcusd")
+	require.NoError(t, err)
+	defer unlock()
+
+	done := make(chan struct{})
+	go func() {
+		unlockOther, err := locker.Lock("btcjpy")
+		if assert.NoError(t, err) {
+			unlockOther()
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("The lock of another key was blocked")
+	}
+}
+
+func TestLocalLocker_Unlocked_CanBeLockedAgain(t *testing.T) {
+	locker := lock.NewLocalLocker()
+	unlock, err := locker.Lock("btcusd")
+	require.NoError(t, err)
+	unlock()
+
+	unlock, err = locker.Lock("btcusd")
+	require.NoError(t, err)
+	unlock()
+}
+
+func TestNewLocker_Providers(t *testing.T) {
+	db := test.NewOrm().GetDB()
+	tests := []struct {
+		name     string
+		provider string
+		hasError bool
+	}{
+		{name: "default", provider: ""},
+		{name: "local", provider: lock.ProviderLocal},
+		{name: "postgres without postgres database", provider: lock.ProviderPostgres, hasError: true},
+		{name: "unknown", provider: "redis", hasError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			locker, err := lock.NewLocker(&lock.Config{Provider: tt.provider}, db)
+
+			if tt.hasError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, locker)
+			}
+		})
+	}
+}
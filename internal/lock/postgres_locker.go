@@ -0,0 +1,65 @@
+package lock
+
+import (
+	"context"
+	"database/sql"
+	"hash/fnv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// NewPostgresLocker returns a locker serializing all the processes sharing the database with postgres
+// advisory locks, waiting at most timeout for a lock (no limit if zero)
+func NewPostgresLocker(db *sql.DB, timeout time.Duration) Locker {
+	return &postgresLocker{
+		db:      db,
+		timeout: timeout,
+		// the goroutines of the process wait for each other before waiting on the database
+		// so that a single connection is used per key
+		local: NewLocalLocker(),
+	}
+}
+
+type postgresLocker struct {
+	db      *sql.DB
+	timeout time.Duration
+	local   Locker
+}
+
+func (l *postgresLocker) Lock(key string) (func(), error) {
+	unlockLocal, err := l.local.Lock(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// the lock is bound to a dedicated transaction so that it is released with it, even if the connection is lost
+	tx, err := l.db.Begin()
+	if err != nil {
+		unlockLocal()
+		return nil, errors.WithMessage(err, "Could not start the lock transaction")
+	}
+	ctx := context.Background()
+	if l.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, l.timeout)
+		defer cancel()
+	}
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", advisoryLockID(key)); err != nil {
+		tx.Rollback()
+		unlockLocal()
+		return nil, errors.WithMessagef(err, "Could not acquire the lock %s", key)
+	}
+
+	return func() {
+		tx.Rollback()
+		unlockLocal()
+	}, nil
+}
+
+// advisoryLockID returns the 64 bits identifier of the advisory lock of the key
+func advisoryLockID(key string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return int64(h.Sum64())
+}
@@ -2,46 +2,127 @@ package oracle
 
 import (
 	"p2pderivatives-oracle/internal/dlccrypto"
+	"p2pderivatives-oracle/internal/envelope"
+	"sort"
+	"time"
 
 	"github.com/cryptogarageinc/server-common-go/pkg/utils/file"
-
 	"github.com/pkg/errors"
 )
 
-// Oracle represents an oracle with private key, public key pair
+// Oracle represents an oracle with the history of its keys
 type Oracle struct {
-	PrivateKey *dlccrypto.PrivateKey
-	PublicKey  *dlccrypto.SchnorrPublicKey
+	// Keys of the oracle sorted by activation date
+	Keys []*Key
+	// KvalueKeyring decrypts the one time signing keys stored with the events
+	// created before the nonces were derived (nil if none is configured)
+	KvalueKeyring *envelope.Keyring
+}
+
+// Key represents a key of the oracle, with the signer holding its private key
+type Key struct {
+	Signer    dlccrypto.Signer
+	PublicKey *dlccrypto.SchnorrPublicKey
+	// ActivationDate date from which the key is used to announce the events
+	ActivationDate time.Time
+}
+
+// New returns a new Oracle instance with a single key signing with the given signer
+func New(signer dlccrypto.Signer) *Oracle {
+	oracle, _ := NewWithKeys([]*Key{{Signer: signer, PublicKey: signer.PublicKey()}})
+	return oracle
+}
+
+// NewWithKeys returns a new Oracle instance with the given keys history
+func NewWithKeys(keys []*Key) (*Oracle, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("Oracle requires at least one key")
+	}
+	sorted := make([]*Key, len(keys))
+	copy(sorted, keys)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ActivationDate.Before(sorted[j].ActivationDate)
+	})
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].ActivationDate.Equal(sorted[i-1].ActivationDate) {
+			return nil, errors.Errorf("Several oracle keys have the activation date %s", sorted[i].ActivationDate)
+		}
+	}
+	return &Oracle{Keys: sorted}, nil
+}
+
+// ActiveKey returns the key used to announce events at the given date,
+// which is the last key activated before the date (or the first key if none is activated yet)
+func (o *Oracle) ActiveKey(date time.Time) *Key {
+	active := o.Keys[0]
+	for _, key := range o.Keys[1:] {
+		if key.ActivationDate.After(date) {
+			break
+		}
+		active = key
+	}
+	return active
 }
 
-// New returns a new Oracle instance
-// the public key will be calculated from the private key
-func New(privateKey *dlccrypto.PrivateKey, publicKey *dlccrypto.SchnorrPublicKey) *Oracle {
-	return &Oracle{
-		PrivateKey: privateKey,
-		PublicKey:  publicKey,
+// PublicKey returns the public key of the currently active key
+func (o *Oracle) PublicKey() *dlccrypto.SchnorrPublicKey {
+	return o.ActiveKey(time.Now().UTC()).PublicKey
+}
+
+// EventKey returns the key with the given public key which announced an event,
+// the events announced before the key was recorded with them belonging to the first key
+func (o *Oracle) EventKey(publicKey string) (*Key, error) {
+	if publicKey == "" {
+		return o.Keys[0], nil
+	}
+	for _, key := range o.Keys {
+		if key.PublicKey.EncodeToString() == publicKey {
+			return key, nil
+		}
 	}
+	return nil, errors.Errorf("Oracle key %s is not configured", publicKey)
 }
 
 // FromConfig returns an oracle from configuration
 // password has to be defined either from a file or directly in configuration (environment variable)
 // in case of using a txt file as password, the first line will be considered as password
 func FromConfig(config *Config, cryptoService dlccrypto.CryptoService) (*Oracle, error) {
+	if len(config.Keys) == 0 {
+		key, err := newKey(config.KeyFile, config.KeyPass, config.KeyPassFile, cryptoService)
+		if err != nil {
+			return nil, err
+		}
+		return NewWithKeys([]*Key{key})
+	}
+
+	keys := make([]*Key, 0, len(config.Keys))
+	for name, keyConfig := range config.Keys {
+		key, err := newKey(keyConfig.KeyFile, keyConfig.KeyPass, keyConfig.KeyPassFile, cryptoService)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "Invalid oracle key %s", name)
+		}
+		key.ActivationDate = keyConfig.ActivationDate
+		keys = append(keys, key)
+	}
+	return NewWithKeys(keys)
+}
+
+func newKey(keyFile string, keyPass string, keyPassFile string, cryptoService dlccrypto.CryptoService) (*Key, error) {
 	var pass string
 	var err error
-	if config.KeyPass == "" && config.KeyPassFile == "" {
-		return nil, errors.Errorf("No password or password file provided for key %s", config.KeyFile)
+	if keyPass == "" && keyPassFile == "" {
+		return nil, errors.Errorf("No password or password file provided for key %s", keyFile)
 	}
-	if config.KeyPass != "" {
-		pass = config.KeyPass
+	if keyPass != "" {
+		pass = keyPass
 	}
-	if config.KeyPassFile != "" {
-		pass, err = file.ReadFirstLineFromFile(config.KeyPassFile)
+	if keyPassFile != "" {
+		pass, err = file.ReadFirstLineFromFile(keyPassFile)
 		if err != nil {
 			return nil, err
 		}
 	}
-	privKey, err := dlccrypto.ReadPemKeyFile(config.KeyFile, []byte(pass))
+	privKey, err := dlccrypto.ReadPemKeyFile(keyFile, []byte(pass))
 	if err != nil {
 		return nil, errors.WithMessage(err, "Could not recover Oracle Private Key")
 	}
@@ -49,5 +130,8 @@ func FromConfig(config *Config, cryptoService dlccrypto.CryptoService) (*Oracle,
 	if err != nil {
 		return nil, errors.WithMessage(err, "Could not get public key from private key")
 	}
-	return New(privKey, publicKey), nil
+	return &Key{
+		Signer:    dlccrypto.NewKeySigner(privKey, publicKey, cryptoService),
+		PublicKey: publicKey,
+	}, nil
 }
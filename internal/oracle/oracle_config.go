@@ -1,9 +1,24 @@
 package oracle
 
+import "time"
+
 // Config contains the configuration parameters of the oracle.
 type Config struct {
 	// KeyFile has to be a path to a PEM format encoded secp256k1 key
-	KeyFile     string `configkey:"oracle.keyFile" validate:"required"`
+	KeyFile     string `configkey:"oracle.keyFile" validate:"required_without=Keys"`
 	KeyPassFile string `configkey:"oracle.keyPass.file"`
 	KeyPass     string `configkey:"oracle.keyPass"`
+	// Keys history of the oracle keys (by name) used instead of KeyFile,
+	// each key announcing the events from its activation date
+	Keys map[string]KeyConfig `configkey:"oracle.keys" validate:"omitempty,dive"`
+}
+
+// KeyConfig contains the configuration parameters of one of the oracle keys.
+type KeyConfig struct {
+	// KeyFile has to be a path to a PEM format encoded secp256k1 key
+	KeyFile     string `configkey:"keyFile" validate:"required"`
+	KeyPassFile string `configkey:"keyPass.file"`
+	KeyPass     string `configkey:"keyPass"`
+	// ActivationDate date from which the key is used to announce the events
+	ActivationDate time.Time `configkey:"activationDate"`
 }
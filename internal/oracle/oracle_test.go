@@ -2,10 +2,12 @@ package oracle_test
 
 import (
 	"p2pderivatives-oracle/internal/cfddlccrypto"
+	"p2pderivatives-oracle/internal/dlccrypto"
 	"p2pderivatives-oracle/internal/oracle"
 	"p2pderivatives-oracle/test"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -24,6 +26,24 @@ var ExpectedKeyPair = struct {
 	passPath:   filepath.Join(test.VectorsDirectoryPath, "oracle/pass.txt"),
 }
 
+func assertSignsWithExpectedKey(t *testing.T, oracleInstance *oracle.Oracle) {
+	crypto := cfddlccrypto.NewCfdgoCryptoService()
+	privKey, err := dlccrypto.NewPrivateKey(ExpectedKeyPair.privateKey)
+	assert.NoError(t, err)
+	kvalue, rvalue, err := crypto.DeriveSchnorrNonce(privKey, "btcusd", 1623133104, 0)
+	assert.NoError(t, err)
+	expected, err := crypto.ComputeSchnorrSignatureFixedK(privKey, kvalue, "1200")
+	assert.NoError(t, err)
+
+	actualNonce, err := oracleInstance.Keys[0].Signer.DeriveSchnorrNonce("btcusd", 1623133104, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, rvalue.EncodeToString(), actualNonce.EncodeToString())
+	actual, err := oracleInstance.Keys[0].Signer.ComputeSchnorrSignatureWithNonce(
+		&dlccrypto.EventNonce{AssetID: "btcusd", EventMaturity: 1623133104, Index: 0}, "1200")
+	assert.NoError(t, err)
+	assert.Equal(t, expected.EncodeToString(), actual.EncodeToString())
+}
+
 func Test_FromConfig_WithPass_ReturnsOracle(t *testing.T) {
 	config := &oracle.Config{
 		KeyFile: ExpectedKeyPair.keyPath,
@@ -33,8 +53,8 @@ func Test_FromConfig_WithPass_ReturnsOracle(t *testing.T) {
 	oracleInstance, err := oracle.FromConfig(config, cfddlccrypto.NewCfdgoCryptoService())
 	assert.NoError(t, err)
 	if assert.NotNil(t, oracleInstance) {
-		assert.Equal(t, ExpectedKeyPair.privateKey, oracleInstance.PrivateKey.EncodeToString())
-		assert.Equal(t, ExpectedKeyPair.publicKey, oracleInstance.PublicKey.EncodeToString())
+		assertSignsWithExpectedKey(t, oracleInstance)
+		assert.Equal(t, ExpectedKeyPair.publicKey, oracleInstance.PublicKey().EncodeToString())
 	}
 }
 
@@ -46,8 +66,8 @@ func Test_FromConfig_WithPassFile_ReturnsOracle(t *testing.T) {
 	oracleInstance, err := oracle.FromConfig(config, cfddlccrypto.NewCfdgoCryptoService())
 	assert.NoError(t, err)
 	if assert.NotNil(t, oracleInstance) {
-		assert.Equal(t, ExpectedKeyPair.privateKey, oracleInstance.PrivateKey.EncodeToString())
-		assert.Equal(t, ExpectedKeyPair.publicKey, oracleInstance.PublicKey.EncodeToString())
+		assertSignsWithExpectedKey(t, oracleInstance)
+		assert.Equal(t, ExpectedKeyPair.publicKey, oracleInstance.PublicKey().EncodeToString())
 	}
 }
 
@@ -58,3 +78,74 @@ func Test_FromConfig_WithNoPass_ReturnsError(t *testing.T) {
 	_, err := oracle.FromConfig(config, cfddlccrypto.NewCfdgoCryptoService())
 	assert.NotNil(t, err)
 }
+
+func Test_FromConfig_WithKeys_ReturnsOracleWithKeysHistory(t *testing.T) {
+	crypto := cfddlccrypto.NewCfdgoCryptoService()
+	activation := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	config := &oracle.Config{
+		Keys: map[string]oracle.KeyConfig{
+			"rotated": {
+				KeyFile:        filepath.Join(test.VectorsDirectoryPath, "keys/key_0.pem"),
+				KeyPass:        "wKeEhq0DP/rNtcD8u/NxLyJYKmyKqOzklgOamGJlbSA=",
+				ActivationDate: activation,
+			},
+			"initial": {
+				KeyFile:     ExpectedKeyPair.keyPath,
+				KeyPassFile: ExpectedKeyPair.passPath,
+			},
+		},
+	}
+	oracleInstance, err := oracle.FromConfig(config, crypto)
+	assert.NoError(t, err)
+	if !assert.NotNil(t, oracleInstance) || !assert.Len(t, oracleInstance.Keys, 2) {
+		return
+	}
+	rotatedKey, _ := dlccrypto.NewPrivateKey("83e03f14bd6ae801ff21430ec4f745c8ca749945c9c0ba147216c2fd4a6e6df6")
+	rotatedPubKey, _ := crypto.SchnorrPublicKeyFromPrivateKey(rotatedKey)
+
+	initial := oracleInstance.Keys[0]
+	rotated := oracleInstance.Keys[1]
+	assert.Equal(t, ExpectedKeyPair.publicKey, initial.PublicKey.EncodeToString())
+	assert.Equal(t, rotatedPubKey.EncodeToString(), rotated.PublicKey.EncodeToString())
+	assert.Equal(t, activation, rotated.ActivationDate)
+
+	assert.Equal(t, initial, oracleInstance.ActiveKey(activation.Add(-time.Second)))
+	assert.Equal(t, rotated, oracleInstance.ActiveKey(activation))
+	assert.Equal(t, rotated.PublicKey, oracleInstance.PublicKey())
+}
+
+func Test_EventKey_ReturnsKeyWhichAnnouncedTheEvent(t *testing.T) {
+	crypto := cfddlccrypto.NewCfdgoCryptoService()
+	keys := make([]*oracle.Key, 2)
+	for i := range keys {
+		priv, pub, err := crypto.GenerateSchnorrKeyPair()
+		assert.NoError(t, err)
+		keys[i] = &oracle.Key{
+			Signer:         dlccrypto.NewKeySigner(priv, pub, crypto),
+			PublicKey:      pub,
+			ActivationDate: time.Date(2021, time.Month(i+1), 1, 0, 0, 0, 0, time.UTC),
+		}
+	}
+	oracleInstance, err := oracle.NewWithKeys(keys)
+	assert.NoError(t, err)
+
+	actual, err := oracleInstance.EventKey(keys[1].PublicKey.EncodeToString())
+	assert.NoError(t, err)
+	assert.Equal(t, keys[1], actual)
+	// events announced before the keys were recorded belong to the first key
+	actual, err = oracleInstance.EventKey("")
+	assert.NoError(t, err)
+	assert.Equal(t, keys[0], actual)
+	_, err = oracleInstance.EventKey("unknown")
+	assert.Error(t, err)
+}
+
+func Test_NewWithKeys_SameActivationDate_ReturnsError(t *testing.T) {
+	crypto := cfddlccrypto.NewCfdgoCryptoService()
+	priv, pub, _ := crypto.GenerateSchnorrKeyPair()
+	key := &oracle.Key{Signer: dlccrypto.NewKeySigner(priv, pub, crypto), PublicKey: pub}
+
+	_, err := oracle.NewWithKeys([]*oracle.Key{key, key})
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,65 @@
+package threshold
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// HeaderTimestamp header of the unix timestamp at which a request was sent
+	HeaderTimestamp = "X-Threshold-Timestamp"
+	// HeaderSignature header of the HMAC-SHA256 of a request (see requestMAC)
+	HeaderSignature = "X-Threshold-Signature"
+	// MaxClockSkew maximum difference between the timestamp of a request and the time it is received
+	MaxClockSkew = 30 * time.Second
+)
+
+// requestMAC authenticates a request with the secret shared by the coordinator and the participant,
+// the requests being idempotent a replayed request has no effect
+func requestMAC(secret []byte, method string, route string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(method + "\n" + route + "\n" + timestamp + "\n"))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Authenticate returns a middleware rejecting the requests which are not authenticated with the secret
+// or which were sent too long ago
+func Authenticate(secret []byte) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timestamp := c.GetHeader(HeaderTimestamp)
+		unix, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, &ErrorResponse{Message: "Missing or invalid timestamp"})
+			return
+		}
+		if skew := time.Since(time.Unix(unix, 0)); skew > MaxClockSkew || skew < -MaxClockSkew {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, &ErrorResponse{Message: "Request timestamp out of range"})
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil {
+			body, err = ioutil.ReadAll(c.Request.Body)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, &ErrorResponse{Message: "Could not read request body"})
+				return
+			}
+			c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+		expected := requestMAC(secret, c.Request.Method, c.FullPath(), timestamp, body)
+		if !hmac.Equal([]byte(expected), []byte(c.GetHeader(HeaderSignature))) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, &ErrorResponse{Message: "Invalid request signature"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,96 @@
+package threshold
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+)
+
+// participantClient sends the authenticated requests of the coordinator to a participant
+type participantClient struct {
+	name       string
+	secret     []byte
+	httpClient *resty.Client
+}
+
+func newParticipantClient(name string, url string, secret []byte, timeout time.Duration) *participantClient {
+	httpClient := resty.New()
+	httpClient.SetHostURL(url + BaseRoute)
+	httpClient.SetHeader("Accept", "application/json")
+	httpClient.SetTimeout(timeout)
+	return &participantClient{
+		name:       name,
+		secret:     secret,
+		httpClient: httpClient,
+	}
+}
+
+func (c *participantClient) info() (*ParticipantInfo, error) {
+	info := &ParticipantInfo{}
+	if err := c.send(http.MethodGet, RouteGETInfo, nil, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func (c *participantClient) nonceShares(ids []NonceID) ([]string, error) {
+	response := &NonceSharesResponse{}
+	if err := c.send(http.MethodPost, RoutePOSTNonces, &NonceSharesRequest{IDs: ids}, response); err != nil {
+		return nil, err
+	}
+	if len(response.Shares) != len(ids) {
+		return nil, errors.Errorf("Participant %s returned %d nonce shares instead of %d", c.name, len(response.Shares), len(ids))
+	}
+	return response.Shares, nil
+}
+
+func (c *participantClient) commitNonces(commitments []NonceCommitment) error {
+	return c.send(http.MethodPost, RoutePOSTCommit, &CommitNoncesRequest{Nonces: commitments}, nil)
+}
+
+func (c *participantClient) sign(commitment *NonceCommitment, message []byte) (string, error) {
+	response := &SignResponse{}
+	err := c.send(http.MethodPost, RoutePOSTSign, &SignRequest{Nonce: *commitment, Message: message}, response)
+	if err != nil {
+		return "", err
+	}
+	return response.PartialSignature, nil
+}
+
+// send sends a request authenticated with the secret (see requestMAC), decoding the response in result
+func (c *participantClient) send(method string, route string, body interface{}, result interface{}) error {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	request := c.httpClient.R().
+		SetHeader(HeaderTimestamp, timestamp).
+		SetHeader(HeaderSignature, requestMAC(c.secret, method, BaseRoute+route, timestamp, data)).
+		SetError(&ErrorResponse{})
+	if data != nil {
+		request.SetHeader("Content-Type", "application/json").SetBody(data)
+	}
+	if result != nil {
+		request.SetResult(result)
+	}
+
+	resp, err := request.Execute(method, route)
+	if err != nil {
+		return errors.WithMessagef(err, "Request to participant %s failed", c.name)
+	}
+	if resp.IsError() {
+		if errResponse, ok := resp.Error().(*ErrorResponse); ok && errResponse.Message != "" {
+			return errors.Errorf("Participant %s returned an error (%d): %s", c.name, resp.StatusCode(), errResponse.Message)
+		}
+		return errors.Errorf("Participant %s returned an error (%d)", c.name, resp.StatusCode())
+	}
+	return nil
+}
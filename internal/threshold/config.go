@@ -0,0 +1,67 @@
+package threshold
+
+import (
+	"time"
+
+	"github.com/cryptogarageinc/server-common-go/pkg/utils/file"
+	"github.com/pkg/errors"
+)
+
+// Config contains the configuration of the oracle signing with the participants of a threshold oracle
+// (the oracle acting as coordinator)
+type Config struct {
+	Enabled bool `configkey:"threshold.enabled"`
+	// PublicKey schnorr public key of the oracle shared by the participants
+	PublicKey    string                       `configkey:"threshold.publicKey"`
+	Participants map[string]ParticipantConfig `configkey:"threshold.participants" validate:"omitempty,dive"`
+	Timeout      time.Duration                `configkey:"threshold.timeout,duration,iso8601" default:"PT10S"`
+}
+
+// ParticipantConfig contains the url of a participant and the secret authenticating the requests sent to it
+type ParticipantConfig struct {
+	URL        string `configkey:"url" validate:"required"`
+	Secret     string `configkey:"secret" validate:"required_without=SecretFile"`
+	SecretFile string `configkey:"secretFile" validate:"required_without=Secret"`
+}
+
+// ParticipantServerConfig contains the configuration of an oracle running as a participant of a threshold oracle
+type ParticipantServerConfig struct {
+	Enabled bool `configkey:"participant.enabled"`
+	// ShareFile path of the encrypted key share file of the participant (see Split)
+	ShareFile     string `configkey:"participant.shareFile"`
+	SharePass     string `configkey:"participant.sharePass"`
+	SharePassFile string `configkey:"participant.sharePassFile"`
+	// Secret authenticating the requests of the coordinator
+	Secret     string `configkey:"participant.secret"`
+	SecretFile string `configkey:"participant.secretFile"`
+}
+
+// ReadKeyShare returns the key share of the participant
+func (c *ParticipantServerConfig) ReadKeyShare() (*KeyShare, error) {
+	if c.ShareFile == "" {
+		return nil, errors.New("No key share file configured")
+	}
+	pass, err := ReadSecret(c.SharePass, c.SharePassFile)
+	if err != nil {
+		return nil, errors.WithMessage(err, "Invalid key share password")
+	}
+	return ReadKeyShareFile(c.ShareFile, pass)
+}
+
+// ReadSecret returns the secret either given directly or as the first line of a file
+func ReadSecret(secret string, secretFile string) ([]byte, error) {
+	if secret != "" {
+		return []byte(secret), nil
+	}
+	if secretFile == "" {
+		return nil, errors.New("No secret or secret file provided")
+	}
+	secret, err := file.ReadFirstLineFromFile(secretFile)
+	if err != nil {
+		return nil, err
+	}
+	if secret == "" {
+		return nil, errors.Errorf("Could not read secret from %s", secretFile)
+	}
+	return []byte(secret), nil
+}
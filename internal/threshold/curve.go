@@ -0,0 +1,150 @@
+package threshold
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"p2pderivatives-oracle/internal/dlccrypto"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/pkg/errors"
+)
+
+// tag of the BIP340 challenge hash
+const bip340ChallengeTag = "BIP0340/challenge"
+
+// parseScalar parses a 32 bytes scalar, which must be lower than the curve order
+func parseScalar(b []byte) (*secp256k1.ModNScalar, error) {
+	if len(b) != 32 {
+		return nil, errors.New("Invalid scalar size")
+	}
+	s := new(secp256k1.ModNScalar)
+	if overflow := s.SetByteSlice(b); overflow {
+		return nil, errors.New("Scalar is out of range")
+	}
+	return s, nil
+}
+
+// hashToScalar returns the hash reduced modulo the curve order
+func hashToScalar(hash [32]byte) *secp256k1.ModNScalar {
+	s := new(secp256k1.ModNScalar)
+	s.SetBytes(&hash)
+	return s
+}
+
+// intScalar returns the scalar of a (possibly negative) integer
+func intScalar(i int) *secp256k1.ModNScalar {
+	if i < 0 {
+		return new(secp256k1.ModNScalar).SetInt(uint32(-i)).Negate()
+	}
+	return new(secp256k1.ModNScalar).SetInt(uint32(i))
+}
+
+// lagrangeCoefficient returns the coefficient of the share of participant i to interpolate
+// at x the polynomial from the shares of the given participants
+func lagrangeCoefficient(i int, indices []int, x int) *secp256k1.ModNScalar {
+	num := intScalar(1)
+	den := intScalar(1)
+	for _, j := range indices {
+		if j == i {
+			continue
+		}
+		num.Mul(intScalar(x - j))
+		den.Mul(intScalar(i - j))
+	}
+	return num.Mul(den.InverseNonConst())
+}
+
+// baseMult returns the affine point k*G
+func baseMult(k *secp256k1.ModNScalar) *secp256k1.JacobianPoint {
+	var p secp256k1.JacobianPoint
+	secp256k1.ScalarBaseMultNonConst(k, &p)
+	p.ToAffine()
+	return &p
+}
+
+// pointMult returns the affine point k*P
+func pointMult(k *secp256k1.ModNScalar, p *secp256k1.JacobianPoint) *secp256k1.JacobianPoint {
+	var res secp256k1.JacobianPoint
+	secp256k1.ScalarMultNonConst(k, p, &res)
+	res.ToAffine()
+	return &res
+}
+
+// pointAdd returns the affine point P+Q
+func pointAdd(p *secp256k1.JacobianPoint, q *secp256k1.JacobianPoint) *secp256k1.JacobianPoint {
+	var res secp256k1.JacobianPoint
+	secp256k1.AddNonConst(p, q, &res)
+	res.ToAffine()
+	return &res
+}
+
+// isInfinity returns true if the point is the point at infinity
+func isInfinity(p *secp256k1.JacobianPoint) bool {
+	return (p.X.IsZero() && p.Y.IsZero()) || p.Z.IsZero()
+}
+
+// equalPoints returns true if the affine points are equal
+func equalPoints(p *secp256k1.JacobianPoint, q *secp256k1.JacobianPoint) bool {
+	return p.X.Equals(&q.X) && p.Y.Equals(&q.Y)
+}
+
+// encodePoint returns the compressed encoding of an affine point
+func encodePoint(p *secp256k1.JacobianPoint) []byte {
+	return secp256k1.NewPublicKey(&p.X, &p.Y).SerializeCompressed()
+}
+
+// parsePoint parses a compressed point
+func parsePoint(b []byte) (*secp256k1.JacobianPoint, error) {
+	if len(b) != secp256k1.PubKeyBytesLenCompressed {
+		return nil, errors.New("Invalid point size")
+	}
+	pubKey, err := secp256k1.ParsePubKey(b)
+	if err != nil {
+		return nil, err
+	}
+	var p secp256k1.JacobianPoint
+	pubKey.AsJacobian(&p)
+	return &p, nil
+}
+
+// parseHexPoint parses a hex encoded compressed point
+func parseHexPoint(s string) (*secp256k1.JacobianPoint, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return parsePoint(b)
+}
+
+// xOnly returns the x coordinate of an affine point
+func xOnly(p *secp256k1.JacobianPoint) []byte {
+	var x [32]byte
+	p.X.PutBytes(&x)
+	return x[:]
+}
+
+// schnorrPublicKey returns the BIP340 public key (x coordinate) of an affine point
+func schnorrPublicKey(p *secp256k1.JacobianPoint) *dlccrypto.SchnorrPublicKey {
+	// cannot fail as the size is always valid
+	publicKey, _ := dlccrypto.NewSchnorrPublicKey(hex.EncodeToString(xOnly(p)))
+	return publicKey
+}
+
+// challenge computes the BIP340 challenge of a signature on the message (hashed by sha256 as by the crypto services)
+func challenge(r *secp256k1.JacobianPoint, p *secp256k1.JacobianPoint, message []byte) *secp256k1.ModNScalar {
+	hash := sha256.Sum256(message)
+	msg := make([]byte, 0, 96)
+	msg = append(msg, xOnly(r)...)
+	msg = append(msg, xOnly(p)...)
+	msg = append(msg, hash[:]...)
+	return hashToScalar(dlccrypto.TaggedHash(bip340ChallengeTag, msg))
+}
+
+// parityFactor returns the factor (1 or -1) applied to the secrets of a point so that it has an even y coordinate
+// as required by BIP340
+func parityFactor(p *secp256k1.JacobianPoint) *secp256k1.ModNScalar {
+	if p.Y.IsOdd() {
+		return intScalar(-1)
+	}
+	return intScalar(1)
+}
@@ -0,0 +1,75 @@
+package threshold
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// NonceID identifies a nonce shared by the participants, either the nonce at an index of an event
+// (used to sign the outcome digit) or the nonce used to sign an announcement (derived from its hash)
+type NonceID struct {
+	AssetID       string `json:"assetId,omitempty"`
+	EventMaturity uint32 `json:"eventMaturity,omitempty"`
+	Index         int    `json:"index,omitempty"`
+	// AnnouncementHash sha256 of the announced oracle event (only set for the announcement nonces)
+	AnnouncementHash []byte `json:"announcementHash,omitempty"`
+}
+
+// EventNonceID returns the ID of the nonce at the given index of an event
+func EventNonceID(assetID string, eventMaturity uint32, index int) NonceID {
+	return NonceID{AssetID: assetID, EventMaturity: eventMaturity, Index: index}
+}
+
+// AnnouncementNonceID returns the ID of the nonce used to sign the given serialized oracle event
+func AnnouncementNonceID(event []byte) NonceID {
+	hash := sha256.Sum256(event)
+	return NonceID{AnnouncementHash: hash[:]}
+}
+
+// IsAnnouncement returns true if the nonce is used to sign an announcement
+func (id NonceID) IsAnnouncement() bool {
+	return len(id.AnnouncementHash) > 0
+}
+
+// Validate checks that the nonce ID is either the ID of an event nonce or of an announcement nonce
+func (id NonceID) Validate() error {
+	if id.IsAnnouncement() {
+		if len(id.AnnouncementHash) != sha256.Size || id.AssetID != "" || id.EventMaturity != 0 || id.Index != 0 {
+			return errors.New("Invalid announcement nonce ID")
+		}
+		return nil
+	}
+	if id.AssetID == "" || id.Index < 0 {
+		return errors.New("Invalid event nonce ID")
+	}
+	return nil
+}
+
+// String returns the key under which the nonce is recorded
+func (id NonceID) String() string {
+	if id.IsAnnouncement() {
+		return "announcement/" + hex.EncodeToString(id.AnnouncementHash)
+	}
+	return fmt.Sprintf("event/%s/%d/%d", id.AssetID, id.EventMaturity, id.Index)
+}
+
+// bytes returns the unambiguous encoding of the ID from which the nonce shares are derived
+func (id NonceID) bytes() []byte {
+	buf := new(bytes.Buffer)
+	if id.IsAnnouncement() {
+		buf.WriteByte(1)
+		buf.Write(id.AnnouncementHash)
+		return buf.Bytes()
+	}
+	buf.WriteByte(0)
+	binary.Write(buf, binary.BigEndian, uint32(len(id.AssetID)))
+	buf.WriteString(id.AssetID)
+	binary.Write(buf, binary.BigEndian, id.EventMaturity)
+	binary.Write(buf, binary.BigEndian, uint32(id.Index))
+	return buf.Bytes()
+}
@@ -0,0 +1,238 @@
+package threshold
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"p2pderivatives-oracle/internal/database/entity"
+	"p2pderivatives-oracle/internal/dlccrypto"
+	"strconv"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// EventVerifier checks the announcements and outcomes signed by a participant against its own
+// configuration and datafeed, so that the participant only signs what it would have published alone
+type EventVerifier interface {
+	// VerifyEvent checks that the serialized oracle event is the event of the asset at the maturity
+	// announced with the given nonces
+	VerifyEvent(assetID string, eventMaturity uint32, nonces []dlccrypto.SchnorrPublicKey, event []byte) error
+	// VerifyOutcome checks that the message is the outcome of the event to sign with the nonce at the given index
+	VerifyOutcome(assetID string, eventMaturity uint32, index int, message string) error
+}
+
+// NewParticipant returns a participant computing partial signatures with its key share
+func NewParticipant(share *KeyShare, verifier EventVerifier) *Participant {
+	return &Participant{
+		share:    share,
+		verifier: verifier,
+	}
+}
+
+// Participant holds a key share of a threshold oracle and computes partial signatures with it.
+// It records the group nonce and the message of each nonce it signs with so that it never reveals
+// its key share by signing twice with the same nonce share.
+type Participant struct {
+	share    *KeyShare
+	verifier EventVerifier
+}
+
+// Info returns the public information of the key share of the participant
+func (p *Participant) Info() *ParticipantInfo {
+	info := &ParticipantInfo{
+		Index:              p.share.Index,
+		Threshold:          p.share.Threshold,
+		Participants:       p.share.Participants,
+		PublicKey:          hex.EncodeToString(encodePoint(p.share.publicKey)),
+		VerificationShares: make([]string, len(p.share.verificationShares)),
+	}
+	for i, share := range p.share.verificationShares {
+		info.VerificationShares[i] = hex.EncodeToString(encodePoint(share))
+	}
+	return info
+}
+
+// NonceShares returns the public nonce shares of the participant for the given nonces
+func (p *Participant) NonceShares(ids []NonceID) ([]string, error) {
+	shares := make([]string, len(ids))
+	for i, id := range ids {
+		if err := id.Validate(); err != nil {
+			return nil, errors.WithMessage(ErrInvalidRequest, err.Error())
+		}
+		k := p.share.nonceShare(id)
+		shares[i] = hex.EncodeToString(encodePoint(baseMult(k)))
+		k.Zero()
+	}
+	return shares, nil
+}
+
+// CommitNonces records the group nonces of event nonces, each nonce keeping the first group nonce
+// recorded for it
+func (p *Participant) CommitNonces(db *gorm.DB, commitments []NonceCommitment) error {
+	for i := range commitments {
+		if commitments[i].ID.IsAnnouncement() {
+			return errors.WithMessage(ErrInvalidRequest, "Announcement nonces are recorded when signing")
+		}
+		groupNonce, err := p.groupNonce(&commitments[i])
+		if err != nil {
+			return err
+		}
+		if err := commitNonce(db, commitments[i].ID, groupNonce); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Sign returns the partial signature of the participant on the message with the requested nonce,
+// after checking that the message is the announcement or outcome the participant would sign alone
+func (p *Participant) Sign(db *gorm.DB, request *SignRequest) (string, error) {
+	id := request.Nonce.ID
+	if !contains(request.Nonce.Signers, p.share.Index) {
+		return "", errors.WithMessage(ErrInvalidRequest, "The participant is not one of the signers")
+	}
+	groupNonce, err := p.groupNonce(&request.Nonce)
+	if err != nil {
+		return "", err
+	}
+
+	if id.IsAnnouncement() {
+		hash := sha256.Sum256(request.Message)
+		if !bytes.Equal(hash[:], id.AnnouncementHash) {
+			return "", errors.WithMessage(ErrInvalidRequest, "The announcement does not match the nonce")
+		}
+		if err := p.verifyAnnouncement(db, request.Message); err != nil {
+			return "", err
+		}
+	} else {
+		err := p.verifier.VerifyOutcome(id.AssetID, id.EventMaturity, id.Index, string(request.Message))
+		if err != nil {
+			return "", errors.WithMessage(ErrRejected, err.Error())
+		}
+	}
+
+	// the nonce might not have been committed by the participant if it was unavailable when it was created
+	if err := commitNonce(db, id, groupNonce); err != nil {
+		return "", err
+	}
+	messageHash := sha256.Sum256(request.Message)
+	if err := entity.UseThresholdNonce(db, id.String(), hex.EncodeToString(messageHash[:])); err != nil {
+		if errors.Is(err, entity.ErrThresholdNonceUsed) {
+			return "", errors.WithMessage(ErrNonceConflict, err.Error())
+		}
+		return "", err
+	}
+
+	// s_i = lambda_i * (k_i + c * x_i), the nonce and key being negated if their group point has an odd y
+	k := p.share.nonceShare(id)
+	defer k.Zero()
+	k.Mul(parityFactor(groupNonce))
+	x := new(secp256k1.ModNScalar).Mul2(p.share.secret, parityFactor(p.share.publicKey))
+	defer x.Zero()
+	c := challenge(groupNonce, p.share.publicKey, request.Message)
+	s := x.Mul(c).Add(k).Mul(lagrangeCoefficient(p.share.Index, request.Nonce.Signers, 0))
+	partial := s.Bytes()
+	return hex.EncodeToString(partial[:]), nil
+}
+
+// groupNonce returns the group nonce interpolated from the nonce shares of the signers,
+// after checking that they are consistent with the nonce share of the participant
+func (p *Participant) groupNonce(commitment *NonceCommitment) (*secp256k1.JacobianPoint, error) {
+	if err := commitment.ID.Validate(); err != nil {
+		return nil, errors.WithMessage(ErrInvalidRequest, err.Error())
+	}
+	points, err := parseCommitment(commitment, p.share.Threshold, p.share.Participants)
+	if err != nil {
+		return nil, err
+	}
+
+	k := p.share.nonceShare(commitment.ID)
+	ownShare := baseMult(k)
+	k.Zero()
+	// the shares are on a polynomial of degree threshold-1, which must go through the share of the participant
+	if !equalPoints(interpolatePoints(commitment.Signers, points, p.share.Index), ownShare) {
+		return nil, errors.WithMessage(ErrInvalidRequest, "The nonce shares are not consistent with the participant nonce share")
+	}
+	groupNonce := interpolatePoints(commitment.Signers, points, 0)
+	if isInfinity(groupNonce) {
+		return nil, errors.WithMessage(ErrInvalidRequest, "Invalid group nonce")
+	}
+	return groupNonce, nil
+}
+
+// verifyAnnouncement checks that the announced event was created with the recorded group nonces of the event
+// and is the one the participant would announce
+func (p *Participant) verifyAnnouncement(db *gorm.DB, message []byte) error {
+	event, err := dlccrypto.ParseEvent(message)
+	if err != nil {
+		return errors.WithMessage(ErrRejected, err.Error())
+	}
+	suffix := entity.EventIDSeparator + strconv.FormatUint(uint64(event.EventMaturity), 10)
+	if !strings.HasSuffix(event.EventID, suffix) {
+		return errors.WithMessagef(ErrRejected, "Unexpected event ID %s", event.EventID)
+	}
+	assetID := strings.TrimSuffix(event.EventID, suffix)
+
+	nonces, err := entity.FindEventThresholdNonces(db, assetID, event.EventMaturity)
+	if err != nil {
+		return err
+	}
+	if len(nonces) != len(event.Nonces) {
+		return errors.WithMessage(ErrRejected, "The event nonces were not all recorded by the participant")
+	}
+	for i, nonce := range nonces {
+		groupNonce, err := parseHexPoint(nonce.GroupNonce)
+		if err != nil {
+			return err
+		}
+		if nonce.NonceIndex != i || hex.EncodeToString(xOnly(groupNonce)) != event.Nonces[i].EncodeToString() {
+			return errors.WithMessagef(ErrRejected, "Nonce %d of the event is not the recorded group nonce", i)
+		}
+	}
+	if err := p.verifier.VerifyEvent(assetID, event.EventMaturity, event.Nonces, message); err != nil {
+		return errors.WithMessage(ErrRejected, err.Error())
+	}
+	return nil
+}
+
+// commitNonce records the group nonce of a nonce if none is recorded, and checks that it is the recorded one otherwise
+func commitNonce(db *gorm.DB, id NonceID, groupNonce *secp256k1.JacobianPoint) error {
+	encoded := hex.EncodeToString(encodePoint(groupNonce))
+	nonce := &entity.ThresholdNonce{NonceID: id.String(), GroupNonce: encoded}
+	if !id.IsAnnouncement() {
+		nonce.AssetID = id.AssetID
+		nonce.EventMaturity = id.EventMaturity
+		nonce.NonceIndex = id.Index
+	}
+	recorded, err := entity.CommitThresholdNonce(db, nonce)
+	if err != nil {
+		return err
+	}
+	if recorded.GroupNonce != encoded {
+		return errors.WithMessagef(ErrNonceConflict, "Nonce %s", id)
+	}
+	return nil
+}
+
+// parseCommitment checks that the commitment contains the shares of threshold distinct participants
+// and returns the parsed shares
+func parseCommitment(commitment *NonceCommitment, threshold int, participants int) ([]*secp256k1.JacobianPoint, error) {
+	if len(commitment.Signers) != threshold || len(commitment.Shares) != threshold {
+		return nil, errors.WithMessagef(ErrInvalidRequest, "The nonce shares of %d signers are required", threshold)
+	}
+	points := make([]*secp256k1.JacobianPoint, threshold)
+	for i, signer := range commitment.Signers {
+		if signer < 1 || signer > participants || contains(commitment.Signers[:i], signer) {
+			return nil, errors.WithMessagef(ErrInvalidRequest, "Invalid signer %d", signer)
+		}
+		var err error
+		points[i], err = parseHexPoint(commitment.Shares[i])
+		if err != nil {
+			return nil, errors.WithMessagef(ErrInvalidRequest, "Invalid nonce share of signer %d", signer)
+		}
+	}
+	return points, nil
+}
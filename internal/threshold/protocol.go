@@ -0,0 +1,76 @@
+package threshold
+
+import "github.com/pkg/errors"
+
+// routes of the participant api
+const (
+	// BaseRoute base route of the participant api
+	BaseRoute = "/threshold"
+	// RouteGETInfo relative GET route to retrieve the public information of the participant key share
+	RouteGETInfo = "/info"
+	// RoutePOSTNonces relative POST route to retrieve the public nonce shares of the participant
+	RoutePOSTNonces = "/nonces"
+	// RoutePOSTCommit relative POST route to record the group nonces of event nonces
+	RoutePOSTCommit = "/commit"
+	// RoutePOSTSign relative POST route to compute a partial signature
+	RoutePOSTSign = "/sign"
+)
+
+var (
+	// ErrInvalidRequest is returned for malformed requests
+	ErrInvalidRequest = errors.New("Invalid threshold request")
+	// ErrNonceConflict is returned when a nonce is requested with another group nonce than the recorded one
+	ErrNonceConflict = errors.New("Nonce recorded with another group nonce")
+	// ErrRejected is returned when the participant does not agree with the message to sign
+	ErrRejected = errors.New("Message rejected by the participant")
+)
+
+// ParticipantInfo contains the public information of the key share of a participant
+type ParticipantInfo struct {
+	Index        int `json:"index"`
+	Threshold    int `json:"threshold"`
+	Participants int `json:"participants"`
+	// PublicKey compressed oracle public key (hex)
+	PublicKey string `json:"publicKey"`
+	// VerificationShares compressed public keys of the key shares of all the participants (hex)
+	VerificationShares []string `json:"verificationShares"`
+}
+
+// NonceSharesRequest requests the public nonce shares of the participant for the given nonces
+type NonceSharesRequest struct {
+	IDs []NonceID `json:"ids"`
+}
+
+// NonceSharesResponse contains the compressed public nonce shares of the participant (hex)
+type NonceSharesResponse struct {
+	Shares []string `json:"shares"`
+}
+
+// NonceCommitment contains the public nonce shares of the signers of a nonce,
+// from which the group nonce is interpolated
+type NonceCommitment struct {
+	ID      NonceID  `json:"id"`
+	Signers []int    `json:"signers"`
+	Shares  []string `json:"shares"`
+}
+
+// CommitNoncesRequest requests the participant to record the group nonces of event nonces
+type CommitNoncesRequest struct {
+	Nonces []NonceCommitment `json:"nonces"`
+}
+
+// SignRequest requests a partial signature of the message with the given nonce
+type SignRequest struct {
+	Nonce   NonceCommitment `json:"nonce"`
+	Message []byte          `json:"message"`
+}
+
+// SignResponse contains the partial signature of the participant (hex)
+type SignResponse struct {
+	PartialSignature string `json:"partialSignature"`
+}
+
+// ErrorResponse is returned by the participant api on error
+type ErrorResponse struct {
+	Message string `json:"message"`
+}
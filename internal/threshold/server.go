@@ -0,0 +1,128 @@
+package threshold
+
+import (
+	"net/http"
+
+	"github.com/cryptogarageinc/server-common-go/pkg/database/orm"
+	"github.com/cryptogarageinc/server-common-go/pkg/log"
+	"github.com/cryptogarageinc/server-common-go/pkg/rest/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// NewParticipantAPI returns the api serving the requests of the coordinator to a participant,
+// authenticated with the given secret
+func NewParticipantAPI(l *log.Log, participant *Participant, orm *orm.ORM, secret []byte) *ParticipantAPI {
+	return &ParticipantAPI{
+		logger:      l,
+		participant: participant,
+		orm:         orm,
+		secret:      secret,
+	}
+}
+
+// ParticipantAPI represents the api of a participant of a threshold oracle
+type ParticipantAPI struct {
+	logger      *log.Log
+	participant *Participant
+	orm         *orm.ORM
+	secret      []byte
+}
+
+// Routes defines (and attached to a gin.routerGroup) the routes of the api
+func (a *ParticipantAPI) Routes(route *gin.RouterGroup) {
+	group := route.Group(BaseRoute)
+	group.GET(RouteGETInfo, a.GetInfo)
+	group.POST(RoutePOSTNonces, a.PostNonces)
+	group.POST(RoutePOSTCommit, a.PostCommit)
+	group.POST(RoutePOSTSign, a.PostSign)
+}
+
+// GlobalMiddlewares returns the global middlewares that the api should use
+func (a *ParticipantAPI) GlobalMiddlewares() []gin.HandlerFunc {
+	return []gin.HandlerFunc{
+		middleware.GinLogrus(a.logger.Logger),
+		Authenticate(a.secret),
+	}
+}
+
+// InitializeServices initializes the api services
+func (a *ParticipantAPI) InitializeServices() error {
+	if !a.orm.IsInitialized() {
+		return a.orm.Initialize()
+	}
+	return nil
+}
+
+// AreServicesInitialized returns a boolean to check if the services are initialized
+func (a *ParticipantAPI) AreServicesInitialized() bool {
+	return a.orm.IsInitialized()
+}
+
+// FinalizeServices releases the resources held by the api services
+func (a *ParticipantAPI) FinalizeServices() error {
+	return a.orm.Finalize()
+}
+
+// GetInfo handler returns the public information of the key share of the participant
+func (a *ParticipantAPI) GetInfo(c *gin.Context) {
+	c.JSON(http.StatusOK, a.participant.Info())
+}
+
+// PostNonces handler returns the public nonce shares of the participant
+func (a *ParticipantAPI) PostNonces(c *gin.Context) {
+	request := &NonceSharesRequest{}
+	if err := c.ShouldBindJSON(request); err != nil {
+		abortWithError(c, errors.WithMessage(ErrInvalidRequest, err.Error()))
+		return
+	}
+	shares, err := a.participant.NonceShares(request.IDs)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, &NonceSharesResponse{Shares: shares})
+}
+
+// PostCommit handler records the group nonces of event nonces
+func (a *ParticipantAPI) PostCommit(c *gin.Context) {
+	request := &CommitNoncesRequest{}
+	if err := c.ShouldBindJSON(request); err != nil {
+		abortWithError(c, errors.WithMessage(ErrInvalidRequest, err.Error()))
+		return
+	}
+	if err := a.participant.CommitNonces(a.orm.GetDB(), request.Nonces); err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// PostSign handler returns the partial signature of the participant
+func (a *ParticipantAPI) PostSign(c *gin.Context) {
+	request := &SignRequest{}
+	if err := c.ShouldBindJSON(request); err != nil {
+		abortWithError(c, errors.WithMessage(ErrInvalidRequest, err.Error()))
+		return
+	}
+	partial, err := a.participant.Sign(a.orm.GetDB(), request)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, &SignResponse{PartialSignature: partial})
+}
+
+func abortWithError(c *gin.Context, err error) {
+	c.Error(err)
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, ErrInvalidRequest):
+		status = http.StatusBadRequest
+	case errors.Is(err, ErrRejected):
+		status = http.StatusForbidden
+	case errors.Is(err, ErrNonceConflict):
+		status = http.StatusConflict
+	}
+	c.AbortWithStatusJSON(status, &ErrorResponse{Message: err.Error()})
+}
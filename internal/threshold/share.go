@@ -0,0 +1,268 @@
+package threshold
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"p2pderivatives-oracle/internal/dlccrypto"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/pkg/errors"
+)
+
+const (
+	// NonceShareTag tag of the hash deriving the nonce shares from the nonce seeds
+	NonceShareTag = "P2PDOracle/threshold/nonce"
+	// MaxParticipants maximum number of participants, the number of nonce seeds growing combinatorially with it
+	MaxParticipants = 16
+
+	nonceSeedSize = 32
+)
+
+// KeyShare is the share of the oracle key held by a participant of a threshold oracle.
+// The oracle key is shared with a polynomial of degree Threshold-1 so that any Threshold
+// participants can sign together while fewer learn nothing about the key.
+// The nonces are shared the same way without interaction from seeds known by all the participants
+// except Threshold-1 of them (pseudo random secret sharing), so that the participants derive
+// their nonce shares independently and any Threshold of them can sign with the same nonce.
+type KeyShare struct {
+	// Index of the participant (from 1 to Participants)
+	Index        int
+	Threshold    int
+	Participants int
+	// the group public key (oracle public key) and the public keys of the shares of all the participants
+	publicKey          *secp256k1.JacobianPoint
+	verificationShares []*secp256k1.JacobianPoint
+	secret             *secp256k1.ModNScalar
+	nonceSeeds         []nonceSeed
+}
+
+// nonceSeed is a seed of the nonce shares known by all the participants except the excluded ones
+type nonceSeed struct {
+	excluded []int
+	seed     []byte
+}
+
+// Split splits the private key in shares for the given number of participants, any threshold of them
+// being able to sign with the key. The threshold must be more than half of the participants: any two sets
+// of signers then share a participant, which never signs two messages with the same nonce (two disjoint
+// sets would derive the same nonce and could each sign another outcome with it, revealing the key). The dealer running the split sees the key and all the shares so it must
+// be run on a trusted machine, the key (and the shares of the other participants) being deleted afterwards.
+func Split(privateKey *dlccrypto.PrivateKey, threshold int, participants int) ([]*KeyShare, error) {
+	if threshold < 2 || threshold > participants {
+		return nil, errors.Errorf("The threshold should be between 2 and the number of participants, got %d of %d", threshold, participants)
+	}
+	if participants >= 2*threshold {
+		return nil, errors.Errorf(
+			"The threshold should be more than half of the number of participants so that any two sets of signers overlap, got %d of %d",
+			threshold, participants)
+	}
+	if participants > MaxParticipants {
+		return nil, errors.Errorf("The number of participants should not exceed %d", MaxParticipants)
+	}
+	keyBytes, err := hex.DecodeString(privateKey.EncodeToString())
+	if err != nil {
+		return nil, err
+	}
+	defer zero(keyBytes)
+	secret, err := parseScalar(keyBytes)
+	if err != nil || secret.IsZero() {
+		return nil, errors.New("Invalid private key")
+	}
+
+	// f(x) = secret + a_1*x + ... + a_(t-1)*x^(t-1)
+	coefficients := make([]*secp256k1.ModNScalar, threshold)
+	coefficients[0] = secret
+	for i := 1; i < threshold; i++ {
+		coefficients[i], err = randomScalar()
+		if err != nil {
+			return nil, err
+		}
+	}
+	defer func() {
+		for _, c := range coefficients {
+			c.Zero()
+		}
+	}()
+
+	publicKey := baseMult(secret)
+	shares := make([]*KeyShare, participants)
+	verificationShares := make([]*secp256k1.JacobianPoint, participants)
+	for i := range shares {
+		shareSecret := evaluatePolynomial(coefficients, i+1)
+		verificationShares[i] = baseMult(shareSecret)
+		shares[i] = &KeyShare{
+			Index:              i + 1,
+			Threshold:          threshold,
+			Participants:       participants,
+			publicKey:          publicKey,
+			verificationShares: verificationShares,
+			secret:             shareSecret,
+		}
+	}
+
+	// a seed is unknown to the threshold-1 excluded participants
+	for _, excluded := range subsets(participants, threshold-1) {
+		seed := make([]byte, nonceSeedSize)
+		if _, err := rand.Read(seed); err != nil {
+			return nil, err
+		}
+		for _, share := range shares {
+			if !contains(excluded, share.Index) {
+				share.nonceSeeds = append(share.nonceSeeds, nonceSeed{excluded: excluded, seed: seed})
+			}
+		}
+	}
+	return shares, nil
+}
+
+// PublicKey returns the schnorr public key of the oracle
+func (s *KeyShare) PublicKey() *dlccrypto.SchnorrPublicKey {
+	return schnorrPublicKey(s.publicKey)
+}
+
+// Zero overwrites the secrets of the share, which must not be used afterwards
+func (s *KeyShare) Zero() {
+	s.secret.Zero()
+	for _, seed := range s.nonceSeeds {
+		zero(seed.seed)
+	}
+}
+
+// Validate checks that the share is consistent with the verification shares and the public key
+func (s *KeyShare) Validate() error {
+	if s.Threshold < 2 || s.Threshold > s.Participants || s.Participants >= 2*s.Threshold || s.Participants > MaxParticipants {
+		return errors.Errorf("Invalid threshold %d of %d", s.Threshold, s.Participants)
+	}
+	if s.Index < 1 || s.Index > s.Participants {
+		return errors.Errorf("Invalid participant index %d", s.Index)
+	}
+	if len(s.verificationShares) != s.Participants {
+		return errors.New("Invalid number of verification shares")
+	}
+	if !equalPoints(baseMult(s.secret), s.verificationShares[s.Index-1]) {
+		return errors.New("The key share does not match its verification share")
+	}
+	// all the verification shares must be on the polynomial of the public key
+	indices := make([]int, s.Threshold)
+	for i := range indices {
+		indices[i] = i + 1
+	}
+	for x := 0; x <= s.Participants; x++ {
+		expected := s.publicKey
+		if x > 0 {
+			expected = s.verificationShares[x-1]
+		}
+		actual := interpolatePoints(indices, s.verificationShares[:s.Threshold], x)
+		if !equalPoints(actual, expected) {
+			return errors.New("The verification shares do not match the public key")
+		}
+	}
+	expectedSeeds := 0
+	for _, excluded := range subsets(s.Participants, s.Threshold-1) {
+		if !contains(excluded, s.Index) {
+			expectedSeeds++
+		}
+	}
+	if len(s.nonceSeeds) != expectedSeeds {
+		return errors.New("Invalid number of nonce seeds")
+	}
+	for _, seed := range s.nonceSeeds {
+		if len(seed.excluded) != s.Threshold-1 || contains(seed.excluded, s.Index) || len(seed.seed) != nonceSeedSize {
+			return errors.New("Invalid nonce seed")
+		}
+	}
+	return nil
+}
+
+// verificationShare returns the public key of the share of a participant
+func (s *KeyShare) verificationShare(index int) (*secp256k1.JacobianPoint, error) {
+	if index < 1 || index > len(s.verificationShares) {
+		return nil, errors.Errorf("Unknown participant %d", index)
+	}
+	return s.verificationShares[index-1], nil
+}
+
+// nonceShare derives the share of the participant of the nonce with the given ID:
+// k_i = sum over the seeds of PRF(seed, id) * f_A(i), f_A being the polynomial of degree t-1
+// equal to 1 at 0 and to 0 at the participants A excluded from the seed
+func (s *KeyShare) nonceShare(id NonceID) *secp256k1.ModNScalar {
+	idBytes := id.bytes()
+	share := new(secp256k1.ModNScalar)
+	for _, seed := range s.nonceSeeds {
+		msg := make([]byte, 0, len(seed.seed)+len(idBytes))
+		msg = append(msg, seed.seed...)
+		msg = append(msg, idBytes...)
+		value := hashToScalar(dlccrypto.TaggedHash(NonceShareTag, msg))
+		for _, j := range seed.excluded {
+			value.Mul(intScalar(j - s.Index)).Mul(intScalar(j).InverseNonConst())
+		}
+		share.Add(value)
+		zero(msg)
+	}
+	return share
+}
+
+// evaluatePolynomial returns the value of the polynomial at x
+func evaluatePolynomial(coefficients []*secp256k1.ModNScalar, x int) *secp256k1.ModNScalar {
+	res := new(secp256k1.ModNScalar)
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		res.Mul(intScalar(x)).Add(coefficients[i])
+	}
+	return res
+}
+
+// interpolatePoints returns the value at x of the polynomial in the exponent from the points of the given participants
+func interpolatePoints(indices []int, points []*secp256k1.JacobianPoint, x int) *secp256k1.JacobianPoint {
+	res := new(secp256k1.JacobianPoint)
+	for i, index := range indices {
+		res = pointAdd(res, pointMult(lagrangeCoefficient(index, indices, x), points[i]))
+	}
+	return res
+}
+
+// subsets returns all the subsets of the given size of the participants indices
+func subsets(participants int, size int) [][]int {
+	var res [][]int
+	var build func(start int, current []int)
+	build = func(start int, current []int) {
+		if len(current) == size {
+			res = append(res, append([]int(nil), current...))
+			return
+		}
+		for i := start; i <= participants; i++ {
+			build(i+1, append(current, i))
+		}
+	}
+	build(1, make([]int, 0, size))
+	return res
+}
+
+func contains(indices []int, index int) bool {
+	for _, i := range indices {
+		if i == index {
+			return true
+		}
+	}
+	return false
+}
+
+func randomScalar() (*secp256k1.ModNScalar, error) {
+	var b [32]byte
+	defer zero(b[:])
+	for {
+		if _, err := rand.Read(b[:]); err != nil {
+			return nil, err
+		}
+		s := new(secp256k1.ModNScalar)
+		if overflow := s.SetBytes(&b); overflow == 0 && !s.IsZero() {
+			return s, nil
+		}
+	}
+}
+
+// zero overwrites the given buffer with zeros
+func zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,148 @@
+package threshold
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"p2pderivatives-oracle/internal/dlccrypto"
+
+	"github.com/pkg/errors"
+)
+
+// pem type of the key share files
+const pemTypeEncryptedKeyShare = "ENCRYPTED ORACLE KEY SHARE"
+
+// keyShareFile json content of a key share file
+type keyShareFile struct {
+	Index              int                 `json:"index"`
+	Threshold          int                 `json:"threshold"`
+	Participants       int                 `json:"participants"`
+	PublicKey          string              `json:"publicKey"`
+	VerificationShares []string            `json:"verificationShares"`
+	Secret             string              `json:"secret"`
+	NonceSeeds         []nonceSeedFileData `json:"nonceSeeds"`
+}
+
+type nonceSeedFileData struct {
+	Excluded []int  `json:"excluded"`
+	Seed     string `json:"seed"`
+}
+
+// MarshalEncryptedKeyShare returns the key share as a pem block encrypted with the password
+// the same way as the oracle key files (see dlccrypto.MarshalEncryptedPemKey)
+func MarshalEncryptedKeyShare(share *KeyShare, pass []byte, kdf string) ([]byte, error) {
+	secret := share.secret.Bytes()
+	defer zero(secret[:])
+	content := &keyShareFile{
+		Index:              share.Index,
+		Threshold:          share.Threshold,
+		Participants:       share.Participants,
+		PublicKey:          hex.EncodeToString(encodePoint(share.publicKey)),
+		VerificationShares: make([]string, len(share.verificationShares)),
+		Secret:             hex.EncodeToString(secret[:]),
+		NonceSeeds:         make([]nonceSeedFileData, len(share.nonceSeeds)),
+	}
+	for i, p := range share.verificationShares {
+		content.VerificationShares[i] = hex.EncodeToString(encodePoint(p))
+	}
+	for i, seed := range share.nonceSeeds {
+		content.NonceSeeds[i] = nonceSeedFileData{Excluded: seed.excluded, Seed: hex.EncodeToString(seed.seed)}
+	}
+	data, err := json.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+	defer zero(data)
+	encrypted, err := dlccrypto.EncryptWithPassword(data, pass, kdf)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemTypeEncryptedKeyShare, Bytes: encrypted}), nil
+}
+
+// ParseEncryptedKeyShare returns the key share from a pem block encrypted with the password,
+// checking that it is consistent (see KeyShare.Validate)
+func ParseEncryptedKeyShare(content []byte, pass []byte) (*KeyShare, error) {
+	pemBlock, _ := pem.Decode(content)
+	if pemBlock == nil {
+		return nil, errors.New("The file is not of PEM format")
+	}
+	if pemBlock.Type != pemTypeEncryptedKeyShare {
+		return nil, errors.Errorf("Unsupported pem block type %s", pemBlock.Type)
+	}
+	data, err := dlccrypto.DecryptWithPassword(pemBlock.Bytes, pass)
+	if err != nil {
+		return nil, err
+	}
+	defer zero(data)
+	var file keyShareFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, errors.WithMessage(err, "Invalid key share")
+	}
+
+	share := &KeyShare{
+		Index:        file.Index,
+		Threshold:    file.Threshold,
+		Participants: file.Participants,
+	}
+	if share.publicKey, err = parseHexPoint(file.PublicKey); err != nil {
+		return nil, errors.WithMessage(err, "Invalid key share public key")
+	}
+	for _, v := range file.VerificationShares {
+		p, err := parseHexPoint(v)
+		if err != nil {
+			return nil, errors.WithMessage(err, "Invalid key share verification share")
+		}
+		share.verificationShares = append(share.verificationShares, p)
+	}
+	secret, err := hex.DecodeString(file.Secret)
+	if err != nil {
+		return nil, errors.WithMessage(err, "Invalid key share secret")
+	}
+	defer zero(secret)
+	if share.secret, err = parseScalar(secret); err != nil {
+		return nil, errors.WithMessage(err, "Invalid key share secret")
+	}
+	for _, v := range file.NonceSeeds {
+		seed, err := hex.DecodeString(v.Seed)
+		if err != nil {
+			share.Zero()
+			return nil, errors.WithMessage(err, "Invalid key share nonce seed")
+		}
+		share.nonceSeeds = append(share.nonceSeeds, nonceSeed{excluded: v.Excluded, seed: seed})
+	}
+	if err := share.Validate(); err != nil {
+		share.Zero()
+		return nil, err
+	}
+	return share, nil
+}
+
+// ReadKeyShareFile returns the key share from an encrypted key share file
+func ReadKeyShareFile(filePath string, pass []byte) (*KeyShare, error) {
+	content, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "Could not read key share file %s", filePath)
+	}
+	return ParseEncryptedKeyShare(content, pass)
+}
+
+// WriteKeyShareFile writes the key share to a new encrypted file (see MarshalEncryptedKeyShare)
+// readable only by its owner, an existing file is never overwritten
+func WriteKeyShareFile(filePath string, share *KeyShare, pass []byte, kdf string) error {
+	content, err := MarshalEncryptedKeyShare(share, pass, kdf)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,130 @@
+package threshold
+
+import (
+	"encoding/hex"
+	"p2pderivatives-oracle/internal/dlccrypto"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+const testPrivateKey = "c251ebf21fcf41e4875ddfc0a02e5ae849e847b3f528ae0413363f47d2c02e66"
+
+func splitTestKey(t *testing.T, threshold int, participants int) []*KeyShare {
+	privateKey, _ := dlccrypto.NewPrivateKey(testPrivateKey)
+	shares, err := Split(privateKey, threshold, participants)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return shares
+}
+
+// interpolate returns the secret interpolated from the shares of the given participants
+func interpolate(indices []int, values []*secp256k1.ModNScalar) *secp256k1.ModNScalar {
+	res := new(secp256k1.ModNScalar)
+	for i, index := range indices {
+		res.Add(new(secp256k1.ModNScalar).Mul2(lagrangeCoefficient(index, indices, 0), values[i]))
+	}
+	return res
+}
+
+func TestSplit_AnyThresholdShares_InterpolateKey(t *testing.T) {
+	for _, params := range [][2]int{{2, 3}, {3, 5}} {
+		threshold, participants := params[0], params[1]
+		shares := splitTestKey(t, threshold, participants)
+
+		for _, signers := range subsets(participants, threshold) {
+			values := make([]*secp256k1.ModNScalar, len(signers))
+			for i, signer := range signers {
+				values[i] = shares[signer-1].secret
+			}
+			key := interpolate(signers, values).Bytes()
+			assert.Equal(t, testPrivateKey, hex.EncodeToString(key[:]))
+		}
+		for _, share := range shares {
+			assert.NoError(t, share.Validate())
+			assert.Equal(t, shares[0].PublicKey().EncodeToString(), share.PublicKey().EncodeToString())
+		}
+	}
+}
+
+func TestSplit_FewerThanThresholdShares_DoNotInterpolateKey(t *testing.T) {
+	shares := splitTestKey(t, 3, 5)
+
+	key := interpolate([]int{1, 2}, []*secp256k1.ModNScalar{shares[0].secret, shares[1].secret}).Bytes()
+
+	assert.NotEqual(t, testPrivateKey, hex.EncodeToString(key[:]))
+}
+
+func TestSplit_InvalidThreshold_ReturnsError(t *testing.T) {
+	privateKey, _ := dlccrypto.NewPrivateKey(testPrivateKey)
+	_, err := Split(privateKey, 1, 3)
+	assert.Error(t, err)
+	_, err = Split(privateKey, 4, 3)
+	assert.Error(t, err)
+	_, err = Split(privateKey, 2, MaxParticipants+1)
+	assert.Error(t, err)
+}
+
+func TestSplit_DisjointSignerSets_ReturnsError(t *testing.T) {
+	privateKey, _ := dlccrypto.NewPrivateKey(testPrivateKey)
+	// {1, 2} and {3, 4} would derive the same nonces without any participant in common
+	_, err := Split(privateKey, 2, 4)
+	assert.Error(t, err)
+	_, err = Split(privateKey, 3, 6)
+	assert.Error(t, err)
+}
+
+func TestKeyShare_Validate_DisjointSignerSets_ReturnsError(t *testing.T) {
+	share := splitTestKey(t, 2, 3)[0]
+	share.Participants = 4
+
+	assert.Error(t, share.Validate())
+}
+
+func TestKeyShare_NonceShare_AnyThresholdShares_InterpolateSameNonce(t *testing.T) {
+	shares := splitTestKey(t, 3, 5)
+	for _, id := range []NonceID{EventNonceID("btcusd", 1600000000, 2), AnnouncementNonceID([]byte("event"))} {
+		var expected *secp256k1.ModNScalar
+		for _, signers := range subsets(5, 3) {
+			values := make([]*secp256k1.ModNScalar, len(signers))
+			for i, signer := range signers {
+				values[i] = shares[signer-1].nonceShare(id)
+			}
+			nonce := interpolate(signers, values)
+			if expected == nil {
+				expected = nonce
+				assert.False(t, nonce.IsZero())
+			}
+			assert.True(t, expected.Equals(nonce))
+		}
+	}
+	// the nonces of different IDs are unrelated
+	assert.False(t, shares[0].nonceShare(EventNonceID("btcusd", 1600000000, 0)).Equals(shares[0].nonceShare(EventNonceID("btcusd", 1600000000, 1))))
+}
+
+func TestMarshalEncryptedKeyShare_ParsesWithPassword(t *testing.T) {
+	share := splitTestKey(t, 2, 3)[1]
+
+	content, err := MarshalEncryptedKeyShare(share, []byte("pass"), dlccrypto.KDFScrypt)
+
+	assert.NoError(t, err)
+	assert.NotContains(t, string(content), hex.EncodeToString(share.nonceSeeds[0].seed))
+	actual, err := ParseEncryptedKeyShare(content, []byte("pass"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, share.Index, actual.Index)
+		assert.True(t, share.secret.Equals(actual.secret))
+		assert.Equal(t, share.nonceSeeds, actual.nonceSeeds)
+		assert.True(t, share.nonceShare(EventNonceID("btcusd", 1, 0)).Equals(actual.nonceShare(EventNonceID("btcusd", 1, 0))))
+	}
+	_, err = ParseEncryptedKeyShare(content, []byte("invalid pass"))
+	assert.Error(t, err)
+}
+
+func TestKeyShare_Validate_InconsistentShare_ReturnsError(t *testing.T) {
+	share := splitTestKey(t, 2, 3)[0]
+	share.secret.Add(intScalar(1))
+
+	assert.Error(t, share.Validate())
+}
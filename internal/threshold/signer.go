@@ -0,0 +1,320 @@
+package threshold
+
+import (
+	"encoding/hex"
+	"p2pderivatives-oracle/internal/dlccrypto"
+	"sort"
+	"sync"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/pkg/errors"
+)
+
+// NewSigner returns a signer computing the signatures of the oracle with the configured participants
+func NewSigner(config *Config) (*Signer, error) {
+	publicKey, err := dlccrypto.NewSchnorrPublicKey(config.PublicKey)
+	if err != nil {
+		return nil, errors.WithMessage(err, "Invalid threshold oracle public key")
+	}
+	if len(config.Participants) < 2 {
+		return nil, errors.New("At least two threshold participants should be configured")
+	}
+	// the participants are requested in a deterministic order
+	names := make([]string, 0, len(config.Participants))
+	for name := range config.Participants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	clients := make([]*participantClient, len(names))
+	for i, name := range names {
+		participantConfig := config.Participants[name]
+		secret, err := ReadSecret(participantConfig.Secret, participantConfig.SecretFile)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "Invalid secret of threshold participant %s", name)
+		}
+		clients[i] = newParticipantClient(name, participantConfig.URL, secret, config.Timeout)
+	}
+	return &Signer{
+		schnorrPublicKey: publicKey,
+		clients:          clients,
+	}, nil
+}
+
+// Signer is the coordinator of a threshold oracle, computing the signatures of the oracle from the partial
+// signatures of threshold participants (see Participant). The coordinator holds no secret, each participant
+// checking by itself what it signs, and it verifies the partial signatures so that a faulty participant
+// is excluded from the signers.
+type Signer struct {
+	schnorrPublicKey *dlccrypto.SchnorrPublicKey
+	clients          []*participantClient
+
+	// retrieved from the participants on first use
+	mutex sync.Mutex
+	group *participantGroup
+}
+
+// participantGroup contains the public information of the participants
+type participantGroup struct {
+	threshold          int
+	publicKey          *secp256k1.JacobianPoint
+	verificationShares []*secp256k1.JacobianPoint
+	// indices of the participants which returned their information
+	indices map[*participantClient]int
+}
+
+// participantNonceShares the nonce shares returned by a participant
+type participantNonceShares struct {
+	client *participantClient
+	index  int
+	share  *secp256k1.JacobianPoint
+}
+
+// PublicKey returns the schnorr public key of the oracle
+func (s *Signer) PublicKey() *dlccrypto.SchnorrPublicKey {
+	return s.schnorrPublicKey
+}
+
+// DeriveSchnorrNonce returns the group nonce of an event nonce, which is recorded by the participants
+// so that they only sign the event outcome with it
+func (s *Signer) DeriveSchnorrNonce(assetID string, eventMaturity uint32, index int) (*dlccrypto.SchnorrPublicKey, error) {
+	group, err := s.participantGroup()
+	if err != nil {
+		return nil, err
+	}
+	id := EventNonceID(assetID, eventMaturity, index)
+	available, err := s.collectNonceShares(group, id)
+	if err != nil {
+		return nil, err
+	}
+	commitment, groupNonce := commitment(id, available[:group.threshold])
+
+	// all the available participants record the nonce, so that any threshold of them can sign the announcement
+	committed := 0
+	var lastErr error
+	for _, participant := range available {
+		if err := participant.client.commitNonces([]NonceCommitment{*commitment}); err != nil {
+			lastErr = err
+			continue
+		}
+		committed++
+	}
+	if committed < group.threshold {
+		return nil, errors.WithMessagef(lastErr, "Only %d participants recorded the nonce", committed)
+	}
+	return schnorrPublicKey(groupNonce), nil
+}
+
+// ComputeSchnorrSignature computes a schnorr signature on the given byte buffer message (will be hashed by sha256),
+// the participants only signing serialized oracle events
+func (s *Signer) ComputeSchnorrSignature(message []byte) (*dlccrypto.Signature, error) {
+	return s.sign(AnnouncementNonceID(message), message)
+}
+
+// ComputeSchnorrSignatureWithNonce computes a schnorr signature on the given message (will be hashed by sha256)
+// using the group nonce of the given event nonce
+func (s *Signer) ComputeSchnorrSignatureWithNonce(nonce *dlccrypto.EventNonce, message string) (*dlccrypto.Signature, error) {
+	if nonce.Kvalue != nil {
+		return nil, errors.New("Events announced with stored kvalues cannot be signed by a threshold oracle")
+	}
+	return s.sign(EventNonceID(nonce.AssetID, nonce.EventMaturity, nonce.Index), []byte(message))
+}
+
+// sign computes the signature from the partial signatures of threshold participants,
+// another set of participants being used if one of them fails
+func (s *Signer) sign(id NonceID, message []byte) (*dlccrypto.Signature, error) {
+	group, err := s.participantGroup()
+	if err != nil {
+		return nil, err
+	}
+	available, err := s.collectNonceShares(group, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for len(available) >= group.threshold {
+		signers := available[:group.threshold]
+		commitment, groupNonce := commitment(id, signers)
+		c := challenge(groupNonce, group.publicKey, message)
+
+		sum := new(secp256k1.ModNScalar)
+		var failed *participantNonceShares
+		for _, signer := range signers {
+			partial, err := group.partialSignature(signer, commitment, groupNonce, c, message)
+			if err != nil {
+				lastErr = err
+				failed = signer
+				break
+			}
+			sum.Add(partial)
+		}
+		if failed == nil {
+			sig := make([]byte, 64)
+			copy(sig, xOnly(groupNonce))
+			sum.PutBytesUnchecked(sig[32:])
+			return dlccrypto.NewSignature(hex.EncodeToString(sig))
+		}
+		available = removeParticipant(available, failed)
+	}
+	return nil, errors.WithMessage(lastErr, "Not enough participants to sign")
+}
+
+// partialSignature requests the partial signature of a signer and checks it against its public shares:
+// s_i*G = lambda_i * (R_i + c*X_i), R_i and X_i being negated if the group nonce and public key have an odd y
+func (g *participantGroup) partialSignature(
+	signer *participantNonceShares, commitment *NonceCommitment, groupNonce *secp256k1.JacobianPoint, c *secp256k1.ModNScalar, message []byte,
+) (*secp256k1.ModNScalar, error) {
+	encoded, err := signer.client.sign(commitment, message)
+	if err != nil {
+		return nil, err
+	}
+	b, err := hex.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.Errorf("Participant %s returned an invalid partial signature", signer.client.name)
+	}
+	partial, err := parseScalar(b)
+	if err != nil {
+		return nil, errors.Errorf("Participant %s returned an invalid partial signature", signer.client.name)
+	}
+
+	lambda := lagrangeCoefficient(signer.index, commitment.Signers, 0)
+	nonceTerm := pointMult(new(secp256k1.ModNScalar).Mul2(lambda, parityFactor(groupNonce)), signer.share)
+	keyFactor := new(secp256k1.ModNScalar).Mul2(lambda, parityFactor(g.publicKey)).Mul(c)
+	keyTerm := pointMult(keyFactor, g.verificationShares[signer.index-1])
+	if !equalPoints(baseMult(partial), pointAdd(nonceTerm, keyTerm)) {
+		return nil, errors.Errorf("Participant %s returned an invalid partial signature", signer.client.name)
+	}
+	return partial, nil
+}
+
+// collectNonceShares returns the nonce shares of the available participants, of which there must be at least threshold
+func (s *Signer) collectNonceShares(group *participantGroup, id NonceID) ([]*participantNonceShares, error) {
+	var available []*participantNonceShares
+	var lastErr error
+	for _, client := range s.clients {
+		index, ok := group.indices[client]
+		if !ok {
+			continue
+		}
+		encoded, err := client.nonceShares([]NonceID{id})
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		share, err := parseHexPoint(encoded[0])
+		if err != nil {
+			lastErr = errors.Errorf("Participant %s returned an invalid nonce share", client.name)
+			continue
+		}
+		available = append(available, &participantNonceShares{client: client, index: index, share: share})
+	}
+	if len(available) < group.threshold {
+		return nil, errors.WithMessagef(lastErr, "Only %d threshold participants are available", len(available))
+	}
+	return available, nil
+}
+
+// commitment returns the nonce commitment of the signers and the interpolated group nonce
+func commitment(id NonceID, signers []*participantNonceShares) (*NonceCommitment, *secp256k1.JacobianPoint) {
+	commitment := &NonceCommitment{ID: id}
+	points := make([]*secp256k1.JacobianPoint, len(signers))
+	for i, signer := range signers {
+		commitment.Signers = append(commitment.Signers, signer.index)
+		commitment.Shares = append(commitment.Shares, hex.EncodeToString(encodePoint(signer.share)))
+		points[i] = signer.share
+	}
+	return commitment, interpolatePoints(commitment.Signers, points, 0)
+}
+
+// participantGroup returns the public information of the participants, retrieved on first use and checked
+// to match the configured public key
+func (s *Signer) participantGroup() (*participantGroup, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.group != nil {
+		return s.group, nil
+	}
+
+	var reference *ParticipantInfo
+	indices := make(map[*participantClient]int)
+	var lastErr error
+	for _, client := range s.clients {
+		info, err := client.info()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if reference == nil {
+			reference = info
+		} else if !sameGroup(reference, info) {
+			return nil, errors.Errorf("Participant %s does not share the key of the other participants", client.name)
+		}
+		for other, index := range indices {
+			if index == info.Index {
+				return nil, errors.Errorf("Participants %s and %s have the same index", other.name, client.name)
+			}
+		}
+		indices[client] = info.Index
+	}
+	if reference == nil {
+		return nil, errors.WithMessage(lastErr, "No threshold participant is available")
+	}
+
+	publicKey, err := parseHexPoint(reference.PublicKey)
+	if err != nil {
+		return nil, errors.WithMessage(err, "Invalid participants public key")
+	}
+	if hex.EncodeToString(xOnly(publicKey)) != s.schnorrPublicKey.EncodeToString() {
+		return nil, errors.New("The participants public key is not the configured oracle public key")
+	}
+	verificationShares := make([]*secp256k1.JacobianPoint, len(reference.VerificationShares))
+	for i, v := range reference.VerificationShares {
+		if verificationShares[i], err = parseHexPoint(v); err != nil {
+			return nil, errors.WithMessage(err, "Invalid participants verification share")
+		}
+	}
+	if len(verificationShares) != reference.Participants || reference.Threshold < 2 || reference.Threshold > reference.Participants {
+		return nil, errors.New("Invalid participants threshold")
+	}
+	for _, index := range indices {
+		if index < 1 || index > reference.Participants {
+			return nil, errors.Errorf("Invalid participant index %d", index)
+		}
+	}
+
+	group := &participantGroup{
+		threshold:          reference.Threshold,
+		publicKey:          publicKey,
+		verificationShares: verificationShares,
+		indices:            indices,
+	}
+	// not cached until all the participants are available, to retrieve the others later
+	if len(indices) == len(s.clients) {
+		s.group = group
+	}
+	return group, nil
+}
+
+// sameGroup returns true if the participants share the same key
+func sameGroup(a *ParticipantInfo, b *ParticipantInfo) bool {
+	if a.Threshold != b.Threshold || a.Participants != b.Participants || a.PublicKey != b.PublicKey ||
+		len(a.VerificationShares) != len(b.VerificationShares) {
+		return false
+	}
+	for i := range a.VerificationShares {
+		if a.VerificationShares[i] != b.VerificationShares[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func removeParticipant(participants []*participantNonceShares, removed *participantNonceShares) []*participantNonceShares {
+	res := make([]*participantNonceShares, 0, len(participants)-1)
+	for _, p := range participants {
+		if p != removed {
+			res = append(res, p)
+		}
+	}
+	return res
+}
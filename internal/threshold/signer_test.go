@@ -0,0 +1,208 @@
+package threshold_test
+
+import (
+	"net/http/httptest"
+	"p2pderivatives-oracle/internal/database/entity"
+	"p2pderivatives-oracle/internal/dlccrypto"
+	"p2pderivatives-oracle/internal/godlccrypto"
+	"p2pderivatives-oracle/internal/threshold"
+	"p2pderivatives-oracle/test"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cryptogarageinc/server-common-go/pkg/database/orm"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testPrivateKey = "c251ebf21fcf41e4875ddfc0a02e5ae849e847b3f528ae0413363f47d2c02e66"
+	testSecret     = "participant-secret"
+	testAssetID    = "btcusd"
+	testMaturity   = uint32(1600000000)
+)
+
+// testVerifier accepts all the announcements and the configured outcomes (all the outcomes if none is configured)
+type testVerifier struct {
+	outcomes []string
+}
+
+func (v *testVerifier) VerifyEvent(assetID string, eventMaturity uint32, nonces []dlccrypto.SchnorrPublicKey, event []byte) error {
+	return nil
+}
+
+func (v *testVerifier) VerifyOutcome(assetID string, eventMaturity uint32, index int, message string) error {
+	if v.outcomes != nil && (index >= len(v.outcomes) || v.outcomes[index] != message) {
+		return errors.Errorf("Unexpected outcome %s", message)
+	}
+	return nil
+}
+
+type testParticipant struct {
+	participant *threshold.Participant
+	orm         *orm.ORM
+	server      *httptest.Server
+}
+
+func newTestParticipants(t *testing.T, thresh int, n int, verifier threshold.EventVerifier) []*testParticipant {
+	privateKey, _ := dlccrypto.NewPrivateKey(testPrivateKey)
+	shares, err := threshold.Split(privateKey, thresh, n)
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	participants := make([]*testParticipant, n)
+	for i, share := range shares {
+		ormInstance := test.NewOrm(&entity.ThresholdNonce{})
+		// the in memory database is not shared between connections
+		sqlDB, _ := ormInstance.GetDB().DB()
+		sqlDB.SetMaxOpenConns(1)
+		participant := threshold.NewParticipant(share, verifier)
+		api := threshold.NewParticipantAPI(test.NewLogger(), participant, ormInstance, []byte(testSecret))
+		engine := gin.New()
+		engine.Use(api.GlobalMiddlewares()...)
+		api.Routes(&engine.RouterGroup)
+		server := httptest.NewServer(engine)
+		t.Cleanup(server.Close)
+		participants[i] = &testParticipant{participant: participant, orm: ormInstance, server: server}
+	}
+	return participants
+}
+
+func newTestSigner(t *testing.T, participants []*testParticipant, secret string) *threshold.Signer {
+	privateKey, _ := dlccrypto.NewPrivateKey(testPrivateKey)
+	publicKey, _ := godlccrypto.NewGoCryptoService().SchnorrPublicKeyFromPrivateKey(privateKey)
+	config := &threshold.Config{
+		Enabled:      true,
+		PublicKey:    publicKey.EncodeToString(),
+		Participants: make(map[string]threshold.ParticipantConfig),
+		Timeout:      5 * time.Second,
+	}
+	for i, participant := range participants {
+		config.Participants["participant"+strconv.Itoa(i+1)] = threshold.ParticipantConfig{
+			URL:    participant.server.URL,
+			Secret: secret,
+		}
+	}
+	signer, err := threshold.NewSigner(config)
+	require.NoError(t, err)
+	return signer
+}
+
+func announce(t *testing.T, signer *threshold.Signer, nbNonces int) ([]dlccrypto.SchnorrPublicKey, []byte) {
+	nonces := make([]dlccrypto.SchnorrPublicKey, nbNonces)
+	for i := range nonces {
+		nonce, err := signer.DeriveSchnorrNonce(testAssetID, testMaturity, i)
+		require.NoError(t, err)
+		nonces[i] = *nonce
+	}
+	eventID := testAssetID + entity.EventIDSeparator + strconv.FormatUint(uint64(testMaturity), 10)
+	event := dlccrypto.SerializeEvent(nonces, testMaturity, 10, false, "usd/btc", 0, uint16(nbNonces), eventID)
+	return nonces, event
+}
+
+func TestSigner_SignsAnnouncementAndOutcome_SignaturesAreValid(t *testing.T) {
+	cryptoService := godlccrypto.NewGoCryptoService()
+	participants := newTestParticipants(t, 2, 3, &testVerifier{outcomes: []string{"1", "2"}})
+	signer := newTestSigner(t, participants, testSecret)
+	nonces, event := announce(t, signer, 2)
+
+	announcementSig, err := signer.ComputeSchnorrSignature(event)
+	require.NoError(t, err)
+	valid, err := cryptoService.VerifySchnorrSignatureRaw(signer.PublicKey(), announcementSig, event)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+
+	for i, outcome := range []string{"1", "2"} {
+		sig, err := signer.ComputeSchnorrSignatureWithNonce(
+			&dlccrypto.EventNonce{AssetID: testAssetID, EventMaturity: testMaturity, Index: i}, outcome)
+		require.NoError(t, err)
+		valid, err := cryptoService.VerifySchnorrSignature(signer.PublicKey(), sig, outcome)
+		assert.NoError(t, err)
+		assert.True(t, valid)
+		// the signature is made with the announced nonce
+		assert.Equal(t, nonces[i].EncodeToString(), sig.EncodeToString()[:64])
+	}
+}
+
+func TestSigner_ParticipantUnavailable_SignsWithOtherParticipants(t *testing.T) {
+	cryptoService := godlccrypto.NewGoCryptoService()
+	participants := newTestParticipants(t, 2, 3, &testVerifier{outcomes: []string{"1"}})
+	signer := newTestSigner(t, participants, testSecret)
+	nonces, event := announce(t, signer, 1)
+
+	participants[0].server.Close()
+
+	announcementSig, err := signer.ComputeSchnorrSignature(event)
+	require.NoError(t, err)
+	valid, _ := cryptoService.VerifySchnorrSignatureRaw(signer.PublicKey(), announcementSig, event)
+	assert.True(t, valid)
+	sig, err := signer.ComputeSchnorrSignatureWithNonce(
+		&dlccrypto.EventNonce{AssetID: testAssetID, EventMaturity: testMaturity, Index: 0}, "1")
+	require.NoError(t, err)
+	valid, _ = cryptoService.VerifySchnorrSignature(signer.PublicKey(), sig, "1")
+	assert.True(t, valid)
+	assert.Equal(t, nonces[0].EncodeToString(), sig.EncodeToString()[:64])
+
+	participants[1].server.Close()
+	_, err = signer.ComputeSchnorrSignatureWithNonce(
+		&dlccrypto.EventNonce{AssetID: testAssetID, EventMaturity: testMaturity, Index: 0}, "1")
+	assert.Error(t, err)
+}
+
+func TestSigner_OutcomeRejectedByParticipants_ReturnsError(t *testing.T) {
+	participants := newTestParticipants(t, 2, 3, &testVerifier{outcomes: []string{"1"}})
+	signer := newTestSigner(t, participants, testSecret)
+	announce(t, signer, 1)
+
+	_, err := signer.ComputeSchnorrSignatureWithNonce(
+		&dlccrypto.EventNonce{AssetID: testAssetID, EventMaturity: testMaturity, Index: 0}, "2")
+
+	assert.Error(t, err)
+}
+
+func TestSigner_AnnouncementWithUnrecordedNonces_ReturnsError(t *testing.T) {
+	participants := newTestParticipants(t, 2, 3, &testVerifier{outcomes: []string{}})
+	signer := newTestSigner(t, participants, testSecret)
+	nonces, _ := announce(t, signer, 1)
+	// a nonce which was not derived through the participants
+	other, _ := dlccrypto.NewSchnorrPublicKey(signer.PublicKey().EncodeToString())
+	eventID := testAssetID + entity.EventIDSeparator + strconv.FormatUint(uint64(testMaturity), 10)
+	event := dlccrypto.SerializeEvent([]dlccrypto.SchnorrPublicKey{nonces[0], *other}, testMaturity, 10, false, "usd/btc", 0, 2, eventID)
+
+	_, err := signer.ComputeSchnorrSignature(event)
+
+	assert.Error(t, err)
+}
+
+func TestSigner_InvalidSecret_ReturnsError(t *testing.T) {
+	participants := newTestParticipants(t, 2, 3, &testVerifier{})
+	signer := newTestSigner(t, participants, "invalid-secret")
+
+	_, err := signer.DeriveSchnorrNonce(testAssetID, testMaturity, 0)
+
+	assert.Error(t, err)
+}
+
+func TestParticipant_Sign_OtherMessageWithSameNonce_ReturnsConflict(t *testing.T) {
+	participants := newTestParticipants(t, 2, 3, &testVerifier{})
+	id := threshold.EventNonceID(testAssetID, testMaturity, 0)
+	commitment := threshold.NonceCommitment{ID: id, Signers: []int{1, 2}}
+	for _, participant := range participants[:2] {
+		shares, err := participant.participant.NonceShares([]threshold.NonceID{id})
+		require.NoError(t, err)
+		commitment.Shares = append(commitment.Shares, shares[0])
+	}
+	p := participants[0]
+
+	first, err := p.participant.Sign(p.orm.GetDB(), &threshold.SignRequest{Nonce: commitment, Message: []byte("1")})
+	require.NoError(t, err)
+	again, err := p.participant.Sign(p.orm.GetDB(), &threshold.SignRequest{Nonce: commitment, Message: []byte("1")})
+	require.NoError(t, err)
+	assert.Equal(t, first, again)
+
+	_, err = p.participant.Sign(p.orm.GetDB(), &threshold.SignRequest{Nonce: commitment, Message: []byte("2")})
+	assert.True(t, errors.Is(err, threshold.ErrNonceConflict))
+}
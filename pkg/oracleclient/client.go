@@ -0,0 +1,95 @@
+package oracleclient
+
+import (
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+)
+
+const (
+	routeEventAnnouncement = "/event/{eventId}/announcement"
+	routeEventAttestation  = "/event/{eventId}/attestation"
+
+	defaultTimeout = 10 * time.Second
+)
+
+// ErrorResponse represents an error response from the oracle
+type ErrorResponse struct {
+	ErrorCode int    `json:"errorCode"`
+	Message   string `json:"message"`
+	Cause     string `json:"cause,omitempty"`
+}
+
+// NewClient returns a client retrieving the events of the oracle served at the given base url
+// (e.g. https://oracle.example.com)
+func NewClient(baseURL string) *Client {
+	httpClient := resty.New()
+	httpClient.SetHostURL(baseURL)
+	httpClient.SetHeader("Accept", "application/json")
+	httpClient.SetTimeout(defaultTimeout)
+	return &Client{httpClient: httpClient}
+}
+
+// Client retrieves the announcements and attestations of an oracle and verifies them
+type Client struct {
+	httpClient *resty.Client
+}
+
+// SetTimeout sets the timeout of the requests to the oracle
+func (c *Client) SetTimeout(timeout time.Duration) {
+	c.httpClient.SetTimeout(timeout)
+}
+
+// GetAnnouncement returns the announcement of the event with the given ID (without verifying it)
+func (c *Client) GetAnnouncement(eventID string) (*Announcement, error) {
+	announcement := &Announcement{}
+	if err := c.get(routeEventAnnouncement, eventID, announcement); err != nil {
+		return nil, err
+	}
+	return announcement, nil
+}
+
+// GetAttestation returns the attestation of the event with the given ID (without verifying it)
+func (c *Client) GetAttestation(eventID string) (*Attestation, error) {
+	attestation := &Attestation{}
+	if err := c.get(routeEventAttestation, eventID, attestation); err != nil {
+		return nil, err
+	}
+	return attestation, nil
+}
+
+// GetVerifiedOutcome retrieves the announcement and attestation of the event with the given ID and returns its outcome,
+// after checking that the event was announced by the oracle with the given public key (see VerifyAttestation)
+func (c *Client) GetVerifiedOutcome(eventID string, oraclePublicKey string) (*Outcome, error) {
+	announcement, err := c.GetAnnouncement(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if announcement.OraclePublicKey != oraclePublicKey {
+		return nil, errors.WithMessagef(ErrInvalidAnnouncement, "The event was announced with the oracle key %s", announcement.OraclePublicKey)
+	}
+	attestation, err := c.GetAttestation(eventID)
+	if err != nil {
+		return nil, err
+	}
+	return VerifyAttestation(announcement, attestation)
+}
+
+func (c *Client) get(route string, eventID string, result interface{}) error {
+	resp, err := c.httpClient.R().
+		SetPathParams(map[string]string{"eventId": eventID}).
+		SetResult(result).
+		SetError(&ErrorResponse{}).
+		Get(route)
+	if err != nil {
+		return errors.WithMessage(err, "Request to the oracle failed")
+	}
+	if resp.IsError() {
+		if errResponse, ok := resp.Error().(*ErrorResponse); ok && errResponse.Message != "" {
+			return errors.Errorf("The oracle returned an error (%d): %s", resp.StatusCode(), errResponse.Message)
+		}
+		return errors.Errorf("The oracle returned an error (%d)", resp.StatusCode())
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package oracleclient_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"p2pderivatives-oracle/pkg/oracleclient"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestOracleServer(announcement *oracleclient.Announcement, attestation *oracleclient.Attestation) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/event/"+announcement.OracleEvent.EventID+"/announcement", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(announcement)
+	})
+	mux.HandleFunc("/event/"+announcement.OracleEvent.EventID+"/attestation", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(attestation)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(&oracleclient.ErrorResponse{ErrorCode: 3, Message: "Could not find the specified record"})
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestClient_GetVerifiedOutcome_ReturnsOutcome(t *testing.T) {
+	announcement, attestation := newTestNumericEvent(t, 9876, 2, 20, false, 0)
+	server := newTestOracleServer(announcement, attestation)
+	defer server.Close()
+	client := oracleclient.NewClient(server.URL)
+
+	outcome, err := client.GetVerifiedOutcome(announcement.OracleEvent.EventID, announcement.OraclePublicKey)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(9876), outcome.Value)
+}
+
+func TestClient_GetVerifiedOutcome_OtherOracleKey_ReturnsError(t *testing.T) {
+	announcement, attestation := newTestNumericEvent(t, 9876, 2, 20, false, 0)
+	server := newTestOracleServer(announcement, attestation)
+	defer server.Close()
+	client := oracleclient.NewClient(server.URL)
+
+	_, err := client.GetVerifiedOutcome(announcement.OracleEvent.EventID, announcement.OracleEvent.Nonces[0])
+
+	assert.True(t, errors.Is(err, oracleclient.ErrInvalidAnnouncement), err)
+}
+
+func TestClient_GetAttestation_UnknownEvent_ReturnsError(t *testing.T) {
+	announcement, attestation := newTestNumericEvent(t, 9876, 2, 20, false, 0)
+	server := newTestOracleServer(announcement, attestation)
+	defer server.Close()
+	client := oracleclient.NewClient(server.URL)
+
+	_, err := client.GetAttestation("btcusd-1")
+
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Could not find the specified record")
+	}
+}
@@ -0,0 +1,57 @@
+package oracleclient
+
+// DigitDecompositionDescriptor contains information about a numerical event.
+type DigitDecompositionDescriptor struct {
+	Base      int    `json:"base"`
+	IsSigned  bool   `json:"isSigned"`
+	Unit      string `json:"unit"`
+	Precision int    `json:"precision"`
+	NbDigits  int    `json:"nbDigits"`
+}
+
+// EnumEventDescriptor contains information about an enumerable event.
+type EnumEventDescriptor struct {
+	Outcomes []string `json:"outcomes"`
+}
+
+// EventDescriptor can contain information about either an enumerable event
+// or a numerical event (only one of the fields is set).
+type EventDescriptor struct {
+	DigitDecompositionDescriptor *DigitDecompositionDescriptor `json:"digitDecompositionEvent,omitempty"`
+	EnumEventDescriptor          *EnumEventDescriptor          `json:"enumEvent,omitempty"`
+}
+
+// Event contains information about an event
+type Event struct {
+	Nonces             []string        `json:"oracleNonces"`
+	EventMaturityEpoch int64           `json:"eventMaturityEpoch"`
+	EventDescriptor    EventDescriptor `json:"eventDescriptor"`
+	EventID            string          `json:"eventId"`
+}
+
+// Announcement contains information about an event and a signature of the oracle over the event
+// (as returned by the announcement routes of the oracle)
+type Announcement struct {
+	AnnouncementSignature string `json:"announcementSignature"`
+	OraclePublicKey       string `json:"oraclePublicKey"`
+	OracleEvent           Event  `json:"oracleEvent"`
+}
+
+// Attestation contains the outcome of an event and the signatures of the oracle over its values
+// (as returned by the attestation routes of the oracle)
+type Attestation struct {
+	EventID    string   `json:"eventId"`
+	Signatures []string `json:"signatures"`
+	Values     []string `json:"values"`
+}
+
+// Outcome is the outcome of an event reconstructed from a verified attestation
+type Outcome struct {
+	EventID string   `json:"eventId"`
+	Values  []string `json:"values"`
+	// Outcome the outcome of an enumerated event, or the decimal representation of the outcome of a numerical event
+	Outcome string `json:"outcome"`
+	// Value the integer represented by the digits of a numerical event, its outcome being Value * 10^Precision
+	Value     int64 `json:"value"`
+	Precision int   `json:"precision"`
+}
@@ -0,0 +1,195 @@
+package oracleclient
+
+import (
+	"math"
+	"math/big"
+	"p2pderivatives-oracle/internal/dlccrypto"
+	"p2pderivatives-oracle/internal/godlccrypto"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrInvalidAnnouncement is returned when an announcement is malformed or its signature is invalid
+	ErrInvalidAnnouncement = errors.New("Invalid announcement")
+	// ErrInvalidAttestation is returned when an attestation is malformed, does not match its announcement
+	// or one of its signatures is invalid
+	ErrInvalidAttestation = errors.New("Invalid attestation")
+)
+
+// the signatures are verified in pure go so that the library can be used without cgo
+var cryptoService = godlccrypto.NewGoCryptoService()
+
+// VerifyAnnouncement checks the signature of the oracle over the serialized event of the announcement
+// (the caller still has to check that the oracle public key is the one of the expected oracle)
+func VerifyAnnouncement(announcement *Announcement) error {
+	_, _, err := parseAnnouncement(announcement)
+	return err
+}
+
+// VerifyAttestation checks the announcement and the signature of each value of the attestation against
+// the announced nonce of its index, and returns the outcome reconstructed from the values
+func VerifyAttestation(announcement *Announcement, attestation *Attestation) (*Outcome, error) {
+	publicKey, nonces, err := parseAnnouncement(announcement)
+	if err != nil {
+		return nil, err
+	}
+	event := &announcement.OracleEvent
+	if attestation.EventID != event.EventID {
+		return nil, errors.WithMessagef(ErrInvalidAttestation, "Event ID %s does not match the announced event %s", attestation.EventID, event.EventID)
+	}
+	if len(attestation.Signatures) != len(nonces) || len(attestation.Values) != len(nonces) {
+		return nil, errors.WithMessagef(ErrInvalidAttestation, "%d signatures and values are required", len(nonces))
+	}
+
+	for i, encoded := range attestation.Signatures {
+		signature, err := dlccrypto.NewSignature(encoded)
+		if err != nil {
+			return nil, errors.WithMessagef(ErrInvalidAttestation, "Signature %d: %v", i, err)
+		}
+		// the signature has to be made with the nonce announced for the value
+		if !strings.EqualFold(encoded[:64], nonces[i].EncodeToString()) {
+			return nil, errors.WithMessagef(ErrInvalidAttestation, "Signature %d is not made with the announced nonce", i)
+		}
+		valid, err := cryptoService.VerifySchnorrSignature(publicKey, signature, attestation.Values[i])
+		if err != nil || !valid {
+			return nil, errors.WithMessagef(ErrInvalidAttestation, "Signature %d of value %q is invalid", i, attestation.Values[i])
+		}
+	}
+
+	outcome := &Outcome{
+		EventID: event.EventID,
+		Values:  attestation.Values,
+	}
+	if enum := event.EventDescriptor.EnumEventDescriptor; enum != nil {
+		if !contains(enum.Outcomes, attestation.Values[0]) {
+			return nil, errors.WithMessagef(ErrInvalidAttestation, "%q is not one of the event outcomes", attestation.Values[0])
+		}
+		outcome.Outcome = attestation.Values[0]
+		return outcome, nil
+	}
+	descriptor := event.EventDescriptor.DigitDecompositionDescriptor
+	value, err := composeValue(attestation.Values, descriptor.Base, descriptor.IsSigned)
+	if err != nil {
+		return nil, err
+	}
+	outcome.Value = value
+	outcome.Precision = descriptor.Precision
+	outcome.Outcome = formatValue(value, descriptor.Precision)
+	return outcome, nil
+}
+
+// parseAnnouncement checks the announcement and returns its oracle public key and nonces
+func parseAnnouncement(announcement *Announcement) (*dlccrypto.SchnorrPublicKey, []dlccrypto.SchnorrPublicKey, error) {
+	publicKey, err := dlccrypto.NewSchnorrPublicKey(announcement.OraclePublicKey)
+	if err != nil {
+		return nil, nil, errors.WithMessagef(ErrInvalidAnnouncement, "Oracle public key: %v", err)
+	}
+	signature, err := dlccrypto.NewSignature(announcement.AnnouncementSignature)
+	if err != nil {
+		return nil, nil, errors.WithMessagef(ErrInvalidAnnouncement, "Announcement signature: %v", err)
+	}
+	event := &announcement.OracleEvent
+	nonces := make([]dlccrypto.SchnorrPublicKey, len(event.Nonces))
+	for i, n := range event.Nonces {
+		nonce, err := dlccrypto.NewSchnorrPublicKey(n)
+		if err != nil {
+			return nil, nil, errors.WithMessagef(ErrInvalidAnnouncement, "Nonce %d: %v", i, err)
+		}
+		nonces[i] = *nonce
+	}
+	if event.EventMaturityEpoch < 0 || event.EventMaturityEpoch > math.MaxUint32 {
+		return nil, nil, errors.WithMessagef(ErrInvalidAnnouncement, "Invalid event maturity %d", event.EventMaturityEpoch)
+	}
+
+	serialized, err := serializeEvent(event, nonces)
+	if err != nil {
+		return nil, nil, err
+	}
+	valid, err := cryptoService.VerifySchnorrSignatureRaw(publicKey, signature, serialized)
+	if err != nil || !valid {
+		return nil, nil, errors.WithMessage(ErrInvalidAnnouncement, "The announcement signature is invalid")
+	}
+	return publicKey, nonces, nil
+}
+
+// serializeEvent serializes the event as signed by the oracle, checking that its descriptor matches its nonces
+func serializeEvent(event *Event, nonces []dlccrypto.SchnorrPublicKey) ([]byte, error) {
+	maturity := uint32(event.EventMaturityEpoch)
+	digits := event.EventDescriptor.DigitDecompositionDescriptor
+	enum := event.EventDescriptor.EnumEventDescriptor
+	switch {
+	case enum != nil && digits == nil:
+		if len(nonces) != 1 || len(enum.Outcomes) == 0 {
+			return nil, errors.WithMessage(ErrInvalidAnnouncement, "An enumerated event has a single nonce and at least one outcome")
+		}
+		return dlccrypto.SerializeEnumEvent(nonces, maturity, enum.Outcomes, event.EventID), nil
+	case digits != nil && enum == nil:
+		nbNonces := digits.NbDigits
+		if digits.IsSigned {
+			nbNonces++
+		}
+		if digits.Base < 2 || digits.NbDigits < 1 || len(nonces) != nbNonces {
+			return nil, errors.WithMessage(ErrInvalidAnnouncement, "The digit decomposition descriptor does not match the nonces")
+		}
+		return dlccrypto.SerializeEvent(
+			nonces, maturity, uint16(digits.Base), digits.IsSigned, digits.Unit,
+			int32(digits.Precision), uint16(digits.NbDigits), event.EventID), nil
+	default:
+		return nil, errors.WithMessage(ErrInvalidAnnouncement, "The event should have either an enum or a digit decomposition descriptor")
+	}
+}
+
+// composeValue returns the integer represented by the digits (preceded by a sign if the event is signed)
+func composeValue(values []string, base int, isSigned bool) (int64, error) {
+	sign := int64(1)
+	if isSigned {
+		switch values[0] {
+		case dlccrypto.PositiveSign:
+		case dlccrypto.NegativeSign:
+			sign = -1
+		default:
+			return 0, errors.WithMessagef(ErrInvalidAttestation, "Invalid sign %q", values[0])
+		}
+		values = values[1:]
+	}
+	value := int64(0)
+	for _, v := range values {
+		digit, err := strconv.Atoi(v)
+		if err != nil || digit < 0 || digit >= base {
+			return 0, errors.WithMessagef(ErrInvalidAttestation, "Invalid digit %q", v)
+		}
+		if value > (math.MaxInt64-int64(digit))/int64(base) {
+			return 0, errors.WithMessage(ErrInvalidAttestation, "The outcome value overflows")
+		}
+		value = value*int64(base) + int64(digit)
+	}
+	return sign * value, nil
+}
+
+// formatValue returns the exact decimal representation of value * 10^precision
+func formatValue(value int64, precision int) string {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(absInt(precision))), nil)
+	if precision >= 0 {
+		return new(big.Int).Mul(big.NewInt(value), scale).String()
+	}
+	return new(big.Rat).SetFrac(big.NewInt(value), scale).FloatString(-precision)
+}
+
+func absInt(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,206 @@
+package oracleclient_test
+
+import (
+	"p2pderivatives-oracle/internal/dlccrypto"
+	"p2pderivatives-oracle/internal/godlccrypto"
+	"p2pderivatives-oracle/pkg/oracleclient"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testPrivateKey = "c251ebf21fcf41e4875ddfc0a02e5ae849e847b3f528ae0413363f47d2c02e66"
+	testMaturity   = uint32(1600000000)
+)
+
+func newTestSigner() dlccrypto.Signer {
+	cryptoService := godlccrypto.NewGoCryptoService()
+	privateKey, _ := dlccrypto.NewPrivateKey(testPrivateKey)
+	publicKey, _ := cryptoService.SchnorrPublicKeyFromPrivateKey(privateKey)
+	return dlccrypto.NewKeySigner(privateKey, publicKey, cryptoService)
+}
+
+// newTestNumericEvent returns the announcement and attestation of a numerical event attesting value
+func newTestNumericEvent(t *testing.T, value float64, base int, nbDigits int, isSigned bool, precision int) (*oracleclient.Announcement, *oracleclient.Attestation) {
+	signer := newTestSigner()
+	eventID := "btcusd-1600000000"
+	nbNonces := nbDigits
+	if isSigned {
+		nbNonces++
+	}
+	nonces := make([]dlccrypto.SchnorrPublicKey, nbNonces)
+	eventNonces := make([]dlccrypto.EventNonce, nbNonces)
+	encodedNonces := make([]string, nbNonces)
+	for i := range nonces {
+		nonce, err := signer.DeriveSchnorrNonce("btcusd", testMaturity, i)
+		require.NoError(t, err)
+		nonces[i] = *nonce
+		eventNonces[i] = dlccrypto.EventNonce{AssetID: "btcusd", EventMaturity: testMaturity, Index: i}
+		encodedNonces[i] = nonce.EncodeToString()
+	}
+	event := dlccrypto.SerializeEvent(nonces, testMaturity, uint16(base), isSigned, "usd/btc", int32(precision), uint16(nbDigits), eventID)
+	announcementSig, err := signer.ComputeSchnorrSignature(event)
+	require.NoError(t, err)
+	sigs, values, err := dlccrypto.GetRoundedDecomposedSignaturesForValue(value, base, nbDigits, isSigned, precision, signer, eventNonces)
+	require.NoError(t, err)
+
+	announcement := &oracleclient.Announcement{
+		AnnouncementSignature: announcementSig.EncodeToString(),
+		OraclePublicKey:       signer.PublicKey().EncodeToString(),
+		OracleEvent: oracleclient.Event{
+			Nonces:             encodedNonces,
+			EventMaturityEpoch: int64(testMaturity),
+			EventDescriptor: oracleclient.EventDescriptor{
+				DigitDecompositionDescriptor: &oracleclient.DigitDecompositionDescriptor{
+					Base:      base,
+					IsSigned:  isSigned,
+					Unit:      "usd/btc",
+					Precision: precision,
+					NbDigits:  nbDigits,
+				},
+			},
+			EventID: eventID,
+		},
+	}
+	return announcement, &oracleclient.Attestation{EventID: eventID, Signatures: sigs, Values: values}
+}
+
+func newTestEnumEvent(t *testing.T, outcomes []string, outcome string) (*oracleclient.Announcement, *oracleclient.Attestation) {
+	signer := newTestSigner()
+	eventID := "btcusd50k-1600000000"
+	nonce, err := signer.DeriveSchnorrNonce("btcusd50k", testMaturity, 0)
+	require.NoError(t, err)
+	event := dlccrypto.SerializeEnumEvent([]dlccrypto.SchnorrPublicKey{*nonce}, testMaturity, outcomes, eventID)
+	announcementSig, err := signer.ComputeSchnorrSignature(event)
+	require.NoError(t, err)
+	sig, err := signer.ComputeSchnorrSignatureWithNonce(&dlccrypto.EventNonce{AssetID: "btcusd50k", EventMaturity: testMaturity}, outcome)
+	require.NoError(t, err)
+
+	announcement := &oracleclient.Announcement{
+		AnnouncementSignature: announcementSig.EncodeToString(),
+		OraclePublicKey:       signer.PublicKey().EncodeToString(),
+		OracleEvent: oracleclient.Event{
+			Nonces:             []string{nonce.EncodeToString()},
+			EventMaturityEpoch: int64(testMaturity),
+			EventDescriptor: oracleclient.EventDescriptor{
+				EnumEventDescriptor: &oracleclient.EnumEventDescriptor{Outcomes: outcomes},
+			},
+			EventID: eventID,
+		},
+	}
+	return announcement, &oracleclient.Attestation{EventID: eventID, Signatures: []string{sig.EncodeToString()}, Values: []string{outcome}}
+}
+
+func TestVerifyAttestation_NumericEvent_ReturnsOutcome(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     float64
+		base      int
+		nbDigits  int
+		isSigned  bool
+		precision int
+		expected  int64
+		outcome   string
+	}{
+		{name: "unsigned base 2", value: 9876, base: 2, nbDigits: 20, expected: 9876, outcome: "9876"},
+		{name: "signed negative", value: -123.45, base: 10, nbDigits: 6, isSigned: true, precision: -2, expected: -12345, outcome: "-123.45"},
+		{name: "positive precision", value: 51234, base: 10, nbDigits: 4, precision: 2, expected: 512, outcome: "51200"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			announcement, attestation := newTestNumericEvent(t, test.value, test.base, test.nbDigits, test.isSigned, test.precision)
+
+			outcome, err := oracleclient.VerifyAttestation(announcement, attestation)
+
+			require.NoError(t, err)
+			assert.Equal(t, announcement.OracleEvent.EventID, outcome.EventID)
+			assert.Equal(t, test.expected, outcome.Value)
+			assert.Equal(t, test.precision, outcome.Precision)
+			assert.Equal(t, test.outcome, outcome.Outcome)
+		})
+	}
+}
+
+func TestVerifyAttestation_EnumEvent_ReturnsOutcome(t *testing.T) {
+	announcement, attestation := newTestEnumEvent(t, []string{"below", "above"}, "above")
+
+	outcome, err := oracleclient.VerifyAttestation(announcement, attestation)
+
+	require.NoError(t, err)
+	assert.Equal(t, "above", outcome.Outcome)
+}
+
+func TestVerifyAnnouncement_InvalidAnnouncement_ReturnsError(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(a *oracleclient.Announcement)
+	}{
+		{name: "other descriptor", modify: func(a *oracleclient.Announcement) {
+			a.OracleEvent.EventDescriptor.DigitDecompositionDescriptor.Unit = "usd"
+		}},
+		{name: "other maturity", modify: func(a *oracleclient.Announcement) { a.OracleEvent.EventMaturityEpoch++ }},
+		{name: "other event id", modify: func(a *oracleclient.Announcement) { a.OracleEvent.EventID = "btcjpy-1600000000" }},
+		{name: "swapped nonces", modify: func(a *oracleclient.Announcement) {
+			a.OracleEvent.Nonces[0], a.OracleEvent.Nonces[1] = a.OracleEvent.Nonces[1], a.OracleEvent.Nonces[0]
+		}},
+		{name: "nonces not matching the digits", modify: func(a *oracleclient.Announcement) {
+			a.OracleEvent.EventDescriptor.DigitDecompositionDescriptor.NbDigits++
+		}},
+		{name: "other public key", modify: func(a *oracleclient.Announcement) { a.OraclePublicKey = a.OracleEvent.Nonces[0] }},
+		{name: "no descriptor", modify: func(a *oracleclient.Announcement) {
+			a.OracleEvent.EventDescriptor.DigitDecompositionDescriptor = nil
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			announcement, _ := newTestNumericEvent(t, 10, 2, 4, false, 0)
+			assert.NoError(t, oracleclient.VerifyAnnouncement(announcement))
+
+			test.modify(announcement)
+
+			err := oracleclient.VerifyAnnouncement(announcement)
+			assert.True(t, errors.Is(err, oracleclient.ErrInvalidAnnouncement), err)
+		})
+	}
+}
+
+func TestVerifyAttestation_InvalidAttestation_ReturnsError(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(a *oracleclient.Attestation)
+	}{
+		{name: "other value", modify: func(a *oracleclient.Attestation) { a.Values[3] = "0" }},
+		{name: "swapped signatures", modify: func(a *oracleclient.Attestation) {
+			a.Signatures[0], a.Signatures[1] = a.Signatures[1], a.Signatures[0]
+			a.Values[0], a.Values[1] = a.Values[1], a.Values[0]
+		}},
+		{name: "missing value", modify: func(a *oracleclient.Attestation) {
+			a.Signatures = a.Signatures[1:]
+			a.Values = a.Values[1:]
+		}},
+		{name: "other event", modify: func(a *oracleclient.Attestation) { a.EventID = "btcusd-1600000001" }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// 5 = 0101
+			announcement, attestation := newTestNumericEvent(t, 5, 2, 4, false, 0)
+
+			test.modify(attestation)
+
+			_, err := oracleclient.VerifyAttestation(announcement, attestation)
+			assert.True(t, errors.Is(err, oracleclient.ErrInvalidAttestation), err)
+		})
+	}
+}
+
+func TestVerifyAttestation_EnumOutcomeNotAnnounced_ReturnsError(t *testing.T) {
+	// the oracle signed an outcome which is not one of the announced outcomes
+	announcement, attestation := newTestEnumEvent(t, []string{"below", "above"}, "unknown")
+
+	_, err := oracleclient.VerifyAttestation(announcement, attestation)
+
+	assert.True(t, errors.Is(err, oracleclient.ErrInvalidAttestation), err)
+}
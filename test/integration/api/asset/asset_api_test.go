@@ -198,7 +198,7 @@ func assertSignature(assertSub *assert.Assertions, sigsHex []string, messages []
 		sig, err := dlccrypto.NewSignature(sigsHex[i])
 		assertSub.NoError(err)
 		isValidSignature, err := cfddlccrypto.NewCfdgoCryptoService().VerifySchnorrSignature(
-			helper.ExpectedOracle.PublicKey,
+			helper.ExpectedOracle.PublicKey(),
 			sig,
 			messages[i])
 		assertSub.NoError(err)
@@ -206,7 +206,7 @@ func assertSignature(assertSub *assert.Assertions, sigsHex []string, messages []
 			isValidSignature,
 			"Signature %v does not match using oracle public key: %s rvalue:　%s message: %s",
 			sig,
-			helper.ExpectedOracle.PublicKey.EncodeToString(),
+			helper.ExpectedOracle.PublicKey().EncodeToString(),
 			sigsHex[i])
 	}
 
@@ -34,5 +34,5 @@ func TestGetOraclePublicKey_Returns_CorrectValue(t *testing.T) {
 	assert.NoError(t, err)
 	actualPubkey, err := dlccrypto.NewSchnorrPublicKey(actual.PublicKey)
 	assert.NoError(t, err)
-	assert.Equal(t, helper.ExpectedOracle.PublicKey, actualPubkey)
+	assert.Equal(t, helper.ExpectedOracle.PublicKey(), actualPubkey)
 }
@@ -0,0 +1,130 @@
+// +build integration
+
+package replicas_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"p2pderivatives-oracle/internal/api"
+	"p2pderivatives-oracle/internal/cfddlccrypto"
+	"p2pderivatives-oracle/internal/datafeed"
+	"p2pderivatives-oracle/internal/lock"
+	helper "p2pderivatives-oracle/test/integration"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cryptogarageinc/server-common-go/pkg/database/orm"
+	"github.com/cryptogarageinc/server-common-go/pkg/log"
+	"github.com/gin-gonic/gin"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// number of oracle servers sharing the database
+const nbReplicas = 3
+
+func TestMain(m *testing.M) {
+	helper.InitHelper()
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+// newReplica starts an oracle server using its own connections to the integration database
+// (migrated by the integration oracle server) and returning the given price
+func newReplica(t *testing.T, price float64) *httptest.Server {
+	logConfig := &log.Config{}
+	require.NoError(t, helper.Config.InitializeComponentConfig(logConfig))
+	logger := log.NewLog(logConfig)
+	require.NoError(t, logger.Initialize())
+	logger.Logger.SetOutput(ioutil.Discard)
+
+	ormConfig := &orm.Config{}
+	require.NoError(t, helper.Config.InitializeComponentConfig(ormConfig))
+	ormInstance := orm.NewORM(ormConfig, logger)
+	require.NoError(t, ormInstance.Initialize())
+	t.Cleanup(func() { ormInstance.Finalize() })
+
+	locker, err := lock.NewLocker(&lock.Config{Provider: lock.ProviderPostgres, Timeout: 30 * time.Second}, ormInstance.GetDB())
+	require.NoError(t, err)
+
+	feed := datafeed.NewDummyDataFeed(&datafeed.DummyConfig{ReturnValue: price})
+	oracleAPI := api.NewOracleAPI(helper.APIConfig, logger, helper.ExpectedOracle, ormInstance, cfddlccrypto.NewCfdgoCryptoService(), feed)
+	oracleAPI.SetLocker(locker)
+
+	engine := gin.New()
+	engine.Use(oracleAPI.GlobalMiddlewares()...)
+	oracleAPI.Routes(engine.Group(""))
+	server := httptest.NewServer(engine)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func getRoute(route string, assetID string, date time.Time) string {
+	route = api.AssetBaseRoute + "/" + assetID + route
+	return strings.Replace(route, ":"+api.URLParamTagTime, date.Format(api.TimeFormatISO8601), 1)
+}
+
+// getConcurrently sends the request to every server at the same time, each server receiving it several times
+func getConcurrently(t *testing.T, servers []*httptest.Server, route string, newResult func() interface{}) []interface{} {
+	const nbRequestsPerServer = 3
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	var mut sync.Mutex
+	results := []interface{}{}
+	for _, server := range servers {
+		for i := 0; i < nbRequestsPerServer; i++ {
+			wg.Add(1)
+			go func(url string) {
+				defer wg.Done()
+				result := newResult()
+				client := resty.New().SetHostURL(url).SetHeader("Accept", "application/json")
+				<-start
+				resp, err := client.R().SetResult(result).Get(route)
+				if assert.NoError(t, err) && assert.Equal(t, http.StatusOK, resp.StatusCode(), resp.String()) {
+					mut.Lock()
+					results = append(results, result)
+					mut.Unlock()
+				}
+			}(server.URL)
+		}
+	}
+	close(start)
+	wg.Wait()
+	return results
+}
+
+func TestReplicas_SameEvent_ReturnSameAnnouncementAndAttestation(t *testing.T) {
+	// each replica would attest another value if it signed the event itself
+	servers := make([]*httptest.Server, nbReplicas)
+	for i := range servers {
+		servers[i] = newReplica(t, float64(9000+i))
+	}
+	// past events which were most probably never requested
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	for assetID, config := range helper.APIConfig.AssetConfigs {
+		publishDate := config.StartDate.Add(time.Duration(r.Int63n(10000)) * config.Frequency)
+		t.Run(fmt.Sprintf("asset %s at %s", assetID, publishDate.Format(api.TimeFormatISO8601)), func(t *testing.T) {
+			announcements := getConcurrently(t, servers, getRoute(api.RouteGETAssetAnnouncement, assetID, publishDate),
+				func() interface{} { return &api.OracleAnnouncement{} })
+			require.Len(t, announcements, nbReplicas*3)
+			for _, announcement := range announcements[1:] {
+				assert.Equal(t, announcements[0], announcement)
+			}
+
+			attestations := getConcurrently(t, servers, getRoute(api.RouteGETAssetAttestation, assetID, publishDate),
+				func() interface{} { return &api.OracleAttestation{} })
+			require.Len(t, attestations, nbReplicas*3)
+			for _, attestation := range attestations[1:] {
+				assert.Equal(t, attestations[0], attestation)
+			}
+		})
+	}
+}
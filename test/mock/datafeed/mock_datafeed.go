@@ -5,6 +5,7 @@
 package mock_datafeed
 
 import (
+	datafeed "p2pderivatives-oracle/internal/datafeed"
 	reflect "reflect"
 	time "time"
 
@@ -64,6 +65,51 @@ func (mr *MockDataFeedMockRecorder) FindPastAssetPrice(assetID, date interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPastAssetPrice", reflect.TypeOf((*MockDataFeed)(nil).FindPastAssetPrice), assetID, date)
 }
 
+// FindPastAssetPriceRecord mocks base method.
+func (m *MockDataFeed) FindPastAssetPriceRecord(assetID string, date time.Time) (*datafeed.PriceRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPastAssetPriceRecord", assetID, date)
+	ret0, _ := ret[0].(*datafeed.PriceRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPastAssetPriceRecord indicates an expected call of FindPastAssetPriceRecord.
+func (mr *MockDataFeedMockRecorder) FindPastAssetPriceRecord(assetID, date interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPastAssetPriceRecord", reflect.TypeOf((*MockDataFeed)(nil).FindPastAssetPriceRecord), assetID, date)
+}
+
+// FindPastAssetPriceSeries mocks base method.
+func (m *MockDataFeed) FindPastAssetPriceSeries(assetID string, from, to time.Time) ([]datafeed.Candle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPastAssetPriceSeries", assetID, from, to)
+	ret0, _ := ret[0].([]datafeed.Candle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPastAssetPriceSeries indicates an expected call of FindPastAssetPriceSeries.
+func (mr *MockDataFeedMockRecorder) FindPastAssetPriceSeries(assetID, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPastAssetPriceSeries", reflect.TypeOf((*MockDataFeed)(nil).FindPastAssetPriceSeries), assetID, from, to)
+}
+
+// FindPastOutcome mocks base method.
+func (m *MockDataFeed) FindPastOutcome(assetID string, date time.Time, outcomes []string) (*string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPastOutcome", assetID, date, outcomes)
+	ret0, _ := ret[0].(*string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPastOutcome indicates an expected call of FindPastOutcome.
+func (mr *MockDataFeedMockRecorder) FindPastOutcome(assetID, date, outcomes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPastOutcome", reflect.TypeOf((*MockDataFeed)(nil).FindPastOutcome), assetID, date, outcomes)
+}
+
 // MockAssetPriceFeed is a mock of AssetPriceFeed interface.
 type MockAssetPriceFeed struct {
 	ctrl     *gomock.Controller
@@ -116,3 +162,94 @@ func (mr *MockAssetPriceFeedMockRecorder) FindPastAssetPrice(assetID, date inter
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPastAssetPrice", reflect.TypeOf((*MockAssetPriceFeed)(nil).FindPastAssetPrice), assetID, date)
 }
+
+// FindPastAssetPriceRecord mocks base method.
+func (m *MockAssetPriceFeed) FindPastAssetPriceRecord(assetID string, date time.Time) (*datafeed.PriceRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPastAssetPriceRecord", assetID, date)
+	ret0, _ := ret[0].(*datafeed.PriceRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPastAssetPriceRecord indicates an expected call of FindPastAssetPriceRecord.
+func (mr *MockAssetPriceFeedMockRecorder) FindPastAssetPriceRecord(assetID, date interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPastAssetPriceRecord", reflect.TypeOf((*MockAssetPriceFeed)(nil).FindPastAssetPriceRecord), assetID, date)
+}
+
+// MockAssetPriceSeriesFeed is a mock of AssetPriceSeriesFeed interface.
+type MockAssetPriceSeriesFeed struct {
+	ctrl     *gomock.Controller
+	recorder *MockAssetPriceSeriesFeedMockRecorder
+}
+
+// MockAssetPriceSeriesFeedMockRecorder is the mock recorder for MockAssetPriceSeriesFeed.
+type MockAssetPriceSeriesFeedMockRecorder struct {
+	mock *MockAssetPriceSeriesFeed
+}
+
+// NewMockAssetPriceSeriesFeed creates a new mock instance.
+func NewMockAssetPriceSeriesFeed(ctrl *gomock.Controller) *MockAssetPriceSeriesFeed {
+	mock := &MockAssetPriceSeriesFeed{ctrl: ctrl}
+	mock.recorder = &MockAssetPriceSeriesFeedMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAssetPriceSeriesFeed) EXPECT() *MockAssetPriceSeriesFeedMockRecorder {
+	return m.recorder
+}
+
+// FindPastAssetPriceSeries mocks base method.
+func (m *MockAssetPriceSeriesFeed) FindPastAssetPriceSeries(assetID string, from, to time.Time) ([]datafeed.Candle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPastAssetPriceSeries", assetID, from, to)
+	ret0, _ := ret[0].([]datafeed.Candle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPastAssetPriceSeries indicates an expected call of FindPastAssetPriceSeries.
+func (mr *MockAssetPriceSeriesFeedMockRecorder) FindPastAssetPriceSeries(assetID, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPastAssetPriceSeries", reflect.TypeOf((*MockAssetPriceSeriesFeed)(nil).FindPastAssetPriceSeries), assetID, from, to)
+}
+
+// MockOutcomeFeed is a mock of OutcomeFeed interface.
+type MockOutcomeFeed struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutcomeFeedMockRecorder
+}
+
+// MockOutcomeFeedMockRecorder is the mock recorder for MockOutcomeFeed.
+type MockOutcomeFeedMockRecorder struct {
+	mock *MockOutcomeFeed
+}
+
+// NewMockOutcomeFeed creates a new mock instance.
+func NewMockOutcomeFeed(ctrl *gomock.Controller) *MockOutcomeFeed {
+	mock := &MockOutcomeFeed{ctrl: ctrl}
+	mock.recorder = &MockOutcomeFeedMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutcomeFeed) EXPECT() *MockOutcomeFeedMockRecorder {
+	return m.recorder
+}
+
+// FindPastOutcome mocks base method.
+func (m *MockOutcomeFeed) FindPastOutcome(assetID string, date time.Time, outcomes []string) (*string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPastOutcome", assetID, date, outcomes)
+	ret0, _ := ret[0].(*string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPastOutcome indicates an expected call of FindPastOutcome.
+func (mr *MockOutcomeFeedMockRecorder) FindPastOutcome(assetID, date, outcomes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPastOutcome", reflect.TypeOf((*MockOutcomeFeed)(nil).FindPastOutcome), assetID, date, outcomes)
+}
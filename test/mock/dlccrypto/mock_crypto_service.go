@@ -64,6 +64,37 @@ func (mr *MockCryptoServiceMockRecorder) ComputeSchnorrSignatureFixedK(privateKe
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ComputeSchnorrSignatureFixedK", reflect.TypeOf((*MockCryptoService)(nil).ComputeSchnorrSignatureFixedK), privateKey, oneTimeSigningK, message)
 }
 
+// ComputeSigPoint mocks base method.
+func (m *MockCryptoService) ComputeSigPoint(publicKey, nonce *dlccrypto.SchnorrPublicKey, message string) (*dlccrypto.Point, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ComputeSigPoint", publicKey, nonce, message)
+	ret0, _ := ret[0].(*dlccrypto.Point)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ComputeSigPoint indicates an expected call of ComputeSigPoint.
+func (mr *MockCryptoServiceMockRecorder) ComputeSigPoint(publicKey, nonce, message interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ComputeSigPoint", reflect.TypeOf((*MockCryptoService)(nil).ComputeSigPoint), publicKey, nonce, message)
+}
+
+// DeriveSchnorrNonce mocks base method.
+func (m *MockCryptoService) DeriveSchnorrNonce(privateKey *dlccrypto.PrivateKey, assetID string, eventMaturity uint32, index int) (*dlccrypto.PrivateKey, *dlccrypto.SchnorrPublicKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeriveSchnorrNonce", privateKey, assetID, eventMaturity, index)
+	ret0, _ := ret[0].(*dlccrypto.PrivateKey)
+	ret1, _ := ret[1].(*dlccrypto.SchnorrPublicKey)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// DeriveSchnorrNonce indicates an expected call of DeriveSchnorrNonce.
+func (mr *MockCryptoServiceMockRecorder) DeriveSchnorrNonce(privateKey, assetID, eventMaturity, index interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeriveSchnorrNonce", reflect.TypeOf((*MockCryptoService)(nil).DeriveSchnorrNonce), privateKey, assetID, eventMaturity, index)
+}
+
 // GenerateSchnorrKeyPair mocks base method.
 func (m *MockCryptoService) GenerateSchnorrKeyPair() (*dlccrypto.PrivateKey, *dlccrypto.SchnorrPublicKey, error) {
 	m.ctrl.T.Helper()